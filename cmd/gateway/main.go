@@ -6,26 +6,49 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
+	"strings"
 	"syscall"
 	"time"
+	_ "time/tzdata" // Встроенная база часовых поясов для валидации time_zone событий
 
 	"github.com/go-chi/chi/v5"
 
 	pbAuth "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/auth"
 	pbEvent "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/event"
+	pbFavorite "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/favorite"
+	pbRsvp "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/rsvp"
 	pbUser "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/user"
+	"github.com/rx3lixir/gateway-service/pkg/analytics"
+	"github.com/rx3lixir/gateway-service/pkg/blobstore"
+	"github.com/rx3lixir/gateway-service/pkg/broker"
+	"github.com/rx3lixir/gateway-service/pkg/cache"
+	"github.com/rx3lixir/gateway-service/pkg/fakes"
+	"github.com/rx3lixir/gateway-service/pkg/graphql"
 	"github.com/rx3lixir/gateway-service/pkg/health"
+	"github.com/rx3lixir/gateway-service/pkg/history"
+	"github.com/rx3lixir/gateway-service/pkg/httpcache"
+	"github.com/rx3lixir/gateway-service/pkg/i18n"
 	"github.com/rx3lixir/gateway-service/pkg/logger"
+	"github.com/rx3lixir/gateway-service/pkg/middleware"
+	"github.com/rx3lixir/gateway-service/pkg/recent"
+	"github.com/rx3lixir/gateway-service/pkg/savedsearch"
+	"github.com/rx3lixir/gateway-service/pkg/webhook"
 
 	"github.com/rx3lixir/gateway-service/internal/config"
+	"github.com/rx3lixir/gateway-service/internal/handler/adminHandler"
 	"github.com/rx3lixir/gateway-service/internal/handler/authHandler"
 	"github.com/rx3lixir/gateway-service/internal/handler/eventHandler"
+	"github.com/rx3lixir/gateway-service/internal/handler/graphqlHandler"
 	"github.com/rx3lixir/gateway-service/internal/handler/userHandler"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
+// mediaPathPrefix путь раздачи изображений из локального хранилища (см. image_store.base_url)
+const mediaPathPrefix = "/media"
+
 func main() {
 	c, err := config.New()
 	if err != nil {
@@ -50,6 +73,8 @@ func main() {
 		"grpc_event_addr", c.Clients.EventClientAddress,
 		"grpc_auth_addr", c.Clients.AuthClientAddress,
 		"grpc_user_addr", c.Clients.UserClientAddress,
+		"grpc_favorite_addr", c.Clients.FavoriteClientAddress,
+		"grpc_rsvp_addr", c.Clients.RsvpClientAddress,
 		"http_port", c.Server.HTTPPort,
 	)
 
@@ -98,6 +123,47 @@ func main() {
 	connections = append(connections, eventMcsConn)
 	log.Info("Connected to gRPC event service")
 
+	// Сервис избранного необязателен: без адреса избранное выключено,
+	// а в разработке (in_memory_fallback) хранится в памяти
+	var favoriteClient pbFavorite.FavoriteServiceClient
+	switch {
+	case c.Clients.FavoriteClientAddress != "":
+		favoriteMcsConn, err := grpc.NewClient(c.Clients.FavoriteClientAddress, opts...)
+		if err != nil {
+			log.Error("Failed to connect to favorite service", "error", err)
+			cleanupConnections()
+			os.Exit(1)
+		}
+		connections = append(connections, favoriteMcsConn)
+		favoriteClient = pbFavorite.NewFavoriteServiceClient(favoriteMcsConn)
+		log.Info("Connected to gRPC favorite service")
+	case c.Clients.InMemoryFallback:
+		favoriteClient = fakes.NewFavoriteClient()
+		log.Warn("Favorite service address is not set, favorites are stored in memory")
+	default:
+		log.Warn("Favorite service address is not set, favorites are disabled")
+	}
+
+	// Сервис регистраций тоже необязателен
+	var rsvpClient pbRsvp.RsvpServiceClient
+	switch {
+	case c.Clients.RsvpClientAddress != "":
+		rsvpMcsConn, err := grpc.NewClient(c.Clients.RsvpClientAddress, opts...)
+		if err != nil {
+			log.Error("Failed to connect to rsvp service", "error", err)
+			cleanupConnections()
+			os.Exit(1)
+		}
+		connections = append(connections, rsvpMcsConn)
+		rsvpClient = pbRsvp.NewRsvpServiceClient(rsvpMcsConn)
+		log.Info("Connected to gRPC rsvp service")
+	case c.Clients.InMemoryFallback:
+		rsvpClient = fakes.NewRsvpClient()
+		log.Warn("Rsvp service address is not set, rsvps are stored in memory")
+	default:
+		log.Warn("Rsvp service address is not set, rsvps are disabled")
+	}
+
 	defer cleanupConnections()
 
 	// Создание gRPC клиентов
@@ -105,10 +171,212 @@ func main() {
 	authClient := pbAuth.NewAuthServiceClient(authMcsConn)
 	userClient := pbUser.NewUserServiceClient(userMcsConn)
 
+	// Языки контента: основной и языки переводов
+	languages := contentLanguages(c.Language)
+	log.Info("Content languages configured", "default", languages.Default, "supported", languages.Supported)
+
 	// Создание обработчиков
-	eHandler := eventHandler.NewEventHandler(eventClient, c.Service.SecretKey, log)
+	eventOpts := []eventHandler.Option{
+		eventHandler.WithCachePolicies(eventHandler.CachePolicies{
+			Events:     httpcache.Policy(c.Cache.Events),
+			Event:      httpcache.Policy(c.Cache.Event),
+			Categories: httpcache.Policy(c.Cache.Categories),
+			Category:   httpcache.Policy(c.Cache.Category),
+			Calendar:   httpcache.Policy(c.Cache.Calendar),
+		}),
+		eventHandler.WithFavorites(favoriteClient),
+		eventHandler.WithRsvp(rsvpClient),
+		eventHandler.WithLanguages(languages),
+	}
+
+	// API ключи парсеров: их события уходят на модерацию
+	if len(c.APIKeys) > 0 {
+		apiKeys := make([]middleware.APIKey, 0, len(c.APIKeys))
+		for _, key := range c.APIKeys {
+			apiKeys = append(apiKeys, middleware.APIKey{Name: key.Name, SHA256: strings.ToLower(key.SHA256)})
+		}
+		eventOpts = append(eventOpts, eventHandler.WithAPIKeys(apiKeys))
+		log.Info("API keys configured", "count", len(apiKeys))
+	}
+	// Хранилище изображений событий
+	var mediaHandler http.Handler
+	switch c.Images.Driver {
+	case "local":
+		localStore, err := blobstore.NewLocalStore(c.Images.Local.Dir, c.Images.BaseURL)
+		if err != nil {
+			log.Error("Failed to init local image store", "error", err)
+			os.Exit(1)
+		}
+		mediaHandler = localStore.Handler()
+		eventOpts = append(eventOpts, eventHandler.WithImageStore(localStore))
+		log.Info("Image store configured", "driver", "local", "dir", c.Images.Local.Dir)
+	case "s3":
+		s3Store, err := blobstore.NewS3Store(blobstore.S3Config{
+			Endpoint:  c.Images.S3.Endpoint,
+			Region:    c.Images.S3.Region,
+			Bucket:    c.Images.S3.Bucket,
+			AccessKey: c.Images.S3.AccessKey,
+			SecretKey: c.Images.S3.SecretKey,
+			PublicURL: c.Images.BaseURL,
+		})
+		if err != nil {
+			log.Error("Failed to init S3 image store", "error", err)
+			os.Exit(1)
+		}
+		eventOpts = append(eventOpts, eventHandler.WithImageStore(s3Store))
+		log.Info("Image store configured", "driver", "s3", "bucket", c.Images.S3.Bucket)
+	}
+
+	// Уведомления об изменениях событий (SSE и WebSocket)
+	var changeBroker *broker.MemoryBroker
+	if c.Streams.Enabled {
+		changeBroker = broker.NewMemoryBroker(c.Streams.History)
+		eventOpts = append(eventOpts, eventHandler.WithBroker(changeBroker, eventHandler.StreamSettings{
+			Heartbeat: c.Streams.Heartbeat,
+			Buffer:    c.Streams.Buffer,
+		}))
+		log.Info("Event streams enabled", "history", c.Streams.History)
+	}
+
+	// Недавние поисковые запросы пользователей (хранятся в памяти gateway)
+	if c.Recent.Enabled {
+		eventOpts = append(eventOpts, eventHandler.WithRecentSearches(recent.NewMemoryStore(c.Recent.PerUser, c.Recent.MaxUsers)))
+		log.Info("Recent searches enabled", "per_user", c.Recent.PerUser, "max_users", c.Recent.MaxUsers)
+	}
+
+	// История переходов к событиям для рекомендаций (хранится в памяти gateway)
+	if c.History.Enabled {
+		eventOpts = append(eventOpts, eventHandler.WithClickHistory(history.NewMemoryStore(c.History.PerUser, c.History.MaxUsers, c.History.MaxClicks)))
+		log.Info("Click history enabled", "per_user", c.History.PerUser, "max_users", c.History.MaxUsers, "max_clicks", c.History.MaxClicks)
+	}
+
+	// Поисковая аналитика: записи пишутся в sink асинхронно, статистика для отчетов — в памяти
+	var searchAnalytics *analytics.Recorder
+	if c.Analytics.Enabled {
+		stats := analytics.NewMemoryStats(c.Analytics.Retention)
+		sinks := []analytics.Sink{stats}
+		switch c.Analytics.Sink {
+		case "jsonl":
+			fileSink, err := analytics.OpenFileSink(c.Analytics.Path)
+			if err != nil {
+				log.Error("Failed to init search analytics sink", "error", err)
+				os.Exit(1)
+			}
+			sinks = append(sinks, fileSink)
+		case "stdout":
+			sinks = append(sinks, analytics.NewWriterSink(os.Stdout))
+		}
+		searchAnalytics = analytics.NewRecorder(analytics.MultiSink(sinks...), analytics.Config{
+			Buffer:        c.Analytics.Buffer,
+			BatchSize:     c.Analytics.BatchSize,
+			FlushInterval: c.Analytics.FlushInterval,
+		}, log)
+		eventOpts = append(eventOpts, eventHandler.WithSearchAnalytics(searchAnalytics, stats))
+		log.Info("Search analytics enabled", "sink", c.Analytics.Sink, "retention", stats.Retention())
+	}
+
+	userOpts := []userhandler.Option{
+		userhandler.WithFavorites(favoriteClient, eventClient),
+		userhandler.WithRsvps(rsvpClient, eventClient),
+	}
+
+	// Исходящие вебхуки
+	var webhooks *webhook.Dispatcher
+	if c.Webhooks.Enabled {
+		var endpoints webhook.EndpointStore
+		var queue webhook.Queue
+		switch c.Webhooks.Store.Driver {
+		case "file":
+			fileEndpoints, err := webhook.NewFileEndpointStore(c.Webhooks.Store.Dir)
+			if err != nil {
+				log.Error("Failed to open webhook endpoint store", "dir", c.Webhooks.Store.Dir, "error", err)
+				os.Exit(1)
+			}
+			fileQueue, err := webhook.NewFileQueue(c.Webhooks.Store.Dir)
+			if err != nil {
+				log.Error("Failed to open webhook delivery queue", "dir", c.Webhooks.Store.Dir, "error", err)
+				os.Exit(1)
+			}
+			endpoints, queue = fileEndpoints, fileQueue
+		default:
+			log.Warn("Webhooks are stored in memory and will be lost on restart")
+			endpoints, queue = webhook.NewMemoryEndpointStore(), webhook.NewMemoryQueue()
+		}
+
+		webhooks = webhook.NewDispatcher(
+			endpoints,
+			queue,
+			webhook.Config{
+				MaxAttempts:          c.Webhooks.MaxAttempts,
+				InitialBackoff:       c.Webhooks.InitialBackoff,
+				MaxBackoff:           c.Webhooks.MaxBackoff,
+				Timeout:              c.Webhooks.Timeout,
+				Workers:              c.Webhooks.Workers,
+				AllowPrivateNetworks: c.Webhooks.AllowPrivateNetworks,
+			},
+			log,
+		)
+		eventOpts = append(eventOpts, eventHandler.WithWebhooks(webhooks))
+		userOpts = append(userOpts, userhandler.WithWebhooks(webhooks))
+		log.Info("Webhooks enabled", "max_attempts", c.Webhooks.MaxAttempts, "store", c.Webhooks.Store.Driver)
+	}
+
+	// Сохраненные поиски: хранятся согласно saved_searches.store, дайджесты отправляет notifier
+	var savedSearches savedsearch.Store
+	var savedSearchNotifier savedsearch.Notifier
+	if c.Saved.Enabled {
+		switch c.Saved.Store.Driver {
+		case "file":
+			fileStore, err := savedsearch.NewFileStore(c.Saved.Store.Dir)
+			if err != nil {
+				log.Error("Failed to open saved search store", "dir", c.Saved.Store.Dir, "error", err)
+				os.Exit(1)
+			}
+			savedSearches = fileStore
+		default:
+			log.Warn("Saved searches are stored in memory and will be lost on restart")
+			savedSearches = savedsearch.NewMemoryStore()
+		}
+		switch c.Saved.Notifier {
+		case "webhook":
+			if webhooks == nil {
+				log.Error("Saved search webhook notifier requires webhooks to be enabled")
+				os.Exit(1)
+			}
+			savedSearchNotifier = savedsearch.NewWebhookNotifier(webhooks)
+		default:
+			savedSearchNotifier = savedsearch.NewLogNotifier(log)
+		}
+		userOpts = append(userOpts, userhandler.WithSavedSearches(savedSearches, c.Saved.MaxPerUser))
+		eventOpts = append(eventOpts, eventHandler.WithSavedSearches(savedSearches))
+	}
+
+	if c.Store.Enabled {
+		eventOpts = append(eventOpts, eventHandler.WithResponseCache(
+			cache.NewMemoryStore(c.Store.Capacity),
+			eventHandler.CacheTTLs{
+				Event:       c.Store.EventTTL,
+				Category:    c.Store.CategoryTTL,
+				Categories:  c.Store.CategoriesTTL,
+				Suggestions: c.Store.SuggestionsTTL,
+			},
+		))
+		log.Info("Response cache enabled", "capacity", c.Store.Capacity)
+	}
+
+	eHandler := eventHandler.NewEventHandler(eventClient, c.Service.SecretKey, log, eventOpts...)
 	aHandler := authhandler.NewAuthHandler(authClient, userClient, c.Service.SecretKey, log)
-	uHandler := userhandler.NewUserHandler(userClient, authClient, c.Service.SecretKey, log)
+	uHandler := userhandler.NewUserHandler(userClient, authClient, c.Service.SecretKey, log, userOpts...)
+
+	// Оповещения по сохраненным поискам: поиски повторяются через event-service
+	var savedSearchAlerter *savedsearch.Alerter
+	if savedSearches != nil {
+		savedSearchAlerter = savedsearch.NewAlerter(savedSearches, eHandler.SavedSearchMatcher(), savedSearchNotifier, savedsearch.Config{
+			Interval:   c.Saved.Interval,
+			DigestSize: c.Saved.DigestSize,
+		}, log)
+		log.Info("Saved searches enabled", "interval", c.Saved.Interval, "notifier", c.Saved.Notifier, "store", c.Saved.Store.Driver)
+	}
 
 	// Регистрация маршрутов
 	eventRoutes := eventHandler.RegisterRoutes(eHandler)
@@ -118,11 +386,42 @@ func main() {
 	// Создаем корневой роутер для объединения маршрутов
 	rootRouter := chi.NewRouter()
 
+	// Язык ответа (?lang= или Accept-Language) для контента и сообщений об ошибках
+	rootRouter.Use(i18n.Middleware(languages))
+
 	// Монтируем роутеры на корневой роутер
 	rootRouter.Mount("/event", eventRoutes)
 	rootRouter.Mount("/auth", authRoutes)
 	rootRouter.Mount("/user", userRoutes)
 
+	// Админские эндпоинты вебхуков
+	if webhooks != nil {
+		adminRoutes := adminhandler.RegisterRoutes(adminhandler.NewAdminHandler(webhooks, c.Service.SecretKey, log))
+		rootRouter.Mount("/admin", adminRoutes)
+	}
+
+	// GraphQL поверх event-service и user-service; события идут через кэш ответов обработчика событий
+	if c.GraphQL.Enabled {
+		graphqlOpts := []graphqlhandler.Option{
+			graphqlhandler.WithLimits(graphql.Limits{
+				MaxDepth:      c.GraphQL.MaxDepth,
+				MaxComplexity: c.GraphQL.MaxComplexity,
+			}),
+			graphqlhandler.WithMaxBody(c.GraphQL.MaxBody),
+		}
+		if c.GraphQL.PersistedQueries > 0 {
+			graphqlOpts = append(graphqlOpts, graphqlhandler.WithPersistedQueries(graphql.NewMemoryPersistedQueries(c.GraphQL.PersistedQueries)))
+		}
+		gHandler := graphqlhandler.NewGraphQLHandler(eHandler.EventClient(), userClient, c.Service.SecretKey, log, graphqlOpts...)
+		rootRouter.Mount("/graphql", graphqlhandler.RegisterRoutes(gHandler))
+		log.Info("GraphQL enabled", "max_depth", c.GraphQL.MaxDepth, "max_complexity", c.GraphQL.MaxComplexity, "persisted_queries", c.GraphQL.PersistedQueries)
+	}
+
+	// Раздача изображений из локального хранилища
+	if mediaHandler != nil {
+		rootRouter.Mount(mediaPathPrefix, http.StripPrefix(mediaPathPrefix, mediaHandler))
+	}
+
 	// Создаем HealthCheck сервер
 	healthServer := health.NewServer(
 		authMcsConn,
@@ -133,6 +432,24 @@ func main() {
 		health.WithVersion("1.0.0"),
 		health.WithPort(":8070"),
 		health.WithTimeout(5*time.Second),
+		health.WithCheck("response_cache", health.CheckerFunc(func(ctx context.Context) health.CheckResult {
+			stats, ok := eHandler.CacheStats()
+			if !ok {
+				return health.CheckResult{Status: health.StatusUp, Details: map[string]any{"enabled": false}}
+			}
+			return health.CheckResult{
+				Status: health.StatusUp,
+				Details: map[string]any{
+					"enabled":       true,
+					"hits":          stats.Hits,
+					"misses":        stats.Misses,
+					"shared":        stats.Shared,
+					"invalidations": stats.Invalidations,
+					"errors":        stats.Errors,
+					"hit_ratio":     stats.HitRatio(),
+				},
+			}
+		})),
 	)
 
 	// Создаем HTTP Gateway сервер
@@ -141,9 +458,36 @@ func main() {
 		Handler: rootRouter,
 	}
 
+	// Shutdown не ждет захваченные WebSocket соединения и не прерывает SSE,
+	// поэтому потоки завершаются закрытием брокера
+	if changeBroker != nil {
+		server.RegisterOnShutdown(changeBroker.Close)
+	}
+
 	// Запускаем серверы
 	errCh := make(chan error, 2)
 
+	// Фоновая доставка вебхуков
+	webhooksCtx, stopWebhooks := context.WithCancel(context.Background())
+	defer stopWebhooks()
+	if webhooks != nil {
+		go webhooks.Run(webhooksCtx)
+	}
+
+	// Фоновая проверка сохраненных поисков
+	alertsCtx, stopAlerts := context.WithCancel(context.Background())
+	defer stopAlerts()
+	if savedSearchAlerter != nil {
+		go savedSearchAlerter.Run(alertsCtx)
+	}
+
+	// Фоновая запись поисковой аналитики
+	analyticsCtx, stopAnalytics := context.WithCancel(context.Background())
+	defer stopAnalytics()
+	if searchAnalytics != nil {
+		go searchAnalytics.Run(analyticsCtx)
+	}
+
 	// Запускаем health сервер
 	go func() {
 		log.Info("Starting health check server on :8070")
@@ -193,5 +537,32 @@ func main() {
 		}
 	}
 
+	// Дописываем накопленные записи аналитики
+	if searchAnalytics != nil {
+		stopAnalytics()
+		select {
+		case <-searchAnalytics.Done():
+		case <-shutdownCtx.Done():
+			log.Warn("Search analytics flush timed out")
+		}
+	}
+
 	log.Info("All servers stopped gracefully")
 }
+
+// contentLanguages приводит языки из конфигурации к i18n.Languages.
+// Язык по умолчанию всегда входит в список поддерживаемых.
+func contentLanguages(params config.LanguageParams) i18n.Languages {
+	languages := i18n.Languages{Default: i18n.Normalize(params.Default)}
+	if languages.Default == "" {
+		languages.Default = "ru"
+	}
+
+	languages.Supported = []string{languages.Default}
+	for _, lang := range params.Supported {
+		if lang = i18n.Normalize(lang); !slices.Contains(languages.Supported, lang) {
+			languages.Supported = append(languages.Supported, lang)
+		}
+	}
+	return languages
+}
@@ -6,22 +6,39 @@ import (
 	"github.com/spf13/viper"
 	"os"
 	"path/filepath"
+	"time"
 )
 
 const (
-	envKey               = "service_params.env"
-	secretKey            = "service_params.secret_key"
-	gateway_http_port    = "server_params.http_port"
-	user_client_address  = "clients_params.user_client_address"
-	auth_client_address  = "clients_params.auth_client_address"
-	event_client_address = "clients_params.event_client_address"
+	envKey                  = "service_params.env"
+	secretKey               = "service_params.secret_key"
+	gateway_http_port       = "server_params.http_port"
+	user_client_address     = "clients_params.user_client_address"
+	auth_client_address     = "clients_params.auth_client_address"
+	event_client_address    = "clients_params.event_client_address"
+	favorite_client_address = "clients_params.favorite_client_address"
+	rsvp_client_address     = "clients_params.rsvp_client_address"
+	s3_access_key           = "image_store.s3.access_key"
+	s3_secret_key           = "image_store.s3.secret_key"
 )
 
 // AppConfig представляет конфигурацию всего приложения
 type AppConfig struct {
-	Service ServiceParams `mapstructure:"service_params" validate:"required"`
-	Server  ServerParams  `mapstructure:"server_params" validate:"required"`
-	Clients ClientsParams `mapstructure:"clients_params" validate:"required"`
+	Service   ServiceParams   `mapstructure:"service_params" validate:"required"`
+	Server    ServerParams    `mapstructure:"server_params" validate:"required"`
+	Clients   ClientsParams   `mapstructure:"clients_params" validate:"required"`
+	Cache     CacheParams     `mapstructure:"http_cache"`
+	Store     StoreParams     `mapstructure:"response_cache"`
+	Images    ImageParams     `mapstructure:"image_store"`
+	Streams   StreamParams    `mapstructure:"event_streams"`
+	Webhooks  WebhookParams   `mapstructure:"webhooks"`
+	APIKeys   []APIKeyParams  `mapstructure:"api_keys" validate:"dive"`
+	Language  LanguageParams  `mapstructure:"languages"`
+	Recent    RecentParams    `mapstructure:"recent_searches"`
+	Analytics AnalyticsParams `mapstructure:"search_analytics"`
+	Saved     SavedParams     `mapstructure:"saved_searches"`
+	History   HistoryParams   `mapstructure:"click_history"`
+	GraphQL   GraphQLParams   `mapstructure:"graphql"`
 }
 
 // ApplicationParams содержит общие параметры приложения
@@ -38,17 +55,165 @@ type ClientsParams struct {
 	EventClientAddress string `mapstructure:"event_client_address" validate:"required"`
 	AuthClientAddress  string `mapstructure:"auth_client_address" validate:"required"`
 	UserClientAddress  string `mapstructure:"user_client_address" validate:"required"`
+
+	// Пусто = избранное выключено или, с in_memory_fallback, хранится в памяти gateway
+	FavoriteClientAddress string `mapstructure:"favorite_client_address"`
+
+	// Пусто = регистрации выключены или, с in_memory_fallback, хранятся в памяти gateway
+	RsvpClientAddress string `mapstructure:"rsvp_client_address"`
+
+	// Только для разработки: сервисы без адреса заменяются хранилищами в памяти
+	// gateway (пакет fakes), данные теряются при перезапуске. Запрещено в prod.
+	InMemoryFallback bool `mapstructure:"in_memory_fallback"`
+}
+
+// CacheParams содержит политики HTTP кэширования публичных эндпоинтов событий
+type CacheParams struct {
+	Events     CachePolicyParams `mapstructure:"events"`
+	Event      CachePolicyParams `mapstructure:"event"`
+	Categories CachePolicyParams `mapstructure:"categories"`
+	Category   CachePolicyParams `mapstructure:"category"`
+	Calendar   CachePolicyParams `mapstructure:"calendar"`
+}
+
+// CachePolicyParams политика кэширования одного маршрута
+type CachePolicyParams struct {
+	MaxAge               time.Duration `mapstructure:"max_age" validate:"gte=0"`
+	StaleWhileRevalidate time.Duration `mapstructure:"stale_while_revalidate" validate:"gte=0"`
+}
+
+// StoreParams содержит параметры кэша ответов event-service
+type StoreParams struct {
+	Enabled        bool          `mapstructure:"enabled"`
+	Capacity       int           `mapstructure:"capacity" validate:"gte=0"`
+	EventTTL       time.Duration `mapstructure:"event_ttl" validate:"gte=0"`
+	CategoryTTL    time.Duration `mapstructure:"category_ttl" validate:"gte=0"`
+	CategoriesTTL  time.Duration `mapstructure:"categories_ttl" validate:"gte=0"`
+	SuggestionsTTL time.Duration `mapstructure:"suggestions_ttl" validate:"gte=0"`
+}
+
+// ImageParams содержит параметры хранилища изображений событий
+type ImageParams struct {
+	Driver  string   `mapstructure:"driver" validate:"omitempty,oneof=local s3"` // Пусто = загрузка выключена
+	BaseURL string   `mapstructure:"base_url"`
+	Local   LocalFS  `mapstructure:"local"`
+	S3      S3Params `mapstructure:"s3"`
+}
+
+// LocalFS параметры хранения изображений на локальном диске
+type LocalFS struct {
+	Dir string `mapstructure:"dir"`
+}
+
+// S3Params параметры S3-совместимого хранилища изображений
+type S3Params struct {
+	Endpoint  string `mapstructure:"endpoint"`
+	Region    string `mapstructure:"region"`
+	Bucket    string `mapstructure:"bucket"`
+	AccessKey string `mapstructure:"access_key"`
+	SecretKey string `mapstructure:"secret_key"`
+}
+
+// StreamParams содержит параметры уведомлений об изменениях событий (SSE и WebSocket)
+type StreamParams struct {
+	Enabled   bool          `mapstructure:"enabled"`
+	History   int           `mapstructure:"history" validate:"gte=0"` // Сколько последних уведомлений хранить для Last-Event-ID
+	Buffer    int           `mapstructure:"buffer" validate:"gte=0"`  // Очередь уведомлений одного подключения
+	Heartbeat time.Duration `mapstructure:"heartbeat" validate:"gte=0"`
+}
+
+// RecentParams содержит параметры хранения недавних поисковых запросов пользователей
+type RecentParams struct {
+	Enabled  bool `mapstructure:"enabled"`
+	PerUser  int  `mapstructure:"per_user" validate:"gte=0"`  // Сколько запросов хранить на пользователя
+	MaxUsers int  `mapstructure:"max_users" validate:"gte=0"` // Сколько пользователей держать в памяти
+}
+
+// HistoryParams содержит параметры истории переходов к событиям для рекомендаций
+type HistoryParams struct {
+	Enabled   bool `mapstructure:"enabled"`
+	PerUser   int  `mapstructure:"per_user" validate:"gte=0"`   // Сколько событий хранить на пользователя
+	MaxUsers  int  `mapstructure:"max_users" validate:"gte=0"`  // Сколько пользователей держать в памяти
+	MaxClicks int  `mapstructure:"max_clicks" validate:"gte=0"` // Сколько последних переходов учитывать в популярности
+}
+
+// GraphQLParams содержит параметры эндпоинта /graphql
+type GraphQLParams struct {
+	Enabled          bool  `mapstructure:"enabled"`
+	MaxDepth         int   `mapstructure:"max_depth" validate:"gte=0"`         // 0 = без ограничения
+	MaxComplexity    int   `mapstructure:"max_complexity" validate:"gte=0"`    // 0 = без ограничения
+	PersistedQueries int   `mapstructure:"persisted_queries" validate:"gte=0"` // Сколько запросов APQ хранить; 0 = APQ выключены
+	MaxBody          int64 `mapstructure:"max_body" validate:"gte=0"`          // Байт в теле POST; 0 = 1 МБ
+}
+
+// AnalyticsParams содержит параметры поисковой аналитики
+type AnalyticsParams struct {
+	Enabled       bool          `mapstructure:"enabled"`
+	Sink          string        `mapstructure:"sink" validate:"omitempty,oneof=jsonl stdout"` // Пусто = только статистика в памяти
+	Path          string        `mapstructure:"path" validate:"required_if=Sink jsonl"`
+	Buffer        int           `mapstructure:"buffer" validate:"gte=0"`
+	BatchSize     int           `mapstructure:"batch_size" validate:"gte=0"`
+	FlushInterval time.Duration `mapstructure:"flush_interval" validate:"gte=0"`
+	Retention     time.Duration `mapstructure:"retention" validate:"gte=0"` // Сколько хранить статистику для отчетов
+}
+
+// SavedParams содержит параметры сохраненных поисков и оповещений о новых событиях
+type SavedParams struct {
+	Enabled    bool          `mapstructure:"enabled"`
+	MaxPerUser int           `mapstructure:"max_per_user" validate:"gte=0"`                   // 0 = без ограничения
+	Interval   time.Duration `mapstructure:"interval" validate:"gte=0"`                       // Как часто проверять поиски
+	DigestSize int           `mapstructure:"digest_size" validate:"gte=0"`                    // Событий в одном дайджесте
+	Notifier   string        `mapstructure:"notifier" validate:"omitempty,oneof=log webhook"` // Пусто = log
+	Store      StateStore    `mapstructure:"store"`
+}
+
+// WebhookParams содержит параметры доставки исходящих вебхуков
+type WebhookParams struct {
+	Enabled        bool          `mapstructure:"enabled"`
+	MaxAttempts    int           `mapstructure:"max_attempts" validate:"gte=0"`
+	InitialBackoff time.Duration `mapstructure:"initial_backoff" validate:"gte=0"`
+	MaxBackoff     time.Duration `mapstructure:"max_backoff" validate:"gte=0"`
+	Timeout        time.Duration `mapstructure:"timeout" validate:"gte=0"`
+	Workers        int           `mapstructure:"workers" validate:"gte=0"`
+	Store          StateStore    `mapstructure:"store"`
+
+	// AllowPrivateNetworks разрешает подписки на localhost и частные сети. Только для разработки.
+	AllowPrivateNetworks bool `mapstructure:"allow_private_networks"`
+}
+
+// StateStore хранилище состояния gateway (подписки вебхуков, сохраненные поиски)
+type StateStore struct {
+	Driver string `mapstructure:"driver" validate:"omitempty,oneof=file memory"` // memory только для разработки
+	Dir    string `mapstructure:"dir" validate:"required_if=Driver file"`
+}
+
+// APIKeyParams API ключ машинного клиента (парсера источника событий).
+// События, отправленные с ключом, уходят на модерацию.
+type APIKeyParams struct {
+	Name   string `mapstructure:"name" validate:"required"`
+	SHA256 string `mapstructure:"sha256" validate:"required,len=64,hexadecimal"` // echo -n "$KEY" | sha256sum
+}
+
+// LanguageParams содержит языки контента. Основные поля событий и категорий
+// написаны на языке по умолчанию, на остальные поддерживаемые языки добавляются переводы.
+type LanguageParams struct {
+	Default   string   `mapstructure:"default" validate:"omitempty,min=2"` // Пусто = ru
+	Supported []string `mapstructure:"supported" validate:"dive,min=2"`
 }
 
 // EnvBindings возвращает мапу ключей конфигурации и соответствующих им переменных окружения
 func envBindings() map[string]string {
 	return map[string]string{
-		envKey:               "SERVICE_ENV",
-		secretKey:            "SECRET_KEY",
-		gateway_http_port:    "GATEWAY_HTTP_PORT",
-		event_client_address: "EVENT_CLIENT_ADDR",
-		auth_client_address:  "AUTH_CLIENT_ADDR",
-		user_client_address:  "USER_CLIENT_ADDR",
+		envKey:                  "SERVICE_ENV",
+		secretKey:               "SECRET_KEY",
+		gateway_http_port:       "GATEWAY_HTTP_PORT",
+		event_client_address:    "EVENT_CLIENT_ADDR",
+		auth_client_address:     "AUTH_CLIENT_ADDR",
+		user_client_address:     "USER_CLIENT_ADDR",
+		favorite_client_address: "FAVORITE_CLIENT_ADDR",
+		rsvp_client_address:     "RSVP_CLIENT_ADDR",
+		s3_access_key:           "S3_ACCESS_KEY",
+		s3_secret_key:           "S3_SECRET_KEY",
 	}
 }
 
@@ -92,5 +257,45 @@ func New() (*AppConfig, error) {
 		return nil, fmt.Errorf("ошибка валидации конфигурации: %w", err)
 	}
 
+	if err := validateStateStores(&config); err != nil {
+		return nil, fmt.Errorf("ошибка валидации конфигурации: %w", err)
+	}
+
+	if err := validateDevOnly(&config); err != nil {
+		return nil, fmt.Errorf("ошибка валидации конфигурации: %w", err)
+	}
+
 	return &config, nil
 }
+
+// validateStateStores требует явно выбрать хранилище для включенных функций,
+// чтобы состояние не терялось при перезапуске незаметно
+func validateStateStores(config *AppConfig) error {
+	if config.Webhooks.Enabled && config.Webhooks.Store.Driver == "" {
+		return fmt.Errorf("webhooks.store.driver обязателен, если вебхуки включены")
+	}
+	if config.Saved.Enabled && config.Saved.Store.Driver == "" {
+		return fmt.Errorf("saved_searches.store.driver обязателен, если сохраненные поиски включены")
+	}
+	return nil
+}
+
+// validateDevOnly запрещает в prod настройки, предназначенные только для разработки
+func validateDevOnly(config *AppConfig) error {
+	if config.Service.Env != "prod" {
+		return nil
+	}
+	if config.Clients.InMemoryFallback {
+		return fmt.Errorf("clients_params.in_memory_fallback недоступен в prod")
+	}
+	if config.Webhooks.Enabled && config.Webhooks.Store.Driver == "memory" {
+		return fmt.Errorf("webhooks.store.driver=memory недоступен в prod")
+	}
+	if config.Saved.Enabled && config.Saved.Store.Driver == "memory" {
+		return fmt.Errorf("saved_searches.store.driver=memory недоступен в prod")
+	}
+	if config.Webhooks.AllowPrivateNetworks {
+		return fmt.Errorf("webhooks.allow_private_networks недоступен в prod")
+	}
+	return nil
+}
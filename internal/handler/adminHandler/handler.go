@@ -0,0 +1,305 @@
+package adminhandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rx3lixir/gateway-service/pkg/logger"
+	"github.com/rx3lixir/gateway-service/pkg/token"
+	"github.com/rx3lixir/gateway-service/pkg/webhook"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// defaultDeliveriesLimit и maxDeliveriesLimit ограничивают списки доставок
+	defaultDeliveriesLimit = 50
+	maxDeliveriesLimit     = 500
+)
+
+type adminHandler struct {
+	webhooks   *webhook.Dispatcher
+	tokenMaker *token.JWTMaker
+	logger     logger.Logger
+}
+
+func NewAdminHandler(webhooks *webhook.Dispatcher, secretKey string, log logger.Logger) *adminHandler {
+	return &adminHandler{
+		webhooks:   webhooks,
+		tokenMaker: token.NewJWTMaker(secretKey),
+		logger:     log,
+	}
+}
+
+// handleListWebhooks возвращает все зарегистрированные вебхуки
+func (h *adminHandler) handleListWebhooks(w http.ResponseWriter, r *http.Request) error {
+	endpoints, err := h.webhooks.Endpoints().List(r.Context())
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "Failed to list webhooks", "error", err)
+		return err
+	}
+
+	res := ListWebhooksRes{Webhooks: make([]WebhookRes, 0, len(endpoints))}
+	for _, endpoint := range endpoints {
+		res.Webhooks = append(res.Webhooks, toWebhookRes(endpoint))
+	}
+	return WriteJSON(w, http.StatusOK, res)
+}
+
+// handleCreateWebhook регистрирует вебхук. Ключ подписи возвращается только в этом ответе.
+func (h *adminHandler) handleCreateWebhook(w http.ResponseWriter, r *http.Request) error {
+	var req CreateWebhookReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WarnContext(r.Context(), "Failed to decode create webhook request", "error", err)
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	defer r.Body.Close()
+
+	if err := h.validateWebhook(r.Context(), req.URL, req.EventTypes, req.CategoryIDs); err != nil {
+		h.logger.WarnContext(r.Context(), "Webhook validation failed", "reason", err)
+		return err
+	}
+
+	now := time.Now()
+	endpoint := &webhook.Endpoint{
+		ID:          uuid.NewString(),
+		URL:         req.URL,
+		Secret:      webhook.NewSecret(),
+		Description: req.Description,
+		EventTypes:  slices.Compact(slices.Sorted(slices.Values(req.EventTypes))),
+		CategoryIDs: req.CategoryIDs,
+		Active:      req.Active == nil || *req.Active,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := h.webhooks.Endpoints().Create(r.Context(), endpoint); err != nil {
+		h.logger.ErrorContext(r.Context(), "Failed to create webhook", "url", req.URL, "error", err)
+		return err
+	}
+
+	h.logger.InfoContext(r.Context(), "Webhook created", "id", endpoint.ID, "url", endpoint.URL, "event_types", endpoint.EventTypes)
+
+	res := toWebhookRes(endpoint)
+	res.Secret = endpoint.Secret
+	return WriteJSON(w, http.StatusCreated, res)
+}
+
+// handleGetWebhook возвращает вебхук по ID
+func (h *adminHandler) handleGetWebhook(w http.ResponseWriter, r *http.Request) error {
+	endpoint, err := h.getEndpoint(r)
+	if err != nil {
+		return err
+	}
+	return WriteJSON(w, http.StatusOK, toWebhookRes(endpoint))
+}
+
+// handleUpdateWebhook частично обновляет вебхук
+func (h *adminHandler) handleUpdateWebhook(w http.ResponseWriter, r *http.Request) error {
+	endpoint, err := h.getEndpoint(r)
+	if err != nil {
+		return err
+	}
+
+	var req UpdateWebhookReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WarnContext(r.Context(), "Failed to decode update webhook request", "error", err)
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	defer r.Body.Close()
+
+	if req.URL != nil {
+		endpoint.URL = *req.URL
+	}
+	if req.Description != nil {
+		endpoint.Description = *req.Description
+	}
+	if req.EventTypes != nil {
+		endpoint.EventTypes = slices.Compact(slices.Sorted(slices.Values(*req.EventTypes)))
+	}
+	if req.CategoryIDs != nil {
+		endpoint.CategoryIDs = *req.CategoryIDs
+	}
+	if req.Active != nil {
+		endpoint.Active = *req.Active
+	}
+	if req.RotateSecret {
+		endpoint.Secret = webhook.NewSecret()
+	}
+
+	if err := h.validateWebhook(r.Context(), endpoint.URL, endpoint.EventTypes, endpoint.CategoryIDs); err != nil {
+		h.logger.WarnContext(r.Context(), "Webhook validation failed", "id", endpoint.ID, "reason", err)
+		return err
+	}
+
+	endpoint.UpdatedAt = time.Now()
+	if err := h.webhooks.Endpoints().Update(r.Context(), endpoint); err != nil {
+		h.logger.ErrorContext(r.Context(), "Failed to update webhook", "id", endpoint.ID, "error", err)
+		return notFoundOr(err, "webhook", endpoint.ID)
+	}
+
+	h.logger.InfoContext(r.Context(), "Webhook updated", "id", endpoint.ID, "rotated_secret", req.RotateSecret)
+
+	res := toWebhookRes(endpoint)
+	if req.RotateSecret {
+		res.Secret = endpoint.Secret
+	}
+	return WriteJSON(w, http.StatusOK, res)
+}
+
+// handleDeleteWebhook удаляет вебхук. Ожидающие доставки уходят в dead letter.
+func (h *adminHandler) handleDeleteWebhook(w http.ResponseWriter, r *http.Request) error {
+	id, err := parseUUIDFromURL(r, "id")
+	if err != nil {
+		return err
+	}
+
+	if err := h.webhooks.Endpoints().Delete(r.Context(), id); err != nil {
+		h.logger.WarnContext(r.Context(), "Failed to delete webhook", "id", id, "error", err)
+		return notFoundOr(err, "webhook", id)
+	}
+
+	h.logger.InfoContext(r.Context(), "Webhook deleted", "id", id)
+
+	return WriteJSON(w, http.StatusOK, map[string]string{
+		"message": fmt.Sprintf("webhook %s successfully deleted", id),
+	})
+}
+
+// handleListWebhookDeliveries возвращает последние доставки вебхука, опционально по статусу
+func (h *adminHandler) handleListWebhookDeliveries(w http.ResponseWriter, r *http.Request) error {
+	endpoint, err := h.getEndpoint(r)
+	if err != nil {
+		return err
+	}
+
+	statusFilter := r.URL.Query().Get("status")
+	if statusFilter != "" && !slices.Contains([]string{webhook.StatusPending, webhook.StatusDelivering, webhook.StatusSucceeded, webhook.StatusDead}, statusFilter) {
+		return fmt.Errorf("invalid status %q", statusFilter)
+	}
+
+	limit, err := parseLimit(r, defaultDeliveriesLimit, maxDeliveriesLimit)
+	if err != nil {
+		return err
+	}
+
+	deliveries, err := h.webhooks.Deliveries().List(r.Context(), webhook.DeliveryFilter{
+		EndpointID: endpoint.ID,
+		Status:     statusFilter,
+		Limit:      limit,
+	})
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "Failed to list webhook deliveries", "id", endpoint.ID, "error", err)
+		return err
+	}
+
+	return WriteJSON(w, http.StatusOK, toDeliveriesRes(deliveries))
+}
+
+// handleListDeadLetters возвращает доставки, исчерпавшие попытки
+func (h *adminHandler) handleListDeadLetters(w http.ResponseWriter, r *http.Request) error {
+	limit, err := parseLimit(r, defaultDeliveriesLimit, maxDeliveriesLimit)
+	if err != nil {
+		return err
+	}
+
+	deliveries, err := h.webhooks.Deliveries().List(r.Context(), webhook.DeliveryFilter{
+		Status: webhook.StatusDead,
+		Limit:  limit,
+	})
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "Failed to list dead letter deliveries", "error", err)
+		return err
+	}
+
+	return WriteJSON(w, http.StatusOK, toDeliveriesRes(deliveries))
+}
+
+// handleRedeliver ставит доставку на повторную отправку с обнуленным счетчиком попыток
+func (h *adminHandler) handleRedeliver(w http.ResponseWriter, r *http.Request) error {
+	id, err := parseUUIDFromURL(r, "deliveryID")
+	if err != nil {
+		return err
+	}
+
+	current, err := h.webhooks.Deliveries().Get(r.Context(), id)
+	if err != nil {
+		return notFoundOr(err, "delivery", id)
+	}
+	if current.Status == webhook.StatusDelivering {
+		return status.Errorf(codes.FailedPrecondition, "delivery %s is in progress", id)
+	}
+
+	delivery, err := h.webhooks.Redeliver(r.Context(), id)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "Failed to redeliver webhook", "delivery_id", id, "error", err)
+		return notFoundOr(err, "delivery", id)
+	}
+
+	h.logger.InfoContext(r.Context(), "Webhook delivery requeued", "delivery_id", id, "webhook_id", delivery.EndpointID)
+
+	return WriteJSON(w, http.StatusAccepted, toDeliveryRes(delivery))
+}
+
+// getEndpoint загружает вебхук по ID из URL
+func (h *adminHandler) getEndpoint(r *http.Request) (*webhook.Endpoint, error) {
+	id, err := parseUUIDFromURL(r, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint, err := h.webhooks.Endpoints().Get(r.Context(), id)
+	if err != nil {
+		return nil, notFoundOr(err, "webhook", id)
+	}
+	return endpoint, nil
+}
+
+// validateWebhook проверяет URL, типы событий и категории вебхука.
+// Адреса внутренней сети (localhost, частные сети, метаданные облака) запрещены.
+func (h *adminHandler) validateWebhook(ctx context.Context, rawURL string, eventTypes []string, categoryIDs []int64) error {
+	if rawURL == "" {
+		return fmt.Errorf("url is required")
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid url %q: absolute http(s) URL expected", rawURL)
+	}
+	if err := h.webhooks.CheckDestination(ctx, rawURL); err != nil {
+		if errors.Is(err, webhook.ErrForbiddenDestination) {
+			return fmt.Errorf("invalid url %q: %w", rawURL, err)
+		}
+		return err
+	}
+
+	if len(eventTypes) == 0 {
+		return fmt.Errorf("event_types is required")
+	}
+	for _, eventType := range eventTypes {
+		if !webhook.IsKnownType(eventType) {
+			return fmt.Errorf("invalid event type %q: supported types are %v", eventType, webhook.EventTypes)
+		}
+	}
+
+	for _, id := range categoryIDs {
+		if id <= 0 {
+			return fmt.Errorf("invalid category_id %d: must be positive", id)
+		}
+	}
+	return nil
+}
+
+// notFoundOr превращает webhook.ErrNotFound в понятную ошибку 404
+func notFoundOr(err error, kind, id string) error {
+	if errors.Is(err, webhook.ErrNotFound) {
+		return fmt.Errorf("%s %s not found", kind, id)
+	}
+	return err
+}
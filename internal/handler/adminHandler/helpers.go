@@ -0,0 +1,103 @@
+package adminhandler
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/rx3lixir/gateway-service/pkg/i18n"
+
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// APIError представляет структуру ошибки для ответов API.
+type APIError struct {
+	Error string `json:"error"`
+}
+
+// WriteJSON отправляет данные в формате JSON с указанным HTTP статусом.
+func WriteJSON(w http.ResponseWriter, statusCode int, data any) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if data == nil && (statusCode == http.StatusNoContent || statusCode == http.StatusAccepted) {
+		return nil
+	}
+	if data == nil {
+		data = map[string]any{}
+	}
+	return json.NewEncoder(w).Encode(data)
+}
+
+// apiFunc определяет сигнатуру функций-обработчиков API,
+// которые возвращают ошибку для централизованной обработки.
+type apiFunc func(w http.ResponseWriter, r *http.Request) error
+
+// makeHTTPHandleFunc преобразует apiFunc в стандартный http.HandlerFunc,
+// добавляя унифицированную обработку ошибок.
+func (h *adminHandler) makeHTTPHandlerFunc(f apiFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := f(w, r); err != nil {
+			st, ok := status.FromError(err)
+			if ok {
+				var httpStatus int
+				switch st.Code() {
+				case codes.NotFound:
+					httpStatus = http.StatusNotFound
+				case codes.InvalidArgument:
+					httpStatus = http.StatusBadRequest
+				case codes.FailedPrecondition, codes.Aborted, codes.AlreadyExists:
+					httpStatus = http.StatusConflict
+				case codes.PermissionDenied:
+					httpStatus = http.StatusForbidden
+				default:
+					h.logger.Error("Unhandled status error", "code", st.Code(), "message", st.Message(), "path", r.URL.Path)
+					httpStatus = http.StatusInternalServerError
+				}
+				WriteJSON(w, httpStatus, APIError{Error: i18n.Localize(r.Context(), st.Message())})
+				return
+			}
+
+			errStr := strings.ToLower(err.Error())
+			if strings.Contains(errStr, "required") || strings.Contains(errStr, "invalid") || strings.Contains(errStr, "format") {
+				WriteJSON(w, http.StatusBadRequest, APIError{Error: i18n.Localize(r.Context(), err.Error())})
+				return
+			}
+
+			if strings.Contains(errStr, "not found") {
+				WriteJSON(w, http.StatusNotFound, APIError{Error: i18n.Localize(r.Context(), err.Error())})
+				return
+			}
+
+			h.logger.Error("HTTP handler error", "error", err, "path", r.URL.Path)
+			WriteJSON(w, http.StatusInternalServerError, APIError{Error: i18n.Localize(r.Context(), "An unexpected error occurred")})
+		}
+	}
+}
+
+// parseUUIDFromURL извлекает и валидирует UUID из URL
+func parseUUIDFromURL(r *http.Request, paramName string) (string, error) {
+	value := chi.URLParam(r, paramName)
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s format: %v", paramName, value)
+	}
+	return id.String(), nil
+}
+
+// parseLimit читает параметр limit с ограничением сверху
+func parseLimit(r *http.Request, def, maxLimit int) (int, error) {
+	value := r.URL.Query().Get("limit")
+	if value == "" {
+		return def, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit <= 0 {
+		return 0, fmt.Errorf("invalid limit format: %q", value)
+	}
+	return min(limit, maxLimit), nil
+}
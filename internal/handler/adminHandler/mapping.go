@@ -0,0 +1,46 @@
+package adminhandler
+
+import "github.com/rx3lixir/gateway-service/pkg/webhook"
+
+func toWebhookRes(e *webhook.Endpoint) WebhookRes {
+	return WebhookRes{
+		ID:          e.ID,
+		URL:         e.URL,
+		Description: e.Description,
+		EventTypes:  e.EventTypes,
+		CategoryIDs: e.CategoryIDs,
+		Active:      e.Active,
+		CreatedAt:   e.CreatedAt,
+		UpdatedAt:   e.UpdatedAt,
+	}
+}
+
+func toDeliveryRes(d *webhook.Delivery) DeliveryRes {
+	res := DeliveryRes{
+		ID:             d.ID,
+		WebhookID:      d.EndpointID,
+		EventID:        d.EventID,
+		EventType:      d.EventType,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		LastError:      d.LastError,
+		LastStatusCode: d.LastStatusCode,
+		CreatedAt:      d.CreatedAt,
+		UpdatedAt:      d.UpdatedAt,
+		DeliveredAt:    d.DeliveredAt,
+	}
+	// Время следующей попытки имеет смысл только для ожидающих доставок
+	if d.Status == webhook.StatusPending {
+		next := d.NextAttemptAt
+		res.NextAttemptAt = &next
+	}
+	return res
+}
+
+func toDeliveriesRes(deliveries []*webhook.Delivery) ListDeliveriesRes {
+	res := ListDeliveriesRes{Deliveries: make([]DeliveryRes, 0, len(deliveries))}
+	for _, d := range deliveries {
+		res.Deliveries = append(res.Deliveries, toDeliveryRes(d))
+	}
+	return res
+}
@@ -0,0 +1,42 @@
+package adminhandler
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/rx3lixir/gateway-service/pkg/middleware"
+)
+
+func RegisterRoutes(a *adminHandler) *chi.Mux {
+	r := chi.NewRouter()
+
+	middlewareConfig := &middleware.Config{
+		TokenMaker: a.tokenMaker,
+		Logger:     a.logger,
+		CORSConfig: middleware.DefaultCORSConfig(),
+	}
+
+	// Общие middleware
+	for _, mw := range middleware.CommonMiddlewares(middlewareConfig) {
+		r.Use(mw)
+	}
+
+	r.Route("/api/v1", func(r chi.Router) {
+		// Все админские эндпоинты требуют прав администратора
+		r.Use(middleware.RequireAuth(middlewareConfig, true))
+
+		r.Route("/webhooks", func(r chi.Router) {
+			r.Get("/", a.makeHTTPHandlerFunc(a.handleListWebhooks))
+			r.Post("/", a.makeHTTPHandlerFunc(a.handleCreateWebhook))
+
+			// Dead letter и ручная повторная доставка
+			r.Get("/dead-letters", a.makeHTTPHandlerFunc(a.handleListDeadLetters))
+			r.Post("/deliveries/{deliveryID}/redeliver", a.makeHTTPHandlerFunc(a.handleRedeliver))
+
+			r.Get("/{id}", a.makeHTTPHandlerFunc(a.handleGetWebhook))
+			r.Patch("/{id}", a.makeHTTPHandlerFunc(a.handleUpdateWebhook))
+			r.Delete("/{id}", a.makeHTTPHandlerFunc(a.handleDeleteWebhook))
+			r.Get("/{id}/deliveries", a.makeHTTPHandlerFunc(a.handleListWebhookDeliveries))
+		})
+	})
+
+	return r
+}
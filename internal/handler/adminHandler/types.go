@@ -0,0 +1,61 @@
+package adminhandler
+
+import "time"
+
+// CreateWebhookReq запрос на регистрацию вебхука
+type CreateWebhookReq struct {
+	URL         string   `json:"url"`
+	Description string   `json:"description"`
+	EventTypes  []string `json:"event_types"`
+	CategoryIDs []int64  `json:"category_ids"` // Пусто = все категории
+	Active      *bool    `json:"active"`       // По умолчанию true
+}
+
+// UpdateWebhookReq частичное обновление вебхука: изменяются только переданные поля
+type UpdateWebhookReq struct {
+	URL          *string   `json:"url"`
+	Description  *string   `json:"description"`
+	EventTypes   *[]string `json:"event_types"`
+	CategoryIDs  *[]int64  `json:"category_ids"`
+	Active       *bool     `json:"active"`
+	RotateSecret bool      `json:"rotate_secret"` // Выпустить новый ключ подписи
+}
+
+// WebhookRes вебхук в ответе API
+type WebhookRes struct {
+	ID          string    `json:"id"`
+	URL         string    `json:"url"`
+	Description string    `json:"description,omitempty"`
+	EventTypes  []string  `json:"event_types"`
+	CategoryIDs []int64   `json:"category_ids,omitempty"`
+	Active      bool      `json:"active"`
+	Secret      string    `json:"secret,omitempty"` // Только при создании и смене ключа
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ListWebhooksRes список вебхуков
+type ListWebhooksRes struct {
+	Webhooks []WebhookRes `json:"webhooks"`
+}
+
+// DeliveryRes доставка вебхука
+type DeliveryRes struct {
+	ID             string     `json:"id"`
+	WebhookID      string     `json:"webhook_id"`
+	EventID        string     `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	LastStatusCode int        `json:"last_status_code,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+}
+
+// ListDeliveriesRes список доставок
+type ListDeliveriesRes struct {
+	Deliveries []DeliveryRes `json:"deliveries"`
+}
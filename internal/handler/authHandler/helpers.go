@@ -4,6 +4,8 @@ import (
 	"google.golang.org/grpc/codes"  // Для кодов gRPC ошибо
 	"google.golang.org/grpc/status" // Для обработки gRPC ошибок
 
+	"github.com/rx3lixir/gateway-service/pkg/i18n"
+
 	"context"
 	"encoding/json"
 	"net/http"
@@ -65,7 +67,7 @@ func (h *authHandler) makeHTTPHandlerFunc(f apiFunc) http.HandlerFunc {
 					h.logger.Error("Unhandled gRPC error", "code", st.Code(), "message", st.Message(), "path", r.URL.Path)
 					httpStatus = http.StatusInternalServerError
 				}
-				WriteJSON(w, httpStatus, APIError{Error: st.Message()})
+				WriteJSON(w, httpStatus, APIError{Error: i18n.Localize(r.Context(), st.Message())})
 				return
 			}
 
@@ -74,23 +76,23 @@ func (h *authHandler) makeHTTPHandlerFunc(f apiFunc) http.HandlerFunc {
 			// Проверка на "is required" или "invalid"
 			errStr := strings.ToLower(err.Error())
 			if strings.Contains(errStr, "required") || strings.Contains(errStr, "invalid") || strings.Contains(errStr, "format") || strings.Contains(errStr, "positive integer") {
-				WriteJSON(w, http.StatusBadRequest, APIError{Error: err.Error()})
+				WriteJSON(w, http.StatusBadRequest, APIError{Error: i18n.Localize(r.Context(), err.Error())})
 				return
 			}
 
 			if strings.Contains(errStr, "unauthorized") || strings.Contains(errStr, "unauthenticated") {
-				WriteJSON(w, http.StatusUnauthorized, APIError{Error: "Unauthorized"})
+				WriteJSON(w, http.StatusUnauthorized, APIError{Error: i18n.Localize(r.Context(), "Unauthorized")})
 				return
 			}
 
 			// Если ошибка содержит "not found" (из старого кода, но лучше полагаться на gRPC codes.NotFound)
 			if strings.Contains(errStr, "not found") {
-				WriteJSON(w, http.StatusNotFound, APIError{Error: err.Error()})
+				WriteJSON(w, http.StatusNotFound, APIError{Error: i18n.Localize(r.Context(), err.Error())})
 				return
 			}
 
 			h.logger.Error("HTTP handler error", "error", err, "path", r.URL.Path)
-			WriteJSON(w, http.StatusInternalServerError, APIError{Error: "An unexpected error occurred"})
+			WriteJSON(w, http.StatusInternalServerError, APIError{Error: i18n.Localize(r.Context(), "An unexpected error occurred")})
 		}
 	}
 }
@@ -0,0 +1,211 @@
+package eventHandler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rx3lixir/gateway-service/pkg/analytics"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// maxClickBodySize ограничивает тело beacon-запроса перехода
+	maxClickBodySize = 4 << 10
+
+	// clickRateLimit переходов за clickRateWindow от одного клиента (пользователя или IP)
+	clickRateLimit  = 60
+	clickRateWindow = time.Minute
+
+	// defaultAnalyticsWindow и defaultAnalyticsLimit параметры отчета по умолчанию
+	defaultAnalyticsWindow = 24 * time.Hour
+	defaultAnalyticsLimit  = 20
+	maxAnalyticsLimit      = 100
+)
+
+// trackSearch записывает поиск событий в аналитику. Запросы без search_text не записываются.
+func (h *eventHandler) trackSearch(source string, req *ListEventsReq, results int, started time.Time) {
+	query := strings.TrimSpace(valueOrZero(req.SearchText))
+	if h.analytics == nil || query == "" {
+		return
+	}
+	h.analytics.Record(analytics.Record{
+		Type:      analytics.TypeSearch,
+		Source:    source,
+		Query:     query,
+		Results:   results,
+		Offset:    int(valueOrZero(req.Offset)),
+		LatencyMs: time.Since(started).Milliseconds(),
+	})
+}
+
+// trackSuggestion записывает запрос подсказок в аналитику
+func (h *eventHandler) trackSuggestion(query string, results int, started time.Time) {
+	if h.analytics == nil || query == "" {
+		return
+	}
+	h.analytics.Record(analytics.Record{
+		Type:      analytics.TypeSuggestion,
+		Source:    analytics.SourceSuggestions,
+		Query:     query,
+		Results:   results,
+		LatencyMs: time.Since(started).Milliseconds(),
+	})
+}
+
+// searchResultsCount возвращает число найденных событий: общее, если сервис его посчитал
+func searchResultsCount(res *ListEventsRes) int {
+	if res.Pagination != nil && res.Pagination.TotalCount > int64(len(res.Events)) {
+		return int(res.Pagination.TotalCount)
+	}
+	return len(res.Events)
+}
+
+// handleSearchClick принимает beacon перехода из выдачи к событию (navigator.sendBeacon).
+// Тело читается как JSON независимо от Content-Type: sendBeacon отправляет text/plain.
+func (h *eventHandler) handleSearchClick(w http.ResponseWriter, r *http.Request) error {
+	var req SearchClickReq
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxClickBodySize)).Decode(&req); err != nil {
+		h.logger.WarnContext(r.Context(), "Failed to decode search click", "error", err)
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	defer r.Body.Close()
+
+	req.Query = strings.TrimSpace(req.Query)
+	if req.Query == "" {
+		return errors.New("query is required")
+	}
+	if len([]rune(req.Query)) > maxSuggestionQueryLength {
+		return fmt.Errorf("invalid query: must be at most %d characters", maxSuggestionQueryLength)
+	}
+	if req.EventID <= 0 {
+		return errors.New("invalid event_id: must be a positive integer")
+	}
+	if req.Position < 0 {
+		return errors.New("invalid position: must be a positive integer")
+	}
+	if req.Source != "" && !slices.Contains(analytics.Sources, req.Source) {
+		return fmt.Errorf("invalid source %q: expected one of %s", req.Source, strings.Join(analytics.Sources, ", "))
+	}
+
+	// Переходы к несуществующим или скрытым событиям не учитываются
+	grpcCtx, cancel := h.createContext(r)
+	defer cancel()
+	protoEvent, err := h.eventClient.GetEvent(grpcCtx, IDToProtoGetEventByIDReq(req.EventID))
+	if err != nil {
+		h.logger.WarnContext(grpcCtx, "Failed to get clicked event", "event_id", req.EventID, "error", err)
+		return err
+	}
+	if protoEvent == nil || !canView(r, protoEvent) {
+		return status.Error(codes.NotFound, fmt.Sprintf("event with id %d not found", req.EventID))
+	}
+
+	if h.analytics != nil {
+		h.analytics.Record(analytics.Record{
+			Type:     analytics.TypeClick,
+			Source:   req.Source,
+			Query:    req.Query,
+			EventID:  req.EventID,
+			Position: req.Position,
+		})
+	}
+	h.recordClick(r, req.EventID)
+
+	return WriteJSON(w, http.StatusNoContent, nil)
+}
+
+// parseAnalyticsParams разбирает window (например 1h, 24h, 7d) и limit отчета
+func parseAnalyticsParams(r *http.Request, retention time.Duration) (time.Duration, int, error) {
+	q := r.URL.Query()
+
+	window := defaultAnalyticsWindow
+	if raw := q.Get("window"); raw != "" {
+		var err error
+		if days, ok := strings.CutSuffix(raw, "d"); ok {
+			var n int
+			n, err = strconv.Atoi(days)
+			window = time.Duration(n) * 24 * time.Hour
+		} else {
+			window, err = time.ParseDuration(raw)
+		}
+		if err != nil || window < analytics.BucketSize || window > retention {
+			return 0, 0, fmt.Errorf("invalid window %q: expected duration between %s and %s", raw, analytics.BucketSize, retention)
+		}
+	}
+	window = min(window, retention)
+
+	limit := defaultAnalyticsLimit
+	if raw := q.Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 || parsed > maxAnalyticsLimit {
+			return 0, 0, fmt.Errorf("invalid limit %q: must be between 1 and %d", raw, maxAnalyticsLimit)
+		}
+		limit = parsed
+	}
+
+	return window, limit, nil
+}
+
+// handleSearchAnalytics возвращает популярные запросы и запросы без результатов за окно
+func (h *eventHandler) handleSearchAnalytics(w http.ResponseWriter, r *http.Request) error {
+	if h.searchStats == nil {
+		return status.Error(codes.Unimplemented, "search analytics are not configured")
+	}
+
+	window, limit, err := parseAnalyticsParams(r, h.searchStats.Retention())
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Invalid search analytics parameters", "error", err)
+		return err
+	}
+
+	report, err := h.searchStats.Report(r.Context(), window, limit)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "Failed to build search analytics report", "error", err)
+		return err
+	}
+
+	h.logger.InfoContext(r.Context(), "Search analytics report built",
+		"window", window,
+		"searches", report.Searches,
+		"zero_result_searches", report.ZeroResultSearches)
+
+	return WriteJSON(w, http.StatusOK, toSearchAnalyticsRes(report, window))
+}
+
+// toSearchAnalyticsRes конвертирует отчет в HTTP ответ
+func toSearchAnalyticsRes(report *analytics.Report, window time.Duration) *SearchAnalyticsRes {
+	return &SearchAnalyticsRes{
+		From:               report.From,
+		To:                 report.To,
+		Window:             window.String(),
+		Searches:           report.Searches,
+		ZeroResultSearches: report.ZeroResultSearches,
+		Suggestions:        report.Suggestions,
+		Clicks:             report.Clicks,
+		TopQueries:         toSearchQueryStats(report.TopQueries),
+		ZeroResultQueries:  toSearchQueryStats(report.ZeroResultQueries),
+	}
+}
+
+func toSearchQueryStats(stats []analytics.QueryStats) []SearchQueryStats {
+	result := make([]SearchQueryStats, 0, len(stats))
+	for _, s := range stats {
+		result = append(result, SearchQueryStats{
+			Query:            s.Query,
+			Searches:         s.Searches,
+			ZeroResults:      s.ZeroResults,
+			Suggestions:      s.Suggestions,
+			Clicks:           s.Clicks,
+			ClickThroughRate: s.ClickThroughRate(),
+			AvgResults:       s.AvgResults,
+			AvgLatencyMs:     s.AvgLatencyMs,
+		})
+	}
+	return result
+}
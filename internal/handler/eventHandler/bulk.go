@@ -0,0 +1,745 @@
+package eventHandler
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	pbEvent "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/event"
+	contextpkg "github.com/rx3lixir/gateway-service/pkg/context"
+	"github.com/rx3lixir/gateway-service/pkg/ical"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	contentTypeJSONLines = "application/jsonl"
+	contentTypeNDJSON    = "application/x-ndjson"
+	contentTypeCSV       = "text/csv"
+	contentTypeCalendar  = "text/calendar"
+	contentTypeMultipart = "multipart/form-data"
+
+	// maxBatchRows ограничивает количество событий в одном пакете
+	maxBatchRows = 1000
+
+	// maxBatchBodyBytes ограничивает размер тела пакетного запроса
+	maxBatchBodyBytes = 10 << 20
+
+	// batchConcurrency количество одновременных вызовов CreateEvent
+	batchConcurrency = 8
+
+	// exportPageSize размер страницы ListEvents при выгрузке
+	exportPageSize = 500
+
+	// maxExportRows ограничивает количество событий в одной выгрузке
+	maxExportRows = 50000
+
+	// exportTruncatedHeader заголовок или трейлер неполной выгрузки и его значения
+	exportTruncatedHeader  = "X-Export-Truncated"
+	exportTruncatedMaxRows = "max_rows" // Событий больше maxExportRows
+	exportTruncatedError   = "error"    // Выгрузка прервалась из-за ошибки
+
+	batchStatusCreated   = "created"
+	batchStatusDuplicate = "duplicate"
+	batchStatusInvalid   = "invalid"
+	batchStatusFailed    = "failed"
+)
+
+// acceptBatch значение заголовка Accept в ответе 415 на пакетный импорт
+var acceptBatch = strings.Join([]string{contentTypeJSON, contentTypeJSONLines, contentTypeNDJSON, contentTypeCSV, contentTypeMultipart}, ", ")
+
+// errUnsupportedBatchType возвращается для пакета с неподдерживаемым Content-Type
+var errUnsupportedBatchType = errors.New("unsupported batch content type")
+
+// eventColumns колонки CSV для импорта событий, совпадают с JSON полями CreateEventReq
+var eventColumns = []string{
+	"name", "description", "category_id", "date", "time", "location", "price",
+	"image", "source", "lat", "lon", "starts_at", "ends_at", "time_zone", "recurrence_rule",
+}
+
+// exportColumns колонки CSV выгрузки событий
+var exportColumns = append([]string{"id"}, append(eventColumns, "created_at", "updated_at")...)
+
+// batchRow строка пакета: либо разобранный запрос, либо ошибка разбора
+type batchRow struct {
+	req *CreateEventReq
+	err error
+}
+
+// handleBatchCreateEvents создает события пакетом.
+//
+// Принимает JSON массив, JSON Lines или CSV с заголовком — в теле запроса
+// или файлом "file" в multipart/form-data. Каждая строка проверяется отдельно,
+// результат возвращается по каждой строке.
+//
+// Строки, похожие на существующие события или на более раннюю строку того же
+// пакета, не создаются и получают статус duplicate, если не передан force=true.
+func (h *eventHandler) handleBatchCreateEvents(w http.ResponseWriter, r *http.Request) error {
+	force, err := parseForce(r)
+	if err != nil {
+		return err
+	}
+
+	rows, err := readBatchRows(r)
+	if errors.Is(err, errUnsupportedBatchType) {
+		h.logger.WarnContext(r.Context(), "Unsupported batch content type", "content_type", r.Header.Get("Content-Type"))
+		return writeUnsupportedBatchType(w)
+	}
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Failed to read batch create request", "error", err)
+		return err
+	}
+	defer r.Body.Close()
+
+	h.logger.InfoContext(r.Context(), "Handling batch event creation", "rows", len(rows))
+
+	results := make([]BatchCreateResult, len(rows))
+	sem := make(chan struct{}, batchConcurrency)
+	var wg sync.WaitGroup
+
+	finder := h.newDuplicateFinder()
+	by := submitter(r)
+	// Отпечатки принятых строк по дате для поиска дубликатов внутри пакета
+	accepted := make(map[string][]batchFingerprint)
+
+	for i, row := range rows {
+		results[i].Row = i + 1
+
+		if row.err != nil {
+			results[i].Status = batchStatusInvalid
+			results[i].Error = row.err.Error()
+			continue
+		}
+
+		sched, err := validateCreateEventReq(row.req)
+		if err == nil {
+			err = h.validateTranslations(row.req.Translations)
+		}
+		// Статус строки задается по тем же правилам, что и в POST /events
+		var initialStatus string
+		if err == nil {
+			initialStatus, err = createStatus(r, row.req.Status)
+		}
+		if err != nil {
+			results[i].Status = batchStatusInvalid
+			results[i].Error = err.Error()
+			continue
+		}
+
+		fp := newEventFingerprint(row.req, sched)
+		if !force {
+			if prev := duplicateRow(accepted[fp.Date], fp); prev > 0 {
+				results[i].Status = batchStatusDuplicate
+				results[i].Error = fmt.Sprintf("likely duplicate of row %d", prev)
+				results[i].DuplicateOfRow = prev
+				continue
+			}
+			accepted[fp.Date] = append(accepted[fp.Date], batchFingerprint{row: i + 1, fp: fp})
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(result *BatchCreateResult, req *CreateEventReq, sched *eventSchedule, fp eventFingerprint, initialStatus string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			grpcCtx, cancel := h.createContext(r)
+			defer cancel()
+
+			if !force {
+				candidates, err := finder.find(grpcCtx, r, fp)
+				if err != nil {
+					h.logger.WarnContext(grpcCtx, "Duplicate check failed, creating event anyway",
+						"row", result.Row,
+						"fingerprint", fp.Key,
+						"error", err)
+				}
+				if len(candidates) > 0 {
+					result.Status = batchStatusDuplicate
+					result.Error = fmt.Sprintf("likely duplicate of event %d", candidates[0].Event.Id)
+					for _, candidate := range candidates {
+						result.DuplicateIDs = append(result.DuplicateIDs, candidate.Event.Id)
+					}
+					return
+				}
+			}
+
+			protoReq := HTTPCreateReqToProtoCreateEventReq(req, sched)
+			protoReq.Status = initialStatus
+			if by != "" {
+				protoReq.SubmittedBy = &by
+			}
+
+			created, err := h.eventClient.CreateEvent(grpcCtx, protoReq)
+			if err != nil {
+				h.logger.WarnContext(grpcCtx, "Failed to create event in batch",
+					"row", result.Row,
+					"name", req.Name,
+					"error", err)
+				result.Status = batchStatusFailed
+				result.Error = batchErrorMessage(err)
+				return
+			}
+
+			id := created.GetId()
+			result.Status = batchStatusCreated
+			result.Id = &id
+
+			h.publishChange(r.Context(), changeCreated, id, created)
+		}(&results[i], row.req, sched, fp, initialStatus)
+	}
+
+	wg.Wait()
+
+	res := &BatchCreateEventsRes{
+		Total:   len(results),
+		Results: results,
+	}
+	for _, result := range results {
+		switch result.Status {
+		case batchStatusCreated:
+			res.Created++
+		case batchStatusDuplicate:
+			res.Duplicates++
+		default:
+			res.Failed++
+		}
+	}
+
+	h.logger.InfoContext(r.Context(), "Batch event creation finished",
+		"total", res.Total,
+		"created", res.Created,
+		"duplicates", res.Duplicates,
+		"failed", res.Failed)
+
+	return WriteJSON(w, http.StatusOK, res)
+}
+
+// batchFingerprint отпечаток принятой строки пакета
+type batchFingerprint struct {
+	row int
+	fp  eventFingerprint
+}
+
+// duplicateRow возвращает номер более ранней строки, дубликатом которой является fp, или 0
+func duplicateRow(earlier []batchFingerprint, fp eventFingerprint) int {
+	for _, prev := range earlier {
+		if _, ok := duplicateScore(fp, prev.fp); ok {
+			return prev.row
+		}
+	}
+	return 0
+}
+
+// batchErrorMessage возвращает текст ошибки gRPC без служебного префикса
+func batchErrorMessage(err error) string {
+	if st, ok := status.FromError(err); ok {
+		return st.Message()
+	}
+	return err.Error()
+}
+
+// writeUnsupportedBatchType отвечает 415 с перечнем поддерживаемых форматов
+func writeUnsupportedBatchType(w http.ResponseWriter) error {
+	w.Header().Set("Accept", acceptBatch)
+	return WriteJSON(w, http.StatusUnsupportedMediaType, APIError{
+		Error: fmt.Sprintf("unsupported Content-Type: use %s, %s, %s or a %s file", contentTypeJSON, contentTypeJSONLines, contentTypeCSV, contentTypeMultipart),
+	})
+}
+
+// readBatchRows читает и разбирает строки пакета в зависимости от формата
+func readBatchRows(r *http.Request) ([]batchRow, error) {
+	mediaType := contentTypeJSON
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		parsed, _, err := mime.ParseMediaType(ct)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Content-Type: %w", err)
+		}
+		mediaType = parsed
+	}
+
+	r.Body = http.MaxBytesReader(nil, r.Body, maxBatchBodyBytes)
+	var body io.Reader = r.Body
+
+	if mediaType == contentTypeMultipart {
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			return nil, fmt.Errorf("invalid request body: multipart field \"file\" is required: %w", err)
+		}
+		defer file.Close()
+
+		body = file
+		mediaType = header.Header.Get("Content-Type")
+		if parsed, _, err := mime.ParseMediaType(mediaType); err == nil {
+			mediaType = parsed
+		}
+		// Браузеры часто присылают application/octet-stream — определяем формат по расширению
+		switch strings.ToLower(filepath.Ext(header.Filename)) {
+		case ".csv":
+			mediaType = contentTypeCSV
+		case ".jsonl", ".ndjson":
+			mediaType = contentTypeJSONLines
+		case ".json":
+			mediaType = contentTypeJSON
+		}
+	}
+
+	var (
+		rows []batchRow
+		err  error
+	)
+	switch mediaType {
+	case contentTypeJSON:
+		rows, err = readJSONRows(body)
+	case contentTypeJSONLines, contentTypeNDJSON:
+		rows, err = readJSONLinesRows(body)
+	case contentTypeCSV:
+		rows, err = readCSVRows(body)
+	default:
+		return nil, errUnsupportedBatchType
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("invalid request body: batch is empty")
+	}
+	if len(rows) > maxBatchRows {
+		return nil, fmt.Errorf("invalid request body: batch contains %d events, maximum is %d", len(rows), maxBatchRows)
+	}
+
+	return rows, nil
+}
+
+// readJSONRows разбирает JSON массив событий; некорректный элемент не ломает остальные
+func readJSONRows(body io.Reader) ([]batchRow, error) {
+	var items []json.RawMessage
+	if err := json.NewDecoder(body).Decode(&items); err != nil {
+		return nil, fmt.Errorf("invalid request body: expected JSON array of events: %w", err)
+	}
+
+	rows := make([]batchRow, 0, len(items))
+	for _, item := range items {
+		rows = append(rows, decodeBatchJSON(item))
+	}
+	return rows, nil
+}
+
+// readJSONLinesRows разбирает JSON Lines: одно событие на строку, пустые строки пропускаются
+func readJSONLinesRows(body io.Reader) ([]batchRow, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxPatchBodyBytes)
+
+	var rows []batchRow
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		rows = append(rows, decodeBatchJSON(line))
+		if len(rows) > maxBatchRows {
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("invalid request body: %w", err)
+	}
+	return rows, nil
+}
+
+// decodeBatchJSON разбирает одно событие. Лишние поля (id, created_at из выгрузки)
+// игнорируются, чтобы выгрузку JSON Lines можно было загрузить обратно.
+func decodeBatchJSON(raw []byte) batchRow {
+	var req CreateEventReq
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return batchRow{err: fmt.Errorf("invalid event: %w", err)}
+	}
+	return batchRow{req: &req}
+}
+
+// readCSVRows разбирает CSV с заголовком; имена колонок совпадают с JSON полями события
+func readCSVRows(body io.Reader) ([]batchRow, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("invalid request body: failed to read CSV header: %w", err)
+	}
+
+	// Допускаются и колонки выгрузки, чтобы CSV можно было загрузить обратно
+	known := make(map[string]bool, len(exportColumns))
+	for _, column := range exportColumns {
+		known[column] = true
+	}
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+		if !known[column] {
+			return nil, fmt.Errorf("invalid CSV header: unknown column %q", column)
+		}
+		header[i] = column
+	}
+
+	var rows []batchRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				rows = append(rows, batchRow{err: fmt.Errorf("invalid CSV row: %w", err)})
+				continue
+			}
+			return nil, fmt.Errorf("invalid request body: %w", err)
+		}
+		if len(record) != len(header) {
+			rows = append(rows, batchRow{err: fmt.Errorf("invalid CSV row: expected %d columns, got %d", len(header), len(record))})
+			continue
+		}
+
+		req, err := csvRecordToCreateReq(header, record)
+		rows = append(rows, batchRow{req: req, err: err})
+		if len(rows) > maxBatchRows {
+			break
+		}
+	}
+	return rows, nil
+}
+
+// csvRecordToCreateReq конвертирует строку CSV в CreateEventReq
+func csvRecordToCreateReq(header, record []string) (*CreateEventReq, error) {
+	req := &CreateEventReq{}
+
+	for i, column := range header {
+		value := strings.TrimSpace(record[i])
+		if value == "" {
+			continue
+		}
+
+		switch column {
+		case "name":
+			req.Name = value
+		case "description":
+			req.Description = value
+		case "category_id":
+			id, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid category_id %q", value)
+			}
+			req.CategoryID = id
+		case "date":
+			req.Date = value
+		case "time":
+			req.Time = value
+		case "location":
+			req.Location = value
+		case "price":
+			price, err := strconv.ParseFloat(value, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid price %q", value)
+			}
+			req.Price = float32(price)
+		case "image":
+			req.Image = value
+		case "source":
+			req.Source = value
+		case "lat", "lon":
+			coord, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s %q", column, value)
+			}
+			if column == "lat" {
+				req.Lat = &coord
+			} else {
+				req.Lon = &coord
+			}
+		case "starts_at":
+			req.StartsAt = &value
+		case "ends_at":
+			req.EndsAt = &value
+		case "time_zone":
+			req.TimeZone = value
+		case "recurrence_rule":
+			req.RecurrenceRule = value
+		}
+	}
+
+	return req, nil
+}
+
+// exportFormat формат выгрузки событий
+type exportFormat struct {
+	contentType string
+	extension   string
+}
+
+var exportFormats = map[string]exportFormat{
+	"csv":   {contentType: contentTypeCSV + "; charset=utf-8", extension: "csv"},
+	"jsonl": {contentType: contentTypeJSONLines, extension: "jsonl"},
+	"ics":   {contentType: contentTypeCalendar + "; charset=utf-8", extension: "ics"},
+}
+
+// eventExporter записывает события в формате выгрузки
+type eventExporter interface {
+	Write(event *Event) error
+	Close() error
+}
+
+// handleExportEvents выгружает все события, подходящие под фильтры GET /events.
+// События читаются из ListEvents постранично и сразу отправляются клиенту.
+//
+// Неполная выгрузка видна клиенту: если событий больше maxExportRows, заголовок
+// X-Export-Truncated: max_rows; если выгрузка прервалась после отправки заголовков,
+// трейлер X-Export-Truncated: error. Выгрузка долгая, поэтому маршрут без общего таймаута.
+func (h *eventHandler) handleExportEvents(w http.ResponseWriter, r *http.Request) error {
+	formatName := r.URL.Query().Get("format")
+	if formatName == "" {
+		formatName = "jsonl"
+	}
+	format, ok := exportFormats[formatName]
+	if !ok {
+		return fmt.Errorf("invalid format %q: supported formats are csv, jsonl, ics", formatName)
+	}
+
+	filterReq, err := ParseQueryParams(r.URL.Query())
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Failed to parse export query parameters", "error", err)
+		return fmt.Errorf("invalid query parameters: %w", err)
+	}
+	// Пагинацией управляет выгрузка, фасеты и счетчики не нужны
+	filterReq.Limit, filterReq.Offset = nil, nil
+	filterReq.IncludeCount, filterReq.IncludeFacets = nil, nil
+	if filterReq.Statuses, err = visibleStatuses(r, filterReq.Statuses); err != nil {
+		return err
+	}
+	if err := h.expandCategoryFilter(r, filterReq); err != nil {
+		return err
+	}
+
+	h.logger.InfoContext(r.Context(), "Handling events export", "format", formatName)
+
+	// Первую страницу запрашиваем до отправки заголовков, чтобы ошибка
+	// фильтров или сервиса вернулась обычным ответом с кодом ошибки.
+	// Общее количество нужно, чтобы заранее сообщить об обрезанной выгрузке
+	includeCount := true
+	filterReq.IncludeCount = &includeCount
+	page, total, err := h.exportPage(r, filterReq, 0)
+	if err != nil {
+		return err
+	}
+	filterReq.IncludeCount = nil
+
+	var categoryNames map[int64]string
+	if formatName == "ics" {
+		grpcCtx, cancel := h.createContext(r)
+		categoryNames = h.categoryNames(grpcCtx)
+		cancel()
+	}
+
+	filename := fmt.Sprintf("events-%s.%s", time.Now().UTC().Format("20060102-150405"), format.extension)
+	w.Header().Set("Content-Type", format.contentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	w.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
+	if total > maxExportRows {
+		w.Header().Set(exportTruncatedHeader, exportTruncatedMaxRows)
+	} else {
+		w.Header().Set("Trailer", exportTruncatedHeader)
+	}
+	w.WriteHeader(http.StatusOK)
+
+	// interrupted помечает выгрузку, оборвавшуюся после отправки заголовков
+	interrupted := func() {
+		w.Header().Set(exportTruncatedHeader, exportTruncatedError)
+	}
+
+	exporter := newEventExporter(formatName, w, categoryNames)
+	flusher, _ := w.(http.Flusher)
+
+	exported := 0
+	for offset := 0; ; {
+		if remaining := maxExportRows - exported; len(page) > remaining {
+			page = page[:remaining]
+		}
+		for _, event := range page {
+			if err := exporter.Write(event); err != nil {
+				// Заголовки уже отправлены — остается только прервать выгрузку
+				h.logger.ErrorContext(r.Context(), "Failed to write exported event", "exported", exported, "error", err)
+				interrupted()
+				return nil
+			}
+			exported++
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+
+		if len(page) < exportPageSize || exported >= maxExportRows {
+			break
+		}
+
+		offset += len(page)
+		page, _, err = h.exportPage(r, filterReq, offset)
+		if err != nil {
+			h.logger.ErrorContext(r.Context(), "Events export interrupted", "exported", exported, "error", err)
+			interrupted()
+			return nil
+		}
+	}
+
+	if err := exporter.Close(); err != nil {
+		h.logger.ErrorContext(r.Context(), "Failed to finish events export", "error", err)
+		interrupted()
+		return nil
+	}
+
+	h.logger.InfoContext(r.Context(), "Events export finished", "format", formatName, "exported", exported)
+	return nil
+}
+
+// exportPage загружает одну страницу событий для выгрузки. total — общее количество
+// событий, если filterReq.IncludeCount, иначе 0
+func (h *eventHandler) exportPage(r *http.Request, filterReq *ListEventsReq, offset int) ([]*Event, int64, error) {
+	grpcCtx, cancel := contextpkg.GRPCContextFromHTTPLongRunning(r)
+	defer cancel()
+
+	protoReq := HTTPListReqToProtoListReq(filterReq)
+	limit, off := int32(exportPageSize), int32(offset)
+	protoReq.Limit, protoReq.Offset = &limit, &off
+
+	res, err := h.eventClient.ListEvents(grpcCtx, protoReq)
+	if err != nil {
+		h.logger.ErrorContext(grpcCtx, "Failed to list events for export via gRPC", "offset", offset, "error", err)
+		return nil, 0, err
+	}
+
+	return ProtoListResToHTTPListRes(res, nil).Events, res.GetPagination().GetTotalCount(), nil
+}
+
+// categoryNames загружает названия категорий; при ошибке возвращает nil
+func (h *eventHandler) categoryNames(ctx context.Context) map[int64]string {
+	res, err := h.eventClient.ListCategories(ctx, &pbEvent.ListCategoriesReq{})
+	if err != nil {
+		h.logger.WarnContext(ctx, "Failed to load category names", "error", err)
+		return nil
+	}
+
+	// Названия на языке запроса
+	names := ProtoCategoriesToNameMap(res.GetCategories())
+	for _, category := range res.GetCategories() {
+		name, description := category.GetName(), ""
+		localize(ctx, &name, &description, categoryTranslationsFromProto(category.GetTranslations()))
+		names[int64(category.GetId())] = name
+	}
+	return names
+}
+
+func newEventExporter(format string, w io.Writer, categoryNames map[int64]string) eventExporter {
+	switch format {
+	case "csv":
+		return newCSVExporter(w)
+	case "ics":
+		return &icsExporter{w: ical.NewWriter(w, calendarProdID, "Events"), categoryNames: categoryNames}
+	default:
+		return &jsonLinesExporter{enc: json.NewEncoder(w)}
+	}
+}
+
+// jsonLinesExporter пишет по одному JSON объекту Event на строку
+type jsonLinesExporter struct {
+	enc *json.Encoder
+}
+
+func (e *jsonLinesExporter) Write(event *Event) error { return e.enc.Encode(event) }
+func (e *jsonLinesExporter) Close() error             { return nil }
+
+// csvExporter пишет события в CSV с колонками exportColumns
+type csvExporter struct {
+	w *csv.Writer
+}
+
+func newCSVExporter(w io.Writer) *csvExporter {
+	cw := csv.NewWriter(w)
+	cw.Write(exportColumns)
+	return &csvExporter{w: cw}
+}
+
+func (e *csvExporter) Write(event *Event) error {
+	record := []string{
+		strconv.FormatInt(event.Id, 10),
+		event.Name,
+		event.Description,
+		strconv.FormatInt(event.CategoryID, 10),
+		event.Date,
+		event.Time,
+		event.Location,
+		strconv.FormatFloat(float64(event.Price), 'f', -1, 32),
+		event.Image,
+		event.Source,
+		formatOptionalFloat(event.Lat),
+		formatOptionalFloat(event.Lon),
+		formatOptionalTime(event.StartsAt),
+		formatOptionalTime(event.EndsAt),
+		event.TimeZone,
+		event.RecurrenceRule,
+		event.CreatedAt.Format(time.RFC3339),
+		formatOptionalTime(event.UpdatedAt),
+	}
+	if err := e.w.Write(record); err != nil {
+		return err
+	}
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvExporter) Close() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// icsExporter пишет события в iCalendar; события без времени начала пропускаются
+type icsExporter struct {
+	w             *ical.Writer
+	categoryNames map[int64]string
+}
+
+func (e *icsExporter) Write(event *Event) error {
+	calEvent, ok := HTTPEventToICalEvent(event, e.categoryNames)
+	if !ok {
+		return nil
+	}
+	if err := e.w.WriteEvent(calEvent); err != nil {
+		return err
+	}
+	return e.w.Flush()
+}
+
+func (e *icsExporter) Close() error { return e.w.Close() }
+
+func formatOptionalFloat(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*v, 'f', -1, 64)
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,319 @@
+package eventHandler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	pbEvent "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/event"
+	"github.com/rx3lixir/gateway-service/pkg/cache"
+	"github.com/rx3lixir/gateway-service/pkg/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+const (
+	cacheKeyPrefix = "gateway:event:"
+
+	// cacheFetchTimeout таймаут запроса к event-service при промахе. Запрос
+	// общий для всех ожидающих, поэтому не зависит от контекста первого из них.
+	cacheFetchTimeout = 5 * time.Second
+
+	// keyVersionStripes число счетчиков инвалидации ключей, см. keyVersions
+	keyVersionStripes = 1024
+)
+
+// CacheTTLs время жизни закэшированных ответов по типам запросов
+type CacheTTLs struct {
+	Event       time.Duration
+	Category    time.Duration
+	Categories  time.Duration
+	Suggestions time.Duration
+}
+
+// cachedEventClient read-through кэш поверх pbEvent.EventServiceClient.
+//
+// Кэшируются GetEvent, GetCategory, ListCategories и GetSuggestions.
+// Изменяющие вызовы проходят к сервису и инвалидируют затронутые ключи.
+// Одновременные одинаковые промахи схлопываются в один gRPC вызов.
+type cachedEventClient struct {
+	pbEvent.EventServiceClient
+
+	store   cache.Store
+	ttl     CacheTTLs
+	group   cache.Group
+	metrics cache.Metrics
+	logger  logger.Logger
+
+	// versions счетчики инвалидации ключей: ответ, загруженный до инвалидации,
+	// не записывается в кэш поверх нее
+	versions keyVersions
+
+	// suggestionsGen поколение кэша подсказок и количества событий в категориях.
+	// Они зависят от всех событий сразу, поэтому вместо удаления отдельных ключей
+	// при изменении событий меняется поколение. Хранится в памяти, а не в store:
+	// вытесненное из LRU поколение вернуло бы устаревшие ответы прошлых поколений.
+	suggestionsGen atomic.Uint64
+}
+
+// keyVersions счетчики инвалидации по хешу ключа. Совпадение хешей разных
+// ключей приводит только к лишнему пропуску записи в кэш.
+type keyVersions [keyVersionStripes]atomic.Uint64
+
+func (v *keyVersions) stripe(key string) *atomic.Uint64 {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return &v[h.Sum32()%keyVersionStripes]
+}
+
+func newCachedEventClient(client pbEvent.EventServiceClient, store cache.Store, ttl CacheTTLs, log logger.Logger) *cachedEventClient {
+	return &cachedEventClient{
+		EventServiceClient: client,
+		store:              store,
+		ttl:                ttl,
+		logger:             log,
+	}
+}
+
+// Stats возвращает метрики кэша
+func (c *cachedEventClient) Stats() cache.Stats {
+	return c.metrics.Snapshot()
+}
+
+func eventCacheKey(id int64) string {
+	return cacheKeyPrefix + "event:" + strconv.FormatInt(id, 10)
+}
+
+func categoryCacheKey(id int32) string {
+	return cacheKeyPrefix + "category:" + strconv.FormatInt(int64(id), 10)
+}
+
+func categoriesCacheKey() string {
+	return cacheKeyPrefix + "categories"
+}
+
+// categoryCountsCacheKey ключ списка категорий с количеством событий. Количество
+// меняется вместе с любыми событиями, поэтому ключ, как и у подсказок, зависит от поколения.
+func categoryCountsCacheKey(generation string) string {
+	return cacheKeyPrefix + "categories:counts:" + generation
+}
+
+// suggestionsCacheKey нормализует запрос подсказок: регистр и пробелы запроса
+// и порядок полей не влияют на ключ
+func suggestionsCacheKey(generation string, req *pbEvent.SuggestionReq) string {
+	fields := make([]string, 0, len(req.GetFields()))
+	for _, f := range req.GetFields() {
+		fields = append(fields, strings.ToLower(strings.TrimSpace(f)))
+	}
+	sort.Strings(fields)
+
+	query := strings.Join(strings.Fields(strings.ToLower(req.GetQuery())), " ")
+
+	statuses := append([]string(nil), req.GetStatuses()...)
+	sort.Strings(statuses)
+
+	return fmt.Sprintf("%ssuggestions:%s:%d:%s:%s:%t:%q",
+		cacheKeyPrefix, generation, req.GetMaxResults(), strings.Join(fields, ","), strings.Join(statuses, ","), req.GetFuzzy(), query)
+}
+
+// cachedCall возвращает ответ из кэша или выполняет fetch и сохраняет результат.
+// fetch получает собственный контекст с таймаутом cacheFetchTimeout: его результат
+// достается всем одновременным вызовам, и отмена первого не должна их прерывать.
+func cachedCall[T proto.Message](ctx context.Context, c *cachedEventClient, key string, ttl time.Duration, newT func() T, fetch func(context.Context) (T, error)) (T, error) {
+	if cache.IsBypassed(ctx) {
+		return fetch(ctx)
+	}
+
+	if raw, err := c.store.Get(ctx, key); err == nil {
+		msg := newT()
+		if err := proto.Unmarshal(raw, msg); err == nil {
+			c.metrics.Hit()
+			return msg, nil
+		}
+		c.metrics.Error()
+		c.logger.WarnContext(ctx, "Failed to decode cached response", "key", key)
+	} else if !errors.Is(err, cache.ErrNotFound) {
+		c.metrics.Error()
+		c.logger.WarnContext(ctx, "Failed to read from cache", "key", key, "error", err)
+	}
+
+	c.metrics.Miss()
+
+	val, err, shared := c.group.Do(key, func() (any, error) {
+		version := c.versions.stripe(key).Load()
+
+		fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cacheFetchTimeout)
+		defer cancel()
+
+		msg, err := fetch(fetchCtx)
+		if err != nil {
+			return nil, err
+		}
+
+		// Ключ инвалидировали, пока шел запрос: ответ мог устареть
+		if c.versions.stripe(key).Load() != version {
+			return msg, nil
+		}
+
+		raw, err := proto.Marshal(msg)
+		if err != nil {
+			c.metrics.Error()
+			c.logger.WarnContext(ctx, "Failed to encode response for cache", "key", key, "error", err)
+			return msg, nil
+		}
+		if err := c.store.Set(fetchCtx, key, raw, ttl); err != nil {
+			c.metrics.Error()
+			c.logger.WarnContext(ctx, "Failed to write to cache", "key", key, "error", err)
+		}
+		return msg, nil
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	msg, ok := val.(T)
+	if !ok {
+		var zero T
+		return zero, fmt.Errorf("cache: unexpected result %T for key %q", val, key)
+	}
+	if shared {
+		c.metrics.SharedCall()
+		// Каждый вызывающий получает собственную копию ответа
+		msg = proto.Clone(msg).(T)
+	}
+	return msg, nil
+}
+
+// invalidate удаляет ключи из кэша. Ошибки хранилища только логируются:
+// запись уже выполнена, а ключ в худшем случае истечет по TTL.
+func (c *cachedEventClient) invalidate(ctx context.Context, keys ...string) {
+	for _, key := range keys {
+		c.versions.stripe(key).Add(1)
+		c.group.Forget(key)
+	}
+
+	if err := c.store.Delete(ctx, keys...); err != nil {
+		c.metrics.Error()
+		c.logger.WarnContext(ctx, "Failed to invalidate cache keys", "keys", keys, "error", err)
+		return
+	}
+	c.metrics.Invalidated(len(keys))
+}
+
+// suggestionsGeneration возвращает текущее поколение кэша подсказок
+func (c *cachedEventClient) suggestionsGeneration() string {
+	return strconv.FormatUint(c.suggestionsGen.Load(), 36)
+}
+
+// invalidateSuggestions начинает новое поколение кэша подсказок и количества событий в категориях
+func (c *cachedEventClient) invalidateSuggestions() {
+	c.suggestionsGen.Add(1)
+	c.metrics.Invalidated(1)
+}
+
+// - Кэшируемые чтения - \\
+
+func (c *cachedEventClient) GetEvent(ctx context.Context, in *pbEvent.GetEventReq, opts ...grpc.CallOption) (*pbEvent.EventRes, error) {
+	return cachedCall(ctx, c, eventCacheKey(in.GetId()), c.ttl.Event,
+		func() *pbEvent.EventRes { return &pbEvent.EventRes{} },
+		func(ctx context.Context) (*pbEvent.EventRes, error) {
+			return c.EventServiceClient.GetEvent(ctx, in, opts...)
+		},
+	)
+}
+
+func (c *cachedEventClient) GetCategory(ctx context.Context, in *pbEvent.GetCategoryReq, opts ...grpc.CallOption) (*pbEvent.CategoryRes, error) {
+	return cachedCall(ctx, c, categoryCacheKey(in.GetId()), c.ttl.Category,
+		func() *pbEvent.CategoryRes { return &pbEvent.CategoryRes{} },
+		func(ctx context.Context) (*pbEvent.CategoryRes, error) {
+			return c.EventServiceClient.GetCategory(ctx, in, opts...)
+		},
+	)
+}
+
+func (c *cachedEventClient) ListCategories(ctx context.Context, in *pbEvent.ListCategoriesReq, opts ...grpc.CallOption) (*pbEvent.ListCategoriesRes, error) {
+	key := categoriesCacheKey()
+	if in.GetIncludeCounts() {
+		key = categoryCountsCacheKey(c.suggestionsGeneration())
+	}
+	return cachedCall(ctx, c, key, c.ttl.Categories,
+		func() *pbEvent.ListCategoriesRes { return &pbEvent.ListCategoriesRes{} },
+		func(ctx context.Context) (*pbEvent.ListCategoriesRes, error) {
+			return c.EventServiceClient.ListCategories(ctx, in, opts...)
+		},
+	)
+}
+
+func (c *cachedEventClient) GetSuggestions(ctx context.Context, in *pbEvent.SuggestionReq, opts ...grpc.CallOption) (*pbEvent.SuggestionRes, error) {
+	return cachedCall(ctx, c, suggestionsCacheKey(c.suggestionsGeneration(), in), c.ttl.Suggestions,
+		func() *pbEvent.SuggestionRes { return &pbEvent.SuggestionRes{} },
+		func(ctx context.Context) (*pbEvent.SuggestionRes, error) {
+			return c.EventServiceClient.GetSuggestions(ctx, in, opts...)
+		},
+	)
+}
+
+// - Изменения с инвалидацией - \\
+
+func (c *cachedEventClient) CreateEvent(ctx context.Context, in *pbEvent.CreateEventReq, opts ...grpc.CallOption) (*pbEvent.EventRes, error) {
+	res, err := c.EventServiceClient.CreateEvent(ctx, in, opts...)
+	if err == nil {
+		c.invalidateSuggestions()
+	}
+	return res, err
+}
+
+func (c *cachedEventClient) UpdateEvent(ctx context.Context, in *pbEvent.UpdateEventReq, opts ...grpc.CallOption) (*pbEvent.EventRes, error) {
+	res, err := c.EventServiceClient.UpdateEvent(ctx, in, opts...)
+	if err == nil {
+		c.invalidate(ctx, eventCacheKey(in.GetId()))
+		c.invalidateSuggestions()
+	}
+	return res, err
+}
+
+func (c *cachedEventClient) DeleteEvent(ctx context.Context, in *pbEvent.DeleteEventReq, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	res, err := c.EventServiceClient.DeleteEvent(ctx, in, opts...)
+	if err == nil {
+		c.invalidate(ctx, eventCacheKey(in.GetId()))
+		c.invalidateSuggestions()
+	}
+	return res, err
+}
+
+func (c *cachedEventClient) CreateCategory(ctx context.Context, in *pbEvent.CreateCategoryReq, opts ...grpc.CallOption) (*pbEvent.CategoryRes, error) {
+	res, err := c.EventServiceClient.CreateCategory(ctx, in, opts...)
+	if err == nil {
+		c.invalidate(ctx, categoriesCacheKey())
+		// Новая категория должна появиться и в списке с количеством событий
+		c.invalidateSuggestions()
+	}
+	return res, err
+}
+
+func (c *cachedEventClient) UpdateCategory(ctx context.Context, in *pbEvent.UpdateCategoryReq, opts ...grpc.CallOption) (*pbEvent.CategoryRes, error) {
+	res, err := c.EventServiceClient.UpdateCategory(ctx, in, opts...)
+	if err == nil {
+		c.invalidate(ctx, categoryCacheKey(in.GetId()), categoriesCacheKey())
+		// Подсказки содержат названия категорий
+		c.invalidateSuggestions()
+	}
+	return res, err
+}
+
+func (c *cachedEventClient) DeleteCategory(ctx context.Context, in *pbEvent.DeleteCategoryReq, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	res, err := c.EventServiceClient.DeleteCategory(ctx, in, opts...)
+	if err == nil {
+		c.invalidate(ctx, categoryCacheKey(in.GetId()), categoriesCacheKey())
+		c.invalidateSuggestions()
+	}
+	return res, err
+}
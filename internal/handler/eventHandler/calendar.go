@@ -0,0 +1,201 @@
+package eventHandler
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rx3lixir/gateway-service/pkg/ical"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// calendarProdID идентификатор продукта в выгрузках iCalendar
+	calendarProdID = "-//rx3lixir//gateway-service//RU"
+
+	// calendarUIDDomain домен в UID событий календаря
+	calendarUIDDomain = "events.gateway-service"
+
+	// calendarFeedName отображаемое имя подписываемого календаря
+	calendarFeedName = "События"
+
+	// calendarFeedLookback насколько далеко в прошлое смотрит фид без date_from
+	calendarFeedLookback = 30 * 24 * time.Hour
+
+	// maxCalendarFeedEvents ограничивает количество событий в фиде
+	maxCalendarFeedEvents = 1000
+)
+
+// eventCalendarUID возвращает стабильный UID события для iCalendar
+func eventCalendarUID(id int64) string {
+	return "event-" + strconv.FormatInt(id, 10) + "@" + calendarUIDDomain
+}
+
+// HTTPEventToICalEvent конвертирует Event в событие календаря.
+// Возвращает false для событий без времени начала — их нельзя поставить в календарь.
+func HTTPEventToICalEvent(event *Event, categoryNames map[int64]string) (ical.Event, bool) {
+	if event == nil || event.StartsAt == nil {
+		return ical.Event{}, false
+	}
+
+	calEvent := ical.Event{
+		UID:         eventCalendarUID(event.Id),
+		Summary:     event.Name,
+		Description: event.Description,
+		Location:    event.Location,
+		Start:       *event.StartsAt,
+		End:         event.EndsAt,
+		RRule:       event.RecurrenceRule,
+		Created:     event.CreatedAt,
+		Lat:         event.Lat,
+		Lon:         event.Lon,
+	}
+
+	// source может быть как ссылкой на первоисточник, так и просто меткой
+	if strings.HasPrefix(event.Source, "http://") || strings.HasPrefix(event.Source, "https://") {
+		calEvent.URL = event.Source
+	}
+
+	if event.UpdatedAt != nil {
+		calEvent.LastModified = *event.UpdatedAt
+	}
+
+	if name, ok := categoryNames[event.CategoryID]; ok {
+		calEvent.Categories = []string{name}
+	}
+
+	return calEvent, true
+}
+
+// handleGetEventICS отдает одно событие в формате iCalendar ("добавить в календарь")
+func (h *eventHandler) handleGetEventICS(w http.ResponseWriter, r *http.Request) error {
+	id, err := parseIDFromURL(r, "id")
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Failed to parse ID from URL", "error", err)
+		return err
+	}
+
+	grpcCtx, cancel := h.createContext(r)
+	defer cancel()
+
+	h.logger.InfoContext(grpcCtx, "Sending GetEvent request for calendar download", "id", id)
+
+	protoEvent, err := h.eventClient.GetEvent(grpcCtx, IDToProtoGetEventByIDReq(id))
+	if err != nil {
+		h.logger.ErrorContext(grpcCtx, "Failed to get event via gRPC", "id", id, "error", err)
+		return err
+	}
+	if !canView(r, protoEvent) {
+		return status.Error(codes.NotFound, fmt.Sprintf("event with id %d not found", id))
+	}
+
+	var categoryNames map[int64]string
+	if category, err := h.eventClient.GetCategory(grpcCtx, IDToProtoGetCategoryByIDReq(int32(protoEvent.GetCategoryID()))); err == nil {
+		name, description := category.GetName(), ""
+		localize(r.Context(), &name, &description, categoryTranslationsFromProto(category.GetTranslations()))
+		categoryNames = map[int64]string{int64(category.GetId()): name}
+	}
+
+	httpEvent := ProtoEventResToHTTPEvent(protoEvent)
+	LocalizeEvents(r, httpEvent)
+	calEvent, ok := HTTPEventToICalEvent(httpEvent, categoryNames)
+	if !ok {
+		// У события нет представления в календаре
+		return status.Error(codes.NotFound, "event has no start time and cannot be added to a calendar")
+	}
+
+	version := resourceVersion(protoEvent.GetUpdatedAt(), protoEvent.GetCreatedAt())
+	setLocalizedETag(w, version, httpEvent.Lang, h.languages.Default)
+	setLastModified(w, version)
+	setContentLanguage(w, httpEvent.Lang)
+
+	filename := fmt.Sprintf("event-%d.ics", id)
+	w.Header().Set("Content-Type", contentTypeCalendar+"; charset=utf-8")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	w.WriteHeader(http.StatusOK)
+
+	cw := ical.NewWriter(w, calendarProdID, "")
+	cw.WriteEvent(calEvent)
+	if err := cw.Close(); err != nil {
+		h.logger.ErrorContext(grpcCtx, "Failed to write calendar", "id", id, "error", err)
+	}
+	return nil
+}
+
+// handleCalendarFeed отдает подписываемый календарь событий.
+// Поддерживает те же фильтры, что и GET /events. Если date_from не задан,
+// в календарь попадают события начиная с calendarFeedLookback назад.
+func (h *eventHandler) handleCalendarFeed(w http.ResponseWriter, r *http.Request) error {
+	filterReq, err := ParseQueryParams(r.URL.Query())
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Failed to parse calendar query parameters", "error", err)
+		return fmt.Errorf("invalid query parameters: %w", err)
+	}
+	filterReq.Limit, filterReq.Offset = nil, nil
+	filterReq.IncludeCount, filterReq.IncludeFacets = nil, nil
+	if filterReq.Statuses, err = visibleStatuses(r, filterReq.Statuses); err != nil {
+		return err
+	}
+	if err := h.expandCategoryFilter(r, filterReq); err != nil {
+		return err
+	}
+
+	if filterReq.DateFrom == nil {
+		dateFrom := time.Now().UTC().Add(-calendarFeedLookback).Format(legacyDateLayout)
+		filterReq.DateFrom = &dateFrom
+	}
+
+	h.logger.InfoContext(r.Context(), "Handling calendar feed request",
+		"category_ids", filterReq.CategoryIDs,
+		"date_from", *filterReq.DateFrom)
+
+	// Фид ограничен по размеру, поэтому собираем его целиком: так ошибка
+	// event-service вернется обычным ответом, а не оборванным календарем
+	var events []*Event
+	for offset := 0; len(events) < maxCalendarFeedEvents; {
+		page, _, err := h.exportPage(r, filterReq, offset)
+		if err != nil {
+			return err
+		}
+		events = append(events, page...)
+		if len(page) < exportPageSize {
+			break
+		}
+		offset += len(page)
+	}
+	if len(events) > maxCalendarFeedEvents {
+		events = events[:maxCalendarFeedEvents]
+	}
+
+	grpcCtx, cancel := h.createContext(r)
+	categoryNames := h.categoryNames(grpcCtx)
+	cancel()
+	setContentLanguage(w, LocalizeEvents(r, events...))
+
+	// Last-Modified не ставим: время последнего изменения не меняется, когда событие
+	// удаляют или оно выпадает из фильтра. Условные запросы проверяются по ETag тела.
+
+	w.Header().Set("Content-Type", contentTypeCalendar+"; charset=utf-8")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": "calendar.ics"}))
+	w.WriteHeader(http.StatusOK)
+
+	cw := ical.NewWriter(w, calendarProdID, calendarFeedName)
+	written := 0
+	for _, event := range events {
+		if calEvent, ok := HTTPEventToICalEvent(event, categoryNames); ok {
+			cw.WriteEvent(calEvent)
+			written++
+		}
+	}
+	if err := cw.Close(); err != nil {
+		h.logger.ErrorContext(r.Context(), "Failed to write calendar feed", "error", err)
+		return nil
+	}
+
+	h.logger.InfoContext(r.Context(), "Calendar feed written", "events", written)
+	return nil
+}
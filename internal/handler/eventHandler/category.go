@@ -0,0 +1,286 @@
+package eventHandler
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	pbEvent "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/event"
+	"github.com/rx3lixir/gateway-service/pkg/textmatch"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// maxCategorySlugLength ограничивает длину slug категории
+	maxCategorySlugLength = 100
+
+	// maxCategoryDescriptionLength ограничивает длину описания категории
+	maxCategoryDescriptionLength = 2000
+)
+
+// categorySlugPattern slug: латиница в нижнем регистре и цифры, слова через дефис
+var categorySlugPattern = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+
+// numericSlugPattern slug из одних цифр неотличим от ID в /categories/{id}
+var numericSlugPattern = regexp.MustCompile(`^[0-9]+$`)
+
+// slugTranslit транслитерация кириллицы для slug
+var slugTranslit = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ж': "zh", 'з': "z",
+	'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p",
+	'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch",
+	'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+}
+
+// slugify строит slug из названия: "Стендап и юмор" -> "stendap-i-yumor".
+// Символы без транслитерации отбрасываются.
+func slugify(name string) string {
+	words := strings.Fields(textmatch.Normalize(name))
+	parts := make([]string, 0, len(words))
+	for _, word := range words {
+		var b strings.Builder
+		for _, r := range word {
+			switch {
+			case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+				b.WriteRune(r)
+			default:
+				b.WriteString(slugTranslit[r])
+			}
+		}
+		if b.Len() > 0 {
+			parts = append(parts, b.String())
+		}
+	}
+
+	slug := strings.Join(parts, "-")
+	if len(slug) > maxCategorySlugLength {
+		slug = strings.TrimRight(slug[:maxCategorySlugLength], "-")
+	}
+	return slug
+}
+
+// validateCategorySlug проверяет формат slug
+func validateCategorySlug(slug string) error {
+	if slug == "" {
+		return fmt.Errorf("category slug is required")
+	}
+	if len(slug) > maxCategorySlugLength || !categorySlugPattern.MatchString(slug) {
+		return fmt.Errorf("invalid slug %q: expected lowercase latin letters, digits and hyphens, at most %d characters", slug, maxCategorySlugLength)
+	}
+	if numericSlugPattern.MatchString(slug) {
+		return fmt.Errorf("invalid slug %q: must contain at least one letter or hyphen", slug)
+	}
+	return nil
+}
+
+// validateCategoryFields проверяет slug и описание категории, если они заданы
+func validateCategoryFields(slug, description *string) error {
+	if slug != nil {
+		if err := validateCategorySlug(*slug); err != nil {
+			return err
+		}
+	}
+	if description != nil && len([]rune(*description)) > maxCategoryDescriptionLength {
+		return fmt.Errorf("invalid description: must be at most %d characters", maxCategoryDescriptionLength)
+	}
+	return nil
+}
+
+// categoryIndex индекс категорий по ID, slug и родителю
+type categoryIndex struct {
+	byID     map[int32]*pbEvent.CategoryRes
+	bySlug   map[string]*pbEvent.CategoryRes
+	children map[int32][]int32 // 0 — корневые категории
+}
+
+func newCategoryIndex(categories []*pbEvent.CategoryRes) *categoryIndex {
+	idx := &categoryIndex{
+		byID:     make(map[int32]*pbEvent.CategoryRes, len(categories)),
+		bySlug:   make(map[string]*pbEvent.CategoryRes, len(categories)),
+		children: make(map[int32][]int32),
+	}
+	for _, category := range categories {
+		idx.byID[category.GetId()] = category
+		if category.GetSlug() != "" {
+			idx.bySlug[category.GetSlug()] = category
+		}
+	}
+	for _, category := range categories {
+		idx.children[idx.parentOf(category)] = append(idx.children[idx.parentOf(category)], category.GetId())
+	}
+	return idx
+}
+
+// parentOf возвращает родителя категории; категории с несуществующим родителем считаются корневыми
+func (idx *categoryIndex) parentOf(category *pbEvent.CategoryRes) int32 {
+	if _, ok := idx.byID[category.GetParentId()]; ok && category.ParentId != nil {
+		return category.GetParentId()
+	}
+	return 0
+}
+
+// descendants возвращает всех потомков категории без нее самой
+func (idx *categoryIndex) descendants(id int32) []int32 {
+	var result []int32
+	seen := map[int32]bool{id: true}
+	queue := []int32{id}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, child := range idx.children[current] {
+			if seen[child] {
+				continue
+			}
+			seen[child] = true
+			result = append(result, child)
+			queue = append(queue, child)
+		}
+	}
+	return result
+}
+
+// validateParent проверяет, что parentID существует и не является самой категорией id
+// или ее потомком. Для новой категории id = 0.
+func (idx *categoryIndex) validateParent(id int32, parentID *int) error {
+	if parentID == nil {
+		return nil
+	}
+	parent := int32(*parentID)
+	if _, ok := idx.byID[parent]; !ok {
+		return fmt.Errorf("invalid parent_id %d: no such category", *parentID)
+	}
+	if id != 0 && (parent == id || slices.Contains(idx.descendants(id), parent)) {
+		return fmt.Errorf("invalid parent_id %d: category cannot be moved under itself or its subcategory", *parentID)
+	}
+	return nil
+}
+
+// checkSlugAvailable проверяет, что slug не занят другой категорией
+func (idx *categoryIndex) checkSlugAvailable(slug string, id int32) error {
+	if other, ok := idx.bySlug[slug]; ok && other.GetId() != id {
+		return status.Errorf(codes.AlreadyExists, "category with slug %q already exists", slug)
+	}
+	return nil
+}
+
+// loadCategoryIndex загружает все категории. includeCounts добавляет количество событий.
+func (h *eventHandler) loadCategoryIndex(ctx context.Context, includeCounts bool) (*categoryIndex, []*pbEvent.CategoryRes, error) {
+	res, err := h.eventClient.ListCategories(ctx, &pbEvent.ListCategoriesReq{IncludeCounts: &includeCounts})
+	if err != nil {
+		return nil, nil, err
+	}
+	return newCategoryIndex(res.GetCategories()), res.GetCategories(), nil
+}
+
+// resolveCategoryID возвращает ID категории из URL: числовой ID или slug
+func (h *eventHandler) resolveCategoryID(ctx context.Context, r *http.Request) (int32, error) {
+	raw := chi.URLParam(r, "id")
+	if _, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		id, err := parseIDFromURL(r, "id")
+		return int32(id), err
+	}
+
+	if err := validateCategorySlug(raw); err != nil {
+		return 0, err
+	}
+	idx, _, err := h.loadCategoryIndex(ctx, false)
+	if err != nil {
+		return 0, err
+	}
+	category, ok := idx.bySlug[raw]
+	if !ok {
+		return 0, status.Errorf(codes.NotFound, "category with slug %q not found", raw)
+	}
+	return category.GetId(), nil
+}
+
+// buildCategoryList конвертирует категории для ответа на языке запроса. При tree = true
+// возвращает корневые категории с вложенными children, иначе плоский список. Соседние
+// категории сортируются по sort_order, затем по переведенному названию. Если у категорий есть event_count,
+// заполняется total_event_count — вместе с подкатегориями.
+func buildCategoryList(r *http.Request, idx *categoryIndex, protoCategories []*pbEvent.CategoryRes, tree bool) []*Category {
+	nodes := make(map[int32]*Category, len(protoCategories))
+	for _, protoCategory := range protoCategories {
+		nodes[protoCategory.GetId()] = ProtoCategoryResToHTTPCategory(protoCategory)
+		localizeCategories(r, nodes[protoCategory.GetId()])
+	}
+
+	var attach func(parent int32) []*Category
+	attach = func(parent int32) []*Category {
+		var children []*Category
+		for _, id := range idx.children[parent] {
+			node := nodes[id]
+			node.Children = attach(id)
+			if node.EventCount != nil {
+				total := *node.EventCount
+				for _, child := range node.Children {
+					total += valueOrZero(child.TotalEventCount)
+				}
+				node.TotalEventCount = &total
+			}
+			children = append(children, node)
+		}
+		slices.SortFunc(children, func(a, b *Category) int {
+			return cmp.Or(cmp.Compare(a.SortOrder, b.SortOrder), strings.Compare(a.Name, b.Name), cmp.Compare(a.Id, b.Id))
+		})
+		return children
+	}
+	roots := attach(0)
+	if roots == nil {
+		roots = []*Category{}
+	}
+	if tree {
+		return roots
+	}
+
+	// Плоский список в порядке обхода дерева
+	flat := make([]*Category, 0, len(nodes))
+	var walk func([]*Category)
+	walk = func(categories []*Category) {
+		for _, category := range categories {
+			flat = append(flat, category)
+			walk(category.Children)
+			category.Children = nil
+		}
+	}
+	walk(roots)
+	return flat
+}
+
+// expandCategoryFilter при include_subcategories добавляет к фильтру по категориям всех потомков
+func (h *eventHandler) expandCategoryFilter(r *http.Request, req *ListEventsReq) error {
+	if !valueOrZero(req.IncludeSubcategories) || len(req.CategoryIDs) == 0 {
+		return nil
+	}
+
+	ctx, cancel := h.createContext(r)
+	defer cancel()
+
+	return h.expandCategoryIDs(ctx, req)
+}
+
+// expandCategoryIDs добавляет к category_ids все подкатегории
+func (h *eventHandler) expandCategoryIDs(ctx context.Context, req *ListEventsReq) error {
+	idx, _, err := h.loadCategoryIndex(ctx, false)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Failed to load categories for subcategory filter", "error", err)
+		return err
+	}
+
+	expanded := slices.Clone(req.CategoryIDs)
+	for _, id := range req.CategoryIDs {
+		for _, child := range idx.descendants(int32(id)) {
+			expanded = append(expanded, int64(child))
+		}
+	}
+	slices.Sort(expanded)
+	req.CategoryIDs = slices.Compact(expanded)
+	return nil
+}
@@ -0,0 +1,376 @@
+package eventHandler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+
+	pbEvent "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/event"
+	"github.com/rx3lixir/gateway-service/pkg/cache"
+	"github.com/rx3lixir/gateway-service/pkg/i18n"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Стратегии удаления категории, на которую ссылаются события и подкатегории
+const (
+	deleteStrategyReject   = "reject"   // 409, если есть события или подкатегории
+	deleteStrategyReassign = "reassign" // События и подкатегории переносятся в категорию to
+	deleteStrategyCascade  = "cascade"  // События и подкатегории удаляются вместе с категорией
+
+	// categoryMigrationPageSize сколько событий категории загружается за раз при переносе или удалении
+	categoryMigrationPageSize = 500
+)
+
+var deleteStrategies = []string{deleteStrategyReject, deleteStrategyReassign, deleteStrategyCascade}
+
+// categoryDeleteOptions параметры DELETE /categories/{id}
+type categoryDeleteOptions struct {
+	strategy string
+	to       *int32
+	dryRun   bool
+}
+
+// parseCategoryDeleteOptions разбирает strategy, to и dry_run
+func parseCategoryDeleteOptions(r *http.Request) (categoryDeleteOptions, error) {
+	query := r.URL.Query()
+	opts := categoryDeleteOptions{strategy: query.Get("strategy")}
+	if opts.strategy == "" {
+		opts.strategy = deleteStrategyReject
+	}
+	if !slices.Contains(deleteStrategies, opts.strategy) {
+		return opts, fmt.Errorf("invalid strategy %q: expected one of reject, reassign, cascade", opts.strategy)
+	}
+
+	if raw := query.Get("to"); raw != "" {
+		if opts.strategy != deleteStrategyReassign {
+			return opts, fmt.Errorf("invalid to parameter: only used with strategy=reassign")
+		}
+		to, err := strconv.ParseInt(raw, 10, 32)
+		if err != nil || to <= 0 {
+			return opts, fmt.Errorf("invalid to parameter %q: expected category ID", raw)
+		}
+		to32 := int32(to)
+		opts.to = &to32
+	} else if opts.strategy == deleteStrategyReassign {
+		return opts, fmt.Errorf("to parameter is required for strategy=reassign")
+	}
+
+	var err error
+	opts.dryRun, err = parseBoolParam(r, "dry_run")
+	return opts, err
+}
+
+// handleDeleteCategory удаляет указанную категорию.
+//
+// По умолчанию (strategy=reject) категорию, на которую ссылаются события или
+// подкатегории, удалить нельзя — 409. strategy=reassign&to=ID переносит события
+// и подкатегории в категорию to, strategy=cascade удаляет их. dry_run=true
+// только возвращает, сколько событий и подкатегорий будет затронуто.
+func (h *eventHandler) handleDeleteCategory(w http.ResponseWriter, r *http.Request) error {
+	id, err := parseIDFromURL(r, "id")
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Failed to parse ID from URL", "error", err)
+		return err
+	}
+
+	opts, err := parseCategoryDeleteOptions(r)
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Invalid category delete options", "id", id, "error", err)
+		return err
+	}
+
+	h.logger.InfoContext(r.Context(), "Handling request to delete category",
+		"id", id,
+		"strategy", opts.strategy,
+		"dry_run", opts.dryRun)
+
+	grpcCtx, cancel := h.createContext(r)
+	defer cancel()
+
+	deleteReq := IDToProtoDeleteCategoryReq(int32(id))
+
+	// Условное удаление по If-Match
+	deleteReq.ExpectedUpdatedAt, err = expectedVersion(r, func() (*timestamppb.Timestamp, error) {
+		category, err := h.eventClient.GetCategory(cache.Bypass(grpcCtx), IDToProtoGetCategoryByIDReq(int32(id)))
+		if err != nil {
+			return nil, err
+		}
+		return resourceVersion(category.GetUpdatedAt(), category.GetCreatedAt()), nil
+	})
+	if err == nil && deleteReq.ExpectedUpdatedAt != nil {
+		// Версию проверяем до переноса событий: иначе несовпадение обнаружится,
+		// когда события уже перенесены или удалены
+		err = h.checkCategoryVersion(grpcCtx, int32(id), deleteReq.ExpectedUpdatedAt)
+	}
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Category precondition failed", "id", id, "if_match", r.Header.Get("If-Match"))
+		return err
+	}
+
+	idx, _, err := h.loadCategoryIndex(cache.Bypass(grpcCtx), false)
+	if err != nil {
+		h.logger.ErrorContext(grpcCtx, "Failed to list categories via gRPC", "error", err)
+		return err
+	}
+	if _, ok := idx.byID[int32(id)]; !ok {
+		return status.Errorf(codes.NotFound, "category with id %d not found", id)
+	}
+
+	// Затрагиваемые подкатегории и категории, события которых переносятся или удаляются
+	children := idx.children[int32(id)]
+	eventCategories := []int64{id}
+	if opts.strategy == deleteStrategyCascade {
+		children = idx.descendants(int32(id))
+		for _, child := range children {
+			eventCategories = append(eventCategories, int64(child))
+		}
+	}
+
+	if opts.to != nil {
+		if *opts.to == int32(id) || slices.Contains(idx.descendants(int32(id)), *opts.to) {
+			return fmt.Errorf("invalid to parameter %d: cannot reassign to the category itself or its subcategory", *opts.to)
+		}
+		if _, ok := idx.byID[*opts.to]; !ok {
+			return fmt.Errorf("invalid to parameter %d: no such category", *opts.to)
+		}
+	}
+
+	eventCount, err := h.countCategoryEvents(r, eventCategories)
+	if err != nil {
+		h.logger.ErrorContext(grpcCtx, "Failed to count category events via gRPC", "id", id, "error", err)
+		return err
+	}
+
+	res := &DeleteCategoryRes{
+		CategoryID:    int(id),
+		Strategy:      opts.strategy,
+		DryRun:        opts.dryRun,
+		Events:        eventCount,
+		Subcategories: len(children),
+	}
+	if opts.to != nil {
+		to := int(*opts.to)
+		res.ReassignTo = &to
+	}
+
+	if opts.strategy == deleteStrategyReject && (eventCount > 0 || len(children) > 0) {
+		if opts.dryRun {
+			res.Message = fmt.Sprintf("category %d cannot be deleted with strategy=reject", id)
+			return WriteJSON(w, http.StatusOK, res)
+		}
+		h.logger.WarnContext(grpcCtx, "Category is still referenced", "id", id, "events", eventCount, "subcategories", len(children))
+		return status.Errorf(codes.Aborted,
+			"category %d is referenced by %d events and %d subcategories: use strategy=reassign&to=ID or strategy=cascade",
+			id, eventCount, len(children))
+	}
+
+	if opts.dryRun {
+		res.Message = "dry run: no changes made"
+		return WriteJSON(w, http.StatusOK, res)
+	}
+
+	// События
+	if eventCount > 0 {
+		moved, err := h.migrateCategoryEvents(r, eventCategories, opts.to)
+		res.Events = moved
+		if err != nil {
+			h.logger.ErrorContext(grpcCtx, "Failed to migrate category events",
+				"id", id,
+				"strategy", opts.strategy,
+				"processed", moved,
+				"error", err)
+			res.Subcategories = 0
+			return writeCategoryDeleteInterrupted(w, r, res, err)
+		}
+	}
+
+	// Подкатегории: перенос под to или удаление начиная с самых глубоких
+	for i := range children {
+		ctx, cancel := h.createContext(r)
+		child := children[i]
+		if opts.to != nil {
+			_, err = h.eventClient.UpdateCategory(ctx, &pbEvent.UpdateCategoryReq{
+				Id:         child,
+				ParentId:   opts.to,
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"parent_id"}},
+			})
+		} else {
+			// descendants идут от родителей к потомкам, удаляем в обратном порядке
+			child = children[len(children)-1-i]
+			_, err = h.eventClient.DeleteCategory(ctx, IDToProtoDeleteCategoryReq(child))
+		}
+		cancel()
+		if err != nil && status.Code(err) != codes.NotFound {
+			h.logger.ErrorContext(grpcCtx, "Failed to process subcategory", "id", id, "subcategory", child, "strategy", opts.strategy, "error", err)
+			res.Subcategories = i
+			return writeCategoryDeleteInterrupted(w, r, res, fmt.Errorf("subcategory %d: %w", child, err))
+		}
+	}
+
+	h.logger.InfoContext(grpcCtx, "Sending DeleteCategory request to gRPC service", "id", id)
+
+	// Перенос событий мог занять дольше таймаута grpcCtx. Маршрут подключен
+	// без общего таймаута, поэтому новый контекст живет, пока жив запрос
+	deleteCtx, cancelDelete := h.createContext(r)
+	defer cancelDelete()
+
+	_, err = h.eventClient.DeleteCategory(deleteCtx, deleteReq)
+	if status.Code(err) == codes.FailedPrecondition && deleteReq.ExpectedUpdatedAt == nil {
+		// Без If-Match FailedPrecondition означает, что на категорию появились новые ссылки
+		h.logger.WarnContext(grpcCtx, "Category is still referenced", "id", id, "error", err)
+		return status.Errorf(codes.Aborted, "category %d is still referenced, retry: %s", id, status.Convert(err).Message())
+	}
+	if err != nil {
+		h.logger.ErrorContext(grpcCtx, "Failed to delete category via gRPC", "id", id, "error", err)
+		return preconditionError(err, deleteReq.ExpectedUpdatedAt)
+	}
+
+	h.logger.InfoContext(grpcCtx, "Category deleted successfully",
+		"id", id,
+		"strategy", opts.strategy,
+		"events", res.Events,
+		"subcategories", res.Subcategories)
+
+	res.Message = fmt.Sprintf("category %d successfully deleted", id)
+	return WriteJSON(w, http.StatusOK, res)
+}
+
+// checkCategoryVersion сравнивает версию из If-Match с текущей версией категории
+func (h *eventHandler) checkCategoryVersion(ctx context.Context, id int32, expected *timestamppb.Timestamp) error {
+	category, err := h.eventClient.GetCategory(cache.Bypass(ctx), IDToProtoGetCategoryByIDReq(id))
+	if err != nil {
+		return err
+	}
+	if !resourceVersion(category.GetUpdatedAt(), category.GetCreatedAt()).AsTime().Equal(expected.AsTime()) {
+		return errPreconditionFailed
+	}
+	return nil
+}
+
+// writeCategoryDeleteInterrupted отвечает, что удаление прервано на середине:
+// категория не удалена, а res.Events и res.Subcategories уже обработаны.
+// Повторный запрос продолжит с оставшихся событий и подкатегорий.
+func writeCategoryDeleteInterrupted(w http.ResponseWriter, r *http.Request, res *DeleteCategoryRes, err error) error {
+	res.Message = fmt.Sprintf("category %d was not deleted: processed %d events and %d subcategories before the error, retry to continue",
+		res.CategoryID, res.Events, res.Subcategories)
+	res.Error = i18n.Localize(r.Context(), batchErrorMessage(err))
+	return WriteJSON(w, grpcHTTPStatus(status.Code(err)), res)
+}
+
+// countCategoryEvents возвращает количество событий категорий в любом статусе
+func (h *eventHandler) countCategoryEvents(r *http.Request, categoryIDs []int64) (int64, error) {
+	ctx, cancel := h.createContext(r)
+	defer cancel()
+
+	limit, includeCount := int32(1), true
+	res, err := h.eventClient.ListEvents(ctx, &pbEvent.ListEventsReq{
+		CategoryIDs:  categoryIDs,
+		Limit:        &limit,
+		IncludeCount: &includeCount,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return res.GetPagination().GetTotalCount(), nil
+}
+
+// migrateCategoryEvents переносит события категорий в to или, если to = nil, удаляет их.
+// Обработанные события выпадают из фильтра, поэтому каждый раз читается первая страница.
+// Возвращает количество обработанных событий.
+func (h *eventHandler) migrateCategoryEvents(r *http.Request, categoryIDs []int64, to *int32) (int64, error) {
+	var processed int64
+	done := make(map[int64]bool)
+
+	for {
+		ctx, cancel := h.createContext(r)
+		limit := int32(categoryMigrationPageSize)
+		page, err := h.eventClient.ListEvents(ctx, &pbEvent.ListEventsReq{CategoryIDs: categoryIDs, Limit: &limit})
+		cancel()
+		if err != nil {
+			return processed, err
+		}
+
+		var pending []*pbEvent.EventRes
+		for _, event := range page.GetEvents() {
+			if !done[event.GetId()] {
+				pending = append(pending, event)
+			}
+		}
+		if len(pending) == 0 {
+			if len(page.GetEvents()) > 0 {
+				// Сервис все еще возвращает обработанные события — не зацикливаемся
+				return processed, status.Errorf(codes.Aborted, "events are still listed under the category after migration, retry later")
+			}
+			return processed, nil
+		}
+
+		var (
+			mu       sync.Mutex
+			firstErr error
+			wg       sync.WaitGroup
+		)
+		sem := make(chan struct{}, batchConcurrency)
+		for _, event := range pending {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+
+				err := h.migrateEvent(r, event, to)
+
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					if firstErr == nil {
+						firstErr = fmt.Errorf("event %d: %w", event.GetId(), err)
+					}
+					return
+				}
+				done[event.GetId()] = true
+				processed++
+			}()
+		}
+		wg.Wait()
+
+		if firstErr != nil {
+			return processed, firstErr
+		}
+	}
+}
+
+// migrateEvent переносит одно событие в категорию to или удаляет его
+func (h *eventHandler) migrateEvent(r *http.Request, event *pbEvent.EventRes, to *int32) error {
+	ctx, cancel := h.createContext(r)
+	defer cancel()
+
+	if to == nil {
+		_, err := h.eventClient.DeleteEvent(ctx, &pbEvent.DeleteEventReq{Id: event.GetId()})
+		if status.Code(err) == codes.NotFound {
+			return nil
+		}
+		if err == nil {
+			h.publishChange(r.Context(), changeDeleted, event.GetId(), event)
+		}
+		return err
+	}
+
+	updated, err := h.eventClient.UpdateEvent(ctx, &pbEvent.UpdateEventReq{
+		Id:         event.GetId(),
+		CategoryID: int64(*to),
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"categoryID"}},
+	})
+	if status.Code(err) == codes.NotFound {
+		return nil
+	}
+	if err == nil {
+		h.publishUpdate(r.Context(), event.GetId(), event, updated)
+	}
+	return err
+}
@@ -0,0 +1,189 @@
+package eventHandler
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	pbEvent "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/event"
+	"github.com/rx3lixir/gateway-service/pkg/textmatch"
+)
+
+const (
+	// duplicateNameThreshold минимальное сходство названий для дубликата
+	duplicateNameThreshold = 0.65
+
+	// duplicateScoreThreshold минимальная общая оценка сходства (название и место)
+	duplicateScoreThreshold = 0.7
+
+	// duplicateNameWeight вес названия в общей оценке, остальное — место
+	duplicateNameWeight = 0.7
+
+	// duplicateTimeWindow события одного дня, начинающиеся дальше друг от друга,
+	// считаются разными (дневной и вечерний сеанс)
+	duplicateTimeWindow = 2 * time.Hour
+
+	// duplicateLookupPageSize сколько событий того же дня загружается за один запрос
+	duplicateLookupPageSize = 500
+
+	// maxDuplicateCandidates сколько кандидатов возвращается в ответе 409
+	maxDuplicateCandidates = 5
+)
+
+// duplicateStatuses статусы, среди которых ищутся дубликаты: отклоненные и архивные не мешают
+var duplicateStatuses = []string{eventStatusDraft, eventStatusPending, eventStatusPublished}
+
+// eventFingerprint нормализованные название, дата и место события для поиска дубликатов
+type eventFingerprint struct {
+	Key      string // textmatch.Fingerprint(name, date, location)
+	Date     string // YYYY-MM-DD
+	StartsAt *time.Time
+	name     textmatch.Profile
+	location textmatch.Profile
+}
+
+// newEventFingerprint строит отпечаток создаваемого события
+func newEventFingerprint(req *CreateEventReq, sched *eventSchedule) eventFingerprint {
+	date := req.Date
+	var startsAt *time.Time
+	if sched != nil && sched.StartsAt != nil {
+		date, startsAt = sched.Date, sched.StartsAt
+	}
+	return makeFingerprint(req.Name, date, req.Location, startsAt)
+}
+
+// protoEventFingerprint строит отпечаток существующего события
+func protoEventFingerprint(event *pbEvent.EventRes) eventFingerprint {
+	var startsAt *time.Time
+	if event.GetStartsAt() != nil {
+		t := event.GetStartsAt().AsTime()
+		startsAt = &t
+	}
+	return makeFingerprint(event.GetName(), event.GetDate(), event.GetLocation(), startsAt)
+}
+
+func makeFingerprint(name, date, location string, startsAt *time.Time) eventFingerprint {
+	return eventFingerprint{
+		Key:      textmatch.Fingerprint(name, date, location),
+		Date:     date,
+		StartsAt: startsAt,
+		name:     textmatch.NewProfile(name),
+		location: textmatch.NewProfile(location),
+	}
+}
+
+// duplicateScore оценивает сходство двух событий; ok = true, если это вероятный дубликат
+func duplicateScore(a, b eventFingerprint) (score float64, ok bool) {
+	if a.Date == "" || a.Date != b.Date {
+		return 0, false
+	}
+	if a.StartsAt != nil && b.StartsAt != nil {
+		if diff := a.StartsAt.Sub(*b.StartsAt); diff > duplicateTimeWindow || diff < -duplicateTimeWindow {
+			return 0, false
+		}
+	}
+
+	nameScore := a.name.Similarity(b.name)
+	if nameScore < duplicateNameThreshold {
+		return nameScore, false
+	}
+
+	// Если место не указано у одного из событий, сравниваем только названия
+	score = nameScore
+	if !a.location.Empty() && !b.location.Empty() {
+		score = duplicateNameWeight*nameScore + (1-duplicateNameWeight)*a.location.Similarity(b.location)
+	}
+	return math.Round(score*100) / 100, score >= duplicateScoreThreshold
+}
+
+// parseForce разбирает параметр force, отключающий проверку дубликатов
+func parseForce(r *http.Request) (bool, error) {
+	return parseBoolParam(r, "force")
+}
+
+// duplicateFinder ищет дубликаты среди существующих событий. События одного
+// дня загружаются один раз, поэтому один finder используется на весь пакет.
+type duplicateFinder struct {
+	h    *eventHandler
+	mu   sync.Mutex
+	days map[string][]*pbEvent.EventRes
+}
+
+func (h *eventHandler) newDuplicateFinder() *duplicateFinder {
+	return &duplicateFinder{h: h, days: make(map[string][]*pbEvent.EventRes)}
+}
+
+// find возвращает вероятные дубликаты, самые похожие первыми.
+// Кандидаты, которые вызывающий не может видеть, не возвращаются.
+func (f *duplicateFinder) find(ctx context.Context, r *http.Request, fp eventFingerprint) ([]DuplicateCandidate, error) {
+	if fp.Date == "" || fp.name.Empty() {
+		return nil, nil
+	}
+
+	events, err := f.eventsOn(ctx, fp.Date)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []DuplicateCandidate
+	for _, event := range events {
+		score, ok := duplicateScore(fp, protoEventFingerprint(event))
+		if !ok || !canView(r, event) {
+			continue
+		}
+		httpEvent := ProtoEventResToHTTPEvent(event)
+		hideModerationDetails(r, httpEvent)
+		candidates = append(candidates, DuplicateCandidate{Score: score, Event: httpEvent})
+	}
+
+	slices.SortStableFunc(candidates, func(a, b DuplicateCandidate) int {
+		switch {
+		case a.Score > b.Score:
+			return -1
+		case a.Score < b.Score:
+			return 1
+		}
+		return 0
+	})
+	if len(candidates) > maxDuplicateCandidates {
+		candidates = candidates[:maxDuplicateCandidates]
+	}
+	return candidates, nil
+}
+
+// eventsOn загружает все события дня (без раскрытия повторяющихся серий) постранично:
+// дубликат может оказаться за пределами первой страницы
+func (f *duplicateFinder) eventsOn(ctx context.Context, date string) ([]*pbEvent.EventRes, error) {
+	f.mu.Lock()
+	events, ok := f.days[date]
+	f.mu.Unlock()
+	if ok {
+		return events, nil
+	}
+
+	for {
+		limit, offset := int32(duplicateLookupPageSize), int32(len(events))
+		res, err := f.h.eventClient.ListEvents(ctx, &pbEvent.ListEventsReq{
+			DateFrom: &date,
+			DateTo:   &date,
+			Limit:    &limit,
+			Offset:   &offset,
+			Statuses: duplicateStatuses,
+		})
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, res.GetEvents()...)
+		if len(res.GetEvents()) < duplicateLookupPageSize {
+			break
+		}
+	}
+
+	f.mu.Lock()
+	f.days[date] = events
+	f.mu.Unlock()
+	return events, nil
+}
@@ -0,0 +1,145 @@
+package eventHandler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// errPreconditionFailed возвращается, если версия ресурса не совпадает с If-Match.
+// Только эта ошибка дает 412; остальные FailedPrecondition сервиса — 409.
+var errPreconditionFailed = errors.New("resource has been modified: If-Match does not match current ETag")
+
+// preconditionError переводит отказ сервиса по expected_updated_at в errPreconditionFailed.
+// expected — версия из If-Match: без нее FailedPrecondition не связан с заголовком.
+func preconditionError(err error, expected *timestamppb.Timestamp) error {
+	if expected != nil && status.Code(err) == codes.FailedPrecondition {
+		return errPreconditionFailed
+	}
+	return err
+}
+
+// resourceVersion возвращает версию ресурса: updated_at, а для ни разу
+// не обновлявшихся ресурсов — created_at
+func resourceVersion(updatedAt, createdAt *timestamppb.Timestamp) *timestamppb.Timestamp {
+	if updatedAt != nil && updatedAt.IsValid() {
+		return updatedAt
+	}
+	if createdAt != nil && createdAt.IsValid() {
+		return createdAt
+	}
+	return nil
+}
+
+// versionETag формирует сильный ETag из версии ресурса
+func versionETag(version *timestamppb.Timestamp) string {
+	if version == nil {
+		return ""
+	}
+	return `"` + strconv.FormatInt(version.AsTime().UnixNano(), 36) + `"`
+}
+
+// localizedETag формирует ETag ответа на языке lang. Варианты ответа на разных
+// языках (Vary: Accept-Language) должны различаться, поэтому к ETag версии
+// добавляется суффикс языка, кроме языка по умолчанию
+func localizedETag(version *timestamppb.Timestamp, lang, defaultLang string) string {
+	etag := versionETag(version)
+	if etag == "" || lang == "" || lang == defaultLang {
+		return etag
+	}
+	return etag[:len(etag)-1] + "-" + lang + `"`
+}
+
+// etagVersion восстанавливает версию ресурса из ETag, сформированного versionETag
+// или localizedETag: суффикс языка не влияет на версию
+func etagVersion(etag string) (*timestamppb.Timestamp, bool) {
+	if strings.HasPrefix(etag, "W/") {
+		// Слабые ETag не подходят для If-Match (RFC 9110, 13.1.1)
+		return nil, false
+	}
+	if len(etag) < 2 || etag[0] != '"' || etag[len(etag)-1] != '"' {
+		return nil, false
+	}
+	value, _, _ := strings.Cut(etag[1:len(etag)-1], "-")
+	nanos, err := strconv.ParseInt(value, 36, 64)
+	if err != nil {
+		return nil, false
+	}
+	return timestamppb.New(time.Unix(0, nanos)), true
+}
+
+// setVersionETag устанавливает заголовок ETag, если версия известна
+func setVersionETag(w http.ResponseWriter, version *timestamppb.Timestamp) {
+	if etag := versionETag(version); etag != "" {
+		w.Header().Set("ETag", etag)
+	}
+}
+
+// setLocalizedETag устанавливает заголовок ETag ответа на языке lang, см. localizedETag
+func setLocalizedETag(w http.ResponseWriter, version *timestamppb.Timestamp, lang, defaultLang string) {
+	if etag := localizedETag(version, lang, defaultLang); etag != "" {
+		w.Header().Set("ETag", etag)
+	}
+}
+
+// setLastModified устанавливает заголовок Last-Modified, если версия известна
+func setLastModified(w http.ResponseWriter, version *timestamppb.Timestamp) {
+	if version != nil {
+		w.Header().Set("Last-Modified", version.AsTime().UTC().Format(http.TimeFormat))
+	}
+}
+
+// parseIfMatch разбирает заголовок If-Match: список ETag или "*"
+func parseIfMatch(r *http.Request) (tags []string, any bool) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" {
+		return nil, false
+	}
+	if header == "*" {
+		return nil, true
+	}
+	for _, tag := range strings.Split(header, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags, false
+}
+
+// expectedVersion вычисляет ожидаемую версию ресурса по If-Match.
+//
+// Возвращает nil, если условие не задано или равно "*". Для одного ETag
+// версия передается сервису как есть, чтобы проверка и запись были атомарными.
+// Для списка ETag текущая версия загружается через current и проверяется
+// на вхождение в список, после чего сервису передается именно она.
+func expectedVersion(r *http.Request, current func() (*timestamppb.Timestamp, error)) (*timestamppb.Timestamp, error) {
+	tags, any := parseIfMatch(r)
+	if any || len(tags) == 0 {
+		return nil, nil
+	}
+
+	if len(tags) == 1 {
+		version, ok := etagVersion(tags[0])
+		if !ok {
+			return nil, errPreconditionFailed
+		}
+		return version, nil
+	}
+
+	version, err := current()
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range tags {
+		if tagVersion, ok := etagVersion(tag); ok && version != nil && tagVersion.AsTime().Equal(version.AsTime()) {
+			return version, nil
+		}
+	}
+	return nil, errPreconditionFailed
+}
@@ -0,0 +1,141 @@
+package eventHandler
+
+import (
+	"context"
+	"net/http"
+
+	pbFavorite "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/favorite"
+	contextkeys "github.com/rx3lixir/gateway-service/pkg/context"
+	"github.com/rx3lixir/gateway-service/pkg/token"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// handleAddFavorite добавляет событие в избранное текущего пользователя.
+// Повторное добавление не ошибка.
+func (h *eventHandler) handleAddFavorite(w http.ResponseWriter, r *http.Request) error {
+	claims, err := h.favoriteClaims(r)
+	if err != nil {
+		return err
+	}
+
+	id, err := parseIDFromURL(r, "id")
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Failed to parse ID from URL", "error", err)
+		return err
+	}
+
+	grpcCtx, cancel := h.createContext(r)
+	defer cancel()
+
+	// Хранилище избранного не знает о событиях, поэтому существование проверяем здесь
+	event, err := h.eventClient.GetEvent(grpcCtx, IDToProtoGetEventByIDReq(id))
+	if err != nil {
+		h.logger.WarnContext(grpcCtx, "Failed to get event for favorite", "id", id, "error", err)
+		return err
+	}
+	if !canView(r, event) {
+		return status.Errorf(codes.NotFound, "event with id %d not found", id)
+	}
+
+	res, err := h.favorites.AddFavorite(grpcCtx, &pbFavorite.FavoriteReq{
+		UserId:  int64(claims.Id),
+		EventId: id,
+	})
+	if err != nil {
+		h.logger.ErrorContext(grpcCtx, "Failed to add favorite via gRPC", "id", id, "user_id", claims.Id, "error", err)
+		return err
+	}
+
+	h.logger.InfoContext(grpcCtx, "Event added to favorites", "id", id, "user_id", claims.Id)
+
+	addedAt := res.GetCreatedAt().AsTime()
+	return WriteJSON(w, http.StatusOK, &FavoriteRes{
+		EventID:    id,
+		IsFavorite: true,
+		AddedAt:    &addedAt,
+	})
+}
+
+// handleRemoveFavorite удаляет событие из избранного текущего пользователя.
+// Событие может быть уже удалено, поэтому его существование не проверяется.
+func (h *eventHandler) handleRemoveFavorite(w http.ResponseWriter, r *http.Request) error {
+	claims, err := h.favoriteClaims(r)
+	if err != nil {
+		return err
+	}
+
+	id, err := parseIDFromURL(r, "id")
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Failed to parse ID from URL", "error", err)
+		return err
+	}
+
+	grpcCtx, cancel := h.createContext(r)
+	defer cancel()
+
+	_, err = h.favorites.RemoveFavorite(grpcCtx, &pbFavorite.FavoriteReq{
+		UserId:  int64(claims.Id),
+		EventId: id,
+	})
+	if err != nil {
+		h.logger.ErrorContext(grpcCtx, "Failed to remove favorite via gRPC", "id", id, "user_id", claims.Id, "error", err)
+		return err
+	}
+
+	h.logger.InfoContext(grpcCtx, "Event removed from favorites", "id", id, "user_id", claims.Id)
+
+	return WriteJSON(w, http.StatusOK, &FavoriteRes{
+		EventID:    id,
+		IsFavorite: false,
+	})
+}
+
+// favoriteClaims проверяет, что избранное настроено, и возвращает данные пользователя
+func (h *eventHandler) favoriteClaims(r *http.Request) (*token.UserClaims, error) {
+	if h.favorites == nil {
+		return nil, status.Error(codes.Unimplemented, "favorites are not configured")
+	}
+
+	claims, ok := r.Context().Value(contextkeys.AuthKey).(*token.UserClaims)
+	if !ok || claims == nil {
+		h.logger.WarnContext(r.Context(), "No auth claims found in context")
+		return nil, status.Error(codes.Unauthenticated, "authorization required")
+	}
+	return claims, nil
+}
+
+// markFavorites заполняет is_favorite для событий списка, если запрос аутентифицирован.
+// Ошибка хранилища избранного не ломает список: флаг просто не выставляется.
+func (h *eventHandler) markFavorites(ctx context.Context, r *http.Request, events []*Event) {
+	if h.favorites == nil || len(events) == 0 {
+		return
+	}
+	claims, ok := r.Context().Value(contextkeys.AuthKey).(*token.UserClaims)
+	if !ok || claims == nil {
+		return
+	}
+
+	ids := make([]int64, 0, len(events))
+	for _, event := range events {
+		ids = append(ids, event.Id)
+	}
+
+	res, err := h.favorites.CheckFavorites(ctx, &pbFavorite.CheckFavoritesReq{
+		UserId:   int64(claims.Id),
+		EventIds: ids,
+	})
+	if err != nil {
+		h.logger.WarnContext(ctx, "Failed to check favorites, is_favorite omitted", "user_id", claims.Id, "error", err)
+		return
+	}
+
+	favorite := make(map[int64]bool, len(res.GetFavoriteEventIds()))
+	for _, id := range res.GetFavoriteEventIds() {
+		favorite[id] = true
+	}
+	for _, event := range events {
+		isFavorite := favorite[event.Id]
+		event.IsFavorite = &isFavorite
+	}
+}
@@ -0,0 +1,221 @@
+package eventHandler
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pbEvent "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/event"
+)
+
+const (
+	// maxRadiusKm ограничивает радиус поиска, чтобы "рядом" не превращалось во "везде"
+	maxRadiusKm = 500.0
+
+	// sortByDistance сортировка результатов по расстоянию до near
+	sortByDistance = "distance"
+
+	// earthRadiusKm средний радиус Земли
+	earthRadiusKm = 6371.0
+)
+
+// parseGeoPoint парсит точку в формате "lat,lon"
+func parseGeoPoint(value string) (*GeoPoint, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid near format: expected lat,lon, got %q", value)
+	}
+
+	lat, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid near latitude: %q", parts[0])
+	}
+
+	lon, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid near longitude: %q", parts[1])
+	}
+
+	return &GeoPoint{Lat: lat, Lon: lon}, nil
+}
+
+// parseBoundingBox парсит прямоугольник в формате "min_lon,min_lat,max_lon,max_lat" (порядок GeoJSON)
+func parseBoundingBox(value string) (*BoundingBox, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("invalid bbox format: expected min_lon,min_lat,max_lon,max_lat, got %q", value)
+	}
+
+	coords := make([]float64, 4)
+	for i, part := range parts {
+		c, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bbox coordinate: %q", part)
+		}
+		coords[i] = c
+	}
+
+	return &BoundingBox{
+		MinLon: coords[0],
+		MinLat: coords[1],
+		MaxLon: coords[2],
+		MaxLat: coords[3],
+	}, nil
+}
+
+// contains проверяет, попадает ли точка в прямоугольник. Как в GeoJSON (RFC 7946,
+// раздел 5.2), min_lon > max_lon означает прямоугольник, пересекающий антимеридиан:
+// 170,-10,-170,10 покрывает долготы от 170 до 180 и от -180 до -170
+func (b *BoundingBox) contains(lat, lon float64) bool {
+	if lat < b.MinLat || lat > b.MaxLat {
+		return false
+	}
+	if b.MinLon <= b.MaxLon {
+		return lon >= b.MinLon && lon <= b.MaxLon
+	}
+	return lon >= b.MinLon || lon <= b.MaxLon
+}
+
+// distanceKm расстояние между точками по формуле гаверсинусов
+func distanceKm(a, b GeoPoint) float64 {
+	lat1, lat2 := a.Lat*math.Pi/180, b.Lat*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (b.Lon - a.Lon) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(h))
+}
+
+// validateCoordinates проверяет диапазоны широты и долготы.
+// NaN не проходит сравнения с границами, поэтому проверяется отдельно.
+func validateCoordinates(lat, lon float64) error {
+	if math.IsNaN(lat) || lat < -90 || lat > 90 {
+		return fmt.Errorf("invalid latitude %v: must be between -90 and 90", lat)
+	}
+	if math.IsNaN(lon) || lon < -180 || lon > 180 {
+		return fmt.Errorf("invalid longitude %v: must be between -180 and 180", lon)
+	}
+	return nil
+}
+
+// validateEventLocation проверяет координаты события: задаются только парой
+func validateEventLocation(lat, lon *float64) error {
+	if lat == nil && lon == nil {
+		return nil
+	}
+	if lat == nil || lon == nil {
+		return fmt.Errorf("lat and lon are required together")
+	}
+	return validateCoordinates(*lat, *lon)
+}
+
+// ValidateGeoFilters проверяет гео-фильтры запроса списка событий.
+// Используется и для query параметров, и для тела расширенного поиска.
+func ValidateGeoFilters(req *ListEventsReq) error {
+	if req.Near != nil {
+		if err := validateCoordinates(req.Near.Lat, req.Near.Lon); err != nil {
+			return err
+		}
+	}
+
+	if req.RadiusKm != nil {
+		if req.Near == nil {
+			return status.Error(codes.InvalidArgument, "radius_km requires near")
+		}
+		if math.IsNaN(*req.RadiusKm) || *req.RadiusKm <= 0 || *req.RadiusKm > maxRadiusKm {
+			return fmt.Errorf("invalid radius_km %v: must be in (0, %v]", *req.RadiusKm, maxRadiusKm)
+		}
+	}
+
+	if req.BBox != nil {
+		if err := validateCoordinates(req.BBox.MinLat, req.BBox.MinLon); err != nil {
+			return fmt.Errorf("invalid bbox: %w", err)
+		}
+		if err := validateCoordinates(req.BBox.MaxLat, req.BBox.MaxLon); err != nil {
+			return fmt.Errorf("invalid bbox: %w", err)
+		}
+		if req.BBox.MinLat >= req.BBox.MaxLat {
+			return fmt.Errorf("invalid bbox: min_lat must be less than max_lat")
+		}
+		// min_lon > max_lon — прямоугольник через антимеридиан, см. BoundingBox.contains
+		if req.BBox.MinLon == req.BBox.MaxLon {
+			return fmt.Errorf("invalid bbox: min_lon must differ from max_lon")
+		}
+	}
+
+	if req.SortBy != nil {
+		if *req.SortBy != sortByDistance {
+			return fmt.Errorf("invalid sort %q: supported values are %q", *req.SortBy, sortByDistance)
+		}
+		if req.Near == nil {
+			return status.Errorf(codes.InvalidArgument, "sort=%s requires near", sortByDistance)
+		}
+	}
+
+	return nil
+}
+
+// parseGeoQueryParams парсит near, radius_km, bbox и sort из query параметров
+func parseGeoQueryParams(params map[string][]string, req *ListEventsReq) error {
+	if values, ok := params["near"]; ok && len(values) > 0 && values[0] != "" {
+		near, err := parseGeoPoint(values[0])
+		if err != nil {
+			return err
+		}
+		req.Near = near
+	}
+
+	if values, ok := params["radius_km"]; ok && len(values) > 0 && values[0] != "" {
+		radius, err := strconv.ParseFloat(values[0], 64)
+		if err != nil {
+			return fmt.Errorf("invalid radius_km format: %q", values[0])
+		}
+		req.RadiusKm = &radius
+	}
+
+	if values, ok := params["bbox"]; ok && len(values) > 0 && values[0] != "" {
+		bbox, err := parseBoundingBox(values[0])
+		if err != nil {
+			return err
+		}
+		req.BBox = bbox
+	}
+
+	if values, ok := params["sort"]; ok && len(values) > 0 && values[0] != "" {
+		req.SortBy = &values[0]
+	}
+
+	return ValidateGeoFilters(req)
+}
+
+// geoFiltersToProto переносит гео-фильтры в pbEvent.ListEventsReq
+func geoFiltersToProto(req *ListEventsReq, protoReq *pbEvent.ListEventsReq) {
+	if req.Near != nil {
+		protoReq.Near = &pbEvent.GeoPoint{
+			Lat: req.Near.Lat,
+			Lon: req.Near.Lon,
+		}
+	}
+
+	if req.RadiusKm != nil {
+		protoReq.RadiusKm = req.RadiusKm
+	}
+
+	if req.BBox != nil {
+		// Прямоугольник через антимеридиан передается как есть, с min_lon > max_lon
+		protoReq.Bbox = &pbEvent.GeoBoundingBox{
+			MinLat: req.BBox.MinLat,
+			MinLon: req.BBox.MinLon,
+			MaxLat: req.BBox.MaxLat,
+			MaxLon: req.BBox.MaxLon,
+		}
+	}
+
+	if req.SortBy != nil {
+		protoReq.SortBy = req.SortBy
+	}
+}
@@ -1,101 +1,60 @@
 package eventHandler
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
-	"strconv"
 	"strings"
+	"time"
 
 	pbEvent "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/event"
+	pbFavorite "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/favorite"
+	pbRsvp "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/rsvp"
+	"github.com/rx3lixir/gateway-service/pkg/analytics"
+	"github.com/rx3lixir/gateway-service/pkg/blobstore"
+	"github.com/rx3lixir/gateway-service/pkg/broker"
+	"github.com/rx3lixir/gateway-service/pkg/cache"
+	"github.com/rx3lixir/gateway-service/pkg/history"
+	"github.com/rx3lixir/gateway-service/pkg/i18n"
 	"github.com/rx3lixir/gateway-service/pkg/logger"
+	"github.com/rx3lixir/gateway-service/pkg/middleware"
+	"github.com/rx3lixir/gateway-service/pkg/recent"
+	"github.com/rx3lixir/gateway-service/pkg/savedsearch"
 	"github.com/rx3lixir/gateway-service/pkg/token"
+	"github.com/rx3lixir/gateway-service/pkg/webhook"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type eventHandler struct {
 	eventClient pbEvent.EventServiceClient
 	tokenMaker  *token.JWTMaker
 	logger      logger.Logger
-}
-
-// handleGetSuggestions обрабатывает запросы автокомплита
-func (h *eventHandler) handleGetSuggestions(w http.ResponseWriter, r *http.Request) error {
-	query := r.URL.Query().Get("q")
-	if query == "" {
-		return WriteJSON(w, http.StatusOK, &SuggestionResponse{
-			Suggestions: []Suggestion{},
-			Query:       "",
-			Total:       0,
-		})
-	}
-
-	// Парсим дополнительные параметры
-	maxResults := 10
-	if mr := r.URL.Query().Get("max_results"); mr != "" {
-		if parsed, err := strconv.Atoi(mr); err == nil && parsed > 0 {
-			maxResults = parsed
-		}
-	}
-
-	fields := []string{"name", "location"}
-	if f := r.URL.Query().Get("fields"); f != "" {
-		fields = strings.Split(f, ",")
-	}
-
-	h.logger.InfoContext(r.Context(), "Handling suggestion request",
-		"query", query,
-		"max_results", maxResults,
-		"fields", fields)
-
-	grpcCtx, cancel := h.createContext(r)
-	defer cancel()
-
-	// Создаем gRPC запрос
-	req := &pbEvent.SuggestionReq{
-		Query:      query,
-		MaxResults: int32(maxResults),
-		Fields:     fields,
-	}
 
-	res, err := h.eventClient.GetSuggestions(grpcCtx, req)
-	if err != nil {
-		h.logger.ErrorContext(grpcCtx, "Failed to get suggestions via gRPC", "error", err)
-		return err
-	}
+	cachePolicies CachePolicies
+	imageStore    blobstore.Store
 
-	// Конвертируем в HTTP ответ
-	suggestions := make([]Suggestion, 0, len(res.GetSuggestions()))
-	for _, item := range res.GetSuggestions() {
-		suggestion := Suggestion{
-			Text:  item.GetText(),
-			Score: item.GetScore(),
-			Type:  item.GetType(),
-		}
+	broker         broker.Broker
+	streamSettings StreamSettings
+	webhooks       webhook.Publisher
 
-		if item.Category != nil {
-			suggestion.Category = *item.Category
-		}
+	favorites pbFavorite.FavoriteServiceClient
+	rsvp      pbRsvp.RsvpServiceClient
+	apiKeys   []middleware.APIKey
+	cors      middleware.CORSConfig
+	languages i18n.Languages
 
-		if item.EventId != nil {
-			suggestion.EventID = item.EventId
-		}
+	recentSearches recent.Store
+	clickHistory   history.Store
+	savedSearches  savedsearch.Store
 
-		suggestions = append(suggestions, suggestion)
-	}
+	analytics   *analytics.Recorder
+	searchStats analytics.Reporter
 
-	response := &SuggestionResponse{
-		Suggestions: suggestions,
-		Query:       res.GetQuery(),
-		Total:       int(res.GetTotal()),
-	}
-
-	h.logger.InfoContext(grpcCtx, "Suggestions retrieved successfully",
-		"query", query,
-		"suggestions_count", len(suggestions))
-
-	return WriteJSON(w, http.StatusOK, response)
+	recommendationCache *recommendationCache
 }
 
 // handleGetEventByID возвращает событие с переданным id
@@ -121,8 +80,8 @@ func (h *eventHandler) handleGetEventByID(w http.ResponseWriter, r *http.Request
 		return err
 	}
 
-	if protoEvent == nil {
-		h.logger.WarnContext(grpcCtx, "Received nil event from gRPC service", "id", id)
+	if protoEvent == nil || !canView(r, protoEvent) {
+		h.logger.WarnContext(grpcCtx, "Event not found or not visible to caller", "id", id)
 		return status.Error(codes.NotFound, fmt.Sprintf("event with id %d not found", id))
 	}
 
@@ -136,6 +95,13 @@ func (h *eventHandler) handleGetEventByID(w http.ResponseWriter, r *http.Request
 		h.logger.ErrorContext(grpcCtx, "Failed to convert Proto event to HTTP event", "id", id)
 		return status.Error(codes.Internal, "error converting event data")
 	}
+	hideModerationDetails(r, httpEvent)
+	LocalizeEvents(r, httpEvent)
+	setContentLanguage(w, httpEvent.Lang)
+
+	version := resourceVersion(protoEvent.GetUpdatedAt(), protoEvent.GetCreatedAt())
+	setLocalizedETag(w, version, httpEvent.Lang, h.languages.Default)
+	setLastModified(w, version)
 	return WriteJSON(w, http.StatusOK, httpEvent)
 }
 
@@ -156,16 +122,55 @@ func (h *eventHandler) handleCreateEvent(w http.ResponseWriter, r *http.Request)
 		"category_id", createEventReq.CategoryID,
 		"date", createEventReq.Date)
 
-	// Базовая валидация (можно расширить с помощью библиотеки валидации)
-	if strings.TrimSpace(createEventReq.Name) == "" {
-		h.logger.WarnContext(r.Context(), "Event validation failed", "reason", "empty name")
-		return fmt.Errorf("event name is required")
+	sched, err := validateCreateEventReq(&createEventReq)
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Event validation failed", "reason", err)
+		return err
+	}
+	if err := h.validateTranslations(createEventReq.Translations); err != nil {
+		h.logger.WarnContext(r.Context(), "Event translations validation failed", "reason", err)
+		return err
+	}
+
+	// Модераторы публикуют сразу, остальные события (пользователи, API ключи) уходят на модерацию
+	initialStatus, err := createStatus(r, createEventReq.Status)
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Event status rejected", "status", createEventReq.Status, "reason", err)
+		return err
+	}
+
+	force, err := parseForce(r)
+	if err != nil {
+		return err
 	}
 
 	grpcCtx, cancel := h.createContext(r)
 	defer cancel()
 
-	protoReq := HTTPCreateReqToProtoCreateEventReq(&createEventReq)
+	// Проверка дубликатов: источники часто публикуют одно событие дважды
+	if !force {
+		fp := newEventFingerprint(&createEventReq, sched)
+		candidates, err := h.newDuplicateFinder().find(grpcCtx, r, fp)
+		if err != nil {
+			h.logger.WarnContext(grpcCtx, "Duplicate check failed, creating event anyway", "fingerprint", fp.Key, "error", err)
+		} else if len(candidates) > 0 {
+			h.logger.InfoContext(grpcCtx, "Possible duplicate event rejected",
+				"fingerprint", fp.Key,
+				"candidates", len(candidates),
+				"best_match_id", candidates[0].Event.Id)
+			return WriteJSON(w, http.StatusConflict, &DuplicateEventsRes{
+				Error:       "possible duplicate events found, retry with force=true to create anyway",
+				Fingerprint: fp.Key,
+				Candidates:  candidates,
+			})
+		}
+	}
+
+	protoReq := HTTPCreateReqToProtoCreateEventReq(&createEventReq, sched)
+	protoReq.Status = initialStatus
+	if by := submitter(r); by != "" {
+		protoReq.SubmittedBy = &by
+	}
 
 	h.logger.InfoContext(grpcCtx, "Sending CreateEvent request to gRPC service")
 
@@ -179,9 +184,14 @@ func (h *eventHandler) handleCreateEvent(w http.ResponseWriter, r *http.Request)
 
 	h.logger.InfoContext(grpcCtx, "Event created successfully",
 		"id", createdEvent.GetId(),
-		"name", createdEvent.GetName())
+		"name", createdEvent.GetName(),
+		"status", initialStatus,
+		"submitted_by", protoReq.GetSubmittedBy())
+
+	h.publishChange(r.Context(), changeCreated, createdEvent.GetId(), createdEvent)
 
 	httpEvent := ProtoEventResToHTTPEvent(createdEvent)
+	hideModerationDetails(r, httpEvent)
 
 	return WriteJSON(w, http.StatusCreated, httpEvent)
 }
@@ -196,36 +206,147 @@ func (h *eventHandler) handleUpdateEvent(w http.ResponseWriter, r *http.Request)
 
 	h.logger.InfoContext(r.Context(), "Handling request to update event", "id", id)
 
-	var updateEventReq UpdateEventReq
+	grpcCtx, cancel := h.createContext(r)
+	defer cancel()
 
-	if err := json.NewDecoder(r.Body).Decode(&updateEventReq); err != nil {
-		h.logger.WarnContext(r.Context(), "Failed to decode update event request", "error", err)
-		return fmt.Errorf("Invalid request body: %w", err)
+	// Текущее событие нужно только для JSON Patch test и частичного
+	// обновления расписания/координат, поэтому загружаем его лениво
+	var current *pbEvent.EventRes
+	loadCurrent := func() (*pbEvent.EventRes, error) {
+		if current == nil {
+			ev, err := h.eventClient.GetEvent(cache.Bypass(grpcCtx), IDToProtoGetEventByIDReq(id))
+			if err != nil {
+				h.logger.ErrorContext(grpcCtx, "Failed to get current event via gRPC", "id", id, "error", err)
+				return nil, err
+			}
+			current = ev
+		}
+		return current, nil
 	}
+
+	doc, err := decodePatchDocument(r, eventPatchFields, func() (any, error) {
+		ev, err := loadCurrent()
+		if err != nil {
+			return nil, err
+		}
+		return ProtoEventResToHTTPEvent(ev), nil
+	})
 	defer r.Body.Close()
+	if errors.Is(err, errUnsupportedPatchType) {
+		h.logger.WarnContext(r.Context(), "Unsupported patch content type", "content_type", r.Header.Get("Content-Type"))
+		return writeUnsupportedPatchType(w)
+	}
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Failed to decode update event request", "error", err)
+		return err
+	}
+	if len(doc) == 0 {
+		return fmt.Errorf("invalid request body: no fields to update")
+	}
+
+	var updateEventReq UpdateEventReq
+	if err := doc.decodeInto(&updateEventReq); err != nil {
+		h.logger.WarnContext(r.Context(), "Failed to decode update event request", "error", err)
+		return err
+	}
+
+	mask := doc.mask(eventPatchFields)
 
 	h.logger.InfoContext(r.Context(), "Received event update data",
 		"id", id,
-		"name", updateEventReq.Name)
+		"update_mask", mask)
 
-	grpcCtx, cancel := h.createContext(r)
-	defer cancel()
+	if doc.has("name") && strings.TrimSpace(valueOrZero(updateEventReq.Name)) == "" {
+		h.logger.WarnContext(r.Context(), "Event validation failed", "id", id, "reason", "empty name")
+		return fmt.Errorf("event name is required")
+	}
+	if err := h.validateTranslations(updateEventReq.Translations); err != nil {
+		h.logger.WarnContext(r.Context(), "Event validation failed", "id", id, "reason", err)
+		return err
+	}
+
+	// Координаты проверяются парой: недостающую берем из текущего события
+	if doc.has("lat") != doc.has("lon") {
+		ev, err := loadCurrent()
+		if err != nil {
+			return err
+		}
+		if !doc.has("lat") {
+			updateEventReq.Lat = ev.Lat
+		} else {
+			updateEventReq.Lon = ev.Lon
+		}
+		mask = mergeMaskPaths(mask, []string{"lat", "lon"})
+	}
+	if err := validateEventLocation(updateEventReq.Lat, updateEventReq.Lon); err != nil {
+		h.logger.WarnContext(r.Context(), "Event validation failed", "id", id, "reason", err)
+		return err
+	}
 
-	protoReq := HTTPUpdateReqToProtoUpdateEventReq(id, &updateEventReq)
+	// Расписание пересчитываем целиком, только если оно затронуто запросом
+	var sched *eventSchedule
+	if patchTouchesSchedule(doc) {
+		ev, err := loadCurrent()
+		if err != nil {
+			return err
+		}
+		sched, err = normalizeSchedule(patchScheduleInput(doc, &updateEventReq, ev))
+		if err != nil {
+			h.logger.WarnContext(r.Context(), "Event schedule validation failed", "id", id, "reason", err)
+			return err
+		}
+	}
 
-	h.logger.InfoContext(grpcCtx, "Sending UpdateEvent request to gRPC service", "id", id)
+	// Потокам уведомлений нужно прежнее состояние: по нему видно, что событие
+	// выпало из фильтра подписчика
+	if h.broker != nil {
+		if _, err := loadCurrent(); err != nil {
+			return err
+		}
+	}
+
+	protoReq := HTTPUpdateReqToProtoUpdateEventReq(id, &updateEventReq, sched, mask)
+
+	// Условное обновление по If-Match
+	protoReq.ExpectedUpdatedAt, err = expectedVersion(r, func() (*timestamppb.Timestamp, error) {
+		ev, err := loadCurrent()
+		if err != nil {
+			return nil, err
+		}
+		return resourceVersion(ev.GetUpdatedAt(), ev.GetCreatedAt()), nil
+	})
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Event precondition failed", "id", id, "if_match", r.Header.Get("If-Match"))
+		return err
+	}
+	// Переводы объединены с прочитанными: без If-Match обновляем по версии прочитанного
+	// события, чтобы не потерять параллельную правку другого языка
+	guardTranslations := doc.has("translations") && current != nil && protoReq.ExpectedUpdatedAt == nil
+	if guardTranslations {
+		protoReq.ExpectedUpdatedAt = resourceVersion(current.GetUpdatedAt(), current.GetCreatedAt())
+	}
+
+	h.logger.InfoContext(grpcCtx, "Sending UpdateEvent request to gRPC service",
+		"id", id,
+		"update_mask", protoReq.GetUpdateMask().GetPaths())
 
 	updatedEvent, err := h.eventClient.UpdateEvent(grpcCtx, protoReq)
+	if status.Code(err) == codes.FailedPrecondition && guardTranslations {
+		return status.Errorf(codes.Aborted, "event %d was modified concurrently, retry", id)
+	}
 	if err != nil {
 		h.logger.ErrorContext(grpcCtx, "Failed to update event via gRPC", "id", id, "error", err)
-		return err
+		return preconditionError(err, protoReq.ExpectedUpdatedAt)
 	}
 
 	h.logger.InfoContext(grpcCtx, "Event updated successfully",
 		"id", updatedEvent.GetId(),
 		"name", updatedEvent.GetName())
 
+	h.publishUpdate(r.Context(), id, current, updatedEvent)
+
 	httpEvent := ProtoEventResToHTTPEvent(updatedEvent)
+	setVersionETag(w, resourceVersion(updatedEvent.GetUpdatedAt(), updatedEvent.GetCreatedAt()))
 	return WriteJSON(w, http.StatusOK, httpEvent)
 }
 
@@ -244,72 +365,112 @@ func (h *eventHandler) handleDeleteEvent(w http.ResponseWriter, r *http.Request)
 
 	deleteReq := IDToProtoDeleteEventReq(id)
 
+	var current *pbEvent.EventRes
+	loadCurrent := func() (*pbEvent.EventRes, error) {
+		if current == nil {
+			ev, err := h.eventClient.GetEvent(cache.Bypass(grpcCtx), IDToProtoGetEventByIDReq(id))
+			if err != nil {
+				return nil, err
+			}
+			current = ev
+		}
+		return current, nil
+	}
+
+	// Условное удаление по If-Match
+	deleteReq.ExpectedUpdatedAt, err = expectedVersion(r, func() (*timestamppb.Timestamp, error) {
+		ev, err := loadCurrent()
+		if err != nil {
+			return nil, err
+		}
+		return resourceVersion(ev.GetUpdatedAt(), ev.GetCreatedAt()), nil
+	})
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Event precondition failed", "id", id, "if_match", r.Header.Get("If-Match"))
+		return err
+	}
+
+	// Подписчикам уведомлений и вебхуков нужно последнее состояние события для фильтрации
+	if h.broker != nil || h.webhooks != nil {
+		if _, err := loadCurrent(); err != nil {
+			h.logger.ErrorContext(grpcCtx, "Failed to get event before delete", "id", id, "error", err)
+			return err
+		}
+	}
+
 	h.logger.InfoContext(grpcCtx, "Sending DeleteEvent request to gRPC service", "id", id)
 
 	_, err = h.eventClient.DeleteEvent(grpcCtx, deleteReq)
 	if err != nil {
 		h.logger.ErrorContext(grpcCtx, "Failed to delete event via gRPC", "id", id, "error", err)
-		return err
+		return preconditionError(err, deleteReq.ExpectedUpdatedAt)
 	}
 
 	h.logger.InfoContext(grpcCtx, "Event deleted successfully", "id", id)
 
+	h.publishChange(r.Context(), changeDeleted, id, current)
+
 	return WriteJSON(w, http.StatusOK, map[string]string{
 		"message": fmt.Sprintf("event %d successfully deleted", id),
 	})
 }
 
-// handleListCategories возвращает информацию обо всех категориях
+// handleListCategories возвращает информацию обо всех категориях.
+// tree=true возвращает дерево категорий, include_counts=true — количество опубликованных событий.
 func (h *eventHandler) handleListCategories(w http.ResponseWriter, r *http.Request) error {
-	h.logger.InfoContext(r.Context(), "Handling request to list categories")
+	tree, err := parseBoolParam(r, "tree")
+	if err != nil {
+		return err
+	}
+	includeCounts, err := parseBoolParam(r, "include_counts")
+	if err != nil {
+		return err
+	}
+
+	h.logger.InfoContext(r.Context(), "Handling request to list categories", "tree", tree, "include_counts", includeCounts)
 
 	// Создаем gRPC контекст
 	grpcCtx, cancel := h.createContext(r)
 	defer cancel()
 
-	// Создаем запрос
-	req := &pbEvent.ListCategoriesReq{}
-
 	h.logger.InfoContext(grpcCtx, "Sending ListCategories request to gRPC service")
-	res, err := h.eventClient.ListCategories(grpcCtx, req)
+	idx, categories, err := h.loadCategoryIndex(grpcCtx, includeCounts)
 	if err != nil {
 		h.logger.ErrorContext(grpcCtx, "Failed to list categories via gRPC", "error", err)
 		return err
 	}
 
-	categoriesCount := 0
-	if res != nil && res.Categories != nil {
-		categoriesCount = len(res.Categories)
-	}
-	h.logger.InfoContext(grpcCtx, "Received categories from gRPC service", "count", categoriesCount)
+	h.logger.InfoContext(grpcCtx, "Received categories from gRPC service", "count", len(categories))
 
 	// Если результат получен, но в нем пусто
-	if res != nil && (res.Categories == nil || len(res.Categories) == 0) {
+	if len(categories) == 0 {
 		h.logger.InfoContext(grpcCtx, "No categories found")
 		return WriteJSON(w, http.StatusOK, []*Category{})
 	}
 
-	httpCategories := ProtoCategoriesListToHTTPCategoriesList(res.GetCategories())
+	httpCategories := buildCategoryList(r, idx, categories, tree)
+	setContentLanguage(w, localeLang(r))
 
 	h.logger.InfoContext(grpcCtx, "Converted to HTTP categories", "count", len(httpCategories))
 
+	// Last-Modified для списка не ставим, см. handleGetEvents
 	return WriteJSON(w, http.StatusOK, httpCategories)
 }
 
-// handleGetCategoryByID возвращает категорию с переданным id
+// handleGetCategoryByID возвращает категорию с переданным id или slug
 func (h *eventHandler) handleGetCategoryByID(w http.ResponseWriter, r *http.Request) error {
-	id, err := parseIDFromURL(r, "id")
+	grpcCtx, cancel := h.createContext(r)
+	defer cancel()
+
+	id, err := h.resolveCategoryID(grpcCtx, r)
 	if err != nil {
-		h.logger.WarnContext(r.Context(), "Failed to parse ID from URL", "error", err)
+		h.logger.WarnContext(r.Context(), "Failed to resolve category from URL", "error", err)
 		return err
 	}
 
 	h.logger.InfoContext(r.Context(), "Handling request to get category by ID", "id", id)
 
-	grpcCtx, cancel := h.createContext(r)
-	defer cancel()
-
-	getCategoryReq := IDToProtoGetCategoryByIDReq(int32(id))
+	getCategoryReq := IDToProtoGetCategoryByIDReq(id)
 
 	h.logger.InfoContext(grpcCtx, "Sending GetCategory request to gRPC service", "id", id)
 
@@ -334,6 +495,13 @@ func (h *eventHandler) handleGetCategoryByID(w http.ResponseWriter, r *http.Requ
 		h.logger.ErrorContext(grpcCtx, "Failed to convert Proto category to HTTP category", "id", id)
 		return status.Error(codes.Internal, "error converting category data")
 	}
+
+	localizeCategories(r, httpCategory)
+	setContentLanguage(w, httpCategory.Lang)
+
+	version := resourceVersion(protoCategory.GetUpdatedAt(), protoCategory.GetCreatedAt())
+	setLocalizedETag(w, version, httpCategory.Lang, h.languages.Default)
+	setLastModified(w, version)
 	return WriteJSON(w, http.StatusOK, httpCategory)
 }
 
@@ -357,10 +525,39 @@ func (h *eventHandler) handleCreateCategory(w http.ResponseWriter, r *http.Reque
 		h.logger.WarnContext(r.Context(), "Category validation failed", "reason", "empty name")
 		return fmt.Errorf("category name is required")
 	}
+	if createCategoryReq.Slug == nil {
+		slug := slugify(createCategoryReq.Name)
+		if slug == "" || numericSlugPattern.MatchString(slug) {
+			h.logger.WarnContext(r.Context(), "Category validation failed", "reason", "cannot derive slug from name", "name", createCategoryReq.Name)
+			return fmt.Errorf("invalid name %q: cannot derive a slug from it, slug is required", createCategoryReq.Name)
+		}
+		createCategoryReq.Slug = &slug
+	}
+	if err := validateCategoryFields(createCategoryReq.Slug, &createCategoryReq.Description); err != nil {
+		h.logger.WarnContext(r.Context(), "Category validation failed", "reason", err)
+		return err
+	}
+	if err := h.validateTranslations(createCategoryReq.Translations); err != nil {
+		h.logger.WarnContext(r.Context(), "Category validation failed", "reason", err)
+		return err
+	}
 
 	grpcCtx, cancel := h.createContext(r)
 	defer cancel()
 
+	// Уникальность slug и родитель проверяются по актуальному списку категорий
+	idx, _, err := h.loadCategoryIndex(cache.Bypass(grpcCtx), false)
+	if err != nil {
+		h.logger.ErrorContext(grpcCtx, "Failed to list categories via gRPC", "error", err)
+		return err
+	}
+	if err := idx.checkSlugAvailable(*createCategoryReq.Slug, 0); err != nil {
+		return err
+	}
+	if err := idx.validateParent(0, createCategoryReq.ParentID); err != nil {
+		return err
+	}
+
 	protoReq := HTTPCreateCategoryReqToProtoCreateCategoryReq(&createCategoryReq)
 
 	h.logger.InfoContext(grpcCtx, "Sending CreateCategory request to gRPC service")
@@ -392,29 +589,120 @@ func (h *eventHandler) handleUpdateCategory(w http.ResponseWriter, r *http.Reque
 
 	h.logger.InfoContext(r.Context(), "Handling request to update category", "id", id)
 
-	var updateCategoryReq UpdateCategoryReq
+	grpcCtx, cancel := h.createContext(r)
+	defer cancel()
 
-	if err := json.NewDecoder(r.Body).Decode(&updateCategoryReq); err != nil {
-		h.logger.WarnContext(r.Context(), "Failed to decode update category request", "error", err)
-		return fmt.Errorf("Invalid request body: %w", err)
+	// Текущая категория нужна только для JSON Patch test и If-Match со списком ETag
+	var current *pbEvent.CategoryRes
+	loadCurrent := func() (*pbEvent.CategoryRes, error) {
+		if current == nil {
+			category, err := h.eventClient.GetCategory(cache.Bypass(grpcCtx), IDToProtoGetCategoryByIDReq(int32(id)))
+			if err != nil {
+				h.logger.ErrorContext(grpcCtx, "Failed to get current category via gRPC", "id", id, "error", err)
+				return nil, err
+			}
+			current = category
+		}
+		return current, nil
 	}
+
+	doc, err := decodePatchDocument(r, categoryPatchFields, func() (any, error) {
+		category, err := loadCurrent()
+		if err != nil {
+			return nil, err
+		}
+		return ProtoCategoryResToHTTPCategory(category), nil
+	})
 	defer r.Body.Close()
+	if errors.Is(err, errUnsupportedPatchType) {
+		h.logger.WarnContext(r.Context(), "Unsupported patch content type", "content_type", r.Header.Get("Content-Type"))
+		return writeUnsupportedPatchType(w)
+	}
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Failed to decode update category request", "error", err)
+		return err
+	}
+	if len(doc) == 0 {
+		return fmt.Errorf("invalid request body: no fields to update")
+	}
+
+	var updateCategoryReq UpdateCategoryReq
+	if err := doc.decodeInto(&updateCategoryReq); err != nil {
+		h.logger.WarnContext(r.Context(), "Failed to decode update category request", "error", err)
+		return err
+	}
+
+	if doc.has("name") && strings.TrimSpace(valueOrZero(updateCategoryReq.Name)) == "" {
+		h.logger.WarnContext(r.Context(), "Category validation failed", "id", id, "reason", "empty name")
+		return fmt.Errorf("category name is required")
+	}
+	if doc.has("slug") && updateCategoryReq.Slug == nil {
+		return fmt.Errorf("category slug is required")
+	}
+	if err := validateCategoryFields(updateCategoryReq.Slug, updateCategoryReq.Description); err != nil {
+		h.logger.WarnContext(r.Context(), "Category validation failed", "id", id, "reason", err)
+		return err
+	}
+	if err := h.validateTranslations(updateCategoryReq.Translations); err != nil {
+		h.logger.WarnContext(r.Context(), "Category validation failed", "id", id, "reason", err)
+		return err
+	}
+
+	// Смена slug или родителя проверяется по актуальному списку категорий
+	if doc.has("slug") || doc.has("parent_id") {
+		idx, _, err := h.loadCategoryIndex(cache.Bypass(grpcCtx), false)
+		if err != nil {
+			h.logger.ErrorContext(grpcCtx, "Failed to list categories via gRPC", "error", err)
+			return err
+		}
+		if _, ok := idx.byID[int32(id)]; !ok {
+			return status.Errorf(codes.NotFound, "category with id %d not found", id)
+		}
+		if doc.has("slug") {
+			if err := idx.checkSlugAvailable(*updateCategoryReq.Slug, int32(id)); err != nil {
+				return err
+			}
+		}
+		if err := idx.validateParent(int32(id), updateCategoryReq.ParentID); err != nil {
+			return err
+		}
+	}
+
+	mask := doc.mask(categoryPatchFields)
 
 	h.logger.InfoContext(r.Context(), "Received category update data",
 		"id", id,
-		"name", updateCategoryReq.Name)
+		"update_mask", mask)
 
-	grpcCtx, cancel := h.createContext(r)
-	defer cancel()
+	protoReq := HTTPUpdateCategoryReqToProtoUpdateCategoryReq(int32(id), &updateCategoryReq, mask)
 
-	protoReq := HTTPUpdateCategoryReqToProtoUpdateCategoryReq(int32(id), &updateCategoryReq)
+	// Условное обновление по If-Match
+	protoReq.ExpectedUpdatedAt, err = expectedVersion(r, func() (*timestamppb.Timestamp, error) {
+		category, err := loadCurrent()
+		if err != nil {
+			return nil, err
+		}
+		return resourceVersion(category.GetUpdatedAt(), category.GetCreatedAt()), nil
+	})
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Category precondition failed", "id", id, "if_match", r.Header.Get("If-Match"))
+		return err
+	}
+	// Переводы объединены с прочитанными, см. handleUpdateEvent
+	guardTranslations := doc.has("translations") && current != nil && protoReq.ExpectedUpdatedAt == nil
+	if guardTranslations {
+		protoReq.ExpectedUpdatedAt = resourceVersion(current.GetUpdatedAt(), current.GetCreatedAt())
+	}
 
 	h.logger.InfoContext(grpcCtx, "Sending UpdateCategory request to gRPC service", "id", id)
 
 	updatedCategory, err := h.eventClient.UpdateCategory(grpcCtx, protoReq)
+	if status.Code(err) == codes.FailedPrecondition && guardTranslations {
+		return status.Errorf(codes.Aborted, "category %d was modified concurrently, retry", id)
+	}
 	if err != nil {
 		h.logger.ErrorContext(grpcCtx, "Failed to update category via gRPC", "id", id, "error", err)
-		return err
+		return preconditionError(err, protoReq.ExpectedUpdatedAt)
 	}
 
 	h.logger.InfoContext(grpcCtx, "Category updated successfully",
@@ -422,49 +710,31 @@ func (h *eventHandler) handleUpdateCategory(w http.ResponseWriter, r *http.Reque
 		"name", updatedCategory.GetName())
 
 	httpCategory := ProtoCategoryResToHTTPCategory(updatedCategory)
+	setVersionETag(w, resourceVersion(updatedCategory.GetUpdatedAt(), updatedCategory.GetCreatedAt()))
 	return WriteJSON(w, http.StatusOK, httpCategory)
 }
 
-// handleDeleteCategory удаляет указанную категорию
-func (h *eventHandler) handleDeleteCategory(w http.ResponseWriter, r *http.Request) error {
-	id, err := parseIDFromURL(r, "id")
-	if err != nil {
-		h.logger.WarnContext(r.Context(), "Failed to parse ID from URL", "error", err)
-		return err
-	}
-
-	h.logger.InfoContext(r.Context(), "Handling request to delete category", "id", id)
-
-	grpcCtx, cancel := h.createContext(r)
-	defer cancel()
-
-	deleteReq := IDToProtoDeleteCategoryReq(int32(id))
-
-	h.logger.InfoContext(grpcCtx, "Sending DeleteCategory request to gRPC service", "id", id)
-
-	_, err = h.eventClient.DeleteCategory(grpcCtx, deleteReq)
-	if err != nil {
-		h.logger.ErrorContext(grpcCtx, "Failed to delete category via gRPC", "id", id, "error", err)
-		return err
-	}
-
-	h.logger.InfoContext(grpcCtx, "Category deleted successfully", "id", id)
-
-	return WriteJSON(w, http.StatusOK, map[string]string{
-		"message": fmt.Sprintf("category %d successfully deleted", id),
-	})
-}
-
-func NewEventHandler(eventClient pbEvent.EventServiceClient, secretKey string, log logger.Logger) *eventHandler {
-	return &eventHandler{
+func NewEventHandler(eventClient pbEvent.EventServiceClient, secretKey string, log logger.Logger, opts ...Option) *eventHandler {
+	h := &eventHandler{
 		eventClient: eventClient,
 		tokenMaker:  token.NewJWTMaker(secretKey),
 		logger:      log,
+		cors:        middleware.DefaultCORSConfig(),
+		languages:   defaultLanguages,
+
+		recommendationCache: newRecommendationCache(),
 	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
 }
 
 // handleGetEvents возвращает информацию обо всех событиях с поддержкой фильтрации и полнотекстового поиска
 func (h *eventHandler) handleGetEvents(w http.ResponseWriter, r *http.Request) error {
+	started := time.Now()
 	h.logger.InfoContext(r.Context(), "Handling request to list events with filters")
 
 	// Парсим параметры запроса в структуру фильтров
@@ -474,6 +744,15 @@ func (h *eventHandler) handleGetEvents(w http.ResponseWriter, r *http.Request) e
 		return fmt.Errorf("invalid query parameters: %w", err)
 	}
 
+	if filterReq.Statuses, err = visibleStatuses(r, filterReq.Statuses); err != nil {
+		h.logger.WarnContext(r.Context(), "Invalid status filter", "error", err)
+		return err
+	}
+	if err := h.expandCategoryFilter(r, filterReq); err != nil {
+		return err
+	}
+	h.recordSearch(r, filterReq)
+
 	// Детальное логирование полученных фильтров (включая поиск)
 	h.logger.InfoContext(r.Context(), "Parsed event filters",
 		"category_ids", filterReq.CategoryIDs,
@@ -487,6 +766,11 @@ func (h *eventHandler) handleGetEvents(w http.ResponseWriter, r *http.Request) e
 		"limit", filterReq.Limit,
 		"offset", filterReq.Offset,
 		"include_count", filterReq.IncludeCount,
+		"include_facets", filterReq.IncludeFacets,
+		"near", filterReq.Near,
+		"radius_km", filterReq.RadiusKm,
+		"bbox", filterReq.BBox,
+		"sort", filterReq.SortBy,
 	)
 
 	// Создаем gRPC контекст
@@ -531,31 +815,42 @@ func (h *eventHandler) handleGetEvents(w http.ResponseWriter, r *http.Request) e
 			"has_pagination", res.GetPagination() != nil)
 	}
 
-	// Если результат получен, но в нем пусто
-	if res != nil && (res.Events == nil || len(res.Events) == 0) {
+	// Если результат получен, но в нем пусто (и фасеты не запрошены)
+	if res != nil && (res.Events == nil || len(res.Events) == 0) && res.GetFacets() == nil {
 		if hasSearch {
 			h.logger.InfoContext(grpcCtx, "No events found for search query", "search_text", *filterReq.SearchText)
 		} else {
 			h.logger.InfoContext(grpcCtx, "No events found with current filters")
 		}
+		h.trackSearch(analytics.SourceEvents, filterReq, 0, started)
 		return WriteJSON(w, http.StatusOK, &ListEventsRes{
 			Events: []*Event{},
 		})
 	}
 
 	// Конвертируем Proto ответ в HTTP ответ
-	httpResponse := ProtoListResToHTTPListRes(res)
+	httpResponse := ProtoListResToHTTPListRes(res, h.facetCategoryNames(grpcCtx, res))
+	expandOccurrences(httpResponse.Events, filterReq)
+	hideModerationDetails(r, httpResponse.Events...)
+	h.markFavorites(grpcCtx, r, httpResponse.Events)
+	setContentLanguage(w, LocalizeEvents(r, httpResponse.Events...))
 
 	h.logger.InfoContext(grpcCtx, "Converted to HTTP events response",
 		"events_count", len(httpResponse.Events),
 		"has_pagination", httpResponse.Pagination != nil,
 	)
 
+	// Last-Modified для списка не ставим: самое позднее updated_at не меняется, когда
+	// событие удаляют или оно выпадает из фильтра, и клиент получил бы 304 со старым
+	// списком. Условные запросы проверяются по ETag тела (httpcache.Middleware).
+
+	h.trackSearch(analytics.SourceEvents, filterReq, searchResultsCount(httpResponse), started)
 	return WriteJSON(w, http.StatusOK, httpResponse)
 }
 
 // handleGetEventsAdvanced обрабатывает POST запрос с фильтрами в теле запроса
 func (h *eventHandler) handleGetEventsAdvanced(w http.ResponseWriter, r *http.Request) error {
+	started := time.Now()
 	h.logger.InfoContext(r.Context(), "Handling advanced events request with body filters")
 
 	var filterReq ListEventsReq
@@ -567,6 +862,26 @@ func (h *eventHandler) handleGetEventsAdvanced(w http.ResponseWriter, r *http.Re
 	}
 	defer r.Body.Close()
 
+	if err := ValidateGeoFilters(&filterReq); err != nil {
+		h.logger.WarnContext(r.Context(), "Invalid geo filters", "error", err)
+		return err
+	}
+
+	if err := ValidateDateFilters(&filterReq); err != nil {
+		h.logger.WarnContext(r.Context(), "Invalid date filters", "error", err)
+		return err
+	}
+
+	var err error
+	if filterReq.Statuses, err = visibleStatuses(r, filterReq.Statuses); err != nil {
+		h.logger.WarnContext(r.Context(), "Invalid status filter", "error", err)
+		return err
+	}
+	if err := h.expandCategoryFilter(r, &filterReq); err != nil {
+		return err
+	}
+	h.recordSearch(r, &filterReq)
+
 	// Детальное логирование полученных фильтров
 	h.logger.InfoContext(r.Context(), "Parsed advanced event filters",
 		"category_ids", filterReq.CategoryIDs,
@@ -580,6 +895,11 @@ func (h *eventHandler) handleGetEventsAdvanced(w http.ResponseWriter, r *http.Re
 		"limit", filterReq.Limit,
 		"offset", filterReq.Offset,
 		"include_count", filterReq.IncludeCount,
+		"include_facets", filterReq.IncludeFacets,
+		"near", filterReq.Near,
+		"radius_km", filterReq.RadiusKm,
+		"bbox", filterReq.BBox,
+		"sort", filterReq.SortBy,
 	)
 
 	// Создаем gRPC контекст
@@ -606,7 +926,11 @@ func (h *eventHandler) handleGetEventsAdvanced(w http.ResponseWriter, r *http.Re
 	}
 
 	// Конвертируем Proto ответ в HTTP ответ
-	httpResponse := ProtoListResToHTTPListRes(res)
+	httpResponse := ProtoListResToHTTPListRes(res, h.facetCategoryNames(grpcCtx, res))
+	expandOccurrences(httpResponse.Events, &filterReq)
+	hideModerationDetails(r, httpResponse.Events...)
+	h.markFavorites(grpcCtx, r, httpResponse.Events)
+	setContentLanguage(w, LocalizeEvents(r, httpResponse.Events...))
 
 	if hasSearch {
 		h.logger.InfoContext(grpcCtx, "Advanced search request completed",
@@ -621,5 +945,16 @@ func (h *eventHandler) handleGetEventsAdvanced(w http.ResponseWriter, r *http.Re
 		)
 	}
 
+	h.trackSearch(analytics.SourceSearch, &filterReq, searchResultsCount(httpResponse), started)
 	return WriteJSON(w, http.StatusOK, httpResponse)
 }
+
+// facetCategoryNames загружает названия категорий для фасетов ответа.
+// Ошибка загрузки не прерывает запрос: фасеты вернутся без названий.
+func (h *eventHandler) facetCategoryNames(ctx context.Context, res *pbEvent.ListEventsRes) map[int64]string {
+	if len(res.GetFacets().GetCategories()) == 0 {
+		return nil
+	}
+
+	return h.categoryNames(ctx)
+}
@@ -6,9 +6,11 @@ import (
 	"google.golang.org/grpc/status" // Для обработки gRPC ошибок
 
 	contextpkg "github.com/rx3lixir/gateway-service/pkg/context"
+	"github.com/rx3lixir/gateway-service/pkg/i18n"
 
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -50,27 +52,20 @@ type apiFunc func(w http.ResponseWriter, r *http.Request) error
 func (h *eventHandler) makeHTTPHandlerFunc(f apiFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if err := f(w, r); err != nil {
+			// Несовпадение If-Match, см. preconditionError
+			if errors.Is(err, errPreconditionFailed) {
+				WriteJSON(w, http.StatusPreconditionFailed, APIError{Error: i18n.Localize(r.Context(), err.Error())})
+				return
+			}
+
 			// Обработка gRPC ошибок
 			st, ok := status.FromError(err)
 			if ok {
-				var httpStatus int
-				switch st.Code() {
-				case codes.NotFound:
-					httpStatus = http.StatusNotFound
-				case codes.InvalidArgument:
-					httpStatus = http.StatusBadRequest
-				case codes.AlreadyExists:
-					httpStatus = http.StatusConflict
-				case codes.Unauthenticated:
-					httpStatus = http.StatusUnauthorized
-				case codes.PermissionDenied:
-					httpStatus = http.StatusForbidden
-				// Добавьте другие коды gRPC по мере необходимости
-				default:
+				httpStatus := grpcHTTPStatus(st.Code())
+				if httpStatus == http.StatusInternalServerError {
 					h.logger.Error("Unhandled gRPC error", "code", st.Code(), "message", st.Message(), "path", r.URL.Path)
-					httpStatus = http.StatusInternalServerError
 				}
-				WriteJSON(w, httpStatus, APIError{Error: st.Message()})
+				WriteJSON(w, httpStatus, APIError{Error: i18n.Localize(r.Context(), st.Message())})
 				return
 			}
 
@@ -79,22 +74,45 @@ func (h *eventHandler) makeHTTPHandlerFunc(f apiFunc) http.HandlerFunc {
 			// Проверка на "is required" или "invalid"
 			errStr := strings.ToLower(err.Error())
 			if strings.Contains(errStr, "required") || strings.Contains(errStr, "invalid") || strings.Contains(errStr, "format") || strings.Contains(errStr, "positive integer") {
-				WriteJSON(w, http.StatusBadRequest, APIError{Error: err.Error()})
+				WriteJSON(w, http.StatusBadRequest, APIError{Error: i18n.Localize(r.Context(), err.Error())})
 				return
 			}
 
 			// Если ошибка содержит "not found" (из старого кода, но лучше полагаться на gRPC codes.NotFound)
 			if strings.Contains(errStr, "not found") {
-				WriteJSON(w, http.StatusNotFound, APIError{Error: err.Error()})
+				WriteJSON(w, http.StatusNotFound, APIError{Error: i18n.Localize(r.Context(), err.Error())})
 				return
 			}
 
 			h.logger.Error("HTTP handler error", "error", err, "path", r.URL.Path)
-			WriteJSON(w, http.StatusInternalServerError, APIError{Error: "An unexpected error occurred"})
+			WriteJSON(w, http.StatusInternalServerError, APIError{Error: i18n.Localize(r.Context(), "An unexpected error occurred")})
 		}
 	}
 }
 
+// grpcHTTPStatus HTTP статус для кода gRPC ошибки
+func grpcHTTPStatus(code codes.Code) int {
+	switch code {
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.AlreadyExists:
+		return http.StatusConflict
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.FailedPrecondition, codes.Aborted:
+		return http.StatusConflict
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	// Добавьте другие коды gRPC по мере необходимости
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
 // clearCookies очищает все аутентификационные cookies
 func (h *eventHandler) clearCookies(w http.ResponseWriter) {
 	cookies := []string{"access_token", "refresh_token", "session_id"}
@@ -125,7 +143,42 @@ func parseIDFromURL(r *http.Request, paramName string) (int64, error) {
 	return id, nil
 }
 
+// parseBoolParam разбирает необязательный логический параметр запроса; по умолчанию false
+func parseBoolParam(r *http.Request, name string) (bool, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return false, nil
+	}
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s parameter %q: expected true or false", name, raw)
+	}
+	return value, nil
+}
+
+// valueOrZero возвращает значение указателя или нулевое значение типа для nil
+func valueOrZero[T any](p *T) T {
+	var zero T
+	if p == nil {
+		return zero
+	}
+	return *p
+}
+
 // createContext создает дочерний контекст с таймаутом для gRPC вызова.
 func (h *eventHandler) createContext(r *http.Request) (context.Context, context.CancelFunc) {
 	return contextpkg.GRPCContextFromHTTP(r)
 }
+
+// validateCreateEventReq проверяет запрос на создание события и нормализует его расписание
+func validateCreateEventReq(req *CreateEventReq) (*eventSchedule, error) {
+	if strings.TrimSpace(req.Name) == "" {
+		return nil, fmt.Errorf("event name is required")
+	}
+
+	if err := validateEventLocation(req.Lat, req.Lon); err != nil {
+		return nil, err
+	}
+
+	return normalizeSchedule(createEventScheduleInput(req))
+}
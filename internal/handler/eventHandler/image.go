@@ -0,0 +1,148 @@
+package eventHandler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+
+	"github.com/google/uuid"
+	pbEvent "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/event"
+	"github.com/rx3lixir/gateway-service/pkg/imageproc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+const (
+	// maxImageBytes ограничивает размер загружаемого изображения
+	maxImageBytes = 10 << 20
+
+	// imageFormField имя поля файла в multipart форме
+	imageFormField = "image"
+
+	// imageVariantMain вариант, URL которого записывается в поле image
+	imageVariantMain = "full"
+)
+
+// handleUploadEventImage загружает изображение события.
+//
+// Тип файла определяется по содержимому, метаданные удаляются перекодированием,
+// создаются варианты imageproc.DefaultVariants. Файлы сохраняются в blob store,
+// а их URL записываются в событие через UpdateEvent (image и image_variants).
+func (h *eventHandler) handleUploadEventImage(w http.ResponseWriter, r *http.Request) error {
+	if h.imageStore == nil {
+		return status.Error(codes.Unimplemented, "image upload is not configured")
+	}
+
+	id, err := parseIDFromURL(r, "id")
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Failed to parse ID from URL", "error", err)
+		return err
+	}
+
+	data, err := readImageUpload(r)
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Failed to read image upload", "id", id, "error", err)
+		return err
+	}
+
+	h.logger.InfoContext(r.Context(), "Processing event image", "id", id, "size", len(data))
+
+	variants, err := imageproc.Process(data, imageproc.DefaultVariants)
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Failed to process event image", "id", id, "error", err)
+		if errors.Is(err, imageproc.ErrUnsupportedType) || errors.Is(err, imageproc.ErrTooLarge) {
+			return fmt.Errorf("invalid image: %w", err)
+		}
+		return err
+	}
+
+	grpcCtx, cancel := h.createContext(r)
+	defer cancel()
+
+	// Событие должно существовать до загрузки файлов, чтобы не оставлять "сирот"
+	if _, err := h.eventClient.GetEvent(grpcCtx, IDToProtoGetEventByIDReq(id)); err != nil {
+		h.logger.ErrorContext(grpcCtx, "Failed to get event for image upload", "id", id, "error", err)
+		return err
+	}
+
+	// Уникальный префикс: старые ссылки продолжают работать из кэшей и CDN
+	prefix := path.Join("events", strconv.FormatInt(id, 10), uuid.NewString())
+	urls := make(map[string]string, len(variants))
+	var keys []string
+
+	for _, v := range variants {
+		key := path.Join(prefix, v.Variant.Name+"."+v.Extension)
+		url, err := h.imageStore.Put(grpcCtx, key, v.Data, v.ContentType)
+		if err != nil {
+			h.logger.ErrorContext(grpcCtx, "Failed to store image variant", "id", id, "variant", v.Variant.Name, "error", err)
+			h.deleteImageKeys(r, keys)
+			return err
+		}
+		keys = append(keys, key)
+		urls[v.Variant.Name] = url
+	}
+
+	protoReq := &pbEvent.UpdateEventReq{
+		Id:            id,
+		Image:         urls[imageVariantMain],
+		ImageVariants: urls,
+		UpdateMask:    &fieldmaskpb.FieldMask{Paths: []string{"image", "image_variants"}},
+	}
+
+	updatedEvent, err := h.eventClient.UpdateEvent(grpcCtx, protoReq)
+	if err != nil {
+		h.logger.ErrorContext(grpcCtx, "Failed to update event image via gRPC", "id", id, "error", err)
+		h.deleteImageKeys(r, keys)
+		return err
+	}
+
+	h.logger.InfoContext(grpcCtx, "Event image uploaded", "id", id, "variants", len(urls))
+
+	h.publishChange(r.Context(), changeUpdated, id, updatedEvent)
+
+	httpEvent := ProtoEventResToHTTPEvent(updatedEvent)
+	setVersionETag(w, resourceVersion(updatedEvent.GetUpdatedAt(), updatedEvent.GetCreatedAt()))
+	return WriteJSON(w, http.StatusOK, httpEvent)
+}
+
+// readImageUpload читает файл изображения из multipart формы с ограничением размера
+func readImageUpload(r *http.Request) ([]byte, error) {
+	// Запас сверх лимита файла на служебные части multipart
+	r.Body = http.MaxBytesReader(nil, r.Body, maxImageBytes+64<<10)
+	defer r.Body.Close()
+
+	file, _, err := r.FormFile(imageFormField)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return nil, fmt.Errorf("invalid image: file is larger than %d MB", maxImageBytes>>20)
+		}
+		return nil, fmt.Errorf("invalid request body: multipart field %q is required: %w", imageFormField, err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxImageBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("invalid request body: %w", err)
+	}
+	if len(data) > maxImageBytes {
+		return nil, fmt.Errorf("invalid image: file is larger than %d MB", maxImageBytes>>20)
+	}
+	return data, nil
+}
+
+// deleteImageKeys удаляет уже загруженные файлы после неудачной загрузки
+func (h *eventHandler) deleteImageKeys(r *http.Request, keys []string) {
+	grpcCtx, cancel := h.createContext(r)
+	defer cancel()
+
+	for _, key := range keys {
+		if err := h.imageStore.Delete(grpcCtx, key); err != nil {
+			h.logger.WarnContext(grpcCtx, "Failed to clean up image", "key", key, "error", err)
+		}
+	}
+}
@@ -1,52 +1,110 @@
 package eventHandler
 
 import (
+	"fmt"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	pbEvent "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/event"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 )
 
 // HTTPCreateReqToProtoCreateEventReq конвертирует
 // CreateEventReq (шлюз) в pbEvent.CreateEventReq (gRPC).
-func HTTPCreateReqToProtoCreateEventReq(req *CreateEventReq) *pbEvent.CreateEventReq {
+// sched — нормализованное расписание, см. normalizeSchedule.
+func HTTPCreateReqToProtoCreateEventReq(req *CreateEventReq, sched *eventSchedule) *pbEvent.CreateEventReq {
 	if req == nil {
 		return nil
 	}
 
+	date, clock := req.Date, req.Time
+	if sched != nil && sched.StartsAt != nil {
+		date, clock = sched.Date, sched.Time
+	}
+	startsAt, endsAt, tz, rrule := scheduleToProto(sched)
+
 	return &pbEvent.CreateEventReq{
-		Name:        req.Name,
-		Description: req.Description,
-		CategoryID:  req.CategoryID,
-		Date:        req.Date,
-		Time:        req.Time,
-		Location:    req.Location,
-		Price:       req.Price,
-		Image:       req.Image,
-		Source:      req.Source,
+		Name:           req.Name,
+		Description:    req.Description,
+		CategoryID:     req.CategoryID,
+		Date:           date,
+		Time:           clock,
+		StartsAt:       startsAt,
+		EndsAt:         endsAt,
+		TimeZone:       tz,
+		RecurrenceRule: rrule,
+		Location:       req.Location,
+		Price:          req.Price,
+		Image:          req.Image,
+		Source:         req.Source,
+		Lat:            req.Lat,
+		Lon:            req.Lon,
+		Status:         valueOrZero(req.Status),
+		Translations:   translationsToEventProto(req.Translations),
 	}
 }
 
 // HTTPUpdateReqToProtoUpdateEventReq конвертирует UpdateEventReq (шлюз)
 // и ID в pbEvent.UpdateEventReq (gRPC).
-func HTTPUpdateReqToProtoUpdateEventReq(id int64, req *UpdateEventReq) *pbEvent.UpdateEventReq {
+// mask — пути обновляемых полей; sched — нормализованное расписание или nil,
+// если поля расписания не обновляются. При sched != nil в маску попадают
+// все поля расписания, так как они пересчитываются вместе.
+func HTTPUpdateReqToProtoUpdateEventReq(id int64, req *UpdateEventReq, sched *eventSchedule, mask []string) *pbEvent.UpdateEventReq {
 	if req == nil {
 		return &pbEvent.UpdateEventReq{Id: id}
 	}
 
-	return &pbEvent.UpdateEventReq{
+	protoReq := &pbEvent.UpdateEventReq{
 		Id:          id,
-		Name:        req.Name,
-		Description: req.Description,
-		CategoryID:  req.CategoryID,
-		Date:        req.Date,
-		Time:        req.Time,
-		Location:    req.Location,
-		Price:       req.Price,
-		Image:       req.Image,
-		Source:      req.Source,
+		Name:        valueOrZero(req.Name),
+		Description: valueOrZero(req.Description),
+		CategoryID:  valueOrZero(req.CategoryID),
+		Date:        valueOrZero(req.Date),
+		Time:        valueOrZero(req.Time),
+		Location:    valueOrZero(req.Location),
+		Price:       valueOrZero(req.Price),
+		Image:       valueOrZero(req.Image),
+		Source:      valueOrZero(req.Source),
+		Lat:         req.Lat,
+		Lon:         req.Lon,
+
+		Translations: translationsToEventProto(req.Translations),
+	}
+
+	if sched != nil {
+		protoReq.Date, protoReq.Time = sched.Date, sched.Time
+		protoReq.StartsAt, protoReq.EndsAt, protoReq.TimeZone, protoReq.RecurrenceRule = scheduleToProto(sched)
+		mask = mergeMaskPaths(mask, scheduleMaskPaths)
+	}
+
+	// Варианты изображения относятся к прежнему image: при ручной смене ссылки сбрасываем их
+	if slices.Contains(mask, "image") {
+		mask = mergeMaskPaths(mask, []string{"image_variants"})
+	}
+
+	protoReq.UpdateMask = &fieldmaskpb.FieldMask{Paths: mask}
+
+	return protoReq
+}
+
+// scheduleMaskPaths пути FieldMask всех полей расписания события
+var scheduleMaskPaths = []string{"date", "time", "starts_at", "ends_at", "time_zone", "recurrence_rule"}
+
+// mergeMaskPaths объединяет пути FieldMask без повторов, сохраняя сортировку
+func mergeMaskPaths(mask []string, extra []string) []string {
+	seen := make(map[string]bool, len(mask)+len(extra))
+	merged := make([]string, 0, len(mask)+len(extra))
+	for _, path := range append(append([]string{}, mask...), extra...) {
+		if !seen[path] {
+			seen[path] = true
+			merged = append(merged, path)
+		}
 	}
+	sort.Strings(merged)
+	return merged
 }
 
 // HTTPListReqToProtoListReq конвертирует ListEventsReq из URL параметров и JSON в pbEvent.ListEventsReq
@@ -104,6 +162,24 @@ func HTTPListReqToProtoListReq(req *ListEventsReq) *pbEvent.ListEventsReq {
 		protoReq.IncludeCount = req.IncludeCount
 	}
 
+	if req.IncludeFacets != nil {
+		protoReq.IncludeFacets = req.IncludeFacets
+	}
+
+	// Гео-фильтры
+	geoFiltersToProto(req, protoReq)
+
+	if len(req.Statuses) > 0 {
+		protoReq.Statuses = req.Statuses
+	}
+
+	// При окне дат просим сервис вернуть и повторяющиеся серии, пересекающие окно:
+	// повторения раскрываются шлюзом, см. expandOccurrences
+	if req.DateFrom != nil || req.DateTo != nil {
+		includeRecurring := true
+		protoReq.IncludeRecurring = &includeRecurring
+	}
+
 	return protoReq
 }
 
@@ -129,7 +205,7 @@ func ProtoEventResToHTTPEvent(protoEvent *pbEvent.EventRes) *Event {
 		createdAt = time.Now()
 	}
 
-	return &Event{
+	event := &Event{
 		Id:          protoEvent.GetId(),
 		Name:        protoEvent.GetName(),
 		Description: protoEvent.GetDescription(),
@@ -140,9 +216,24 @@ func ProtoEventResToHTTPEvent(protoEvent *pbEvent.EventRes) *Event {
 		Price:       protoEvent.GetPrice(),
 		Image:       protoEvent.GetImage(),
 		Source:      protoEvent.GetSource(),
+		Lat:         protoEvent.Lat,
+		Lon:         protoEvent.Lon,
+		DistanceKm:  protoEvent.DistanceKm,
 		CreatedAt:   createdAt,
 		UpdatedAt:   updatedAt,
+
+		ImageVariants: protoEvent.GetImageVariants(),
+
+		Status:           eventStatus(protoEvent),
+		ModerationReason: protoEvent.GetModerationReason(),
+		SubmittedBy:      protoEvent.GetSubmittedBy(),
+
+		Translations: eventTranslationsFromProto(protoEvent.GetTranslations()),
 	}
+
+	protoScheduleToHTTP(protoEvent, event)
+
+	return event
 }
 
 // ProtoEventsListToHTTPEventsList конвертирует []*pbEvent.EventRes (gRPC)
@@ -160,8 +251,9 @@ func ProtoEventsListToHTTPEventsList(protoEvents []*pbEvent.EventRes) []*Event {
 	return httpEvents
 }
 
-// ProtoListResToHTTPListRes конвертирует pbEvent.ListEventsRes в ListEventsRes
-func ProtoListResToHTTPListRes(protoRes *pbEvent.ListEventsRes) *ListEventsRes {
+// ProtoListResToHTTPListRes конвертирует pbEvent.ListEventsRes в ListEventsRes.
+// categoryNames используется для подстановки названий категорий в фасеты и может быть nil.
+func ProtoListResToHTTPListRes(protoRes *pbEvent.ListEventsRes, categoryNames map[int64]string) *ListEventsRes {
 	if protoRes == nil {
 		return &ListEventsRes{
 			Events: []*Event{},
@@ -177,9 +269,68 @@ func ProtoListResToHTTPListRes(protoRes *pbEvent.ListEventsRes) *ListEventsRes {
 		httpRes.Pagination = ProtoPaginationToHTTPPagination(protoRes.GetPagination())
 	}
 
+	// Конвертируем фасеты если они запрошены
+	if protoRes.GetFacets() != nil {
+		httpRes.Facets = ProtoFacetsToHTTPFacets(protoRes.GetFacets(), categoryNames)
+	}
+
 	return httpRes
 }
 
+// ProtoFacetsToHTTPFacets конвертирует pbEvent.SearchFacets в SearchFacets,
+// подставляя названия категорий по их ID.
+func ProtoFacetsToHTTPFacets(protoFacets *pbEvent.SearchFacets, categoryNames map[int64]string) *SearchFacets {
+	if protoFacets == nil {
+		return nil
+	}
+
+	facets := &SearchFacets{
+		Categories:     make([]CategoryFacet, 0, len(protoFacets.GetCategories())),
+		PriceHistogram: make([]PriceBucket, 0, len(protoFacets.GetPriceHistogram())),
+		Locations:      protoTermFacetsToHTTP(protoFacets.GetLocations()),
+		Sources:        protoTermFacetsToHTTP(protoFacets.GetSources()),
+	}
+
+	for _, c := range protoFacets.GetCategories() {
+		facets.Categories = append(facets.Categories, CategoryFacet{
+			CategoryID: c.GetCategoryID(),
+			Name:       categoryNames[c.GetCategoryID()],
+			Count:      c.GetCount(),
+		})
+	}
+
+	for _, b := range protoFacets.GetPriceHistogram() {
+		facets.PriceHistogram = append(facets.PriceHistogram, PriceBucket{
+			From:  b.GetFrom(),
+			To:    b.To,
+			Count: b.GetCount(),
+		})
+	}
+
+	return facets
+}
+
+// protoTermFacetsToHTTP конвертирует []*pbEvent.TermFacet в []TermFacet
+func protoTermFacetsToHTTP(protoTerms []*pbEvent.TermFacet) []TermFacet {
+	terms := make([]TermFacet, 0, len(protoTerms))
+	for _, t := range protoTerms {
+		terms = append(terms, TermFacet{
+			Value: t.GetValue(),
+			Count: t.GetCount(),
+		})
+	}
+	return terms
+}
+
+// ProtoCategoriesToNameMap строит отображение ID категории -> название
+func ProtoCategoriesToNameMap(protoCategories []*pbEvent.CategoryRes) map[int64]string {
+	names := make(map[int64]string, len(protoCategories))
+	for _, c := range protoCategories {
+		names[int64(c.GetId())] = c.GetName()
+	}
+	return names
+}
+
 // ProtoPaginationToHTTPPagination конвертирует pbEvent.PaginationMeta в PaginationMeta
 func ProtoPaginationToHTTPPagination(protoPagination *pbEvent.PaginationMeta) *PaginationMeta {
 	if protoPagination == nil {
@@ -234,23 +385,47 @@ func HTTPCreateCategoryReqToProtoCreateCategoryReq(req *CreateCategoryReq) *pbEv
 	}
 
 	return &pbEvent.CreateCategoryReq{
-		Name: req.Name,
+		Name:        req.Name,
+		ParentId:    intToInt32Ptr(req.ParentID),
+		Slug:        valueOrZero(req.Slug),
+		Description: req.Description,
+		Icon:        req.Icon,
+		SortOrder:   int32(req.SortOrder),
+
+		Translations: translationsToCategoryProto(req.Translations),
 	}
 }
 
 // HTTPUpdateCategoryReqToProtoUpdateCategoryReq конвертирует UpdateCategoryReq (шлюз)
-// и ID в pbEvent.UpdateCategoryReq (gRPC).
-func HTTPUpdateCategoryReqToProtoUpdateCategoryReq(id int32, req *UpdateCategoryReq) *pbEvent.UpdateCategoryReq {
+// и ID в pbEvent.UpdateCategoryReq (gRPC). mask — пути обновляемых полей.
+func HTTPUpdateCategoryReqToProtoUpdateCategoryReq(id int32, req *UpdateCategoryReq, mask []string) *pbEvent.UpdateCategoryReq {
 	if req == nil {
 		return &pbEvent.UpdateCategoryReq{Id: id}
 	}
 
 	return &pbEvent.UpdateCategoryReq{
-		Id:   id,
-		Name: req.Name,
+		Id:          id,
+		Name:        valueOrZero(req.Name),
+		ParentId:    intToInt32Ptr(req.ParentID),
+		Slug:        valueOrZero(req.Slug),
+		Description: valueOrZero(req.Description),
+		Icon:        valueOrZero(req.Icon),
+		SortOrder:   int32(valueOrZero(req.SortOrder)),
+		UpdateMask:  &fieldmaskpb.FieldMask{Paths: mask},
+
+		Translations: translationsToCategoryProto(req.Translations),
 	}
 }
 
+// intToInt32Ptr конвертирует *int в *int32
+func intToInt32Ptr(v *int) *int32 {
+	if v == nil {
+		return nil
+	}
+	i := int32(*v)
+	return &i
+}
+
 // ProtoCategoryResToHTTPCategory конвертирует pbEvent.CategoryRes (gRPC)
 // в Category (шлюз).
 func ProtoCategoryResToHTTPCategory(protoCategory *pbEvent.CategoryRes) *Category {
@@ -274,12 +449,72 @@ func ProtoCategoryResToHTTPCategory(protoCategory *pbEvent.CategoryRes) *Categor
 		updatedAt = createdAt
 	}
 
-	return &Category{
-		Id:        int(protoCategory.GetId()),
-		Name:      protoCategory.GetName(),
-		CreatedAt: createdAt,
-		UpdatedAt: updatedAt,
+	category := &Category{
+		Id:          int(protoCategory.GetId()),
+		Name:        protoCategory.GetName(),
+		Slug:        protoCategory.GetSlug(),
+		Description: protoCategory.GetDescription(),
+		Icon:        protoCategory.GetIcon(),
+		SortOrder:   int(protoCategory.GetSortOrder()),
+		EventCount:  protoCategory.EventCount,
+		CreatedAt:   createdAt,
+		UpdatedAt:   updatedAt,
+
+		Translations: categoryTranslationsFromProto(protoCategory.GetTranslations()),
+	}
+	if protoCategory.ParentId != nil {
+		parentID := int(protoCategory.GetParentId())
+		category.ParentID = &parentID
+	}
+	return category
+}
+
+// translationsToEventProto конвертирует переводы события для gRPC запроса
+func translationsToEventProto(translations map[string]Translation) map[string]*pbEvent.EventTranslation {
+	if translations == nil {
+		return nil
+	}
+	result := make(map[string]*pbEvent.EventTranslation, len(translations))
+	for lang, t := range translations {
+		result[lang] = &pbEvent.EventTranslation{Name: t.Name, Description: t.Description}
+	}
+	return result
+}
+
+// eventTranslationsFromProto конвертирует переводы события из gRPC ответа
+func eventTranslationsFromProto(translations map[string]*pbEvent.EventTranslation) map[string]Translation {
+	if len(translations) == 0 {
+		return nil
+	}
+	result := make(map[string]Translation, len(translations))
+	for lang, t := range translations {
+		result[lang] = Translation{Name: t.GetName(), Description: t.GetDescription()}
+	}
+	return result
+}
+
+// translationsToCategoryProto конвертирует переводы категории для gRPC запроса
+func translationsToCategoryProto(translations map[string]Translation) map[string]*pbEvent.CategoryTranslation {
+	if translations == nil {
+		return nil
+	}
+	result := make(map[string]*pbEvent.CategoryTranslation, len(translations))
+	for lang, t := range translations {
+		result[lang] = &pbEvent.CategoryTranslation{Name: t.Name, Description: t.Description}
+	}
+	return result
+}
+
+// categoryTranslationsFromProto конвертирует переводы категории из gRPC ответа
+func categoryTranslationsFromProto(translations map[string]*pbEvent.CategoryTranslation) map[string]Translation {
+	if len(translations) == 0 {
+		return nil
 	}
+	result := make(map[string]Translation, len(translations))
+	for lang, t := range translations {
+		result[lang] = Translation{Name: t.GetName(), Description: t.GetDescription()}
+	}
+	return result
 }
 
 // ProtoCategoriesListToHTTPCategoriesList конвертирует []*pbEvent.CategoryRes (gRPC)
@@ -377,6 +612,11 @@ func ParseQueryParams(params map[string][]string) (*ListEventsReq, error) {
 		req.DateTo = &dateTos[0]
 	}
 
+	// Валидируем и нормализуем окно дат
+	if err := ValidateDateFilters(req); err != nil {
+		return nil, err
+	}
+
 	// Парсим location
 	if locations, ok := params["location"]; ok && len(locations) > 0 && locations[0] != "" {
 		req.Location = &locations[0]
@@ -415,5 +655,35 @@ func ParseQueryParams(params map[string][]string) (*ListEventsReq, error) {
 		}
 	}
 
+	// Парсим include_facets
+	if includeFacets, ok := params["include_facets"]; ok && len(includeFacets) > 0 {
+		if include, err := strconv.ParseBool(includeFacets[0]); err == nil {
+			req.IncludeFacets = &include
+		}
+	}
+
+	// Парсим include_subcategories
+	if values, ok := params["include_subcategories"]; ok && len(values) > 0 {
+		include, err := strconv.ParseBool(values[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s parameter %q: expected true or false", "include_subcategories", values[0])
+		}
+		req.IncludeSubcategories = &include
+	}
+
+	// Парсим и валидируем гео-фильтры
+	if err := parseGeoQueryParams(params, req); err != nil {
+		return nil, err
+	}
+
+	// Парсим status (через запятую или несколько параметров)
+	for _, value := range params["status"] {
+		for _, part := range strings.Split(value, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				req.Statuses = append(req.Statuses, part)
+			}
+		}
+	}
+
 	return req, nil
 }
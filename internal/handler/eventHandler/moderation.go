@@ -0,0 +1,312 @@
+package eventHandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	pbEvent "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/event"
+	"github.com/rx3lixir/gateway-service/pkg/cache"
+	contextkeys "github.com/rx3lixir/gateway-service/pkg/context"
+	"github.com/rx3lixir/gateway-service/pkg/token"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// Статусы модерации событий
+const (
+	eventStatusDraft     = "draft"
+	eventStatusPending   = "pending"
+	eventStatusPublished = "published"
+	eventStatusRejected  = "rejected"
+	eventStatusArchived  = "archived"
+)
+
+// maxModerationReasonLength ограничивает длину причины отклонения или архивации
+const maxModerationReasonLength = 1000
+
+var (
+	// eventStatuses все статусы модерации
+	eventStatuses = []string{eventStatusDraft, eventStatusPending, eventStatusPublished, eventStatusRejected, eventStatusArchived}
+
+	// creatableStatuses статусы, которые модератор может задать при создании события
+	creatableStatuses = []string{eventStatusDraft, eventStatusPending, eventStatusPublished}
+
+	// publicStatuses статусы, видимые без прав модератора
+	publicStatuses = []string{eventStatusPublished}
+)
+
+// moderationAction переход статуса события по действию модератора
+type moderationAction struct {
+	name           string
+	to             string
+	from           []string
+	reasonRequired bool
+}
+
+var (
+	actionApprove = moderationAction{
+		name: "approve",
+		to:   eventStatusPublished,
+		from: []string{eventStatusDraft, eventStatusPending, eventStatusRejected},
+	}
+	actionReject = moderationAction{
+		name:           "reject",
+		to:             eventStatusRejected,
+		from:           []string{eventStatusDraft, eventStatusPending},
+		reasonRequired: true,
+	}
+	actionArchive = moderationAction{
+		name: "archive",
+		to:   eventStatusArchived,
+		from: []string{eventStatusDraft, eventStatusPending, eventStatusPublished, eventStatusRejected},
+	}
+)
+
+// eventStatus возвращает статус события; пустой статус (события до модерации) — published
+func eventStatus(event *pbEvent.EventRes) string {
+	if s := event.GetStatus(); s != "" {
+		return s
+	}
+	return eventStatusPublished
+}
+
+// isModerator проверяет права модератора. Модераторы — администраторы.
+func isModerator(r *http.Request) bool {
+	return moderatorFromContext(r.Context())
+}
+
+func moderatorFromContext(ctx context.Context) bool {
+	claims, ok := ctx.Value(contextkeys.AuthKey).(*token.UserClaims)
+	return ok && claims != nil && claims.IsAdmin
+}
+
+// submitter возвращает, кто отправляет событие: "user:<id>" или "api_key:<имя>"
+func submitter(r *http.Request) string {
+	return submitterFromContext(r.Context())
+}
+
+func submitterFromContext(ctx context.Context) string {
+	if name, ok := ctx.Value(contextkeys.APIKeyKey).(string); ok {
+		return "api_key:" + name
+	}
+	if claims, ok := ctx.Value(contextkeys.AuthKey).(*token.UserClaims); ok && claims != nil {
+		return "user:" + strconv.Itoa(claims.Id)
+	}
+	return ""
+}
+
+// createStatus определяет статус нового события: модераторы публикуют сразу
+// (или задают статус явно), остальные события уходят на модерацию
+func createStatus(r *http.Request, requested *string) (string, error) {
+	if !isModerator(r) {
+		if requested != nil && *requested != eventStatusPending {
+			return "", status.Error(codes.PermissionDenied, "only moderators can set event status")
+		}
+		return eventStatusPending, nil
+	}
+
+	if requested == nil {
+		return eventStatusPublished, nil
+	}
+	if !slices.Contains(creatableStatuses, *requested) {
+		return "", fmt.Errorf("invalid status %q: expected one of %s", *requested, strings.Join(creatableStatuses, ", "))
+	}
+	return *requested, nil
+}
+
+// visibleStatuses возвращает фильтр статусов для списка событий.
+// Без прав модератора видны только опубликованные события.
+func visibleStatuses(r *http.Request, requested []string) ([]string, error) {
+	for _, s := range requested {
+		if !slices.Contains(eventStatuses, s) {
+			return nil, fmt.Errorf("invalid status %q: expected one of %s", s, strings.Join(eventStatuses, ", "))
+		}
+	}
+
+	if isModerator(r) {
+		return requested, nil
+	}
+	for _, s := range requested {
+		if s != eventStatusPublished {
+			return nil, status.Error(codes.PermissionDenied, "status filter requires moderator rights")
+		}
+	}
+	return publicStatuses, nil
+}
+
+// canView проверяет, видно ли событие вызывающему: опубликованные видны всем,
+// остальные — модераторам и отправителю
+func canView(r *http.Request, event *pbEvent.EventRes) bool {
+	return visibleTo(r.Context(), eventStatus(event), event.GetSubmittedBy())
+}
+
+// CanView — canView для события в формате HTTP API. Используется обработчиками,
+// которые отдают события вне маршрутов /events (избранное, регистрации, GraphQL).
+func CanView(ctx context.Context, event *Event) bool {
+	return visibleTo(ctx, event.Status, event.SubmittedBy)
+}
+
+func visibleTo(ctx context.Context, state, submittedBy string) bool {
+	if state == eventStatusPublished || moderatorFromContext(ctx) {
+		return true
+	}
+	by := submitterFromContext(ctx)
+	return by != "" && by == submittedBy
+}
+
+// hideModerationDetails скрывает отправителя событий от всех, кроме модераторов
+func hideModerationDetails(r *http.Request, events ...*Event) {
+	HideModerationDetails(r.Context(), events...)
+}
+
+// HideModerationDetails — hideModerationDetails по контексту запроса
+func HideModerationDetails(ctx context.Context, events ...*Event) {
+	if moderatorFromContext(ctx) {
+		return
+	}
+	for _, event := range events {
+		if event != nil {
+			event.SubmittedBy = ""
+		}
+	}
+}
+
+// handleApproveEvent публикует событие
+func (h *eventHandler) handleApproveEvent(w http.ResponseWriter, r *http.Request) error {
+	return h.moderateEvent(w, r, actionApprove)
+}
+
+// handleRejectEvent отклоняет событие с указанием причины
+func (h *eventHandler) handleRejectEvent(w http.ResponseWriter, r *http.Request) error {
+	return h.moderateEvent(w, r, actionReject)
+}
+
+// handleArchiveEvent переносит событие в архив
+func (h *eventHandler) handleArchiveEvent(w http.ResponseWriter, r *http.Request) error {
+	return h.moderateEvent(w, r, actionArchive)
+}
+
+// moderateEvent выполняет переход статуса события.
+//
+// Переход проверяется по текущему статусу, а обновление выполняется с
+// expected_updated_at, поэтому параллельное изменение события дает 409, а не
+// переход из устаревшего статуса.
+func (h *eventHandler) moderateEvent(w http.ResponseWriter, r *http.Request, action moderationAction) error {
+	id, err := parseIDFromURL(r, "id")
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Failed to parse ID from URL", "error", err)
+		return err
+	}
+
+	var req ModerationReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.logger.WarnContext(r.Context(), "Failed to decode moderation request", "error", err)
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	defer r.Body.Close()
+
+	req.Reason = strings.TrimSpace(req.Reason)
+	if action.reasonRequired && req.Reason == "" {
+		return fmt.Errorf("reason is required to %s an event", action.name)
+	}
+	if len([]rune(req.Reason)) > maxModerationReasonLength {
+		return fmt.Errorf("invalid reason: must be at most %d characters", maxModerationReasonLength)
+	}
+
+	grpcCtx, cancel := h.createContext(r)
+	defer cancel()
+
+	current, err := h.eventClient.GetEvent(cache.Bypass(grpcCtx), IDToProtoGetEventByIDReq(id))
+	if err != nil {
+		h.logger.ErrorContext(grpcCtx, "Failed to get event for moderation", "id", id, "error", err)
+		return err
+	}
+
+	from := eventStatus(current)
+	if !slices.Contains(action.from, from) {
+		h.logger.WarnContext(grpcCtx, "Invalid moderation transition", "id", id, "action", action.name, "status", from)
+		return status.Errorf(codes.Aborted, "cannot %s event %d in status %q", action.name, id, from)
+	}
+
+	protoReq := &pbEvent.UpdateEventReq{
+		Id:                id,
+		Status:            action.to,
+		UpdateMask:        &fieldmaskpb.FieldMask{Paths: []string{"status", "moderation_reason"}},
+		ExpectedUpdatedAt: resourceVersion(current.GetUpdatedAt(), current.GetCreatedAt()),
+	}
+	if req.Reason != "" {
+		protoReq.ModerationReason = &req.Reason
+	}
+
+	updated, err := h.eventClient.UpdateEvent(grpcCtx, protoReq)
+	if status.Code(err) == codes.FailedPrecondition {
+		h.logger.WarnContext(grpcCtx, "Event changed during moderation", "id", id, "action", action.name)
+		return status.Errorf(codes.Aborted, "event %d was modified concurrently, retry", id)
+	}
+	if err != nil {
+		h.logger.ErrorContext(grpcCtx, "Failed to moderate event via gRPC", "id", id, "action", action.name, "error", err)
+		return err
+	}
+
+	h.logger.InfoContext(grpcCtx, "Event moderated",
+		"id", id,
+		"action", action.name,
+		"from", from,
+		"to", action.to,
+		"moderator", submitter(r))
+
+	// Для подписчиков уведомлений событие появляется при публикации
+	// и пропадает при архивации опубликованного
+	switch {
+	case action.to == eventStatusPublished:
+		h.publishChange(r.Context(), changeCreated, id, updated)
+	case action.to == eventStatusArchived && from == eventStatusPublished:
+		h.publishChange(r.Context(), changeDeleted, id, current)
+	}
+
+	setVersionETag(w, resourceVersion(updated.GetUpdatedAt(), updated.GetCreatedAt()))
+	return WriteJSON(w, http.StatusOK, ProtoEventResToHTTPEvent(updated))
+}
+
+// handleModerationQueue возвращает очередь модерации. По умолчанию — события
+// в статусе pending; поддерживает все фильтры GET /events.
+func (h *eventHandler) handleModerationQueue(w http.ResponseWriter, r *http.Request) error {
+	filterReq, err := ParseQueryParams(r.URL.Query())
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Failed to parse moderation queue parameters", "error", err)
+		return fmt.Errorf("invalid query parameters: %w", err)
+	}
+
+	if len(filterReq.Statuses) == 0 {
+		filterReq.Statuses = []string{eventStatusPending}
+	}
+	if filterReq.Statuses, err = visibleStatuses(r, filterReq.Statuses); err != nil {
+		return err
+	}
+	if err := h.expandCategoryFilter(r, filterReq); err != nil {
+		return err
+	}
+	includeCount := true
+	filterReq.IncludeCount = &includeCount
+
+	grpcCtx, cancel := h.createContext(r)
+	defer cancel()
+
+	h.logger.InfoContext(grpcCtx, "Sending ListEvents request for moderation queue", "statuses", filterReq.Statuses)
+
+	res, err := h.eventClient.ListEvents(grpcCtx, HTTPListReqToProtoListReq(filterReq))
+	if err != nil {
+		h.logger.ErrorContext(grpcCtx, "Failed to list moderation queue via gRPC", "error", err)
+		return err
+	}
+
+	return WriteJSON(w, http.StatusOK, ProtoListResToHTTPListRes(res, nil))
+}
@@ -0,0 +1,158 @@
+package eventHandler
+
+import (
+	"time"
+
+	pbEvent "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/event"
+	pbFavorite "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/favorite"
+	pbRsvp "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/rsvp"
+	"github.com/rx3lixir/gateway-service/pkg/analytics"
+	"github.com/rx3lixir/gateway-service/pkg/blobstore"
+	"github.com/rx3lixir/gateway-service/pkg/broker"
+	"github.com/rx3lixir/gateway-service/pkg/cache"
+	"github.com/rx3lixir/gateway-service/pkg/history"
+	"github.com/rx3lixir/gateway-service/pkg/httpcache"
+	"github.com/rx3lixir/gateway-service/pkg/i18n"
+	"github.com/rx3lixir/gateway-service/pkg/middleware"
+	"github.com/rx3lixir/gateway-service/pkg/recent"
+	"github.com/rx3lixir/gateway-service/pkg/savedsearch"
+	"github.com/rx3lixir/gateway-service/pkg/webhook"
+)
+
+// Option функция для настройки eventHandler
+type Option func(*eventHandler)
+
+// CachePolicies политики HTTP кэширования публичных маршрутов
+type CachePolicies struct {
+	Events     httpcache.Policy // GET /events
+	Event      httpcache.Policy // GET /events/{id}
+	Categories httpcache.Policy // GET /categories
+	Category   httpcache.Policy // GET /categories/{id}
+	Calendar   httpcache.Policy // GET /calendar.ics
+}
+
+// WithCachePolicies задает политики HTTP кэширования публичных маршрутов.
+// По умолчанию ответы помечаются как no-cache и перепроверяются через ETag.
+func WithCachePolicies(policies CachePolicies) Option {
+	return func(h *eventHandler) {
+		h.cachePolicies = policies
+	}
+}
+
+// WithImageStore задает хранилище загружаемых изображений событий.
+// Без него загрузка изображений отвечает 501.
+func WithImageStore(store blobstore.Store) Option {
+	return func(h *eventHandler) {
+		h.imageStore = store
+	}
+}
+
+// StreamSettings параметры потоков уведомлений об изменениях событий
+type StreamSettings struct {
+	Heartbeat time.Duration // Интервал heartbeat; по умолчанию 15 секунд
+	Buffer    int           // Очередь уведомлений одного подключения
+}
+
+// WithBroker включает публикацию уведомлений об изменениях событий в broker
+// и потоки /events/stream (SSE) и /events/ws (WebSocket). Без брокера потоки отвечают 501.
+func WithBroker(b broker.Broker, settings StreamSettings) Option {
+	return func(h *eventHandler) {
+		h.broker = b
+		h.streamSettings = settings
+	}
+}
+
+// WithWebhooks включает рассылку вебхуков event.created, event.updated и event.deleted
+func WithWebhooks(publisher webhook.Publisher) Option {
+	return func(h *eventHandler) {
+		h.webhooks = publisher
+	}
+}
+
+// WithFavorites включает избранное: /events/{id}/favorite и флаг is_favorite в списках событий.
+// Без него добавление в избранное отвечает 501.
+func WithFavorites(client pbFavorite.FavoriteServiceClient) Option {
+	return func(h *eventHandler) {
+		h.favorites = client
+	}
+}
+
+// WithRsvp включает регистрации на события: /events/{id}/rsvp, список участников
+// и вместимость. Без него регистрация отвечает 501.
+func WithRsvp(client pbRsvp.RsvpServiceClient) Option {
+	return func(h *eventHandler) {
+		h.rsvp = client
+	}
+}
+
+// WithAPIKeys задает API ключи, с которыми можно отправлять события на модерацию (POST /events)
+func WithAPIKeys(keys []middleware.APIKey) Option {
+	return func(h *eventHandler) {
+		h.apiKeys = keys
+	}
+}
+
+// WithLanguages задает языки контента: на язык по умолчанию написаны основные поля
+// событий и категорий, на остальные можно добавить переводы. По умолчанию ru и en.
+func WithLanguages(languages i18n.Languages) Option {
+	return func(h *eventHandler) {
+		h.languages = languages
+	}
+}
+
+// WithRecentSearches включает недавние поисковые запросы: поиск аутентифицированного
+// пользователя сохраняется в store и возвращается в подсказках.
+// Без него /events/suggestions/recent отвечает 501.
+func WithRecentSearches(store recent.Store) Option {
+	return func(h *eventHandler) {
+		h.recentSearches = store
+	}
+}
+
+// WithClickHistory включает историю переходов: переходы из выдачи сохраняются в store,
+// по ним строятся рекомендации и популярность событий
+func WithClickHistory(store history.Store) Option {
+	return func(h *eventHandler) {
+		h.clickHistory = store
+	}
+}
+
+// WithSavedSearches учитывает сохраненные поиски пользователя в рекомендациях
+func WithSavedSearches(store savedsearch.Store) Option {
+	return func(h *eventHandler) {
+		h.savedSearches = store
+	}
+}
+
+// WithSearchAnalytics включает поисковую аналитику: поиски, подсказки и переходы
+// записываются в recorder, отчет GET /analytics/search строит reporter.
+// Без reporter отчет отвечает 501.
+func WithSearchAnalytics(recorder *analytics.Recorder, reporter analytics.Reporter) Option {
+	return func(h *eventHandler) {
+		h.analytics = recorder
+		h.searchStats = reporter
+	}
+}
+
+// WithResponseCache включает кэширование ответов event-service в хранилище store.
+// Опция должна идти после остальных опций, подменяющих клиент.
+func WithResponseCache(store cache.Store, ttl CacheTTLs) Option {
+	return func(h *eventHandler) {
+		h.eventClient = newCachedEventClient(h.eventClient, store, ttl, h.logger)
+	}
+}
+
+// CacheStats возвращает метрики кэша ответов; ok = false, если кэш выключен
+func (h *eventHandler) CacheStats() (stats cache.Stats, ok bool) {
+	cached, ok := h.eventClient.(*cachedEventClient)
+	if !ok {
+		return cache.Stats{}, false
+	}
+	return cached.Stats(), true
+}
+
+// EventClient возвращает клиент event-service, через который работает обработчик,
+// вместе с кэшем ответов, если он включен
+func (h *eventHandler) EventClient() pbEvent.EventServiceClient {
+	return h.eventClient
+}
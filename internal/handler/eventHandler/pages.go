@@ -0,0 +1,156 @@
+package eventHandler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pbEvent "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/event"
+	pbRsvp "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/rsvp"
+	contextkeys "github.com/rx3lixir/gateway-service/pkg/context"
+	"github.com/rx3lixir/gateway-service/pkg/fanout"
+	"github.com/rx3lixir/gateway-service/pkg/token"
+)
+
+const (
+	defaultRelatedLimit = 6
+	maxRelatedLimit     = 20
+)
+
+// Таймауты вызовов страницы события. Общий таймаут запроса — createContext.
+const (
+	pageEventTimeout    = 2 * time.Second
+	pageCategoryTimeout = time.Second
+	pageRelatedTimeout  = 1500 * time.Millisecond
+	pageRsvpTimeout     = time.Second
+)
+
+// handleGetEventPage возвращает все данные страницы события одним ответом:
+// событие, его категорию, похожие события той же категории и, если включены
+// регистрации, вместимость. Без события отвечает ошибкой, остальные части
+// при ошибке равны null и перечислены в unavailable.
+func (h *eventHandler) handleGetEventPage(w http.ResponseWriter, r *http.Request) error {
+	id, err := parseIDFromURL(r, "id")
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Failed to parse ID from URL", "error", err)
+		return err
+	}
+
+	relatedLimit := defaultRelatedLimit
+	if raw := r.URL.Query().Get("related_limit"); raw != "" {
+		relatedLimit, err = strconv.Atoi(raw)
+		if err != nil || relatedLimit < 0 || relatedLimit > maxRelatedLimit {
+			return fmt.Errorf("invalid related_limit %q: must be between 0 and %d", raw, maxRelatedLimit)
+		}
+	}
+
+	h.logger.InfoContext(r.Context(), "Handling request to get event page", "id", id)
+
+	grpcCtx, cancel := h.createContext(r)
+	defer cancel()
+
+	plan := fanout.New(grpcCtx)
+
+	event := fanout.Go(plan, fanout.Call{Name: "event", Timeout: pageEventTimeout}, func(ctx context.Context) (*pbEvent.EventRes, error) {
+		protoEvent, err := h.eventClient.GetEvent(ctx, IDToProtoGetEventByIDReq(id))
+		if err != nil {
+			return nil, err
+		}
+		if protoEvent == nil || !canView(r, protoEvent) {
+			return nil, status.Error(codes.NotFound, fmt.Sprintf("event with id %d not found", id))
+		}
+		return protoEvent, nil
+	})
+
+	category := fanout.Then(plan, event, fanout.Call{Name: "category", Timeout: pageCategoryTimeout, Optional: true}, func(ctx context.Context, e *pbEvent.EventRes) (*pbEvent.CategoryRes, error) {
+		return h.eventClient.GetCategory(ctx, IDToProtoGetCategoryByIDReq(int32(e.GetCategoryID())))
+	})
+
+	var related *fanout.Part[[]*pbEvent.EventRes]
+	if relatedLimit > 0 {
+		related = fanout.Then(plan, event, fanout.Call{Name: "related", Timeout: pageRelatedTimeout, Optional: true}, func(ctx context.Context, e *pbEvent.EventRes) ([]*pbEvent.EventRes, error) {
+			return h.relatedEvents(ctx, e, relatedLimit)
+		})
+	}
+
+	// Вместимость не зависит от события и запрашивается параллельно с ним
+	var rsvp *fanout.Part[*pbRsvp.CapacityRes]
+	if h.rsvp != nil {
+		rsvp = fanout.Go(plan, fanout.Call{Name: "rsvp", Timeout: pageRsvpTimeout, Optional: true}, func(ctx context.Context) (*pbRsvp.CapacityRes, error) {
+			req := &pbRsvp.GetCapacityReq{EventId: id}
+			if claims, ok := r.Context().Value(contextkeys.AuthKey).(*token.UserClaims); ok && claims != nil {
+				userID := int64(claims.Id)
+				req.UserId = &userID
+			}
+			return h.rsvp.GetCapacity(ctx, req)
+		})
+	}
+
+	if err := plan.Run(); err != nil {
+		h.logger.WarnContext(grpcCtx, "Failed to get event for event page", "id", id, "error", err)
+		return err
+	}
+
+	res := &EventPageRes{Unavailable: []string{}}
+	for _, failure := range plan.Unavailable() {
+		h.logger.WarnContext(grpcCtx, "Event page part unavailable", "id", id, "part", failure.Name, "error", failure.Err)
+		res.Unavailable = append(res.Unavailable, failure.Name)
+	}
+
+	protoEvent, _ := event.Value()
+	res.Event = ProtoEventResToHTTPEvent(protoEvent)
+	hideModerationDetails(r, res.Event)
+	lang := LocalizeEvents(r, res.Event)
+
+	if protoCategory, err := category.Value(); err == nil {
+		res.Category = ProtoCategoryResToHTTPCategory(protoCategory)
+		localizeCategories(r, res.Category)
+	}
+	if related != nil {
+		if protoRelated, err := related.Value(); err == nil {
+			res.Related = ProtoEventsListToHTTPEventsList(protoRelated)
+			LocalizeEvents(r, res.Related...)
+		}
+	} else {
+		res.Related = []*Event{}
+	}
+	if rsvp != nil {
+		if capacity, err := rsvp.Value(); err == nil {
+			res.Rsvp = protoCapacityToHTTP(capacity)
+		}
+	}
+
+	setContentLanguage(w, lang)
+	return WriteJSON(w, http.StatusOK, res)
+}
+
+// relatedEvents возвращает до limit предстоящих опубликованных событий
+// из категории события, не считая его самого
+func (h *eventHandler) relatedEvents(ctx context.Context, event *pbEvent.EventRes, limit int) ([]*pbEvent.EventRes, error) {
+	pageSize := int32(limit + 1) // Само событие может попасть в выдачу
+	today := time.Now().UTC().Format(time.DateOnly)
+	req := &ListEventsReq{
+		CategoryIDs: []int64{event.GetCategoryID()},
+		DateFrom:    &today,
+		Limit:       &pageSize,
+		Statuses:    publicStatuses,
+	}
+
+	res, err := h.eventClient.ListEvents(ctx, HTTPListReqToProtoListReq(req))
+	if err != nil {
+		return nil, err
+	}
+
+	related := make([]*pbEvent.EventRes, 0, limit)
+	for _, e := range res.GetEvents() {
+		if e.GetId() != event.GetId() && len(related) < limit {
+			related = append(related, e)
+		}
+	}
+	return related, nil
+}
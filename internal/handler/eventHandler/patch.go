@@ -0,0 +1,323 @@
+package eventHandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	contentTypeJSON       = "application/json"
+	contentTypeMergePatch = "application/merge-patch+json"
+	contentTypeJSONPatch  = "application/json-patch+json"
+
+	// acceptPatch значение заголовка Accept-Patch для PATCH эндпоинтов
+	acceptPatch = contentTypeMergePatch + ", " + contentTypeJSONPatch
+
+	// maxPatchBodyBytes ограничивает размер тела PATCH запроса
+	maxPatchBodyBytes = 1 << 20
+)
+
+// errUnsupportedPatchType возвращается для PATCH с неподдерживаемым Content-Type
+var errUnsupportedPatchType = errors.New("unsupported patch content type")
+
+// eventPatchFields отображает JSON поля события в пути FieldMask gRPC запроса
+var eventPatchFields = map[string]string{
+	"name":            "name",
+	"description":     "description",
+	"category_id":     "categoryID",
+	"date":            "date",
+	"time":            "time",
+	"location":        "location",
+	"price":           "price",
+	"image":           "image",
+	"source":          "source",
+	"lat":             "lat",
+	"lon":             "lon",
+	"starts_at":       "starts_at",
+	"ends_at":         "ends_at",
+	"time_zone":       "time_zone",
+	"recurrence_rule": "recurrence_rule",
+	"translations":    "translations",
+}
+
+// categoryPatchFields отображает JSON поля категории в пути FieldMask gRPC запроса
+var categoryPatchFields = map[string]string{
+	"name":         "name",
+	"slug":         "slug",
+	"parent_id":    "parent_id",
+	"description":  "description",
+	"icon":         "icon",
+	"sort_order":   "sort_order",
+	"translations": "translations",
+}
+
+// nestedPatchFields поля-объекты, которые merge patch объединяет с текущим значением
+// по RFC 7396, а не заменяет целиком: {"translations": {"en": null}} удаляет только en
+var nestedPatchFields = map[string]bool{"translations": true}
+
+// patchDocument документ частичного обновления: поле -> новое значение.
+// Значение null означает очистку поля.
+type patchDocument map[string]json.RawMessage
+
+// jsonPatchOp одна операция JSON Patch (RFC 6902)
+type jsonPatchOp struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+// decodePatchDocument читает тело PATCH запроса и приводит его к patchDocument.
+//
+// Поддерживаются application/json и application/merge-patch+json (RFC 7396),
+// а также application/json-patch+json (RFC 6902) с операциями add, replace,
+// remove и test над полями верхнего уровня. current возвращает текущее HTTP
+// представление ресурса: с ним сравнивает test и объединяются nestedPatchFields.
+func decodePatchDocument(r *http.Request, fields map[string]string, current func() (any, error)) (patchDocument, error) {
+	contentType := contentTypeJSON
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		mediaType, _, err := mime.ParseMediaType(ct)
+		if err != nil {
+			return nil, errUnsupportedPatchType
+		}
+		contentType = mediaType
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxPatchBodyBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("invalid request body: %w", err)
+	}
+	if len(body) > maxPatchBodyBytes {
+		return nil, fmt.Errorf("invalid request body: larger than %d bytes", maxPatchBodyBytes)
+	}
+
+	switch contentType {
+	case contentTypeJSON, contentTypeMergePatch:
+		return decodeMergePatch(body, fields, current)
+	case contentTypeJSONPatch:
+		return decodeJSONPatch(body, fields, current)
+	default:
+		return nil, errUnsupportedPatchType
+	}
+}
+
+// decodeMergePatch разбирает JSON Merge Patch. Значения nestedPatchFields
+// объединяются с текущими, в документ попадает итоговое значение поля.
+func decodeMergePatch(body []byte, fields map[string]string, current func() (any, error)) (patchDocument, error) {
+	var doc patchDocument
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("invalid request body: %w", err)
+	}
+	if doc == nil {
+		return nil, fmt.Errorf("invalid request body: merge patch must be a JSON object")
+	}
+
+	var currentDoc map[string]any
+	for field, value := range doc {
+		if _, ok := fields[field]; !ok {
+			return nil, fmt.Errorf("invalid patch field %q", field)
+		}
+		if !nestedPatchFields[field] {
+			continue
+		}
+
+		var patch any
+		if err := json.Unmarshal(value, &patch); err != nil {
+			return nil, fmt.Errorf("invalid request body: %w", err)
+		}
+		if _, ok := patch.(map[string]any); !ok {
+			continue // null или не объект заменяет поле целиком
+		}
+		if currentDoc == nil {
+			var err error
+			if currentDoc, err = currentPatchTarget(current); err != nil {
+				return nil, err
+			}
+		}
+		merged, err := json.Marshal(mergePatchValue(currentDoc[field], patch))
+		if err != nil {
+			return nil, fmt.Errorf("invalid request body: %w", err)
+		}
+		doc[field] = merged
+	}
+
+	return doc, nil
+}
+
+// mergePatchValue применяет merge patch к значению (RFC 7396, раздел 2):
+// объекты объединяются рекурсивно, null удаляет ключ, остальное заменяет значение
+func mergePatchValue(target, patch any) any {
+	patchObj, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	targetObj, ok := target.(map[string]any)
+	if !ok {
+		targetObj = map[string]any{}
+	}
+
+	merged := make(map[string]any, len(targetObj)+len(patchObj))
+	for key, value := range targetObj {
+		merged[key] = value
+	}
+	for key, value := range patchObj {
+		if value == nil {
+			delete(merged, key)
+			continue
+		}
+		merged[key] = mergePatchValue(merged[key], value)
+	}
+	return merged
+}
+
+// decodeJSONPatch разбирает JSON Patch и сворачивает операции в patchDocument.
+// Операции применяются по порядку: test видит результат предыдущих операций,
+// несовпадение значения — конфликт с состоянием ресурса (409), а не ошибка запроса.
+func decodeJSONPatch(body []byte, fields map[string]string, current func() (any, error)) (patchDocument, error) {
+	var ops []jsonPatchOp
+	if err := json.Unmarshal(body, &ops); err != nil {
+		return nil, fmt.Errorf("invalid request body: json patch must be an array of operations: %w", err)
+	}
+
+	doc := patchDocument{}
+	var currentDoc map[string]any
+
+	for i, op := range ops {
+		field, err := jsonPointerField(op.Path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid patch operation %d: %w", i, err)
+		}
+		if _, ok := fields[field]; !ok {
+			return nil, fmt.Errorf("invalid patch operation %d: unknown field %q", i, field)
+		}
+
+		switch op.Op {
+		case "add", "replace":
+			if op.Value == nil {
+				return nil, fmt.Errorf("invalid patch operation %d: value is required for %s", i, op.Op)
+			}
+			doc[field] = op.Value
+		case "remove":
+			doc[field] = json.RawMessage("null")
+		case "test":
+			// Поля верхнего уровня независимы: уже измененное поле берется
+			// из doc, остальные — из текущего представления ресурса
+			var actual any
+			if value, ok := doc[field]; ok {
+				if err := json.Unmarshal(value, &actual); err != nil {
+					return nil, fmt.Errorf("invalid patch operation %d: %w", i, err)
+				}
+			} else {
+				if currentDoc == nil {
+					if currentDoc, err = currentPatchTarget(current); err != nil {
+						return nil, err
+					}
+				}
+				actual = currentDoc[field]
+			}
+			matched, err := testPatchValue(actual, op.Value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid patch operation %d: %w", i, err)
+			}
+			if !matched {
+				return nil, status.Errorf(codes.FailedPrecondition, "patch operation %d: test failed for %q: value does not match", i, op.Path)
+			}
+		default:
+			return nil, fmt.Errorf("invalid patch operation %d: unsupported op %q", i, op.Op)
+		}
+	}
+
+	return doc, nil
+}
+
+// jsonPointerField извлекает имя поля верхнего уровня из JSON Pointer ("/name")
+func jsonPointerField(pointer string) (string, error) {
+	if !strings.HasPrefix(pointer, "/") || strings.Count(pointer, "/") != 1 {
+		return "", fmt.Errorf("path %q must point to a top-level field", pointer)
+	}
+	field := strings.TrimPrefix(pointer, "/")
+	field = strings.ReplaceAll(field, "~1", "/")
+	field = strings.ReplaceAll(field, "~0", "~")
+	return field, nil
+}
+
+// currentPatchTarget загружает текущее представление ресурса для операций test
+func currentPatchTarget(current func() (any, error)) (map[string]any, error) {
+	resource, err := current()
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := json.Marshal(resource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode current resource: %w", err)
+	}
+
+	doc := map[string]any{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("failed to decode current resource: %w", err)
+	}
+	return doc, nil
+}
+
+// testPatchValue сравнивает значение поля с ожидаемым из операции test
+func testPatchValue(actual any, expected json.RawMessage) (bool, error) {
+	if expected == nil {
+		return false, fmt.Errorf("value is required for test")
+	}
+	var want any
+	if err := json.Unmarshal(expected, &want); err != nil {
+		return false, fmt.Errorf("invalid value: %w", err)
+	}
+	return reflect.DeepEqual(actual, want), nil
+}
+
+// has проверяет, присутствует ли поле в документе
+func (d patchDocument) has(field string) bool {
+	_, ok := d[field]
+	return ok
+}
+
+// decodeInto декодирует документ в структуру запроса с указателями.
+// Поля со значением null остаются nil.
+func (d patchDocument) decodeInto(v any) error {
+	raw, err := json.Marshal(d)
+	if err != nil {
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	return nil
+}
+
+// mask возвращает отсортированные пути FieldMask для полей документа
+func (d patchDocument) mask(fields map[string]string) []string {
+	paths := make([]string, 0, len(d))
+	for field := range d {
+		paths = append(paths, fields[field])
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// writeUnsupportedPatchType отвечает 415 с перечнем поддерживаемых форматов
+func writeUnsupportedPatchType(w http.ResponseWriter) error {
+	w.Header().Set("Accept-Patch", acceptPatch)
+	return WriteJSON(w, http.StatusUnsupportedMediaType, APIError{
+		Error: fmt.Sprintf("unsupported Content-Type: use %s, %s or %s", contentTypeJSON, contentTypeMergePatch, contentTypeJSONPatch),
+	})
+}
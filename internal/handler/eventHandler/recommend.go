@@ -0,0 +1,640 @@
+package eventHandler
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	pbEvent "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/event"
+	pbFavorite "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/favorite"
+	contextkeys "github.com/rx3lixir/gateway-service/pkg/context"
+	"github.com/rx3lixir/gateway-service/pkg/i18n"
+	"github.com/rx3lixir/gateway-service/pkg/token"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultRecommendations = 20
+	maxRecommendations     = 50
+
+	// recommendationCandidates событий из одного источника
+	recommendationCandidates = 50
+	// recommendationProfileSize избранных и просмотренных событий, по которым строится профиль
+	recommendationProfileSize = 50
+	// recommendationTopSignals категорий, мест, поисков и запросов каждого вида
+	recommendationTopSignals = 3
+	// recommendationConcurrency параллельных запросов к сервисам
+	recommendationConcurrency = 8
+	// maxRecommendationSources запросов кандидатов на одну выдачу; источники
+	// с меньшим весом отбрасываются
+	maxRecommendationSources = 8
+	// eventsBatchSize событий в одном запросе ListEvents по ID
+	eventsBatchSize = 100
+
+	// recommendationCacheTTL сколько хранится выдача пользователя: повторные запросы
+	// (обновление страницы, листание) не нагружают event-service
+	recommendationCacheTTL = time.Minute
+	// recommendationCacheUsers пользователей в кэше рекомендаций
+	recommendationCacheUsers = 10000
+
+	// popularityWindow окно, за которое считаются переходы для популярности
+	popularityWindow = 7 * 24 * time.Hour
+)
+
+// Причины рекомендации
+const (
+	reasonFavoriteCategory = "favorite_category"
+	reasonFavoriteLocation = "favorite_location"
+	reasonClickedCategory  = "clicked_category"
+	reasonSavedSearch      = "saved_search"
+	reasonRecentSearch     = "recent_search"
+	reasonPopular          = "popular"
+	reasonUpcoming         = "upcoming"
+)
+
+// recommendationWeights вклад источника в оценку события
+var recommendationWeights = map[string]float64{
+	reasonSavedSearch:      3,
+	reasonFavoriteCategory: 2.5,
+	reasonClickedCategory:  2,
+	reasonRecentSearch:     1.5,
+	reasonFavoriteLocation: 1.5,
+	reasonPopular:          1,
+	reasonUpcoming:         0.5,
+}
+
+// recommendationExplanations объяснения причин; переводы в каталоге i18n
+var recommendationExplanations = map[string]string{
+	reasonFavoriteCategory: "Similar to your favorites in %s",
+	reasonFavoriteLocation: "Takes place at %s, like events in your favorites",
+	reasonClickedCategory:  "You recently viewed events in %s",
+	reasonSavedSearch:      "Matches your saved search %q",
+	reasonRecentSearch:     "Matches your recent search %q",
+	reasonPopular:          "Popular with other users",
+	reasonUpcoming:         "Coming up soon",
+}
+
+// signal категория, место или запрос из профиля пользователя.
+// Share — частота относительно самого частого сигнала того же вида, (0, 1].
+type signal[K cmp.Ordered] struct {
+	value K
+	share float64
+}
+
+// topSignals возвращает n самых частых значений
+func topSignals[K cmp.Ordered](counts map[K]int, n int) []signal[K] {
+	keys := make([]K, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b K) int {
+		if c := cmp.Compare(counts[b], counts[a]); c != 0 {
+			return c
+		}
+		return cmp.Compare(a, b)
+	})
+
+	signals := make([]signal[K], 0, min(n, len(keys)))
+	for _, key := range keys[:min(n, len(keys))] {
+		signals = append(signals, signal[K]{value: key, share: float64(counts[key]) / float64(counts[keys[0]])})
+	}
+	return signals
+}
+
+// recommendationProfile интересы пользователя
+type recommendationProfile struct {
+	favorites          map[int64]bool // Уже в избранном: не рекомендуются
+	favoriteCategories []signal[int64]
+	favoriteLocations  []signal[string]
+	clickedCategories  []signal[int64]
+	savedSearches      []savedSearchSignal
+	recentQueries      []string
+}
+
+type savedSearchSignal struct {
+	name    string
+	filters json.RawMessage
+}
+
+// recommendationSource запрос кандидатов: ListEvents по фильтрам или GetSuggestions по запросу
+type recommendationSource struct {
+	reason string
+	detail string
+	weight float64
+	list   *pbEvent.ListEventsReq
+	query  string
+}
+
+// recommendationCandidate кандидат в рекомендации с накопленной оценкой
+type recommendationCandidate struct {
+	event   *pbEvent.EventRes
+	score   float64
+	reasons []RecommendationReason
+}
+
+// recommendationBlend смешивает оценки кандидатов из нескольких источников
+type recommendationBlend struct {
+	mu         sync.Mutex
+	candidates map[int64]*recommendationCandidate
+}
+
+func newRecommendationBlend() *recommendationBlend {
+	return &recommendationBlend{candidates: make(map[int64]*recommendationCandidate)}
+}
+
+// add добавляет вклад источника. Событие может прийти без данных (подсказки,
+// популярность), тогда оно загружается перед ранжированием.
+func (b *recommendationBlend) add(eventID int64, event *pbEvent.EventRes, reason, detail string, score float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	candidate, ok := b.candidates[eventID]
+	if !ok {
+		candidate = &recommendationCandidate{}
+		b.candidates[eventID] = candidate
+	}
+	if candidate.event == nil {
+		candidate.event = event
+	}
+	candidate.score += score
+	candidate.reasons = append(candidate.reasons, RecommendationReason{Type: reason, Detail: detail, Score: score})
+}
+
+// rankDecay снижает вклад событий, стоящих в выдаче источника ниже
+func rankDecay(position int) float64 {
+	return 1 / (1 + float64(position)/10)
+}
+
+// recordClick сохраняет переход в историю. Анонимные переходы не сохраняются
+// и в популярности не учитываются.
+func (h *eventHandler) recordClick(r *http.Request, eventID int64) {
+	if h.clickHistory == nil {
+		return
+	}
+	var userID int64
+	if claims, ok := r.Context().Value(contextkeys.AuthKey).(*token.UserClaims); ok && claims != nil {
+		userID = int64(claims.Id)
+	}
+	if err := h.clickHistory.Add(r.Context(), userID, eventID); err != nil {
+		h.logger.WarnContext(r.Context(), "Failed to record click", "event_id", eventID, "error", err)
+	}
+}
+
+// handleGetRecommendations возвращает предстоящие события, подобранные по избранному,
+// сохраненным и недавним поискам и истории переходов пользователя. Новым пользователям
+// достаются популярные события, а если переходов еще нет — ближайшие.
+func (h *eventHandler) handleGetRecommendations(w http.ResponseWriter, r *http.Request) error {
+	claims, ok := r.Context().Value(contextkeys.AuthKey).(*token.UserClaims)
+	if !ok || claims == nil {
+		h.logger.WarnContext(r.Context(), "No auth claims found in context")
+		return status.Error(codes.Unauthenticated, "authorization required")
+	}
+	userID := int64(claims.Id)
+
+	limit := defaultRecommendations
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 || parsed > maxRecommendations {
+			return fmt.Errorf("invalid limit %q: must be between 1 and %d", raw, maxRecommendations)
+		}
+		limit = parsed
+	}
+
+	grpcCtx, cancel := h.createContext(r)
+	defer cancel()
+
+	ranked, cached := h.recommendationCache.get(userID, time.Now())
+	if !cached {
+		ranked = h.buildRecommendations(grpcCtx, userID)
+		h.recommendationCache.set(userID, ranked, time.Now())
+	}
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+
+	response := &RecommendationsRes{Recommendations: make([]*Recommendation, 0, len(ranked))}
+	events := make([]*Event, 0, len(ranked))
+	for _, candidate := range ranked {
+		event := ProtoEventResToHTTPEvent(candidate.event)
+		events = append(events, event)
+		response.Recommendations = append(response.Recommendations, &Recommendation{
+			Event:       event,
+			Score:       roundScore(candidate.score),
+			Explanation: explainRecommendation(r.Context(), candidate.reasons[0]),
+			Reasons:     candidate.reasons,
+		})
+		for _, reason := range candidate.reasons {
+			if reason.Type != reasonPopular && reason.Type != reasonUpcoming {
+				response.Personalized = true
+			}
+		}
+	}
+
+	hideModerationDetails(r, events...)
+	if lang := LocalizeEvents(r, events...); lang != "" {
+		w.Header().Set("Content-Language", lang)
+	}
+
+	h.logger.InfoContext(grpcCtx, "Recommendations built",
+		"user_id", userID,
+		"cached", cached,
+		"returned", len(response.Recommendations),
+		"personalized", response.Personalized)
+
+	return WriteJSON(w, http.StatusOK, response)
+}
+
+// buildRecommendations ранжирует до maxRecommendations кандидатов для пользователя
+func (h *eventHandler) buildRecommendations(ctx context.Context, userID int64) []*recommendationCandidate {
+	today := time.Now().UTC().Format(time.DateOnly)
+	profile := h.loadRecommendationProfile(ctx, userID)
+
+	var names map[int64]string
+	if len(profile.favoriteCategories) > 0 || len(profile.clickedCategories) > 0 {
+		names = h.categoryNames(ctx)
+	}
+
+	blend := newRecommendationBlend()
+	h.collectRecommendations(ctx, blend, h.recommendationSources(ctx, profile, names, today))
+	h.addPopularRecommendations(ctx, blend)
+	h.loadRecommendedEvents(ctx, blend)
+
+	ranked := rankRecommendations(blend, profile, today)
+	if len(ranked) < maxRecommendations {
+		// Мало кандидатов: добавляем ближайшие события
+		h.collectRecommendations(ctx, blend, []recommendationSource{{
+			reason: reasonUpcoming,
+			weight: recommendationWeights[reasonUpcoming],
+			list:   upcomingEventsReq(&ListEventsReq{}, today),
+		}})
+		ranked = rankRecommendations(blend, profile, today)
+	}
+	if len(ranked) > maxRecommendations {
+		ranked = ranked[:maxRecommendations]
+	}
+	return ranked
+}
+
+// loadRecommendationProfile собирает интересы пользователя. Недоступный источник
+// не ошибка: рекомендации строятся по остальным.
+func (h *eventHandler) loadRecommendationProfile(ctx context.Context, userID int64) *recommendationProfile {
+	profile := &recommendationProfile{favorites: make(map[int64]bool)}
+
+	var favoriteIDs, clickedIDs []int64
+	if h.favorites != nil {
+		res, err := h.favorites.ListFavorites(ctx, &pbFavorite.ListFavoritesReq{UserId: userID, Limit: recommendationProfileSize})
+		if err != nil {
+			h.logger.WarnContext(ctx, "Failed to list favorites for recommendations", "user_id", userID, "error", err)
+		}
+		for _, favorite := range res.GetFavorites() {
+			favoriteIDs = append(favoriteIDs, favorite.GetEventId())
+			profile.favorites[favorite.GetEventId()] = true
+		}
+	}
+	if h.clickHistory != nil {
+		clicks, err := h.clickHistory.List(ctx, userID)
+		if err != nil {
+			h.logger.WarnContext(ctx, "Failed to list click history", "user_id", userID, "error", err)
+		}
+		for _, click := range clicks[:min(len(clicks), recommendationProfileSize)] {
+			clickedIDs = append(clickedIDs, click.EventID)
+		}
+	}
+	if h.savedSearches != nil {
+		searches, err := h.savedSearches.List(ctx, userID)
+		if err != nil {
+			h.logger.WarnContext(ctx, "Failed to list saved searches for recommendations", "user_id", userID, "error", err)
+		}
+		// Свежие поиски важнее: берем последние созданные
+		for i := len(searches) - 1; i >= 0 && len(profile.savedSearches) < recommendationTopSignals; i-- {
+			profile.savedSearches = append(profile.savedSearches, savedSearchSignal{name: searches[i].Name, filters: searches[i].Filters})
+		}
+	}
+	if h.recentSearches != nil {
+		searches, err := h.recentSearches.List(ctx, userID)
+		if err != nil {
+			h.logger.WarnContext(ctx, "Failed to list recent searches for recommendations", "user_id", userID, "error", err)
+		}
+		for _, search := range searches[:min(len(searches), recommendationTopSignals)] {
+			profile.recentQueries = append(profile.recentQueries, search.Query)
+		}
+	}
+
+	events := h.getEvents(ctx, append(slices.Clone(favoriteIDs), clickedIDs...))
+
+	favoriteCategories, favoriteLocations := make(map[int64]int), make(map[string]int)
+	for _, id := range favoriteIDs {
+		if event := events[id]; event != nil {
+			favoriteCategories[event.GetCategoryID()]++
+			if location := strings.TrimSpace(event.GetLocation()); location != "" {
+				favoriteLocations[location]++
+			}
+		}
+	}
+	clickedCategories := make(map[int64]int)
+	for _, id := range clickedIDs {
+		if event := events[id]; event != nil {
+			clickedCategories[event.GetCategoryID()]++
+		}
+	}
+
+	profile.favoriteCategories = topSignals(favoriteCategories, recommendationTopSignals)
+	profile.favoriteLocations = topSignals(favoriteLocations, recommendationTopSignals)
+	profile.clickedCategories = topSignals(clickedCategories, recommendationTopSignals)
+	return profile
+}
+
+// recommendationSources строит запросы кандидатов по профилю, не больше
+// maxRecommendationSources с наибольшим весом
+func (h *eventHandler) recommendationSources(ctx context.Context, profile *recommendationProfile, names map[int64]string, today string) []recommendationSource {
+	var sources []recommendationSource
+
+	categorySources := func(reason string, signals []signal[int64]) {
+		for _, s := range signals {
+			detail := names[s.value]
+			if detail == "" {
+				detail = strconv.FormatInt(s.value, 10)
+			}
+			sources = append(sources, recommendationSource{
+				reason: reason,
+				detail: detail,
+				weight: recommendationWeights[reason] * s.share,
+				list:   upcomingEventsReq(&ListEventsReq{CategoryIDs: []int64{s.value}}, today),
+			})
+		}
+	}
+	categorySources(reasonFavoriteCategory, profile.favoriteCategories)
+	categorySources(reasonClickedCategory, profile.clickedCategories)
+
+	for _, s := range profile.favoriteLocations {
+		sources = append(sources, recommendationSource{
+			reason: reasonFavoriteLocation,
+			detail: s.value,
+			weight: recommendationWeights[reasonFavoriteLocation] * s.share,
+			list:   upcomingEventsReq(&ListEventsReq{Location: &s.value}, today),
+		})
+	}
+
+	for _, search := range profile.savedSearches {
+		var req ListEventsReq
+		if err := json.Unmarshal(search.filters, &req); err != nil {
+			h.logger.WarnContext(ctx, "Skipping saved search with invalid filters", "name", search.name, "error", err)
+			continue
+		}
+		if req.DateTo != nil && *req.DateTo < today {
+			continue // Все подходящие события уже прошли
+		}
+		if valueOrZero(req.IncludeSubcategories) && len(req.CategoryIDs) > 0 {
+			if err := h.expandCategoryIDs(ctx, &req); err != nil {
+				h.logger.WarnContext(ctx, "Failed to expand saved search categories", "name", search.name, "error", err)
+				continue
+			}
+		}
+		sources = append(sources, recommendationSource{
+			reason: reasonSavedSearch,
+			detail: search.name,
+			weight: recommendationWeights[reasonSavedSearch],
+			list:   upcomingEventsReq(&req, today),
+		})
+	}
+
+	for _, query := range profile.recentQueries {
+		sources = append(sources, recommendationSource{
+			reason: reasonRecentSearch,
+			detail: query,
+			weight: recommendationWeights[reasonRecentSearch],
+			query:  query,
+		})
+	}
+
+	slices.SortStableFunc(sources, func(a, b recommendationSource) int { return cmp.Compare(b.weight, a.weight) })
+	if len(sources) > maxRecommendationSources {
+		sources = sources[:maxRecommendationSources]
+	}
+	return sources
+}
+
+// upcomingEventsReq дополняет фильтры: только опубликованные события не раньше today
+func upcomingEventsReq(req *ListEventsReq, today string) *pbEvent.ListEventsReq {
+	limit := int32(recommendationCandidates)
+	req.Limit, req.Offset, req.SortBy = &limit, nil, nil
+	req.IncludeCount, req.IncludeFacets = nil, nil
+	req.Statuses = publicStatuses
+	if req.DateFrom == nil || *req.DateFrom < today {
+		req.DateFrom = &today
+	}
+	return HTTPListReqToProtoListReq(req)
+}
+
+// collectRecommendations параллельно выполняет запросы кандидатов. Ошибка
+// источника пишется в лог, остальные источники продолжают работу.
+func (h *eventHandler) collectRecommendations(ctx context.Context, blend *recommendationBlend, sources []recommendationSource) {
+	sem := make(chan struct{}, recommendationConcurrency)
+	var wg sync.WaitGroup
+
+	for _, source := range sources {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if source.list != nil {
+				res, err := h.eventClient.ListEvents(ctx, source.list)
+				if err != nil {
+					h.logger.WarnContext(ctx, "Failed to list recommendation candidates", "reason", source.reason, "error", err)
+					return
+				}
+				for i, event := range res.GetEvents() {
+					blend.add(event.GetId(), event, source.reason, source.detail, source.weight*rankDecay(i))
+				}
+				return
+			}
+
+			res, err := h.eventClient.GetSuggestions(ctx, &pbEvent.SuggestionReq{
+				Query:      source.query,
+				MaxResults: recommendationCandidates,
+				Fields:     []string{"name", "description"},
+				Statuses:   publicStatuses,
+				Fuzzy:      true,
+			})
+			if err != nil {
+				h.logger.WarnContext(ctx, "Failed to get recommendation suggestions", "reason", source.reason, "error", err)
+				return
+			}
+			position := 0
+			for _, item := range res.GetSuggestions() {
+				if item.GetType() != "event" || item.GetEventId() <= 0 {
+					continue
+				}
+				blend.add(item.GetEventId(), nil, source.reason, source.detail, source.weight*rankDecay(position))
+				position++
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// addPopularRecommendations добавляет события с наибольшим числом переходов за неделю
+func (h *eventHandler) addPopularRecommendations(ctx context.Context, blend *recommendationBlend) {
+	if h.clickHistory == nil {
+		return
+	}
+	popular, err := h.clickHistory.Popular(ctx, time.Now().Add(-popularityWindow), recommendationCandidates)
+	if err != nil {
+		h.logger.WarnContext(ctx, "Failed to get popular events", "error", err)
+		return
+	}
+	for _, p := range popular {
+		score := recommendationWeights[reasonPopular] * float64(p.Count) / float64(popular[0].Count)
+		blend.add(p.EventID, nil, reasonPopular, "", score)
+	}
+}
+
+// loadRecommendedEvents загружает события, пришедшие из источников без данных события
+func (h *eventHandler) loadRecommendedEvents(ctx context.Context, blend *recommendationBlend) {
+	var missing []int64
+	for id, candidate := range blend.candidates {
+		if candidate.event == nil {
+			missing = append(missing, id)
+		}
+	}
+	for id, event := range h.getEvents(ctx, missing) {
+		blend.candidates[id].event = event
+	}
+}
+
+// getEvents загружает события по ID пачками через ListEvents. Удаленные
+// и недоступные события пропускаются.
+func (h *eventHandler) getEvents(ctx context.Context, ids []int64) map[int64]*pbEvent.EventRes {
+	events := make(map[int64]*pbEvent.EventRes, len(ids))
+	ids = slices.Compact(slices.Sorted(slices.Values(ids)))
+
+	var mu sync.Mutex
+	sem := make(chan struct{}, recommendationConcurrency)
+	var wg sync.WaitGroup
+
+	for batch := range slices.Chunk(ids, eventsBatchSize) {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			limit := int32(len(batch))
+			res, err := h.eventClient.ListEvents(ctx, &pbEvent.ListEventsReq{Ids: batch, Limit: &limit})
+			if err != nil {
+				h.logger.WarnContext(ctx, "Failed to get events for recommendations", "ids", len(batch), "error", err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for _, event := range res.GetEvents() {
+				// Сервис без фильтра по ID вернул бы произвольные события
+				if _, found := slices.BinarySearch(batch, event.GetId()); found {
+					events[event.GetId()] = event
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return events
+}
+
+// recommendationCache недолговечный кэш выдачи рекомендаций по пользователям.
+// Кандидаты после ранжирования не меняются, поэтому хранятся без копирования.
+type recommendationCache struct {
+	mu      sync.Mutex
+	entries map[int64]recommendationCacheEntry
+}
+
+type recommendationCacheEntry struct {
+	ranked  []*recommendationCandidate
+	expires time.Time
+}
+
+func newRecommendationCache() *recommendationCache {
+	return &recommendationCache{entries: make(map[int64]recommendationCacheEntry)}
+}
+
+func (c *recommendationCache) get(userID int64, now time.Time) ([]*recommendationCandidate, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[userID]
+	if !ok || !now.Before(entry.expires) {
+		return nil, false
+	}
+	return entry.ranked, true
+}
+
+// set сохраняет выдачу. Когда кэш заполнен, сначала удаляются истекшие
+// записи, а если их нет — весь кэш: записи все равно живут недолго.
+func (c *recommendationCache) set(userID int64, ranked []*recommendationCandidate, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= recommendationCacheUsers {
+		maps.DeleteFunc(c.entries, func(_ int64, entry recommendationCacheEntry) bool { return !now.Before(entry.expires) })
+		if len(c.entries) >= recommendationCacheUsers {
+			clear(c.entries)
+		}
+	}
+	c.entries[userID] = recommendationCacheEntry{ranked: ranked, expires: now.Add(recommendationCacheTTL)}
+}
+
+// rankRecommendations отбирает опубликованные предстоящие события не из избранного
+// и сортирует их по оценке, а при равенстве — по дате
+func rankRecommendations(blend *recommendationBlend, profile *recommendationProfile, today string) []*recommendationCandidate {
+	ranked := make([]*recommendationCandidate, 0, len(blend.candidates))
+	for id, candidate := range blend.candidates {
+		event := candidate.event
+		if event == nil || profile.favorites[id] || eventStatus(event) != eventStatusPublished {
+			continue
+		}
+		if event.GetDate() != "" && event.GetDate() < today {
+			continue
+		}
+		slices.SortStableFunc(candidate.reasons, func(a, b RecommendationReason) int { return cmp.Compare(b.Score, a.Score) })
+		ranked = append(ranked, candidate)
+	}
+
+	slices.SortFunc(ranked, func(a, b *recommendationCandidate) int {
+		if c := cmp.Compare(b.score, a.score); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(a.event.GetDate(), b.event.GetDate()); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.event.GetId(), b.event.GetId())
+	})
+
+	for _, candidate := range ranked {
+		for i := range candidate.reasons {
+			candidate.reasons[i].Score = roundScore(candidate.reasons[i].Score)
+		}
+	}
+	return ranked
+}
+
+// explainRecommendation объясняет рекомендацию главной причиной на языке контента запроса
+func explainRecommendation(ctx context.Context, reason RecommendationReason) string {
+	format := recommendationExplanations[reason.Type]
+	if strings.Contains(format, "%") {
+		return i18n.Text(ctx, fmt.Sprintf(format, reason.Detail))
+	}
+	return i18n.Text(ctx, format)
+}
+
+// roundScore округляет оценку до тысячных для ответа
+func roundScore(score float64) float64 {
+	return math.Round(score*1000) / 1000
+}
@@ -2,6 +2,7 @@ package eventHandler
 
 import (
 	"github.com/go-chi/chi/v5"
+	"github.com/rx3lixir/gateway-service/pkg/httpcache"
 	"github.com/rx3lixir/gateway-service/pkg/middleware"
 )
 
@@ -12,40 +13,126 @@ func RegisterRoutes(e *eventHandler) *chi.Mux {
 	middlewareConfig := &middleware.Config{
 		TokenMaker: e.tokenMaker,
 		Logger:     e.logger,
-		CORSConfig: middleware.DefaultCORSConfig(),
+		CORSConfig: e.cors,
+		APIKeys:    e.apiKeys,
 	}
 
-	// Общие middleware
-	for _, mw := range middleware.CommonMiddlewares(middlewareConfig) {
-		r.Use(mw)
-	}
+	// Уведомления об изменениях событий: долгоживущие соединения, поэтому
+	// без таймаута и сжатия из общих middleware
+	r.Group(func(r chi.Router) {
+		for _, mw := range middleware.StreamMiddlewares(middlewareConfig) {
+			r.Use(mw)
+		}
+
+		r.Get("/api/v1/events/stream", e.makeHTTPHandlerFunc(e.handleEventStream))
+		r.Get("/api/v1/events/ws", e.makeHTTPHandlerFunc(e.handleEventWebSocket))
+	})
+
+	// Долгие админские операции: обходят события постранично дольше общего таймаута
+	r.Group(func(r chi.Router) {
+		for _, mw := range middleware.StreamMiddlewares(middlewareConfig) {
+			r.Use(mw)
+		}
+		r.Use(middleware.RequireAuth(middlewareConfig, true))
+
+		// Выгрузка событий
+		r.Get("/api/v1/events/export", e.makeHTTPHandlerFunc(e.handleExportEvents))
+		// Удаление категории с переносом ее событий
+		r.Delete("/api/v1/categories/{id}", e.makeHTTPHandlerFunc(e.handleDeleteCategory))
+	})
+
+	r.Group(func(r chi.Router) {
+		// Общие middleware
+		for _, mw := range middleware.CommonMiddlewares(middlewareConfig) {
+			r.Use(mw)
+		}
+
+		r.Route("/api/v1", func(r chi.Router) {
+			// События: без аутентификации, с HTTP кэшированием.
+			// Токен необязателен: с ним в списке появляется is_favorite
+			r.With(middleware.OptionalAuth(middlewareConfig), httpcache.Middleware(e.cachePolicies.Events)).Get("/events", e.makeHTTPHandlerFunc(e.handleGetEvents))
+			// Неопубликованное событие видят только модераторы и отправитель
+			r.With(middleware.OptionalAuth(middlewareConfig), httpcache.Middleware(e.cachePolicies.Event)).Get("/events/{id}", e.makeHTTPHandlerFunc(e.handleGetEventByID))
+
+			// Составные ответы для страниц фронтенда: несколько вызовов gRPC за один запрос
+			r.With(middleware.OptionalAuth(middlewareConfig)).Get("/pages/event/{id}", e.makeHTTPHandlerFunc(e.handleGetEventPage))
+
+			// Календари iCalendar: без аутентификации, с HTTP кэшированием
+			r.With(middleware.OptionalAuth(middlewareConfig), httpcache.Middleware(e.cachePolicies.Event)).Get("/events/{id}.ics", e.makeHTTPHandlerFunc(e.handleGetEventICS))
+			r.With(httpcache.Middleware(e.cachePolicies.Calendar)).Get("/calendar.ics", e.makeHTTPHandlerFunc(e.handleCalendarFeed))
+
+			// Поиск : без аутентификации
+			r.With(middleware.OptionalAuth(middlewareConfig)).Post("/events/search", e.makeHTTPHandlerFunc(e.handleGetEventsAdvanced))
+			// Подсказки: с токеном добавляются недавние запросы пользователя
+			r.With(middleware.OptionalAuth(middlewareConfig)).Get("/events/suggestions", e.makeHTTPHandlerFunc(e.handleGetSuggestions))
+			// Переходы из выдачи для поисковой аналитики (navigator.sendBeacon).
+			// С токеном переход попадает в историю пользователя для рекомендаций
+			r.With(middleware.OptionalAuth(middlewareConfig), middleware.RateLimitPerClient(clickRateLimit, clickRateWindow)).Post("/events/search/click", e.makeHTTPHandlerFunc(e.handleSearchClick))
+
+			// Вместимость и регистрации: с токеном — и регистрация пользователя
+			r.With(middleware.OptionalAuth(middlewareConfig)).Get("/events/{id}/rsvp", e.makeHTTPHandlerFunc(e.handleGetEventRsvp))
+
+			// Категории: без аутентификации
+			r.With(httpcache.Middleware(e.cachePolicies.Categories)).Get("/categories", e.makeHTTPHandlerFunc(e.handleListCategories))
+			r.With(httpcache.Middleware(e.cachePolicies.Category)).Get("/categories/{id}", e.makeHTTPHandlerFunc(e.handleGetCategoryByID))
+
+			// Отправка событий: пользователи и API ключи, события уходят на модерацию
+			r.With(middleware.RequireAuthOrAPIKey(middlewareConfig)).Post("/events", e.makeHTTPHandlerFunc(e.handleCreateEvent))
+
+			// Избранное: нужна аутентификация
+			r.Group(func(r chi.Router) {
+				r.Use(middleware.RequireAuth(middlewareConfig, false))
+
+				r.Post("/events/{id}/favorite", e.makeHTTPHandlerFunc(e.handleAddFavorite))
+				r.Delete("/events/{id}/favorite", e.makeHTTPHandlerFunc(e.handleRemoveFavorite))
+
+				// Регистрация на события
+				r.Post("/events/{id}/rsvp", e.makeHTTPHandlerFunc(e.handleCreateRsvp))
+				r.Delete("/events/{id}/rsvp", e.makeHTTPHandlerFunc(e.handleDeleteRsvp))
+
+				// Недавние поисковые запросы
+				r.Get("/events/suggestions/recent", e.makeHTTPHandlerFunc(e.handleListRecentSearches))
+				r.Delete("/events/suggestions/recent", e.makeHTTPHandlerFunc(e.handleDeleteRecentSearches))
+
+				// Персональные рекомендации
+				r.Get("/events/recommended", e.makeHTTPHandlerFunc(e.handleGetRecommendations))
+			})
+
+			// Защищенные эндпоинты
+			r.Group(func(r chi.Router) {
+				r.Use(middleware.RequireAuth(middlewareConfig, true))
+
+				// Админские операции для событий
+				r.Delete("/events/{id}", e.makeHTTPHandlerFunc(e.handleDeleteEvent))
+				r.Patch("/events/{id}", e.makeHTTPHandlerFunc(e.handleUpdateEvent))
+				r.Post("/events/{id}/image", e.makeHTTPHandlerFunc(e.handleUploadEventImage))
 
-	r.Route("/api/v1", func(r chi.Router) {
-		// События: без аутентификации
-		r.Get("/events", e.makeHTTPHandlerFunc(e.handleGetEvents))
-		r.Get("/events/{id}", e.makeHTTPHandlerFunc(e.handleGetEventByID))
+				// Переводы событий и категорий
+				r.Put("/events/{id}/translations/{lang}", e.makeHTTPHandlerFunc(e.handlePutEventTranslation))
+				r.Delete("/events/{id}/translations/{lang}", e.makeHTTPHandlerFunc(e.handleDeleteEventTranslation))
+				r.Put("/categories/{id}/translations/{lang}", e.makeHTTPHandlerFunc(e.handlePutCategoryTranslation))
+				r.Delete("/categories/{id}/translations/{lang}", e.makeHTTPHandlerFunc(e.handleDeleteCategoryTranslation))
 
-		// Поиск : без аутентификации
-		r.Post("/events/search", e.makeHTTPHandlerFunc(e.handleGetEventsAdvanced))
-		r.Get("/events/suggestions", e.makeHTTPHandlerFunc(e.handleGetSuggestions))
+				// Модерация событий
+				r.Post("/events/{id}:approve", e.makeHTTPHandlerFunc(e.handleApproveEvent))
+				r.Post("/events/{id}:reject", e.makeHTTPHandlerFunc(e.handleRejectEvent))
+				r.Post("/events/{id}:archive", e.makeHTTPHandlerFunc(e.handleArchiveEvent))
+				r.Get("/moderation/events", e.makeHTTPHandlerFunc(e.handleModerationQueue))
 
-		// Категории: без аутентификации
-		r.Get("/categories", e.makeHTTPHandlerFunc(e.handleListCategories))
-		r.Get("/categories/{id}", e.makeHTTPHandlerFunc(e.handleGetCategoryByID))
+				// Участники и вместимость событий
+				r.Get("/events/{id}/attendees", e.makeHTTPHandlerFunc(e.handleListAttendees))
+				r.Put("/events/{id}/capacity", e.makeHTTPHandlerFunc(e.handleSetCapacity))
 
-		// Защищенные эндпоинты
-		r.Group(func(r chi.Router) {
-			r.Use(middleware.RequireAuth(middlewareConfig, true))
+				// Поисковая аналитика
+				r.Get("/analytics/search", e.makeHTTPHandlerFunc(e.handleSearchAnalytics))
 
-			// Админские операции для событий
-			r.Delete("/events/{id}", e.makeHTTPHandlerFunc(e.handleDeleteEvent))
-			r.Patch("/events/{id}", e.makeHTTPHandlerFunc(e.handleUpdateEvent))
-			r.Post("/events", e.makeHTTPHandlerFunc(e.handleCreateEvent))
+				// Пакетный импорт событий, выгрузка — без общего таймаута выше
+				r.Post("/events:batchCreate", e.makeHTTPHandlerFunc(e.handleBatchCreateEvents))
 
-			// Админские операции для категорий
-			r.Post("/categories", e.makeHTTPHandlerFunc(e.handleCreateCategory))
-			r.Patch("/categories/{id}", e.makeHTTPHandlerFunc(e.handleUpdateCategory))
-			r.Delete("/categories/{id}", e.makeHTTPHandlerFunc(e.handleDeleteCategory))
+				// Админские операции для категорий
+				r.Post("/categories", e.makeHTTPHandlerFunc(e.handleCreateCategory))
+				r.Patch("/categories/{id}", e.makeHTTPHandlerFunc(e.handleUpdateCategory))
+			})
 		})
 	})
 
@@ -0,0 +1,353 @@
+package eventHandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	pbEvent "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/event"
+	pbRsvp "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/rsvp"
+	contextkeys "github.com/rx3lixir/gateway-service/pkg/context"
+	"github.com/rx3lixir/gateway-service/pkg/recurrence"
+	"github.com/rx3lixir/gateway-service/pkg/token"
+	"github.com/rx3lixir/gateway-service/pkg/webhook"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// Статусы регистраций
+	rsvpConfirmed  = "confirmed"
+	rsvpWaitlisted = "waitlisted"
+
+	// defaultAttendeesLimit и maxAttendeesLimit ограничивают страницу списка участников
+	defaultAttendeesLimit = 50
+	maxAttendeesLimit     = 500
+)
+
+// rsvpClaims проверяет, что регистрации настроены, и возвращает данные пользователя
+func (h *eventHandler) rsvpClaims(r *http.Request) (*token.UserClaims, error) {
+	if h.rsvp == nil {
+		return nil, status.Error(codes.Unimplemented, "rsvp is not configured")
+	}
+
+	claims, ok := r.Context().Value(contextkeys.AuthKey).(*token.UserClaims)
+	if !ok || claims == nil {
+		h.logger.WarnContext(r.Context(), "No auth claims found in context")
+		return nil, status.Error(codes.Unauthenticated, "authorization required")
+	}
+	return claims, nil
+}
+
+// rsvpEvent загружает событие для регистрации. Невидимое пользователю событие — 404.
+func (h *eventHandler) rsvpEvent(ctx context.Context, r *http.Request, id int64) (*pbEvent.EventRes, error) {
+	// Сервис регистраций не знает о событиях, поэтому существование проверяем здесь
+	event, err := h.eventClient.GetEvent(ctx, IDToProtoGetEventByIDReq(id))
+	if err != nil {
+		h.logger.WarnContext(ctx, "Failed to get event for rsvp", "id", id, "error", err)
+		return nil, err
+	}
+	if !canView(r, event) {
+		return nil, status.Errorf(codes.NotFound, "event with id %d not found", id)
+	}
+	return event, nil
+}
+
+// hasTakenPlace проверяет, прошло ли событие. Регистрация на повторяющееся
+// событие общая для всей серии, поэтому оно прошло, только когда не осталось
+// повторений, начинающихся сегодня или позже.
+func hasTakenPlace(event *pbEvent.EventRes, now time.Time) bool {
+	if event.GetRecurrenceRule() != "" && event.GetStartsAt().IsValid() {
+		if rule, err := recurrence.Parse(event.GetRecurrenceRule()); err == nil {
+			var sched Event
+			protoScheduleToHTTP(event, &sched)
+			y, m, d := now.In(sched.StartsAt.Location()).Date()
+			_, upcoming := rule.Next(*sched.StartsAt, time.Date(y, m, d, 0, 0, 0, 0, sched.StartsAt.Location()))
+			return !upcoming
+		}
+	}
+	return event.GetDate() != "" && event.GetDate() < now.UTC().Format(time.DateOnly)
+}
+
+// handleGetEventRsvp возвращает вместимость события, число регистраций
+// и, если запрос с токеном, регистрацию текущего пользователя
+func (h *eventHandler) handleGetEventRsvp(w http.ResponseWriter, r *http.Request) error {
+	if h.rsvp == nil {
+		return status.Error(codes.Unimplemented, "rsvp is not configured")
+	}
+
+	id, err := parseIDFromURL(r, "id")
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Failed to parse ID from URL", "error", err)
+		return err
+	}
+
+	grpcCtx, cancel := h.createContext(r)
+	defer cancel()
+
+	if _, err := h.rsvpEvent(grpcCtx, r, id); err != nil {
+		return err
+	}
+
+	req := &pbRsvp.GetCapacityReq{EventId: id}
+	if claims, ok := r.Context().Value(contextkeys.AuthKey).(*token.UserClaims); ok && claims != nil {
+		userID := int64(claims.Id)
+		req.UserId = &userID
+	}
+
+	res, err := h.rsvp.GetCapacity(grpcCtx, req)
+	if err != nil {
+		h.logger.ErrorContext(grpcCtx, "Failed to get event capacity via gRPC", "id", id, "error", err)
+		return err
+	}
+
+	return WriteJSON(w, http.StatusOK, protoCapacityToHTTP(res))
+}
+
+// handleCreateRsvp регистрирует текущего пользователя на событие. Если мест нет,
+// пользователь попадает в лист ожидания. Повторная регистрация не ошибка.
+func (h *eventHandler) handleCreateRsvp(w http.ResponseWriter, r *http.Request) error {
+	claims, err := h.rsvpClaims(r)
+	if err != nil {
+		return err
+	}
+
+	id, err := parseIDFromURL(r, "id")
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Failed to parse ID from URL", "error", err)
+		return err
+	}
+
+	grpcCtx, cancel := h.createContext(r)
+	defer cancel()
+
+	event, err := h.rsvpEvent(grpcCtx, r, id)
+	if err != nil {
+		return err
+	}
+	if eventStatus(event) != eventStatusPublished {
+		return status.Errorf(codes.Aborted, "registration for event %d is not open", id)
+	}
+	if hasTakenPlace(event, time.Now()) {
+		return status.Errorf(codes.Aborted, "event %d has already taken place", id)
+	}
+
+	res, err := h.rsvp.Register(grpcCtx, &pbRsvp.RsvpReq{
+		UserId:  int64(claims.Id),
+		EventId: id,
+	})
+	if err != nil {
+		h.logger.ErrorContext(grpcCtx, "Failed to register for event via gRPC", "id", id, "user_id", claims.Id, "error", err)
+		return err
+	}
+
+	h.logger.InfoContext(grpcCtx, "User registered for event",
+		"id", id,
+		"user_id", claims.Id,
+		"status", res.GetStatus())
+
+	return WriteJSON(w, http.StatusOK, ProtoRsvpResToHTTP(res))
+}
+
+// handleDeleteRsvp отменяет регистрацию текущего пользователя. Освободившееся
+// место получает первый в листе ожидания. Событие может быть уже удалено,
+// поэтому его существование не проверяется.
+func (h *eventHandler) handleDeleteRsvp(w http.ResponseWriter, r *http.Request) error {
+	claims, err := h.rsvpClaims(r)
+	if err != nil {
+		return err
+	}
+
+	id, err := parseIDFromURL(r, "id")
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Failed to parse ID from URL", "error", err)
+		return err
+	}
+
+	grpcCtx, cancel := h.createContext(r)
+	defer cancel()
+
+	res, err := h.rsvp.Cancel(grpcCtx, &pbRsvp.RsvpReq{
+		UserId:  int64(claims.Id),
+		EventId: id,
+	})
+	if err != nil {
+		h.logger.ErrorContext(grpcCtx, "Failed to cancel rsvp via gRPC", "id", id, "user_id", claims.Id, "error", err)
+		return err
+	}
+
+	h.logger.InfoContext(grpcCtx, "Rsvp cancelled",
+		"id", id,
+		"user_id", claims.Id,
+		"cancelled", res.GetCancelled(),
+		"promoted", len(res.GetPromoted()))
+	h.publishPromoted(grpcCtx, res.GetPromoted())
+
+	return WriteJSON(w, http.StatusNoContent, nil)
+}
+
+// handleListAttendees возвращает регистрации на событие: подтвержденные в порядке
+// регистрации, затем лист ожидания. Фильтр ?status=confirmed|waitlisted.
+func (h *eventHandler) handleListAttendees(w http.ResponseWriter, r *http.Request) error {
+	if h.rsvp == nil {
+		return status.Error(codes.Unimplemented, "rsvp is not configured")
+	}
+
+	id, err := parseIDFromURL(r, "id")
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Failed to parse ID from URL", "error", err)
+		return err
+	}
+
+	q := r.URL.Query()
+	rsvpStatus := q.Get("status")
+	if rsvpStatus != "" && rsvpStatus != rsvpConfirmed && rsvpStatus != rsvpWaitlisted {
+		return fmt.Errorf("invalid status %q: expected one of %s", rsvpStatus, rsvpConfirmed+", "+rsvpWaitlisted)
+	}
+	limit := defaultAttendeesLimit
+	if raw := q.Get("limit"); raw != "" {
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit <= 0 || limit > maxAttendeesLimit {
+			return fmt.Errorf("invalid limit %q: must be between 1 and %d", raw, maxAttendeesLimit)
+		}
+	}
+	offset := 0
+	if raw := q.Get("offset"); raw != "" {
+		offset, err = strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			return fmt.Errorf("invalid offset %q: must be a non-negative integer", raw)
+		}
+	}
+
+	grpcCtx, cancel := h.createContext(r)
+	defer cancel()
+
+	res, err := h.rsvp.ListAttendees(grpcCtx, &pbRsvp.ListAttendeesReq{
+		EventId: id,
+		Status:  rsvpStatus,
+		Limit:   int32(limit),
+		Offset:  int32(offset),
+	})
+	if err != nil {
+		h.logger.ErrorContext(grpcCtx, "Failed to list attendees via gRPC", "id", id, "error", err)
+		return err
+	}
+
+	attendees := make([]*RsvpRes, 0, len(res.GetRsvps()))
+	for _, rsvp := range res.GetRsvps() {
+		attendees = append(attendees, ProtoRsvpResToHTTP(rsvp))
+	}
+
+	return WriteJSON(w, http.StatusOK, &AttendeesRes{
+		Attendees: attendees,
+		Pagination: &PaginationMeta{
+			TotalCount: int64(res.GetTotal()),
+			Limit:      int32(limit),
+			Offset:     int32(offset),
+			HasMore:    offset+len(attendees) < int(res.GetTotal()),
+		},
+	})
+}
+
+// handleSetCapacity задает вместимость события. Подтвержденные регистрации
+// не отменяются; при увеличении места получают первые в листе ожидания.
+func (h *eventHandler) handleSetCapacity(w http.ResponseWriter, r *http.Request) error {
+	if h.rsvp == nil {
+		return status.Error(codes.Unimplemented, "rsvp is not configured")
+	}
+
+	id, err := parseIDFromURL(r, "id")
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Failed to parse ID from URL", "error", err)
+		return err
+	}
+
+	var req CapacityReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WarnContext(r.Context(), "Failed to decode capacity request", "error", err)
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	defer r.Body.Close()
+
+	if req.Capacity == nil {
+		return errors.New("capacity is required")
+	}
+	if *req.Capacity < 0 {
+		return errors.New("invalid capacity: must be a non-negative integer")
+	}
+
+	grpcCtx, cancel := h.createContext(r)
+	defer cancel()
+
+	if _, err := h.rsvpEvent(grpcCtx, r, id); err != nil {
+		return err
+	}
+
+	res, err := h.rsvp.SetCapacity(grpcCtx, &pbRsvp.SetCapacityReq{
+		EventId:  id,
+		Capacity: int32(*req.Capacity),
+	})
+	if err != nil {
+		h.logger.ErrorContext(grpcCtx, "Failed to set event capacity via gRPC", "id", id, "error", err)
+		return err
+	}
+
+	h.logger.InfoContext(grpcCtx, "Event capacity updated",
+		"id", id,
+		"capacity", res.GetCapacity(),
+		"promoted", len(res.GetPromoted()))
+	h.publishPromoted(grpcCtx, res.GetPromoted())
+
+	return WriteJSON(w, http.StatusOK, protoCapacityToHTTP(res))
+}
+
+// publishPromoted отправляет вебхук rsvp.promoted о каждом переводе из листа ожидания,
+// чтобы сервис уведомлений сообщил пользователю о подтвержденном месте
+func (h *eventHandler) publishPromoted(ctx context.Context, promoted []*pbRsvp.RsvpRes) {
+	if h.webhooks == nil {
+		return
+	}
+	for _, rsvp := range promoted {
+		hook := webhook.Event{Type: webhook.RsvpPromoted, Data: ProtoRsvpResToHTTP(rsvp)}
+		if err := h.webhooks.Publish(ctx, hook); err != nil {
+			h.logger.WarnContext(ctx, "Failed to enqueue rsvp webhook",
+				"event_id", rsvp.GetEventId(),
+				"user_id", rsvp.GetUserId(),
+				"error", err)
+		}
+	}
+}
+
+// ProtoRsvpResToHTTP конвертирует pbRsvp.RsvpRes (gRPC) в RsvpRes (шлюз)
+func ProtoRsvpResToHTTP(rsvp *pbRsvp.RsvpRes) *RsvpRes {
+	if rsvp == nil {
+		return nil
+	}
+	return &RsvpRes{
+		EventID:          rsvp.GetEventId(),
+		UserID:           rsvp.GetUserId(),
+		Status:           rsvp.GetStatus(),
+		WaitlistPosition: int(rsvp.GetWaitlistPosition()),
+		RegisteredAt:     rsvp.GetCreatedAt().AsTime(),
+	}
+}
+
+// protoCapacityToHTTP конвертирует вместимость события в HTTP ответ
+func protoCapacityToHTTP(res *pbRsvp.CapacityRes) *EventRsvpRes {
+	httpRes := &EventRsvpRes{
+		EventID:    res.GetEventId(),
+		Capacity:   int(res.GetCapacity()),
+		Confirmed:  int(res.GetConfirmed()),
+		Waitlisted: int(res.GetWaitlisted()),
+		RSVP:       ProtoRsvpResToHTTP(res.Rsvp),
+	}
+	if httpRes.Capacity > 0 {
+		available := max(httpRes.Capacity-httpRes.Confirmed, 0)
+		httpRes.Available = &available
+	}
+	return httpRes
+}
@@ -0,0 +1,112 @@
+package eventHandler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rx3lixir/gateway-service/pkg/savedsearch"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// NormalizeSavedSearchFilters проверяет фильтры сохраненного поиска (ListEventsReq)
+// и приводит их к каноническому виду. Пагинация, сортировка, счетчики и фасеты
+// к оповещениям не относятся и отбрасываются.
+func NormalizeSavedSearchFilters(raw json.RawMessage) (json.RawMessage, error) {
+	if len(bytes.TrimSpace(raw)) == 0 || string(bytes.TrimSpace(raw)) == "null" {
+		return nil, errors.New("filters are required")
+	}
+
+	var req ListEventsReq
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		return nil, fmt.Errorf("invalid filters: %w", err)
+	}
+	if len(req.Statuses) > 0 {
+		return nil, errors.New("invalid filters: statuses are not supported in saved searches")
+	}
+
+	req.Limit, req.Offset, req.SortBy = nil, nil, nil
+	req.IncludeCount, req.IncludeFacets = nil, nil
+
+	if err := ValidateGeoFilters(&req); err != nil {
+		return nil, err
+	}
+	if err := ValidateDateFilters(&req); err != nil {
+		return nil, err
+	}
+
+	normalized, err := json.Marshal(&req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode filters: %w", err)
+	}
+	if string(normalized) == "{}" {
+		return nil, errors.New("invalid filters: at least one filter is required")
+	}
+	return normalized, nil
+}
+
+// savedSearchMatcher выполняет сохраненные поиски через ListEvents
+type savedSearchMatcher struct {
+	h *eventHandler
+}
+
+// SavedSearchMatcher возвращает savedsearch.Searcher, выполняющий сохраненные
+// поиски среди опубликованных событий по фильтрам GET /events
+func (h *eventHandler) SavedSearchMatcher() savedsearch.Searcher {
+	return &savedSearchMatcher{h: h}
+}
+
+// NewMatches возвращает события, опубликованные в (after, before] и подходящие под фильтры.
+// Считается время публикации, а не создания: событие, прошедшее модерацию позже
+// водяного знака, тоже попадет в дайджест.
+func (m *savedSearchMatcher) NewMatches(ctx context.Context, filters json.RawMessage, after, before time.Time, limit int) ([]savedsearch.Match, int, error) {
+	var req ListEventsReq
+	if err := json.Unmarshal(filters, &req); err != nil {
+		return nil, 0, fmt.Errorf("invalid saved search filters: %w", err)
+	}
+	if valueOrZero(req.IncludeSubcategories) && len(req.CategoryIDs) > 0 {
+		if err := m.h.expandCategoryIDs(ctx, &req); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	pageSize := int32(limit)
+	includeCount := true
+	req.Limit = &pageSize
+	req.Offset = nil
+	req.IncludeCount = &includeCount
+	req.Statuses = publicStatuses // Оповещаем только об опубликованных событиях
+
+	protoReq := HTTPListReqToProtoListReq(&req)
+	protoReq.PublishedAfter = timestamppb.New(after)
+	protoReq.PublishedBefore = timestamppb.New(before)
+
+	res, err := m.h.eventClient.ListEvents(ctx, protoReq)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	matches := make([]savedsearch.Match, 0, len(res.GetEvents()))
+	for _, event := range res.GetEvents() {
+		publishedAt := event.GetCreatedAt().AsTime()
+		if event.GetPublishedAt().IsValid() {
+			publishedAt = event.GetPublishedAt().AsTime()
+		}
+		matches = append(matches, savedsearch.Match{
+			EventID:     event.GetId(),
+			Name:        event.GetName(),
+			Date:        event.GetDate(),
+			Time:        event.GetTime(),
+			Location:    event.GetLocation(),
+			Price:       event.GetPrice(),
+			CreatedAt:   event.GetCreatedAt().AsTime(),
+			PublishedAt: publishedAt,
+		})
+	}
+	return matches, int(res.GetPagination().GetTotalCount()), nil
+}
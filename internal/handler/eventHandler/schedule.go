@@ -0,0 +1,342 @@
+package eventHandler
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	pbEvent "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/event"
+	"github.com/rx3lixir/gateway-service/pkg/recurrence"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// defaultTimeZone используется, если у события не указан time_zone
+	defaultTimeZone = "UTC"
+
+	legacyDateLayout = "2006-01-02"
+	legacyTimeLayout = "15:04"
+
+	// maxExpansionWindow ограничивает окно раскрытия повторений, если date_to не задан
+	maxExpansionWindow = 366 * 24 * time.Hour
+
+	// maxOccurrencesPerEvent ограничивает количество повторений одного события в ответе
+	maxOccurrencesPerEvent = 100
+)
+
+// eventSchedule нормализованное расписание события
+type eventSchedule struct {
+	StartsAt       *time.Time
+	EndsAt         *time.Time
+	TimeZone       string
+	RecurrenceRule string
+
+	// Legacy представление для старых клиентов
+	Date string
+	Time string
+}
+
+// scheduleInput поля расписания из запросов на создание и обновление
+type scheduleInput struct {
+	Date           string
+	Time           string
+	StartsAt       *string
+	EndsAt         *string
+	TimeZone       string
+	RecurrenceRule string
+}
+
+// createEventScheduleInput извлекает поля расписания из CreateEventReq
+func createEventScheduleInput(req *CreateEventReq) scheduleInput {
+	return scheduleInput{
+		Date:           req.Date,
+		Time:           req.Time,
+		StartsAt:       req.StartsAt,
+		EndsAt:         req.EndsAt,
+		TimeZone:       req.TimeZone,
+		RecurrenceRule: req.RecurrenceRule,
+	}
+}
+
+// patchTouchesSchedule возвращает true, если частичное обновление меняет расписание
+func patchTouchesSchedule(doc patchDocument) bool {
+	for field, path := range eventPatchFields {
+		for _, schedulePath := range scheduleMaskPaths {
+			if path == schedulePath && doc.has(field) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// patchScheduleInput собирает расписание для частичного обновления:
+// переданные поля берутся из запроса, остальные — из текущего события.
+// Если передан starts_at, legacy date/time текущего события игнорируются, и наоборот.
+func patchScheduleInput(doc patchDocument, req *UpdateEventReq, current *pbEvent.EventRes) scheduleInput {
+	in := scheduleInput{
+		TimeZone:       current.GetTimeZone(),
+		RecurrenceRule: current.GetRecurrenceRule(),
+	}
+
+	if doc.has("time_zone") {
+		in.TimeZone = valueOrZero(req.TimeZone)
+	}
+	if doc.has("recurrence_rule") {
+		in.RecurrenceRule = valueOrZero(req.RecurrenceRule)
+	}
+
+	if doc.has("ends_at") {
+		in.EndsAt = req.EndsAt
+	} else if current.GetEndsAt() != nil {
+		endsAt := current.GetEndsAt().AsTime().Format(time.RFC3339)
+		in.EndsAt = &endsAt
+	}
+
+	switch {
+	case doc.has("starts_at"):
+		in.StartsAt = req.StartsAt
+		in.Date, in.Time = valueOrZero(req.Date), valueOrZero(req.Time)
+	case doc.has("date") || doc.has("time"):
+		in.Date, in.Time = current.GetDate(), current.GetTime()
+		if doc.has("date") {
+			in.Date = valueOrZero(req.Date)
+		}
+		if doc.has("time") {
+			in.Time = valueOrZero(req.Time)
+		}
+	case current.GetStartsAt() != nil:
+		startsAt := current.GetStartsAt().AsTime().Format(time.RFC3339)
+		in.StartsAt = &startsAt
+	default:
+		in.Date, in.Time = current.GetDate(), current.GetTime()
+	}
+
+	return in
+}
+
+// normalizeSchedule проверяет и нормализует расписание события.
+// Поддерживает как starts_at/ends_at в RFC 3339, так и legacy пару date + time.
+func normalizeSchedule(in scheduleInput) (*eventSchedule, error) {
+	tzName := strings.TrimSpace(in.TimeZone)
+	if tzName == "" {
+		tzName = defaultTimeZone
+	}
+
+	loc, err := time.LoadLocation(tzName)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time_zone %q: expected IANA time zone name", in.TimeZone)
+	}
+
+	sched := &eventSchedule{TimeZone: tzName}
+
+	switch {
+	case in.StartsAt != nil:
+		startsAt, err := time.Parse(time.RFC3339, *in.StartsAt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid starts_at format: expected RFC 3339, got %q", *in.StartsAt)
+		}
+		startsAt = startsAt.In(loc)
+		sched.StartsAt = &startsAt
+
+	case in.Date != "":
+		startsAt, err := parseLegacyDateTime(in.Date, in.Time, loc)
+		if err != nil {
+			return nil, err
+		}
+		sched.StartsAt = &startsAt
+
+	case in.Time != "":
+		return nil, fmt.Errorf("date is required when time is set")
+	}
+
+	if in.EndsAt != nil {
+		if sched.StartsAt == nil {
+			return nil, fmt.Errorf("starts_at is required when ends_at is set")
+		}
+		endsAt, err := time.Parse(time.RFC3339, *in.EndsAt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ends_at format: expected RFC 3339, got %q", *in.EndsAt)
+		}
+		endsAt = endsAt.In(loc)
+		if !endsAt.After(*sched.StartsAt) {
+			return nil, fmt.Errorf("invalid ends_at: must be after starts_at")
+		}
+		sched.EndsAt = &endsAt
+	}
+
+	if in.RecurrenceRule != "" {
+		if sched.StartsAt == nil {
+			return nil, fmt.Errorf("starts_at is required when recurrence_rule is set")
+		}
+		rule, err := recurrence.Parse(in.RecurrenceRule)
+		if err != nil {
+			return nil, fmt.Errorf("invalid recurrence_rule: %w", err)
+		}
+		sched.RecurrenceRule = rule.String()
+	}
+
+	if sched.StartsAt != nil {
+		sched.Date = sched.StartsAt.Format(legacyDateLayout)
+		sched.Time = sched.StartsAt.Format(legacyTimeLayout)
+	}
+
+	return sched, nil
+}
+
+// parseLegacyDateTime собирает время начала из legacy полей date (YYYY-MM-DD) и time (HH:MM[:SS])
+func parseLegacyDateTime(date, clock string, loc *time.Location) (time.Time, error) {
+	d, err := time.ParseInLocation(legacyDateLayout, strings.TrimSpace(date), loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date format: expected YYYY-MM-DD, got %q", date)
+	}
+
+	clock = strings.TrimSpace(clock)
+	if clock == "" {
+		return d, nil
+	}
+
+	for _, layout := range []string{legacyTimeLayout, "15:04:05"} {
+		if t, err := time.Parse(layout, clock); err == nil {
+			return time.Date(d.Year(), d.Month(), d.Day(), t.Hour(), t.Minute(), t.Second(), 0, loc), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time format: expected HH:MM, got %q", clock)
+}
+
+// normalizeDateFilter проверяет фильтр даты и приводит его к YYYY-MM-DD.
+// Помимо даты принимается RFC 3339: берется календарная дата в указанном смещении.
+func normalizeDateFilter(name, value string) (string, error) {
+	if d, err := time.Parse(legacyDateLayout, value); err == nil {
+		return d.Format(legacyDateLayout), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.Format(legacyDateLayout), nil
+	}
+	return "", fmt.Errorf("invalid %s format: expected YYYY-MM-DD, got %q", name, value)
+}
+
+// ValidateDateFilters проверяет и нормализует date_from/date_to запроса списка событий
+func ValidateDateFilters(req *ListEventsReq) error {
+	if req.DateFrom != nil {
+		from, err := normalizeDateFilter("date_from", *req.DateFrom)
+		if err != nil {
+			return err
+		}
+		req.DateFrom = &from
+	}
+
+	if req.DateTo != nil {
+		to, err := normalizeDateFilter("date_to", *req.DateTo)
+		if err != nil {
+			return err
+		}
+		req.DateTo = &to
+	}
+
+	// Даты в одном формате сравниваются лексикографически
+	if req.DateFrom != nil && req.DateTo != nil && *req.DateFrom > *req.DateTo {
+		return fmt.Errorf("invalid date range: date_from must not be after date_to")
+	}
+
+	return nil
+}
+
+// scheduleToProto конвертирует нормализованное расписание в поля gRPC запроса
+func scheduleToProto(sched *eventSchedule) (startsAt, endsAt *timestamppb.Timestamp, tz, rrule *string) {
+	if sched == nil || sched.StartsAt == nil {
+		return nil, nil, nil, nil
+	}
+
+	startsAt = timestamppb.New(*sched.StartsAt)
+	if sched.EndsAt != nil {
+		endsAt = timestamppb.New(*sched.EndsAt)
+	}
+	tz = &sched.TimeZone
+	if sched.RecurrenceRule != "" {
+		rrule = &sched.RecurrenceRule
+	}
+	return startsAt, endsAt, tz, rrule
+}
+
+// protoScheduleToHTTP заполняет поля расписания события из pbEvent.EventRes
+func protoScheduleToHTTP(protoEvent *pbEvent.EventRes, event *Event) {
+	event.TimeZone = protoEvent.GetTimeZone()
+	event.RecurrenceRule = protoEvent.GetRecurrenceRule()
+
+	loc := time.UTC
+	if event.TimeZone != "" {
+		if l, err := time.LoadLocation(event.TimeZone); err == nil {
+			loc = l
+		}
+	}
+
+	if protoEvent.GetStartsAt() != nil && protoEvent.GetStartsAt().IsValid() {
+		t := protoEvent.GetStartsAt().AsTime().In(loc)
+		event.StartsAt = &t
+	}
+
+	if protoEvent.GetEndsAt() != nil && protoEvent.GetEndsAt().IsValid() {
+		t := protoEvent.GetEndsAt().AsTime().In(loc)
+		event.EndsAt = &t
+	}
+}
+
+// expandOccurrences раскрывает повторения повторяющихся событий внутри окна дат фильтра.
+// Окно считается в часовом поясе каждого события: date_from 00:00 — date_to 23:59:59.
+func expandOccurrences(events []*Event, filter *ListEventsReq) {
+	if filter == nil || (filter.DateFrom == nil && filter.DateTo == nil) {
+		return
+	}
+
+	for _, event := range events {
+		if event.RecurrenceRule == "" || event.StartsAt == nil {
+			continue
+		}
+
+		rule, err := recurrence.Parse(event.RecurrenceRule)
+		if err != nil {
+			// Правило уже проверялось при сохранении; некорректное просто не раскрываем
+			continue
+		}
+
+		loc := event.StartsAt.Location()
+		from, to := occurrenceWindow(filter, loc)
+
+		var duration time.Duration
+		if event.EndsAt != nil {
+			duration = event.EndsAt.Sub(*event.StartsAt)
+		}
+
+		starts := rule.Between(*event.StartsAt, from, to, maxOccurrencesPerEvent)
+		event.Occurrences = make([]Occurrence, 0, len(starts))
+		for _, start := range starts {
+			occurrence := Occurrence{StartsAt: start}
+			if duration > 0 {
+				end := start.Add(duration)
+				occurrence.EndsAt = &end
+			}
+			event.Occurrences = append(event.Occurrences, occurrence)
+		}
+	}
+}
+
+// occurrenceWindow вычисляет окно раскрытия повторений в часовом поясе loc
+func occurrenceWindow(filter *ListEventsReq, loc *time.Location) (time.Time, time.Time) {
+	from := time.Now().In(loc)
+	if filter.DateFrom != nil {
+		if d, err := time.ParseInLocation(legacyDateLayout, *filter.DateFrom, loc); err == nil {
+			from = d
+		}
+	}
+
+	to := from.Add(maxExpansionWindow)
+	if filter.DateTo != nil {
+		if d, err := time.ParseInLocation(legacyDateLayout, *filter.DateTo, loc); err == nil {
+			to = d.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+	}
+
+	return from, to
+}
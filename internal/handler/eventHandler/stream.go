@@ -0,0 +1,425 @@
+package eventHandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	pbEvent "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/event"
+	"github.com/rx3lixir/gateway-service/pkg/broker"
+	"github.com/rx3lixir/gateway-service/pkg/webhook"
+	"github.com/rx3lixir/gateway-service/pkg/websocket"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// Типы уведомлений об изменениях событий
+	changeCreated = "created"
+	changeUpdated = "updated"
+	changeDeleted = "deleted"
+
+	// changeRemoved событие изменилось и больше не подходит под фильтр подключения.
+	// Только для потоков: подписчик должен убрать его из своего списка.
+	changeRemoved = "removed"
+
+	// changeReset просит клиента заново загрузить список: часть уведомлений потеряна
+	changeReset = "reset"
+
+	// defaultStreamHeartbeat интервал heartbeat по умолчанию
+	defaultStreamHeartbeat = 15 * time.Second
+
+	// streamWriteTimeout сколько ждать клиента, который не читает поток
+	streamWriteTimeout = 10 * time.Second
+
+	// streamRetry пауза перед переподключением EventSource
+	streamRetry = 3 * time.Second
+
+	// defaultStreamRadiusKm радиус фильтра near, если radius_km не задан
+	defaultStreamRadiusKm = 25.0
+)
+
+// publishChange публикует уведомление об изменении события в потоки и вебхуки.
+// Для deleted передается последнее известное состояние, чтобы подписчики могли его отфильтровать.
+func (h *eventHandler) publishChange(ctx context.Context, changeType string, id int64, event *pbEvent.EventRes) {
+	h.publish(ctx, changeType, id, event, nil)
+}
+
+// publishUpdate публикует изменение события. previous — состояние до изменения
+// (nil, если неизвестно): по нему поток уведомлений определяет подписчиков, из
+// фильтра которых событие выпало. Если событие перестало быть опубликованным,
+// подписчики получают deleted с прежним состоянием.
+func (h *eventHandler) publishUpdate(ctx context.Context, id int64, previous, updated *pbEvent.EventRes) {
+	if previous != nil && eventStatus(previous) == eventStatusPublished && eventStatus(updated) != eventStatusPublished {
+		h.publish(ctx, changeDeleted, id, previous, nil)
+		return
+	}
+	h.publish(ctx, changeUpdated, id, updated, previous)
+}
+
+func (h *eventHandler) publish(ctx context.Context, changeType string, id int64, event, previous *pbEvent.EventRes) {
+	if h.broker == nil && h.webhooks == nil {
+		return
+	}
+	// Подписчики видят только опубликованные события, см. moderateEvent
+	if event != nil && eventStatus(event) != eventStatusPublished {
+		return
+	}
+
+	change := &EventChange{EventID: id}
+	if event != nil {
+		change.Event = ProtoEventResToHTTPEvent(event)
+	}
+	if previous != nil {
+		change.previous = ProtoEventResToHTTPEvent(previous)
+	}
+
+	if h.broker != nil {
+		if _, err := h.broker.Publish(ctx, broker.Message{Type: changeType, Data: change}); err != nil {
+			h.logger.WarnContext(ctx, "Failed to publish event change", "type", changeType, "id", id, "error", err)
+		}
+	}
+
+	if h.webhooks != nil {
+		hook := webhook.Event{Type: "event." + changeType, Data: change.Event}
+		if change.Event != nil {
+			hook.CategoryID = &change.Event.CategoryID
+		} else {
+			hook.Data = map[string]int64{"id": id}
+		}
+		if err := h.webhooks.Publish(ctx, hook); err != nil {
+			h.logger.WarnContext(ctx, "Failed to enqueue event webhook", "type", hook.Type, "id", id, "error", err)
+		}
+	}
+}
+
+// streamFilter фильтр уведомлений подключения: категории, место и гео-фильтры
+type streamFilter struct {
+	categoryIDs map[int64]struct{}
+	location    string // В нижнем регистре, поиск подстроки
+	near        *GeoPoint
+	radiusKm    float64
+	bbox        *BoundingBox
+}
+
+// parseStreamFilter разбирает фильтр потока. Параметры те же, что у GET /events:
+// category_ids/category_id, location, near + radius_km, bbox.
+func parseStreamFilter(params map[string][]string) (*streamFilter, error) {
+	req, err := ParseQueryParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &streamFilter{bbox: req.BBox}
+	if len(req.CategoryIDs) > 0 {
+		f.categoryIDs = make(map[int64]struct{}, len(req.CategoryIDs))
+		for _, id := range req.CategoryIDs {
+			f.categoryIDs[id] = struct{}{}
+		}
+	}
+	if req.Location != nil {
+		f.location = strings.ToLower(strings.TrimSpace(*req.Location))
+	}
+	if req.Near != nil {
+		f.near = req.Near
+		f.radiusKm = defaultStreamRadiusKm
+		if req.RadiusKm != nil {
+			f.radiusKm = *req.RadiusKm
+		}
+	}
+
+	return f, nil
+}
+
+// match проверяет, нужно ли уведомление подписчику: событие подходит под фильтр
+// сейчас или подходило до изменения (тогда уходит removed, см. change)
+func (f *streamFilter) match(msg broker.Message) bool {
+	change, ok := msg.Data.(*EventChange)
+	if !ok || change.Event == nil {
+		return true
+	}
+	return f.matchEvent(change.Event) || (change.previous != nil && f.matchEvent(change.previous))
+}
+
+// change собирает уведомление для подписчика с этим фильтром: updated события,
+// которое больше не подходит под фильтр, превращается в removed
+func (f *streamFilter) change(msg broker.Message) *EventChange {
+	change := messageToChange(msg)
+	if change.Type == changeUpdated && change.Event != nil && !f.matchEvent(change.Event) {
+		change.Type = changeRemoved
+	}
+	return change
+}
+
+// matchEvent проверяет, подходит ли событие под фильтр
+func (f *streamFilter) matchEvent(event *Event) bool {
+	if f.categoryIDs != nil {
+		if _, ok := f.categoryIDs[event.CategoryID]; !ok {
+			return false
+		}
+	}
+	if f.location != "" && !strings.Contains(strings.ToLower(event.Location), f.location) {
+		return false
+	}
+
+	if f.near == nil && f.bbox == nil {
+		return true
+	}
+	// Гео-фильтры отсекают события без координат
+	if event.Lat == nil || event.Lon == nil {
+		return false
+	}
+	if f.near != nil && distanceKm(*f.near, GeoPoint{Lat: *event.Lat, Lon: *event.Lon}) > f.radiusKm {
+		return false
+	}
+	if f.bbox != nil && !f.bbox.contains(*event.Lat, *event.Lon) {
+		return false
+	}
+	return true
+}
+
+// subscribeChanges разбирает фильтр и позицию возобновления и подписывается на уведомления.
+// Позиция берется из заголовка Last-Event-ID (EventSource передает его при переподключении)
+// или из параметра last_event_id.
+func (h *eventHandler) subscribeChanges(r *http.Request) (broker.Subscription, *streamFilter, error) {
+	if h.broker == nil {
+		return nil, nil, status.Error(codes.Unimplemented, "event streams are not configured")
+	}
+
+	filter, err := parseStreamFilter(r.URL.Query())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var afterID uint64
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("last_event_id")
+	}
+	if lastEventID != "" {
+		afterID, err = strconv.ParseUint(strings.TrimSpace(lastEventID), 10, 64)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid Last-Event-ID format: %q", lastEventID)
+		}
+	}
+
+	sub, err := h.broker.Subscribe(r.Context(), broker.SubscribeOptions{
+		AfterID: afterID,
+		Buffer:  h.streamSettings.Buffer,
+		Filter:  filter.match,
+	})
+	return sub, filter, err
+}
+
+// streamHeartbeat возвращает интервал heartbeat
+func (h *eventHandler) streamHeartbeat() time.Duration {
+	if h.streamSettings.Heartbeat > 0 {
+		return h.streamSettings.Heartbeat
+	}
+	return defaultStreamHeartbeat
+}
+
+// messageToChange собирает уведомление для отправки клиенту
+func messageToChange(msg broker.Message) *EventChange {
+	change := &EventChange{}
+	if data, ok := msg.Data.(*EventChange); ok {
+		*change = *data
+	}
+	change.ID = msg.ID
+	change.Type = msg.Type
+	change.OccurredAt = msg.Time
+	return change
+}
+
+// resetChange уведомление о потере части истории
+func resetChange() *EventChange {
+	return &EventChange{Type: changeReset, OccurredAt: time.Now()}
+}
+
+// handleEventStream отдает уведомления об изменениях событий как Server-Sent Events.
+//
+// Каждое уведомление — SSE событие с id (для Last-Event-ID), типом created/updated/deleted/removed
+// и JSON EventChange в data. Раз в heartbeat отправляется комментарий, чтобы прокси не
+// закрывали соединение. Если клиент не успевает читать, подключение закрывается, и
+// EventSource переподключается с Last-Event-ID, получая пропущенное из истории брокера.
+func (h *eventHandler) handleEventStream(w http.ResponseWriter, r *http.Request) error {
+	sub, filter, err := h.subscribeChanges(r)
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Failed to subscribe to event changes", "error", err)
+		return err
+	}
+	defer sub.Close()
+
+	h.logger.InfoContext(r.Context(), "Event stream opened", "transport", "sse", "missed", sub.Missed())
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // Отключает буферизацию в nginx
+	w.WriteHeader(http.StatusOK)
+
+	// send пишет кадр с ограничением по времени: медленный клиент не держит обработчик
+	send := func(write func(io.Writer) error) error {
+		if err := rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return err
+		}
+		if err := write(w); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+
+	err = send(func(w io.Writer) error {
+		if _, err := fmt.Fprintf(w, "retry: %d\n\n", streamRetry.Milliseconds()); err != nil {
+			return err
+		}
+		if sub.Missed() {
+			return writeSSEChange(w, resetChange())
+		}
+		return nil
+	})
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Failed to start event stream", "error", err)
+		return nil
+	}
+
+	heartbeat := time.NewTicker(h.streamHeartbeat())
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			h.logger.InfoContext(r.Context(), "Event stream closed by client", "transport", "sse")
+			return nil
+
+		case <-heartbeat.C:
+			err = send(func(w io.Writer) error {
+				_, err := io.WriteString(w, ": ping\n\n")
+				return err
+			})
+
+		case msg, ok := <-sub.Messages():
+			if !ok {
+				h.logger.InfoContext(r.Context(), "Event stream subscription ended", "transport", "sse", "reason", sub.Err())
+				return nil
+			}
+			err = send(func(w io.Writer) error {
+				return writeSSEChange(w, filter.change(msg))
+			})
+		}
+
+		if err != nil {
+			h.logger.InfoContext(r.Context(), "Event stream write failed", "transport", "sse", "error", err)
+			return nil
+		}
+	}
+}
+
+// writeSSEChange пишет уведомление в формате text/event-stream
+func writeSSEChange(w io.Writer, change *EventChange) error {
+	data, err := json.Marshal(change)
+	if err != nil {
+		return err
+	}
+	if change.ID > 0 {
+		if _, err := fmt.Fprintf(w, "id: %d\n", change.ID); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", change.Type, data)
+	return err
+}
+
+// handleEventWebSocket отдает уведомления об изменениях событий через WebSocket.
+//
+// Каждое уведомление — текстовое сообщение с JSON EventChange. Сервер отправляет ping
+// раз в heartbeat и закрывает соединение, если клиент не отвечает два интервала подряд.
+// Возобновление — параметром last_event_id. Сообщения клиента игнорируются.
+func (h *eventHandler) handleEventWebSocket(w http.ResponseWriter, r *http.Request) error {
+	sub, filter, err := h.subscribeChanges(r)
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Failed to subscribe to event changes", "error", err)
+		return err
+	}
+	defer sub.Close()
+
+	upgrader := websocket.Upgrader{CheckOrigin: h.cors.CheckOrigin, WriteTimeout: streamWriteTimeout}
+	conn, err := upgrader.Upgrade(w, r)
+	if err != nil {
+		// Ответ клиенту уже отправлен
+		h.logger.WarnContext(r.Context(), "WebSocket upgrade failed", "error", err)
+		return nil
+	}
+	defer conn.Close(websocket.CloseNormal, "")
+
+	h.logger.InfoContext(r.Context(), "Event stream opened", "transport", "websocket", "missed", sub.Missed())
+
+	interval := h.streamHeartbeat()
+	pongWait := 2*interval + streamWriteTimeout
+	conn.SetReadDeadline(time.Now().Add(pongWait))
+
+	// Чтение нужно для обработки ping/pong и закрытия со стороны клиента
+	readDone := make(chan error, 1)
+	go func() {
+		extend := func() { conn.SetReadDeadline(time.Now().Add(pongWait)) }
+		for {
+			if _, _, err := conn.ReadMessage(extend); err != nil {
+				readDone <- err
+				return
+			}
+		}
+	}()
+
+	sendChange := func(change *EventChange) error {
+		data, err := json.Marshal(change)
+		if err != nil {
+			return err
+		}
+		return conn.WriteMessage(websocket.OpText, data)
+	}
+
+	if sub.Missed() {
+		if err := sendChange(resetChange()); err != nil {
+			return nil
+		}
+	}
+
+	heartbeat := time.NewTicker(interval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case err := <-readDone:
+			h.logger.InfoContext(r.Context(), "Event stream closed by client", "transport", "websocket", "reason", err)
+			return nil
+
+		case <-heartbeat.C:
+			err = conn.WritePing(nil)
+
+		case msg, ok := <-sub.Messages():
+			if !ok {
+				reason := sub.Err()
+				h.logger.InfoContext(r.Context(), "Event stream subscription ended", "transport", "websocket", "reason", reason)
+				if errors.Is(reason, broker.ErrSlowConsumer) {
+					conn.Close(websocket.CloseGoingAway, "slow consumer")
+				} else {
+					conn.Close(websocket.CloseGoingAway, "server shutdown")
+				}
+				return nil
+			}
+			err = sendChange(filter.change(msg))
+		}
+
+		if err != nil {
+			h.logger.InfoContext(r.Context(), "Event stream write failed", "transport", "websocket", "error", err)
+			return nil
+		}
+	}
+}
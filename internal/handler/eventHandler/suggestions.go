@@ -0,0 +1,322 @@
+package eventHandler
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	pbEvent "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/event"
+	contextkeys "github.com/rx3lixir/gateway-service/pkg/context"
+	"github.com/rx3lixir/gateway-service/pkg/recent"
+	"github.com/rx3lixir/gateway-service/pkg/textmatch"
+	"github.com/rx3lixir/gateway-service/pkg/token"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// defaultSuggestionResults и maxSuggestionResults ограничивают max_results
+	defaultSuggestionResults = 10
+	maxSuggestionResults     = 50
+
+	// maxSuggestionQueryLength ограничивает длину q в символах
+	maxSuggestionQueryLength = 200
+
+	// maxRecentSuggestions сколько недавних запросов показывать, когда q задан
+	maxRecentSuggestions = 5
+)
+
+// suggestionFields поля, по которым event-service ищет подсказки
+var suggestionFields = []string{"name", "description", "location", "category"}
+
+// defaultSuggestionFields поля поиска подсказок по умолчанию
+var defaultSuggestionFields = []string{"name", "location"}
+
+// suggestionTypeOrder порядок групп подсказок при group_by=type
+var suggestionTypeOrder = []string{"event", "location", "category"}
+
+// suggestionParams разобранные параметры запроса подсказок
+type suggestionParams struct {
+	query       string
+	maxResults  int
+	fields      []string
+	fuzzy       bool
+	groupByType bool
+}
+
+// parseSuggestionParams разбирает и проверяет параметры GET /events/suggestions
+func parseSuggestionParams(r *http.Request) (*suggestionParams, error) {
+	q := r.URL.Query()
+	params := &suggestionParams{
+		query:      strings.TrimSpace(q.Get("q")),
+		maxResults: defaultSuggestionResults,
+		fields:     defaultSuggestionFields,
+	}
+
+	if utf8.RuneCountInString(params.query) > maxSuggestionQueryLength {
+		return nil, fmt.Errorf("invalid q: must be at most %d characters", maxSuggestionQueryLength)
+	}
+
+	if raw := q.Get("max_results"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 || parsed > maxSuggestionResults {
+			return nil, fmt.Errorf("invalid max_results %q: must be between 1 and %d", raw, maxSuggestionResults)
+		}
+		params.maxResults = parsed
+	}
+
+	if raw := q.Get("fields"); raw != "" {
+		params.fields = nil
+		for _, field := range strings.Split(raw, ",") {
+			field = strings.ToLower(strings.TrimSpace(field))
+			if !slices.Contains(suggestionFields, field) {
+				return nil, fmt.Errorf("invalid fields %q: expected any of %s", raw, strings.Join(suggestionFields, ", "))
+			}
+			if !slices.Contains(params.fields, field) {
+				params.fields = append(params.fields, field)
+			}
+		}
+	}
+
+	fuzzy, err := parseBoolParam(r, "fuzzy")
+	if err != nil {
+		return nil, err
+	}
+	params.fuzzy = fuzzy
+
+	switch groupBy := q.Get("group_by"); groupBy {
+	case "":
+	case "type":
+		params.groupByType = true
+	default:
+		return nil, fmt.Errorf("invalid group_by %q: expected type", groupBy)
+	}
+
+	return params, nil
+}
+
+// handleGetSuggestions обрабатывает запросы автокомплита.
+// Аутентифицированным пользователям дополнительно возвращаются недавние запросы.
+func (h *eventHandler) handleGetSuggestions(w http.ResponseWriter, r *http.Request) error {
+	started := time.Now()
+	params, err := parseSuggestionParams(r)
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Invalid suggestion parameters", "error", err)
+		return err
+	}
+
+	response := &SuggestionResponse{
+		Suggestions: []Suggestion{},
+		Query:       params.query,
+		Recent:      h.matchRecentSearches(r, params.query),
+	}
+	if params.query == "" {
+		return WriteJSON(w, http.StatusOK, response)
+	}
+
+	h.logger.InfoContext(r.Context(), "Handling suggestion request",
+		"query", params.query,
+		"max_results", params.maxResults,
+		"fields", params.fields,
+		"fuzzy", params.fuzzy)
+
+	grpcCtx, cancel := h.createContext(r)
+	defer cancel()
+
+	// Создаем gRPC запрос
+	req := &pbEvent.SuggestionReq{
+		Query:      params.query,
+		MaxResults: int32(params.maxResults),
+		Fields:     params.fields,
+		Statuses:   publicStatuses, // Подсказки публичные
+		Fuzzy:      params.fuzzy,
+	}
+
+	res, err := h.eventClient.GetSuggestions(grpcCtx, req)
+	if err != nil {
+		h.logger.ErrorContext(grpcCtx, "Failed to get suggestions via gRPC", "error", err)
+		return err
+	}
+
+	// Конвертируем в HTTP ответ; сервис может вернуть больше, чем просили
+	items := res.GetSuggestions()
+	if len(items) > params.maxResults {
+		items = items[:params.maxResults]
+	}
+	for _, item := range items {
+		response.Suggestions = append(response.Suggestions, protoSuggestionToHTTP(item, params))
+	}
+	response.Total = int(res.GetTotal())
+
+	if params.groupByType {
+		response.Groups = groupSuggestions(response.Suggestions)
+	}
+
+	h.logger.InfoContext(grpcCtx, "Suggestions retrieved successfully",
+		"query", params.query,
+		"suggestions_count", len(response.Suggestions))
+
+	h.trackSuggestion(params.query, len(response.Suggestions), started)
+
+	return WriteJSON(w, http.StatusOK, response)
+}
+
+// protoSuggestionToHTTP конвертирует подсказку. Если сервис не вернул
+// подсвеченные фрагменты, они вычисляются по тексту подсказки.
+func protoSuggestionToHTTP(item *pbEvent.SuggestionItem, params *suggestionParams) Suggestion {
+	suggestion := Suggestion{
+		Text:     item.GetText(),
+		Score:    item.GetScore(),
+		Type:     item.GetType(),
+		Category: item.GetCategory(),
+		EventID:  item.EventId,
+	}
+
+	for _, span := range item.GetHighlights() {
+		suggestion.Highlights = append(suggestion.Highlights, HighlightSpan{Start: int(span.GetStart()), End: int(span.GetEnd())})
+	}
+	if len(suggestion.Highlights) == 0 {
+		suggestion.Highlights = highlightSpans(suggestion.Text, params.query, params.fuzzy)
+	}
+	return suggestion
+}
+
+// highlightSpans находит фрагменты текста, совпавшие с запросом, см. textmatch.Highlight
+func highlightSpans(text, query string, fuzzy bool) []HighlightSpan {
+	spans := textmatch.Highlight(text, query, fuzzy)
+	if len(spans) == 0 {
+		return nil
+	}
+	result := make([]HighlightSpan, len(spans))
+	for i, span := range spans {
+		result[i] = HighlightSpan{Start: span.Start, End: span.End}
+	}
+	return result
+}
+
+// groupSuggestions группирует подсказки по типу: event, location, category,
+// затем остальные типы в порядке появления. Внутри группы порядок сохраняется.
+func groupSuggestions(suggestions []Suggestion) []SuggestionGroup {
+	types := slices.Clone(suggestionTypeOrder)
+	byType := make(map[string][]Suggestion)
+	for _, suggestion := range suggestions {
+		if !slices.Contains(types, suggestion.Type) {
+			types = append(types, suggestion.Type)
+		}
+		byType[suggestion.Type] = append(byType[suggestion.Type], suggestion)
+	}
+
+	groups := make([]SuggestionGroup, 0, len(byType))
+	for _, t := range types {
+		if len(byType[t]) > 0 {
+			groups = append(groups, SuggestionGroup{Type: t, Suggestions: byType[t]})
+		}
+	}
+	return groups
+}
+
+// searchUserID возвращает ID аутентифицированного пользователя, если недавние запросы включены
+func (h *eventHandler) searchUserID(r *http.Request) (int64, bool) {
+	if h.recentSearches == nil {
+		return 0, false
+	}
+	claims, ok := r.Context().Value(contextkeys.AuthKey).(*token.UserClaims)
+	if !ok || claims == nil {
+		return 0, false
+	}
+	return int64(claims.Id), true
+}
+
+// matchRecentSearches возвращает недавние запросы пользователя: все, если query пуст,
+// иначе начинающиеся с query (не больше maxRecentSuggestions). Ошибка хранилища
+// не прерывает запрос подсказок.
+func (h *eventHandler) matchRecentSearches(r *http.Request, query string) []RecentSearch {
+	userID, ok := h.searchUserID(r)
+	if !ok {
+		return nil
+	}
+
+	searches, err := h.recentSearches.List(r.Context(), userID)
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Failed to load recent searches", "user_id", userID, "error", err)
+		return nil
+	}
+
+	prefix := recent.Normalize(query)
+	result := make([]RecentSearch, 0, len(searches))
+	for _, search := range searches {
+		if prefix != "" {
+			if len(result) == maxRecentSuggestions {
+				break
+			}
+			if !strings.HasPrefix(recent.Normalize(search.Query), prefix) {
+				continue
+			}
+		}
+		result = append(result, RecentSearch{
+			Query:      search.Query,
+			SearchedAt: search.SearchedAt,
+			Highlights: highlightSpans(search.Query, query, false),
+		})
+	}
+	return result
+}
+
+// recordSearch сохраняет поисковый запрос аутентифицированного пользователя.
+// Сохраняется только первая страница результатов, чтобы пагинация не поднимала запрос.
+func (h *eventHandler) recordSearch(r *http.Request, req *ListEventsReq) {
+	text := strings.TrimSpace(valueOrZero(req.SearchText))
+	if text == "" || valueOrZero(req.Offset) > 0 {
+		return
+	}
+	userID, ok := h.searchUserID(r)
+	if !ok {
+		return
+	}
+	if err := h.recentSearches.Add(r.Context(), userID, text); err != nil {
+		h.logger.WarnContext(r.Context(), "Failed to save recent search", "user_id", userID, "error", err)
+	}
+}
+
+// recentSearchesUserID проверяет, что недавние запросы настроены, и возвращает ID пользователя
+func (h *eventHandler) recentSearchesUserID(r *http.Request) (int64, error) {
+	if h.recentSearches == nil {
+		return 0, status.Error(codes.Unimplemented, "recent searches are not configured")
+	}
+	userID, ok := h.searchUserID(r)
+	if !ok {
+		h.logger.WarnContext(r.Context(), "No auth claims found in context")
+		return 0, status.Error(codes.Unauthenticated, "authorization required")
+	}
+	return userID, nil
+}
+
+// handleListRecentSearches возвращает недавние поисковые запросы текущего пользователя
+func (h *eventHandler) handleListRecentSearches(w http.ResponseWriter, r *http.Request) error {
+	if _, err := h.recentSearchesUserID(r); err != nil {
+		return err
+	}
+	return WriteJSON(w, http.StatusOK, &RecentSearchesRes{Recent: h.matchRecentSearches(r, "")})
+}
+
+// handleDeleteRecentSearches удаляет недавний запрос ?q= или, без q, все запросы пользователя
+func (h *eventHandler) handleDeleteRecentSearches(w http.ResponseWriter, r *http.Request) error {
+	userID, err := h.recentSearchesUserID(r)
+	if err != nil {
+		return err
+	}
+
+	query := r.URL.Query().Get("q")
+	if err := h.recentSearches.Remove(r.Context(), userID, query); err != nil {
+		h.logger.ErrorContext(r.Context(), "Failed to delete recent searches", "user_id", userID, "error", err)
+		return err
+	}
+
+	h.logger.InfoContext(r.Context(), "Recent searches deleted", "user_id", userID, "all", query == "")
+
+	return WriteJSON(w, http.StatusOK, &RecentSearchesRes{Recent: h.matchRecentSearches(r, "")})
+}
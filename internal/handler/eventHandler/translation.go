@@ -0,0 +1,332 @@
+package eventHandler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	pbEvent "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/event"
+	"github.com/rx3lixir/gateway-service/pkg/cache"
+	"github.com/rx3lixir/gateway-service/pkg/i18n"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// defaultLanguages языки контента, если WithLanguages не задан
+var defaultLanguages = i18n.Languages{Default: "ru", Supported: []string{"ru", "en"}}
+
+// translationLanguages возвращает языки, на которые можно переводить: все, кроме языка по умолчанию
+func (h *eventHandler) translationLanguages() []string {
+	langs := make([]string, 0, len(h.languages.Supported))
+	for _, lang := range h.languages.Supported {
+		if lang != h.languages.Default {
+			langs = append(langs, lang)
+		}
+	}
+	return langs
+}
+
+// validateTranslationLang проверяет код языка перевода. Основные поля уже на
+// языке по умолчанию, поэтому перевод на него не принимается.
+func (h *eventHandler) validateTranslationLang(lang string) error {
+	langs := h.translationLanguages()
+	if !slices.Contains(langs, lang) {
+		return fmt.Errorf("invalid language %q: supported languages are %s", lang, strings.Join(langs, ", "))
+	}
+	return nil
+}
+
+// validateTranslation проверяет один перевод
+func validateTranslation(t Translation) error {
+	if strings.TrimSpace(t.Name) == "" {
+		return fmt.Errorf("translation name is required")
+	}
+	return nil
+}
+
+// validateTranslations проверяет переводы из запроса на создание или обновление
+func (h *eventHandler) validateTranslations(translations map[string]Translation) error {
+	for lang, t := range translations {
+		if err := h.validateTranslationLang(lang); err != nil {
+			return err
+		}
+		if err := validateTranslation(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// includeTranslations возвращает true, если клиент запросил переводы на все языки
+func includeTranslations(r *http.Request) bool {
+	include, _ := strconv.ParseBool(r.URL.Query().Get("include_translations"))
+	return include
+}
+
+// localize подставляет в name и description перевод на язык запроса (с откатом
+// на язык по умолчанию) и возвращает выбранный язык. Без i18n.Middleware
+// поля остаются как есть.
+func localize(ctx context.Context, name, description *string, translations map[string]Translation) string {
+	locale, ok := i18n.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	lang := locale.Resolve(func(lang string) bool {
+		_, found := translations[lang]
+		return found
+	})
+	if t, found := translations[lang]; found {
+		*name = t.Name
+		if t.Description != "" {
+			*description = t.Description
+		}
+	}
+	return lang
+}
+
+// LocalizeEvents переводит события на язык запроса, см. localize. Карта translations
+// остается в ответе только при include_translations=true. Возвращает язык запроса
+// для Content-Language; у отдельных событий язык может быть другим (поле lang).
+func LocalizeEvents(r *http.Request, events ...*Event) string {
+	include := includeTranslations(r)
+	for _, event := range events {
+		if event == nil {
+			continue
+		}
+		event.Lang = localize(r.Context(), &event.Name, &event.Description, event.Translations)
+		if !include {
+			event.Translations = nil
+		}
+	}
+	return localeLang(r)
+}
+
+// LocalizeEvent переводит событие на язык из ctx, см. localize. В отличие от
+// LocalizeEvents карта translations сохраняется.
+func LocalizeEvent(ctx context.Context, event *Event) {
+	event.Lang = localize(ctx, &event.Name, &event.Description, event.Translations)
+}
+
+// LocalizeCategory переводит категорию без подкатегорий на язык из ctx, см. LocalizeEvent
+func LocalizeCategory(ctx context.Context, category *Category) {
+	category.Lang = localize(ctx, &category.Name, &category.Description, category.Translations)
+}
+
+// localizeCategories переводит категории и их подкатегории на язык запроса, см. LocalizeEvents
+func localizeCategories(r *http.Request, categories ...*Category) string {
+	include := includeTranslations(r)
+	var walk func([]*Category)
+	walk = func(categories []*Category) {
+		for _, category := range categories {
+			if category == nil {
+				continue
+			}
+			category.Lang = localize(r.Context(), &category.Name, &category.Description, category.Translations)
+			if !include {
+				category.Translations = nil
+			}
+			walk(category.Children)
+		}
+	}
+	walk(categories)
+	return localeLang(r)
+}
+
+// localeLang возвращает язык запроса или пустую строку без i18n.Middleware
+func localeLang(r *http.Request) string {
+	locale, _ := i18n.FromContext(r.Context())
+	return locale.Lang
+}
+
+// setContentLanguage устанавливает заголовок Content-Language, если язык выбран
+func setContentLanguage(w http.ResponseWriter, lang string) {
+	if lang != "" {
+		w.Header().Set("Content-Language", lang)
+	}
+}
+
+// translationEdit изменяет карту переводов ресурса для языка lang
+type translationEdit func(translations map[string]Translation, lang string) error
+
+// putTranslationEdit читает перевод из тела запроса и возвращает правку, сохраняющую его
+func putTranslationEdit(r *http.Request) (translationEdit, error) {
+	var t Translation
+	if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
+		return nil, fmt.Errorf("invalid request body: %w", err)
+	}
+	defer r.Body.Close()
+
+	if err := validateTranslation(t); err != nil {
+		return nil, err
+	}
+	return func(translations map[string]Translation, lang string) error {
+		translations[lang] = t
+		return nil
+	}, nil
+}
+
+// deleteTranslationEdit удаляет перевод; отсутствующий перевод — 404
+func deleteTranslationEdit(translations map[string]Translation, lang string) error {
+	if _, ok := translations[lang]; !ok {
+		return status.Errorf(codes.NotFound, "translation for %q not found", lang)
+	}
+	delete(translations, lang)
+	return nil
+}
+
+// translatedResource загрузка и сохранение переводов ресурса для editTranslations
+type translatedResource struct {
+	kind     string // Тип ресурса для логов: event или category
+	conflict string // Ошибка параллельного изменения без If-Match, шаблон с ID ресурса
+	// load возвращает переводы и версию ресурса
+	load func(ctx context.Context, id int64) (map[string]Translation, *timestamppb.Timestamp, error)
+	// save сохраняет переводы с проверкой версии, возвращает новую версию и тело ответа
+	save func(ctx context.Context, id int64, translations map[string]Translation, expected *timestamppb.Timestamp) (*timestamppb.Timestamp, any, error)
+}
+
+// eventTranslations переводы события
+func (h *eventHandler) eventTranslations(r *http.Request) translatedResource {
+	return translatedResource{
+		kind:     "event",
+		conflict: "event %d was modified concurrently, retry",
+		load: func(ctx context.Context, id int64) (map[string]Translation, *timestamppb.Timestamp, error) {
+			current, err := h.eventClient.GetEvent(cache.Bypass(ctx), IDToProtoGetEventByIDReq(id))
+			if err != nil {
+				return nil, nil, err
+			}
+			return eventTranslationsFromProto(current.GetTranslations()), resourceVersion(current.GetUpdatedAt(), current.GetCreatedAt()), nil
+		},
+		save: func(ctx context.Context, id int64, translations map[string]Translation, expected *timestamppb.Timestamp) (*timestamppb.Timestamp, any, error) {
+			updated, err := h.eventClient.UpdateEvent(ctx, &pbEvent.UpdateEventReq{
+				Id:                id,
+				Translations:      translationsToEventProto(translations),
+				UpdateMask:        &fieldmaskpb.FieldMask{Paths: []string{"translations"}},
+				ExpectedUpdatedAt: expected,
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+			h.publishChange(r.Context(), changeUpdated, id, updated)
+			return resourceVersion(updated.GetUpdatedAt(), updated.GetCreatedAt()), ProtoEventResToHTTPEvent(updated), nil
+		},
+	}
+}
+
+// categoryTranslations переводы категории
+func (h *eventHandler) categoryTranslations() translatedResource {
+	return translatedResource{
+		kind:     "category",
+		conflict: "category %d was modified concurrently, retry",
+		load: func(ctx context.Context, id int64) (map[string]Translation, *timestamppb.Timestamp, error) {
+			current, err := h.eventClient.GetCategory(cache.Bypass(ctx), IDToProtoGetCategoryByIDReq(int32(id)))
+			if err != nil {
+				return nil, nil, err
+			}
+			return categoryTranslationsFromProto(current.GetTranslations()), resourceVersion(current.GetUpdatedAt(), current.GetCreatedAt()), nil
+		},
+		save: func(ctx context.Context, id int64, translations map[string]Translation, expected *timestamppb.Timestamp) (*timestamppb.Timestamp, any, error) {
+			updated, err := h.eventClient.UpdateCategory(ctx, &pbEvent.UpdateCategoryReq{
+				Id:                int32(id),
+				Translations:      translationsToCategoryProto(translations),
+				UpdateMask:        &fieldmaskpb.FieldMask{Paths: []string{"translations"}},
+				ExpectedUpdatedAt: expected,
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+			return resourceVersion(updated.GetUpdatedAt(), updated.GetCreatedAt()), ProtoCategoryResToHTTPCategory(updated), nil
+		},
+	}
+}
+
+// handlePutEventTranslation создает или заменяет перевод события на язык {lang}
+func (h *eventHandler) handlePutEventTranslation(w http.ResponseWriter, r *http.Request) error {
+	edit, err := putTranslationEdit(r)
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Invalid event translation", "error", err)
+		return err
+	}
+	return h.editTranslations(w, r, h.eventTranslations(r), edit)
+}
+
+// handleDeleteEventTranslation удаляет перевод события на язык {lang}
+func (h *eventHandler) handleDeleteEventTranslation(w http.ResponseWriter, r *http.Request) error {
+	return h.editTranslations(w, r, h.eventTranslations(r), deleteTranslationEdit)
+}
+
+// handlePutCategoryTranslation создает или заменяет перевод категории на язык {lang}
+func (h *eventHandler) handlePutCategoryTranslation(w http.ResponseWriter, r *http.Request) error {
+	edit, err := putTranslationEdit(r)
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Invalid category translation", "error", err)
+		return err
+	}
+	return h.editTranslations(w, r, h.categoryTranslations(), edit)
+}
+
+// handleDeleteCategoryTranslation удаляет перевод категории на язык {lang}
+func (h *eventHandler) handleDeleteCategoryTranslation(w http.ResponseWriter, r *http.Request) error {
+	return h.editTranslations(w, r, h.categoryTranslations(), deleteTranslationEdit)
+}
+
+// editTranslations применяет правку к переводам ресурса и сохраняет их целиком
+// (маска translations). Обновление условное: по If-Match, а без него — по версии
+// загруженного ресурса, чтобы не потерять параллельную правку другого языка.
+func (h *eventHandler) editTranslations(w http.ResponseWriter, r *http.Request, resource translatedResource, edit translationEdit) error {
+	id, err := parseIDFromURL(r, "id")
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Failed to parse ID from URL", "error", err)
+		return err
+	}
+	lang := i18n.Normalize(chi.URLParam(r, "lang"))
+	if err := h.validateTranslationLang(lang); err != nil {
+		return err
+	}
+
+	grpcCtx, cancel := h.createContext(r)
+	defer cancel()
+
+	translations, currentVersion, err := resource.load(grpcCtx, id)
+	if err != nil {
+		h.logger.ErrorContext(grpcCtx, "Failed to get translated resource via gRPC", "resource", resource.kind, "id", id, "error", err)
+		return err
+	}
+
+	expected, err := expectedVersion(r, func() (*timestamppb.Timestamp, error) { return currentVersion, nil })
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Translation precondition failed", "resource", resource.kind, "id", id, "if_match", r.Header.Get("If-Match"))
+		return err
+	}
+	explicit := expected != nil
+	if !explicit {
+		expected = currentVersion
+	}
+
+	if translations == nil {
+		translations = make(map[string]Translation)
+	}
+	if err := edit(translations, lang); err != nil {
+		return err
+	}
+
+	version, res, err := resource.save(grpcCtx, id, translations, expected)
+	if status.Code(err) == codes.FailedPrecondition && !explicit {
+		return status.Errorf(codes.Aborted, resource.conflict, id)
+	}
+	if err != nil {
+		h.logger.ErrorContext(grpcCtx, "Failed to update translations via gRPC", "resource", resource.kind, "id", id, "lang", lang, "error", err)
+		return preconditionError(err, expected)
+	}
+
+	h.logger.InfoContext(grpcCtx, "Translations updated", "resource", resource.kind, "id", id, "lang", lang, "translations", len(translations))
+
+	setVersionETag(w, version)
+	return WriteJSON(w, http.StatusOK, res)
+}
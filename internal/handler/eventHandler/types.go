@@ -4,43 +4,112 @@ import "time"
 
 // Event представляет событие для HTTP ответа
 type Event struct {
-	Id          int64      `json:"id"`
-	Name        string     `json:"name"`
-	Description string     `json:"description"`
-	CategoryID  int64      `json:"category_id"`
-	Date        string     `json:"date"`
-	Time        string     `json:"time"`
-	Location    string     `json:"location"`
-	Price       float32    `json:"price"`
-	Image       string     `json:"image"`
-	Source      string     `json:"source"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+	Id          int64    `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	CategoryID  int64    `json:"category_id"`
+	Date        string   `json:"date"`
+	Time        string   `json:"time"`
+	Location    string   `json:"location"`
+	Price       float32  `json:"price"`
+	Image       string   `json:"image"`
+	Source      string   `json:"source"`
+	Lat         *float64 `json:"lat,omitempty"`
+	Lon         *float64 `json:"lon,omitempty"`
+	DistanceKm  *float64 `json:"distance_km,omitempty"` // Только при поиске с near
+
+	// Варианты загруженного изображения: thumbnail, card, full
+	ImageVariants map[string]string `json:"image_variants,omitempty"`
+
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+
+	// Расписание
+	StartsAt       *time.Time   `json:"starts_at,omitempty"`
+	EndsAt         *time.Time   `json:"ends_at,omitempty"`
+	TimeZone       string       `json:"time_zone,omitempty"`
+	RecurrenceRule string       `json:"recurrence_rule,omitempty"`
+	Occurrences    []Occurrence `json:"occurrences,omitempty"` // Повторения внутри окна date_from..date_to
+
+	// Модерация. submitted_by виден только модераторам
+	Status           string `json:"status"`
+	ModerationReason string `json:"moderation_reason,omitempty"`
+	SubmittedBy      string `json:"submitted_by,omitempty"`
+
+	// Только в списках событий для аутентифицированного пользователя
+	IsFavorite *bool `json:"is_favorite,omitempty"`
+
+	// Язык name и description. Переводы на все языки — только при include_translations=true
+	Lang         string                 `json:"lang,omitempty"`
+	Translations map[string]Translation `json:"translations,omitempty"`
+}
+
+// Translation перевод названия и описания события или категории.
+// Ключ в картах translations — код языка ("en"); основные поля — на языке по умолчанию.
+type Translation struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// Occurrence одно повторение повторяющегося события
+type Occurrence struct {
+	StartsAt time.Time  `json:"starts_at"`
+	EndsAt   *time.Time `json:"ends_at,omitempty"`
 }
 
 // CreateEventReq представляет запрос на создание события через HTTP
 type CreateEventReq struct {
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	CategoryID  int64   `json:"category_id"`
-	Date        string  `json:"date"`
-	Time        string  `json:"time"`
-	Location    string  `json:"location"`
-	Price       float32 `json:"price"`
-	Image       string  `json:"image"`
-	Source      string  `json:"source"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	CategoryID  int64    `json:"category_id"`
+	Date        string   `json:"date"`
+	Time        string   `json:"time"`
+	Location    string   `json:"location"`
+	Price       float32  `json:"price"`
+	Image       string   `json:"image"`
+	Source      string   `json:"source"`
+	Lat         *float64 `json:"lat,omitempty"`
+	Lon         *float64 `json:"lon,omitempty"`
+
+	// Расписание: starts_at/ends_at в RFC 3339. Если starts_at не задан,
+	// время начала вычисляется из пары date + time в часовом поясе time_zone.
+	StartsAt       *string `json:"starts_at,omitempty"`
+	EndsAt         *string `json:"ends_at,omitempty"`
+	TimeZone       string  `json:"time_zone,omitempty"`
+	RecurrenceRule string  `json:"recurrence_rule,omitempty"`
+
+	// Статус модерации: draft, pending или published. Задать могут только модераторы,
+	// по умолчанию published для модераторов и pending для остальных
+	Status *string `json:"status,omitempty"`
+
+	// Переводы на языки, кроме языка по умолчанию
+	Translations map[string]Translation `json:"translations,omitempty"`
 }
 
+// UpdateEventReq представляет частичное обновление события (PATCH).
+// nil означает, что поле не передано или очищается (null) — какие поля
+// обновляются, определяет update_mask, см. patchDocument.
 type UpdateEventReq struct {
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	CategoryID  int64   `json:"category_id"`
-	Date        string  `json:"date"`
-	Time        string  `json:"time"`
-	Location    string  `json:"location"`
-	Price       float32 `json:"price"`
-	Image       string  `json:"image"`
-	Source      string  `json:"source"`
+	Name        *string  `json:"name,omitempty"`
+	Description *string  `json:"description,omitempty"`
+	CategoryID  *int64   `json:"category_id,omitempty"`
+	Date        *string  `json:"date,omitempty"`
+	Time        *string  `json:"time,omitempty"`
+	Location    *string  `json:"location,omitempty"`
+	Price       *float32 `json:"price,omitempty"`
+	Image       *string  `json:"image,omitempty"`
+	Source      *string  `json:"source,omitempty"`
+	Lat         *float64 `json:"lat,omitempty"`
+	Lon         *float64 `json:"lon,omitempty"`
+
+	// Расписание: см. CreateEventReq
+	StartsAt       *string `json:"starts_at,omitempty"`
+	EndsAt         *string `json:"ends_at,omitempty"`
+	TimeZone       *string `json:"time_zone,omitempty"`
+	RecurrenceRule *string `json:"recurrence_rule,omitempty"`
+
+	// Заменяет все переводы; отдельный язык — PUT /events/{id}/translations/{lang}
+	Translations map[string]Translation `json:"translations,omitempty"`
 }
 
 // ListEventsReq представляет запрос на получение списка событий с фильтрами
@@ -60,13 +129,69 @@ type ListEventsReq struct {
 	Offset *int32 `json:"offset,omitempty"`
 
 	// Дополнительные опции
-	IncludeCount *bool `json:"include_count,omitempty"`
+	IncludeCount  *bool `json:"include_count,omitempty"`
+	IncludeFacets *bool `json:"include_facets,omitempty"`
+
+	// Гео-фильтры
+	Near     *GeoPoint    `json:"near,omitempty"`
+	RadiusKm *float64     `json:"radius_km,omitempty"`
+	BBox     *BoundingBox `json:"bbox,omitempty"`
+	SortBy   *string      `json:"sort,omitempty"`
+
+	// Статусы модерации; фильтр доступен только модераторам
+	Statuses []string `json:"statuses,omitempty"`
+
+	// Включать события подкатегорий category_ids
+	IncludeSubcategories *bool `json:"include_subcategories,omitempty"`
+}
+
+// GeoPoint географическая точка
+type GeoPoint struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+// BoundingBox ограничивающий прямоугольник
+type BoundingBox struct {
+	MinLat float64 `json:"min_lat"`
+	MinLon float64 `json:"min_lon"`
+	MaxLat float64 `json:"max_lat"`
+	MaxLon float64 `json:"max_lon"`
 }
 
 // ListEventsRes представляет ответ со списком событий
 type ListEventsRes struct {
 	Events     []*Event        `json:"events"`
 	Pagination *PaginationMeta `json:"pagination,omitempty"`
+	Facets     *SearchFacets   `json:"facets,omitempty"`
+}
+
+// SearchFacets содержит агрегации по текущему запросу для панели фильтров
+type SearchFacets struct {
+	Categories     []CategoryFacet `json:"categories"`
+	PriceHistogram []PriceBucket   `json:"price_histogram"`
+	Locations      []TermFacet     `json:"locations"`
+	Sources        []TermFacet     `json:"sources"`
+}
+
+// CategoryFacet количество событий в категории
+type CategoryFacet struct {
+	CategoryID int64  `json:"category_id"`
+	Name       string `json:"name,omitempty"` // Пусто, если категория не найдена
+	Count      int64  `json:"count"`
+}
+
+// PriceBucket корзина гистограммы цен [from, to)
+type PriceBucket struct {
+	From  float32  `json:"from"`
+	To    *float32 `json:"to,omitempty"` // nil для последней (открытой) корзины
+	Count int64    `json:"count"`
+}
+
+// TermFacet количество событий для значения поля (локация, источник)
+type TermFacet struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
 }
 
 // PaginationMeta содержит мета-информацию для пагинации
@@ -79,20 +204,64 @@ type PaginationMeta struct {
 
 // Category представляет категорию событий
 type Category struct {
-	Id        int       `json:"id"`
-	Name      string    `json:"name"`
+	Id          int    `json:"id"`
+	Name        string `json:"name"`
+	Slug        string `json:"slug"`
+	ParentID    *int   `json:"parent_id"` // null для корневых категорий
+	Description string `json:"description,omitempty"`
+	Icon        string `json:"icon,omitempty"`
+	SortOrder   int    `json:"sort_order"`
+
+	// Только при include_counts: опубликованные события в самой категории и вместе с подкатегориями
+	EventCount      *int64 `json:"event_count,omitempty"`
+	TotalEventCount *int64 `json:"total_event_count,omitempty"`
+
+	// Только при tree=true
+	Children []*Category `json:"children,omitempty"`
+
+	// См. Event
+	Lang         string                 `json:"lang,omitempty"`
+	Translations map[string]Translation `json:"translations,omitempty"`
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
 // CreateCategoryReq представляет запрос на создание новой категории
 type CreateCategoryReq struct {
-	Name string `json:"name"`
+	Name        string  `json:"name"`
+	Slug        *string `json:"slug,omitempty"` // По умолчанию строится из названия
+	ParentID    *int    `json:"parent_id,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Icon        string  `json:"icon,omitempty"`
+	SortOrder   int     `json:"sort_order,omitempty"`
+
+	Translations map[string]Translation `json:"translations,omitempty"`
 }
 
-// UpdateCategoryReq представляет запрос на обновление категории
+// UpdateCategoryReq представляет частичное обновление категории (PATCH)
 type UpdateCategoryReq struct {
-	Name string `json:"name"`
+	Name        *string `json:"name,omitempty"`
+	Slug        *string `json:"slug,omitempty"`
+	ParentID    *int    `json:"parent_id,omitempty"` // null — сделать категорию корневой
+	Description *string `json:"description,omitempty"`
+	Icon        *string `json:"icon,omitempty"`
+	SortOrder   *int    `json:"sort_order,omitempty"`
+
+	// Заменяет все переводы; отдельный язык — PUT /categories/{id}/translations/{lang}
+	Translations map[string]Translation `json:"translations,omitempty"`
+}
+
+// DeleteCategoryRes результат удаления категории или dry run
+type DeleteCategoryRes struct {
+	Message       string `json:"message"`
+	CategoryID    int    `json:"category_id"`
+	Strategy      string `json:"strategy"`
+	DryRun        bool   `json:"dry_run"`
+	ReassignTo    *int   `json:"reassign_to,omitempty"`
+	Events        int64  `json:"events"`          // События категории: перенесенные, удаленные или мешающие удалению
+	Subcategories int    `json:"subcategories"`   // Подкатегории: перенесенные, удаленные или мешающие удалению
+	Error         string `json:"error,omitempty"` // Почему удаление прервано; events и subcategories — сколько успели обработать
 }
 
 // NewCategory создает новую категорию из запроса
@@ -113,9 +282,11 @@ type SuggestionRequest struct {
 
 // SuggestionResponse представляет ответ с предложениями
 type SuggestionResponse struct {
-	Suggestions []Suggestion `json:"suggestions"`
-	Query       string       `json:"query"`
-	Total       int          `json:"total"`
+	Suggestions []Suggestion      `json:"suggestions"`
+	Query       string            `json:"query"`
+	Total       int               `json:"total"`
+	Groups      []SuggestionGroup `json:"groups,omitempty"` // Только при group_by=type
+	Recent      []RecentSearch    `json:"recent,omitempty"` // Только для аутентифицированных пользователей
 }
 
 // Suggestion одно предложение
@@ -125,4 +296,187 @@ type Suggestion struct {
 	Type     string  `json:"type"`               // Тип: "event", "location", "category"
 	Category string  `json:"category,omitempty"` // Категория если type="event"
 	EventID  *int64  `json:"event_id,omitempty"` // ID события если type="event"
+
+	// Фрагменты text, совпавшие с запросом
+	Highlights []HighlightSpan `json:"highlights,omitempty"`
+}
+
+// HighlightSpan фрагмент текста, совпавший с запросом: [start, end) в символах
+type HighlightSpan struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// SuggestionGroup подсказки одного типа
+type SuggestionGroup struct {
+	Type        string       `json:"type"`
+	Suggestions []Suggestion `json:"suggestions"`
+}
+
+// RecentSearch недавний поисковый запрос пользователя
+type RecentSearch struct {
+	Query      string          `json:"query"`
+	SearchedAt time.Time       `json:"searched_at"`
+	Highlights []HighlightSpan `json:"highlights,omitempty"`
+}
+
+// RecentSearchesRes недавние поисковые запросы пользователя, новые первыми
+type RecentSearchesRes struct {
+	Recent []RecentSearch `json:"recent"`
+}
+
+// SearchClickReq тело beacon-запроса перехода из выдачи к событию
+type SearchClickReq struct {
+	Query    string `json:"query"`
+	EventID  int64  `json:"event_id"`
+	Position int    `json:"position,omitempty"` // Позиция события в выдаче, с 1
+	Source   string `json:"source,omitempty"`   // events, search или suggestions
+}
+
+// SearchAnalyticsRes отчет поисковой аналитики за окно
+type SearchAnalyticsRes struct {
+	From               time.Time          `json:"from"`
+	To                 time.Time          `json:"to"`
+	Window             string             `json:"window"`
+	Searches           int64              `json:"searches"`
+	ZeroResultSearches int64              `json:"zero_result_searches"`
+	Suggestions        int64              `json:"suggestions"`
+	Clicks             int64              `json:"clicks"`
+	TopQueries         []SearchQueryStats `json:"top_queries"`
+	ZeroResultQueries  []SearchQueryStats `json:"zero_result_queries"`
+}
+
+// SearchQueryStats статистика одного поискового запроса
+type SearchQueryStats struct {
+	Query            string  `json:"query"`
+	Searches         int64   `json:"searches"`
+	ZeroResults      int64   `json:"zero_results"`
+	Suggestions      int64   `json:"suggestions"`
+	Clicks           int64   `json:"clicks"`
+	ClickThroughRate float64 `json:"click_through_rate"`
+	AvgResults       float64 `json:"avg_results"`
+	AvgLatencyMs     float64 `json:"avg_latency_ms"`
+}
+
+// RsvpRes регистрация пользователя на событие
+type RsvpRes struct {
+	EventID          int64     `json:"event_id"`
+	UserID           int64     `json:"user_id"`
+	Status           string    `json:"status"`                      // confirmed или waitlisted
+	WaitlistPosition int       `json:"waitlist_position,omitempty"` // Место в листе ожидания, с 1
+	RegisteredAt     time.Time `json:"registered_at"`
+}
+
+// EventRsvpRes вместимость события и регистрация текущего пользователя
+type EventRsvpRes struct {
+	EventID    int64    `json:"event_id"`
+	Capacity   int      `json:"capacity"`            // 0 — без ограничения
+	Available  *int     `json:"available,omitempty"` // Свободные места; нет при capacity = 0
+	Confirmed  int      `json:"confirmed"`
+	Waitlisted int      `json:"waitlisted"`
+	RSVP       *RsvpRes `json:"rsvp,omitempty"` // Только с токеном, если пользователь зарегистрирован
+}
+
+// CapacityReq тело изменения вместимости события
+type CapacityReq struct {
+	Capacity *int `json:"capacity"` // 0 — без ограничения
+}
+
+// AttendeesRes регистрации на событие для администратора
+type AttendeesRes struct {
+	Attendees  []*RsvpRes      `json:"attendees"`
+	Pagination *PaginationMeta `json:"pagination"`
+}
+
+// RecommendationsRes рекомендованные события пользователя
+type RecommendationsRes struct {
+	Recommendations []*Recommendation `json:"recommendations"`
+	// Personalized рекомендации построены по избранному, поискам или истории;
+	// false — пользователь новый и события отобраны по популярности
+	Personalized bool `json:"personalized"`
+}
+
+// Recommendation рекомендованное событие с объяснением
+type Recommendation struct {
+	Event       *Event                 `json:"event"`
+	Score       float64                `json:"score"`
+	Explanation string                 `json:"explanation"` // Главная причина на языке запроса
+	Reasons     []RecommendationReason `json:"reasons"`     // Вклад каждого источника, по убыванию
+}
+
+// RecommendationReason источник рекомендации
+type RecommendationReason struct {
+	// Type favorite_category, favorite_location, clicked_category,
+	// saved_search, recent_search, popular или upcoming
+	Type   string  `json:"type"`
+	Detail string  `json:"detail,omitempty"` // Категория, место, название поиска или запрос
+	Score  float64 `json:"score"`
+}
+
+// BatchCreateEventsRes результат пакетного создания событий
+type BatchCreateEventsRes struct {
+	Total      int                 `json:"total"`
+	Created    int                 `json:"created"`
+	Duplicates int                 `json:"duplicates"`
+	Failed     int                 `json:"failed"`
+	Results    []BatchCreateResult `json:"results"`
+}
+
+// BatchCreateResult результат обработки одной строки пакета
+type BatchCreateResult struct {
+	Row    int    `json:"row"`             // Номер строки/элемента, начиная с 1
+	Status string `json:"status"`          // created, duplicate, invalid или failed
+	Id     *int64 `json:"id,omitempty"`    // ID созданного события
+	Error  string `json:"error,omitempty"` // Причина ошибки
+
+	// Для status = duplicate: похожие существующие события или более ранняя строка пакета
+	DuplicateIDs   []int64 `json:"duplicate_ids,omitempty"`
+	DuplicateOfRow int     `json:"duplicate_of_row,omitempty"`
+}
+
+// DuplicateEventsRes ответ 409 на создание вероятного дубликата
+type DuplicateEventsRes struct {
+	Error       string               `json:"error"`
+	Fingerprint string               `json:"fingerprint"` // Нормализованные название, дата и место
+	Candidates  []DuplicateCandidate `json:"candidates"`
+}
+
+// DuplicateCandidate существующее событие, похожее на создаваемое
+type DuplicateCandidate struct {
+	Score float64 `json:"score"` // Сходство от 0 до 1
+	Event *Event  `json:"event"`
+}
+
+// EventChange уведомление об изменении события (SSE и WebSocket)
+type EventChange struct {
+	ID         uint64    `json:"id,omitempty"` // Номер уведомления для Last-Event-ID
+	Type       string    `json:"type"`         // created, updated, deleted или reset
+	EventID    int64     `json:"event_id,omitempty"`
+	Event      *Event    `json:"event,omitempty"` // Для deleted и removed — последнее известное состояние
+	OccurredAt time.Time `json:"occurred_at"`
+
+	previous *Event // Для updated — состояние до изменения, если известно; клиентам не отправляется
+}
+
+// FavoriteRes ответ на добавление и удаление события из избранного
+type FavoriteRes struct {
+	EventID    int64      `json:"event_id"`
+	IsFavorite bool       `json:"is_favorite"`
+	AddedAt    *time.Time `json:"added_at,omitempty"`
+}
+
+// ModerationReq тело запросов :approve, :reject и :archive
+type ModerationReq struct {
+	Reason string `json:"reason"` // Обязательна для :reject
+}
+
+// EventPageRes данные страницы события одним ответом. Необязательные части
+// (category, related, rsvp), которые не удалось загрузить, равны null
+// и перечислены в unavailable.
+type EventPageRes struct {
+	Event       *Event        `json:"event"`
+	Category    *Category     `json:"category"`
+	Related     []*Event      `json:"related"`        // Предстоящие события той же категории
+	Rsvp        *EventRsvpRes `json:"rsvp,omitempty"` // Только если регистрации включены
+	Unavailable []string      `json:"unavailable"`
 }
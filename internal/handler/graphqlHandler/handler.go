@@ -0,0 +1,118 @@
+package graphqlhandler
+
+import (
+	"fmt"
+	"net/http"
+
+	pbEvent "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/event"
+	pbUser "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/user"
+
+	"github.com/rx3lixir/gateway-service/pkg/graphql"
+	"github.com/rx3lixir/gateway-service/pkg/i18n"
+	"github.com/rx3lixir/gateway-service/pkg/logger"
+	"github.com/rx3lixir/gateway-service/pkg/token"
+)
+
+// defaultMaxBody ограничение тела POST запроса по умолчанию
+const defaultMaxBody = 1 << 20
+
+type graphqlHandler struct {
+	eventClient pbEvent.EventServiceClient
+	userClient  pbUser.UserServiceClient
+	tokenMaker  *token.JWTMaker
+	logger      logger.Logger
+
+	schema    *graphql.Schema
+	limits    graphql.Limits
+	persisted graphql.PersistedQueries
+	maxBody   int64
+}
+
+// NewGraphQLHandler создает обработчик /graphql поверх event-service и user-service.
+// Сессия берется из токена, который проверяет middleware аутентификации.
+func NewGraphQLHandler(eventClient pbEvent.EventServiceClient, userClient pbUser.UserServiceClient, secretKey string, log logger.Logger, opts ...Option) *graphqlHandler {
+	h := &graphqlHandler{
+		eventClient: eventClient,
+		userClient:  userClient,
+		tokenMaker:  token.NewJWTMaker(secretKey),
+		logger:      log,
+		maxBody:     defaultMaxBody,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	// Схема статична: ошибка в ней — ошибка программы
+	schema, err := h.newSchema()
+	if err != nil {
+		panic(fmt.Sprintf("invalid graphql schema: %v", err))
+	}
+	h.schema = schema
+	return h
+}
+
+// handleQuery выполняет GraphQL запрос (GET или POST)
+func (h *graphqlHandler) handleQuery(w http.ResponseWriter, r *http.Request) error {
+	req, err := graphql.ParseRequest(r, h.maxBody)
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Failed to parse GraphQL request", "error", err)
+		return err
+	}
+
+	// Клиенты Automatic Persisted Queries ждут PersistedQueryNotFound с кодом 200,
+	// после чего повторяют запрос с текстом
+	if perr := req.ResolvePersisted(h.persisted); perr != nil {
+		return WriteJSON(w, http.StatusOK, &graphql.Result{Errors: localizeErrors(r, []*graphql.Error{perr})})
+	}
+
+	ctx, cancel := h.createContext(r)
+	defer cancel()
+	ctx = h.withLoaders(ctx)
+
+	res := graphql.Execute(ctx, graphql.Params{
+		Schema:        h.schema,
+		Query:         req.Query,
+		OperationName: req.OperationName,
+		Variables:     req.Variables,
+		Limits:        h.limits,
+		FormatError:   h.formatError,
+	})
+	res.Errors = localizeErrors(r, res.Errors)
+
+	// Запрос, не дошедший до выполнения (синтаксис, схема, лимиты), — ошибка клиента
+	if !res.Executed() {
+		h.logger.WarnContext(r.Context(), "GraphQL request rejected", "operation", req.OperationName, "errors", len(res.Errors))
+		return WriteJSON(w, http.StatusBadRequest, res)
+	}
+
+	h.logger.InfoContext(r.Context(), "GraphQL request executed",
+		"operation", req.OperationName,
+		"depth", res.Depth,
+		"complexity", res.Complexity,
+		"errors", len(res.Errors),
+	)
+	return WriteJSON(w, http.StatusOK, res)
+}
+
+// handleSchema возвращает схему на языке SDL
+func (h *graphqlHandler) handleSchema(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, err := w.Write([]byte(h.schema.SDL()))
+	return err
+}
+
+// localizeErrors переводит сообщения ошибок на язык запроса. Ошибки копируются:
+// часть из них — общие значения пакета graphql.
+func localizeErrors(r *http.Request, errs []*graphql.Error) []*graphql.Error {
+	if len(errs) == 0 {
+		return errs
+	}
+	out := make([]*graphql.Error, len(errs))
+	for i, err := range errs {
+		c := *err
+		c.Message = i18n.Localize(r.Context(), c.Message)
+		out[i] = &c
+	}
+	return out
+}
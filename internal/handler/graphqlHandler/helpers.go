@@ -0,0 +1,110 @@
+package graphqlhandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	contextpkg "github.com/rx3lixir/gateway-service/pkg/context"
+	"github.com/rx3lixir/gateway-service/pkg/graphql"
+	"github.com/rx3lixir/gateway-service/pkg/i18n"
+	"github.com/rx3lixir/gateway-service/pkg/token"
+)
+
+// APIError представляет структуру ошибки для ответов API.
+type APIError struct {
+	Error string `json:"error"`
+}
+
+// WriteJSON отправляет данные в формате JSON с указанным HTTP статусом.
+func WriteJSON(w http.ResponseWriter, statusCode int, data any) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	return json.NewEncoder(w).Encode(data)
+}
+
+// apiFunc определяет сигнатуру функций-обработчиков API,
+// которые возвращают ошибку для централизованной обработки.
+type apiFunc func(w http.ResponseWriter, r *http.Request) error
+
+// makeHTTPHandlerFunc преобразует apiFunc в стандартный http.HandlerFunc.
+// Ошибки выполнения запроса попадают в поле errors ответа GraphQL, сюда
+// доходят только ошибки разбора HTTP запроса.
+func (h *graphqlHandler) makeHTTPHandlerFunc(f apiFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := f(w, r); err != nil {
+			errStr := strings.ToLower(err.Error())
+			if strings.Contains(errStr, "required") || strings.Contains(errStr, "invalid") {
+				WriteJSON(w, http.StatusBadRequest, APIError{Error: i18n.Localize(r.Context(), err.Error())})
+				return
+			}
+
+			h.logger.Error("HTTP handler error", "error", err, "path", r.URL.Path)
+			WriteJSON(w, http.StatusInternalServerError, APIError{Error: i18n.Localize(r.Context(), "An unexpected error occurred")})
+		}
+	}
+}
+
+// formatError превращает ошибку резолвера в ошибку GraphQL с кодом в extensions.code.
+// Тексты внутренних ошибок не попадают в ответ.
+func (h *graphqlHandler) formatError(ctx context.Context, err error) *graphql.Error {
+	var gqlErr *graphql.Error
+	if errors.As(err, &gqlErr) {
+		return gqlErr
+	}
+
+	code, message := "INTERNAL_SERVER_ERROR", "An unexpected error occurred"
+	if st, ok := status.FromError(err); ok {
+		switch st.Code() {
+		case codes.NotFound:
+			code, message = "NOT_FOUND", st.Message()
+		case codes.InvalidArgument:
+			code, message = "BAD_USER_INPUT", st.Message()
+		case codes.AlreadyExists, codes.Aborted, codes.FailedPrecondition:
+			code, message = "CONFLICT", st.Message()
+		case codes.Unauthenticated:
+			code, message = "UNAUTHENTICATED", st.Message()
+		case codes.PermissionDenied:
+			code, message = "FORBIDDEN", st.Message()
+		case codes.DeadlineExceeded:
+			code, message = "TIMEOUT", "request timed out"
+		default:
+			h.logger.ErrorContext(ctx, "Unhandled gRPC error in GraphQL resolver", "code", st.Code(), "message", st.Message())
+		}
+	} else {
+		errStr := strings.ToLower(err.Error())
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			code, message = "TIMEOUT", "request timed out"
+		case strings.Contains(errStr, "required") || strings.Contains(errStr, "invalid") || strings.Contains(errStr, "format"):
+			code, message = "BAD_USER_INPUT", err.Error()
+		case strings.Contains(errStr, "not found"):
+			code, message = "NOT_FOUND", err.Error()
+		default:
+			h.logger.ErrorContext(ctx, "GraphQL resolver error", "error", err)
+		}
+	}
+	return &graphql.Error{Message: message, Extensions: map[string]any{"code": code}, Err: err}
+}
+
+// createContext создает контекст выполнения запроса с таймаутом на все вызовы gRPC.
+func (h *graphqlHandler) createContext(r *http.Request) (context.Context, context.CancelFunc) {
+	return contextpkg.GRPCContextFromHTTP(r)
+}
+
+// claimsFromContext возвращает данные токена или nil для анонимного запроса
+func claimsFromContext(ctx context.Context) *token.UserClaims {
+	claims, _ := ctx.Value(contextpkg.AuthKey).(*token.UserClaims)
+	return claims
+}
+
+// isModerator проверяет права модератора. Модераторы — администраторы.
+func isModerator(ctx context.Context) bool {
+	claims := claimsFromContext(ctx)
+	return claims != nil && claims.IsAdmin
+}
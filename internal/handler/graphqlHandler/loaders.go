@@ -0,0 +1,160 @@
+package graphqlhandler
+
+import (
+	"context"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pbEvent "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/event"
+	pbUser "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/user"
+	"github.com/rx3lixir/gateway-service/internal/handler/eventHandler"
+	"github.com/rx3lixir/gateway-service/pkg/graphql"
+)
+
+// loadConcurrency максимум параллельных GetEvent и GetUser в одном пакете загрузчика
+const loadConcurrency = 8
+
+type loadersKey struct{}
+
+// loaders загрузчики одного запроса: значения кэшируются до конца запроса,
+// ключи одного уровня выборки загружаются одним пакетом
+type loaders struct {
+	events     *graphql.Loader[int64, *eventHandler.Event]
+	users      *graphql.Loader[int64, *pbUser.UserRes]
+	categories *graphql.Loader[int32, *eventHandler.Category]
+
+	// Все категории загружаются одним ListCategories на запрос: список нужен
+	// и для пакета загрузчика, и для дерева подкатегорий
+	categoriesOnce sync.Once
+	categoryList   []*eventHandler.Category
+	categoryErr    error
+}
+
+// withLoaders добавляет в контекст загрузчики нового запроса
+func (h *graphqlHandler) withLoaders(ctx context.Context) context.Context {
+	l := &loaders{}
+	l.events = graphql.NewLoader(h.batchEvents)
+	l.users = graphql.NewLoader(h.batchUsers)
+	l.categories = graphql.NewLoader(func(ctx context.Context, ids []int32) (map[int32]*eventHandler.Category, error) {
+		all, err := h.allCategories(ctx, l)
+		if err != nil {
+			return nil, err
+		}
+		byID := make(map[int32]*eventHandler.Category, len(ids))
+		for _, category := range all {
+			byID[int32(category.Id)] = category
+		}
+		result := make(map[int32]*eventHandler.Category, len(ids))
+		for _, id := range ids {
+			if category, ok := byID[id]; ok {
+				result[id] = category
+			}
+		}
+		return result, nil
+	})
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+// loadersFrom возвращает загрузчики запроса
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+// allCategories возвращает все категории, переведенные на язык запроса
+func (h *graphqlHandler) allCategories(ctx context.Context, l *loaders) ([]*eventHandler.Category, error) {
+	l.categoriesOnce.Do(func() {
+		res, err := h.eventClient.ListCategories(ctx, &pbEvent.ListCategoriesReq{})
+		if err != nil {
+			h.logger.ErrorContext(ctx, "Failed to list categories via gRPC", "error", err)
+			l.categoryErr = err
+			return
+		}
+		l.categoryList = eventHandler.ProtoCategoriesListToHTTPCategoriesList(res.GetCategories())
+		for _, category := range l.categoryList {
+			eventHandler.LocalizeCategory(ctx, category)
+		}
+	})
+	return l.categoryList, l.categoryErr
+}
+
+// batchEvents параллельно загружает события по ID. Ненайденные события
+// пропускаются: поле получит null.
+func (h *graphqlHandler) batchEvents(ctx context.Context, ids []int64) (map[int64]*eventHandler.Event, error) {
+	events := make([]*eventHandler.Event, len(ids))
+	errs := make([]error, len(ids))
+	sem := make(chan struct{}, loadConcurrency)
+	var wg sync.WaitGroup
+
+	for i, id := range ids {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			res, err := h.eventClient.GetEvent(ctx, eventHandler.IDToProtoGetEventByIDReq(id))
+			if err != nil {
+				if status.Code(err) != codes.NotFound {
+					errs[i] = err
+				}
+				return
+			}
+			events[i] = eventHandler.ProtoEventResToHTTPEvent(res)
+		}()
+	}
+	wg.Wait()
+
+	result := make(map[int64]*eventHandler.Event, len(ids))
+	for i, id := range ids {
+		if errs[i] != nil {
+			h.logger.ErrorContext(ctx, "Failed to get event via gRPC", "id", id, "error", errs[i])
+			return nil, errs[i]
+		}
+		if events[i] != nil {
+			eventHandler.LocalizeEvent(ctx, events[i])
+			result[id] = events[i]
+		}
+	}
+	return result, nil
+}
+
+// batchUsers параллельно загружает пользователей по ID, см. batchEvents
+func (h *graphqlHandler) batchUsers(ctx context.Context, ids []int64) (map[int64]*pbUser.UserRes, error) {
+	users := make([]*pbUser.UserRes, len(ids))
+	errs := make([]error, len(ids))
+	sem := make(chan struct{}, loadConcurrency)
+	var wg sync.WaitGroup
+
+	for i, id := range ids {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			res, err := h.userClient.GetUser(ctx, &pbUser.UserReq{Id: id})
+			if err != nil {
+				if status.Code(err) != codes.NotFound {
+					errs[i] = err
+				}
+				return
+			}
+			users[i] = res
+		}()
+	}
+	wg.Wait()
+
+	result := make(map[int64]*pbUser.UserRes, len(ids))
+	for i, id := range ids {
+		if errs[i] != nil {
+			h.logger.ErrorContext(ctx, "Failed to get user via gRPC", "id", id, "error", errs[i])
+			return nil, errs[i]
+		}
+		if users[i] != nil {
+			result[id] = users[i]
+		}
+	}
+	return result, nil
+}
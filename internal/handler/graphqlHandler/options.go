@@ -0,0 +1,31 @@
+package graphqlhandler
+
+import "github.com/rx3lixir/gateway-service/pkg/graphql"
+
+// Option функция для настройки graphqlHandler
+type Option func(*graphqlHandler)
+
+// WithLimits ограничивает глубину и сложность запросов
+func WithLimits(limits graphql.Limits) Option {
+	return func(h *graphqlHandler) {
+		h.limits = limits
+	}
+}
+
+// WithPersistedQueries включает Automatic Persisted Queries: клиент может
+// прислать вместо текста запроса его SHA-256. Без хранилища такие запросы
+// получают PersistedQueryNotSupported.
+func WithPersistedQueries(store graphql.PersistedQueries) Option {
+	return func(h *graphqlHandler) {
+		h.persisted = store
+	}
+}
+
+// WithMaxBody ограничивает размер тела POST запроса; по умолчанию 1 МБ
+func WithMaxBody(maxBody int64) Option {
+	return func(h *graphqlHandler) {
+		if maxBody > 0 {
+			h.maxBody = maxBody
+		}
+	}
+}
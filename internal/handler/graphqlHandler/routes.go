@@ -0,0 +1,32 @@
+package graphqlhandler
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/rx3lixir/gateway-service/pkg/middleware"
+)
+
+func RegisterRoutes(h *graphqlHandler) *chi.Mux {
+	r := chi.NewRouter()
+
+	middlewareConfig := &middleware.Config{
+		TokenMaker: h.tokenMaker,
+		Logger:     h.logger,
+		CORSConfig: middleware.DefaultCORSConfig(),
+	}
+
+	// Общие middleware
+	for _, mw := range middleware.CommonMiddlewares(middlewareConfig) {
+		r.Use(mw)
+	}
+
+	// API routes. Токен необязателен: без него доступны только публичные данные,
+	// поле me возвращает null
+	r.Route("/api/v1/graphql", func(r chi.Router) {
+		r.Use(middleware.OptionalAuth(middlewareConfig))
+		r.Get("/", h.makeHTTPHandlerFunc(h.handleQuery))
+		r.Post("/", h.makeHTTPHandlerFunc(h.handleQuery))
+		r.Get("/schema", h.makeHTTPHandlerFunc(h.handleSchema))
+	})
+
+	return r
+}
@@ -0,0 +1,587 @@
+package graphqlhandler
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pbUser "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/user"
+	"github.com/rx3lixir/gateway-service/internal/handler/eventHandler"
+	"github.com/rx3lixir/gateway-service/pkg/graphql"
+	"github.com/rx3lixir/gateway-service/pkg/token"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+
+	// Событий категории по умолчанию в Category.events
+	defaultCategoryEvents = 10
+
+	statusPublished = "published"
+)
+
+// eventStatuses статусы модерации событий event-service
+var eventStatuses = []string{"draft", "pending", "published", "rejected", "archived"}
+
+// eventPage страница списка событий
+type eventPage struct {
+	Items      []*eventHandler.Event
+	TotalCount int64
+	Limit      int32
+	Offset     int32
+	HasMore    bool
+}
+
+// translation перевод события или категории на один язык
+type translation struct {
+	Lang string
+	eventHandler.Translation
+}
+
+// newSchema описывает схему: события, категории, пользователи и текущая сессия
+func (h *graphqlHandler) newSchema() (*graphql.Schema, error) {
+	eventStatus := &graphql.Enum{
+		Name:        "EventStatus",
+		Description: "Статус модерации события",
+	}
+	for _, s := range eventStatuses {
+		eventStatus.Values = append(eventStatus.Values, &graphql.EnumValue{Name: strings.ToUpper(s)})
+	}
+
+	translationType := &graphql.Object{
+		Name:        "Translation",
+		Description: "Перевод названия и описания",
+		Fields: []*graphql.Field{
+			{Name: "lang", Type: graphql.NewNonNull(graphql.String), Resolve: source(func(t translation) any { return t.Lang })},
+			{Name: "name", Type: graphql.NewNonNull(graphql.String), Resolve: source(func(t translation) any { return t.Name })},
+			{Name: "description", Type: graphql.String, Resolve: source(func(t translation) any { return optional(t.Description) })},
+		},
+	}
+
+	userType := &graphql.Object{
+		Name:        "User",
+		Description: "Пользователь",
+		Fields: []*graphql.Field{
+			{Name: "id", Type: graphql.NewNonNull(graphql.ID), Resolve: source(func(u *pbUser.UserRes) any { return u.GetId() })},
+			{Name: "name", Type: graphql.NewNonNull(graphql.String), Resolve: source(func(u *pbUser.UserRes) any { return u.GetName() })},
+			{Name: "email", Type: graphql.NewNonNull(graphql.String), Resolve: source(func(u *pbUser.UserRes) any { return u.GetEmail() })},
+			{Name: "isAdmin", Type: graphql.NewNonNull(graphql.Boolean), Resolve: source(func(u *pbUser.UserRes) any { return u.GetIsAdmin() })},
+			{Name: "createdAt", Type: graphql.String, Description: "RFC 3339", Resolve: source(func(u *pbUser.UserRes) any {
+				if !u.GetCreatedAt().IsValid() {
+					return nil
+				}
+				return formatTime(u.GetCreatedAt().AsTime())
+			})},
+		},
+	}
+
+	sessionType := &graphql.Object{
+		Name:        "Session",
+		Description: "Текущая сессия",
+		Fields: []*graphql.Field{
+			{Name: "user", Type: graphql.NewNonNull(userType), Resolve: func(p graphql.ResolveParams) (any, error) {
+				claims := p.Source.(*token.UserClaims)
+				return loadersFrom(p.Context).users.Load(p.Context, int64(claims.Id)), nil
+			}},
+			{Name: "isAdmin", Type: graphql.NewNonNull(graphql.Boolean), Resolve: source(func(c *token.UserClaims) any { return c.IsAdmin })},
+			{Name: "expiresAt", Type: graphql.String, Description: "Срок действия токена, RFC 3339", Resolve: source(func(c *token.UserClaims) any {
+				if c.ExpiresAt == nil {
+					return nil
+				}
+				return formatTime(c.ExpiresAt.Time)
+			})},
+		},
+	}
+
+	categoryType := &graphql.Object{Name: "Category", Description: "Категория событий"}
+	eventType := &graphql.Object{Name: "Event", Description: "Событие"}
+
+	categoryType.Fields = []*graphql.Field{
+		{Name: "id", Type: graphql.NewNonNull(graphql.ID), Resolve: source(func(c *eventHandler.Category) any { return c.Id })},
+		{Name: "name", Type: graphql.NewNonNull(graphql.String), Description: "На языке запроса", Resolve: source(func(c *eventHandler.Category) any { return c.Name })},
+		{Name: "slug", Type: graphql.NewNonNull(graphql.String), Resolve: source(func(c *eventHandler.Category) any { return c.Slug })},
+		{Name: "description", Type: graphql.String, Resolve: source(func(c *eventHandler.Category) any { return optional(c.Description) })},
+		{Name: "icon", Type: graphql.String, Resolve: source(func(c *eventHandler.Category) any { return optional(c.Icon) })},
+		{Name: "sortOrder", Type: graphql.NewNonNull(graphql.Int), Resolve: source(func(c *eventHandler.Category) any { return c.SortOrder })},
+		{Name: "lang", Type: graphql.String, Description: "Язык name и description", Resolve: source(func(c *eventHandler.Category) any { return optional(c.Lang) })},
+		{Name: "translations", Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(translationType))), Resolve: source(func(c *eventHandler.Category) any {
+			return translations(c.Translations)
+		})},
+		{Name: "parent", Type: categoryType, Resolve: func(p graphql.ResolveParams) (any, error) {
+			category := p.Source.(*eventHandler.Category)
+			if category.ParentID == nil {
+				return nil, nil
+			}
+			return loadersFrom(p.Context).categories.Load(p.Context, int32(*category.ParentID)), nil
+		}},
+		{Name: "children", Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(categoryType))), Resolve: func(p graphql.ResolveParams) (any, error) {
+			category := p.Source.(*eventHandler.Category)
+			all, err := h.allCategories(p.Context, loadersFrom(p.Context))
+			if err != nil {
+				return nil, err
+			}
+			children := []*eventHandler.Category{}
+			for _, c := range all {
+				if c.ParentID != nil && *c.ParentID == category.Id {
+					children = append(children, c)
+				}
+			}
+			return children, nil
+		}},
+		{
+			Name:        "events",
+			Description: "Ближайшие опубликованные события категории",
+			Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(eventType))),
+			Args: []*graphql.Argument{
+				{Name: "limit", Type: graphql.Int, Default: defaultCategoryEvents},
+			},
+			Cost: limitCost,
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				category := p.Source.(*eventHandler.Category)
+				limit, err := limitArg(p.Args)
+				if err != nil {
+					return nil, err
+				}
+				req := &eventHandler.ListEventsReq{
+					CategoryIDs: []int64{int64(category.Id)},
+					Limit:       &limit,
+					Statuses:    []string{statusPublished},
+				}
+				return graphql.Async(func() (any, error) {
+					page, err := h.listEvents(p.Context, req)
+					if err != nil {
+						return nil, err
+					}
+					return page.Items, nil
+				}), nil
+			},
+		},
+	}
+
+	eventType.Fields = []*graphql.Field{
+		{Name: "id", Type: graphql.NewNonNull(graphql.ID), Resolve: source(func(e *eventHandler.Event) any { return e.Id })},
+		{Name: "name", Type: graphql.NewNonNull(graphql.String), Description: "На языке запроса", Resolve: source(func(e *eventHandler.Event) any { return e.Name })},
+		{Name: "description", Type: graphql.NewNonNull(graphql.String), Resolve: source(func(e *eventHandler.Event) any { return e.Description })},
+		{Name: "lang", Type: graphql.String, Description: "Язык name и description", Resolve: source(func(e *eventHandler.Event) any { return optional(e.Lang) })},
+		{Name: "translations", Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(translationType))), Resolve: source(func(e *eventHandler.Event) any {
+			return translations(e.Translations)
+		})},
+		{Name: "category", Type: categoryType, Resolve: func(p graphql.ResolveParams) (any, error) {
+			event := p.Source.(*eventHandler.Event)
+			return loadersFrom(p.Context).categories.Load(p.Context, int32(event.CategoryID)), nil
+		}},
+		{Name: "date", Type: graphql.NewNonNull(graphql.String), Resolve: source(func(e *eventHandler.Event) any { return e.Date })},
+		{Name: "time", Type: graphql.NewNonNull(graphql.String), Resolve: source(func(e *eventHandler.Event) any { return e.Time })},
+		{Name: "startsAt", Type: graphql.String, Description: "RFC 3339", Resolve: source(func(e *eventHandler.Event) any { return optionalTime(e.StartsAt) })},
+		{Name: "endsAt", Type: graphql.String, Description: "RFC 3339", Resolve: source(func(e *eventHandler.Event) any { return optionalTime(e.EndsAt) })},
+		{Name: "timeZone", Type: graphql.String, Resolve: source(func(e *eventHandler.Event) any { return optional(e.TimeZone) })},
+		{Name: "recurrenceRule", Type: graphql.String, Resolve: source(func(e *eventHandler.Event) any { return optional(e.RecurrenceRule) })},
+		{Name: "location", Type: graphql.NewNonNull(graphql.String), Resolve: source(func(e *eventHandler.Event) any { return e.Location })},
+		{Name: "lat", Type: graphql.Float, Resolve: source(func(e *eventHandler.Event) any { return optionalFloat(e.Lat) })},
+		{Name: "lon", Type: graphql.Float, Resolve: source(func(e *eventHandler.Event) any { return optionalFloat(e.Lon) })},
+		{Name: "price", Type: graphql.NewNonNull(graphql.Float), Resolve: source(func(e *eventHandler.Event) any { return e.Price })},
+		{Name: "image", Type: graphql.String, Resolve: source(func(e *eventHandler.Event) any { return optional(e.Image) })},
+		{Name: "source", Type: graphql.String, Resolve: source(func(e *eventHandler.Event) any { return optional(e.Source) })},
+		{Name: "status", Type: graphql.NewNonNull(eventStatus), Resolve: source(func(e *eventHandler.Event) any { return strings.ToUpper(e.Status) })},
+		{Name: "createdAt", Type: graphql.NewNonNull(graphql.String), Description: "RFC 3339", Resolve: source(func(e *eventHandler.Event) any { return formatTime(e.CreatedAt) })},
+		{Name: "updatedAt", Type: graphql.String, Description: "RFC 3339", Resolve: source(func(e *eventHandler.Event) any { return optionalTime(e.UpdatedAt) })},
+		{Name: "submittedBy", Type: userType, Description: "Отправитель события; только для модераторов", Resolve: func(p graphql.ResolveParams) (any, error) {
+			event := p.Source.(*eventHandler.Event)
+			if !isModerator(p.Context) {
+				return nil, nil
+			}
+			id, ok := submitterUserID(event.SubmittedBy)
+			if !ok {
+				return nil, nil
+			}
+			return loadersFrom(p.Context).users.Load(p.Context, id), nil
+		}},
+	}
+
+	eventPageType := &graphql.Object{
+		Name:        "EventPage",
+		Description: "Страница списка событий",
+		Fields: []*graphql.Field{
+			{Name: "items", Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(eventType))), Resolve: source(func(p *eventPage) any { return p.Items })},
+			{Name: "totalCount", Type: graphql.NewNonNull(graphql.Int), Resolve: source(func(p *eventPage) any { return p.TotalCount })},
+			{Name: "limit", Type: graphql.NewNonNull(graphql.Int), Resolve: source(func(p *eventPage) any { return p.Limit })},
+			{Name: "offset", Type: graphql.NewNonNull(graphql.Int), Resolve: source(func(p *eventPage) any { return p.Offset })},
+			{Name: "hasMore", Type: graphql.NewNonNull(graphql.Boolean), Resolve: source(func(p *eventPage) any { return p.HasMore })},
+		},
+	}
+
+	eventFilter := &graphql.InputObject{
+		Name:        "EventFilter",
+		Description: "Фильтры списка событий, как у GET /events",
+		Fields: []*graphql.Argument{
+			{Name: "categoryIds", Type: graphql.NewList(graphql.NewNonNull(graphql.ID))},
+			{Name: "includeSubcategories", Type: graphql.Boolean, Default: false},
+			{Name: "minPrice", Type: graphql.Float},
+			{Name: "maxPrice", Type: graphql.Float},
+			{Name: "dateFrom", Type: graphql.String, Description: "YYYY-MM-DD или RFC 3339"},
+			{Name: "dateTo", Type: graphql.String, Description: "YYYY-MM-DD или RFC 3339"},
+			{Name: "location", Type: graphql.String},
+			{Name: "source", Type: graphql.String},
+			{Name: "search", Type: graphql.String},
+			{Name: "statuses", Type: graphql.NewList(graphql.NewNonNull(eventStatus)), Description: "Только для модераторов"},
+		},
+	}
+
+	query := &graphql.Object{
+		Name: "Query",
+		Fields: []*graphql.Field{
+			{
+				Name:        "me",
+				Description: "Текущая сессия; null без токена",
+				Type:        sessionType,
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					if claims := claimsFromContext(p.Context); claims != nil {
+						return claims, nil
+					}
+					return nil, nil
+				},
+			},
+			{
+				Name:        "event",
+				Description: "Событие по ID; неопубликованные видны модераторам и отправителю",
+				Type:        eventType,
+				Args:        []*graphql.Argument{{Name: "id", Type: graphql.NewNonNull(graphql.ID)}},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					id, err := parseID(p.Args["id"])
+					if err != nil {
+						return nil, err
+					}
+					load := loadersFrom(p.Context).events.Load(p.Context, id)
+					return graphql.Thunk(func() (any, error) {
+						value, err := load()
+						if err != nil || value == nil {
+							return nil, err
+						}
+						if event := value.(*eventHandler.Event); eventHandler.CanView(p.Context, event) {
+							return event, nil
+						}
+						return nil, nil
+					}), nil
+				},
+			},
+			{
+				Name:        "events",
+				Description: "Список событий с фильтрами и пагинацией",
+				Type:        graphql.NewNonNull(eventPageType),
+				Args: []*graphql.Argument{
+					{Name: "filter", Type: eventFilter},
+					{Name: "limit", Type: graphql.Int, Default: defaultPageLimit},
+					{Name: "offset", Type: graphql.Int, Default: 0},
+				},
+				Cost: limitCost,
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					req, err := listEventsReq(p.Context, p.Args)
+					if err != nil {
+						return nil, err
+					}
+					return graphql.Async(func() (any, error) {
+						if valueOrZero(req.IncludeSubcategories) && len(req.CategoryIDs) > 0 {
+							if err := h.expandCategoryIDs(p.Context, req); err != nil {
+								return nil, err
+							}
+						}
+						return h.listEvents(p.Context, req)
+					}), nil
+				},
+			},
+			{
+				Name:        "categories",
+				Description: "Все категории",
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(categoryType))),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return graphql.Async(func() (any, error) {
+						return h.allCategories(p.Context, loadersFrom(p.Context))
+					}), nil
+				},
+			},
+			{
+				Name:        "category",
+				Description: "Категория по ID или slug",
+				Type:        categoryType,
+				Args: []*graphql.Argument{
+					{Name: "id", Type: graphql.ID},
+					{Name: "slug", Type: graphql.String},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					rawID, hasID := p.Args["id"]
+					slug, hasSlug := p.Args["slug"].(string)
+					switch {
+					case hasID && rawID != nil && hasSlug:
+						return nil, fmt.Errorf("invalid arguments: specify either id or slug, not both")
+					case hasID && rawID != nil:
+						id, err := parseID(rawID)
+						if err != nil {
+							return nil, err
+						}
+						return loadersFrom(p.Context).categories.Load(p.Context, int32(id)), nil
+					case hasSlug:
+						all, err := h.allCategories(p.Context, loadersFrom(p.Context))
+						if err != nil {
+							return nil, err
+						}
+						for _, category := range all {
+							if category.Slug == slug {
+								return category, nil
+							}
+						}
+						return nil, nil
+					}
+					return nil, fmt.Errorf("either id or slug is required")
+				},
+			},
+			{
+				Name:        "user",
+				Description: "Пользователь по ID; только для администраторов",
+				Type:        userType,
+				Args:        []*graphql.Argument{{Name: "id", Type: graphql.NewNonNull(graphql.ID)}},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					claims := claimsFromContext(p.Context)
+					if claims == nil {
+						return nil, status.Error(codes.Unauthenticated, "authorization required")
+					}
+					if !claims.IsAdmin {
+						return nil, status.Error(codes.PermissionDenied, "Access denied: admin privileges required")
+					}
+					id, err := parseID(p.Args["id"])
+					if err != nil {
+						return nil, err
+					}
+					return loadersFrom(p.Context).users.Load(p.Context, id), nil
+				},
+			},
+		},
+	}
+
+	return graphql.NewSchema(query)
+}
+
+// listEventsReq собирает фильтры GET /events из аргументов поля events
+func listEventsReq(ctx context.Context, args map[string]any) (*eventHandler.ListEventsReq, error) {
+	limit, err := limitArg(args)
+	if err != nil {
+		return nil, err
+	}
+	offset, _ := args["offset"].(int)
+	if offset < 0 {
+		return nil, fmt.Errorf("invalid offset %q: must be a non-negative integer", strconv.Itoa(offset))
+	}
+	offset32 := int32(offset)
+	req := &eventHandler.ListEventsReq{Limit: &limit, Offset: &offset32}
+
+	filter, _ := args["filter"].(map[string]any)
+	if ids, ok := filter["categoryIds"].([]any); ok {
+		for _, raw := range ids {
+			id, err := parseID(raw)
+			if err != nil {
+				return nil, err
+			}
+			req.CategoryIDs = append(req.CategoryIDs, id)
+		}
+	}
+	if v, ok := filter["includeSubcategories"].(bool); ok {
+		req.IncludeSubcategories = &v
+	}
+	if v, ok := filter["minPrice"].(float64); ok {
+		price := float32(v)
+		req.MinPrice = &price
+	}
+	if v, ok := filter["maxPrice"].(float64); ok {
+		price := float32(v)
+		req.MaxPrice = &price
+	}
+	for name, dst := range map[string]**string{
+		"dateFrom": &req.DateFrom,
+		"dateTo":   &req.DateTo,
+		"location": &req.Location,
+		"source":   &req.Source,
+		"search":   &req.SearchText,
+	} {
+		if v, ok := filter[name].(string); ok && v != "" {
+			*dst = &v
+		}
+	}
+	if statuses, ok := filter["statuses"].([]any); ok {
+		for _, s := range statuses {
+			req.Statuses = append(req.Statuses, strings.ToLower(s.(string)))
+		}
+	}
+
+	if err := eventHandler.ValidateDateFilters(req); err != nil {
+		return nil, err
+	}
+
+	// Без прав модератора видны только опубликованные события
+	if !isModerator(ctx) {
+		for _, s := range req.Statuses {
+			if s != statusPublished {
+				return nil, status.Error(codes.PermissionDenied, "status filter requires moderator rights")
+			}
+		}
+		req.Statuses = []string{statusPublished}
+	}
+	return req, nil
+}
+
+// listEvents выполняет ListEvents и возвращает страницу событий на языке запроса
+func (h *graphqlHandler) listEvents(ctx context.Context, req *eventHandler.ListEventsReq) (*eventPage, error) {
+	includeCount := true
+	req.IncludeCount = &includeCount
+
+	res, err := h.eventClient.ListEvents(ctx, eventHandler.HTTPListReqToProtoListReq(req))
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Failed to list events via gRPC", "error", err)
+		return nil, err
+	}
+
+	list := eventHandler.ProtoListResToHTTPListRes(res, nil)
+	for _, event := range list.Events {
+		eventHandler.LocalizeEvent(ctx, event)
+	}
+
+	page := &eventPage{
+		Items:  list.Events,
+		Limit:  valueOrZero(req.Limit),
+		Offset: valueOrZero(req.Offset),
+	}
+	if list.Pagination != nil {
+		page.TotalCount = list.Pagination.TotalCount
+		page.HasMore = list.Pagination.HasMore
+	} else {
+		page.TotalCount = int64(page.Offset) + int64(len(page.Items))
+	}
+	return page, nil
+}
+
+// expandCategoryIDs добавляет к фильтру все подкатегории category_ids
+func (h *graphqlHandler) expandCategoryIDs(ctx context.Context, req *eventHandler.ListEventsReq) error {
+	all, err := h.allCategories(ctx, loadersFrom(ctx))
+	if err != nil {
+		return err
+	}
+	children := make(map[int64][]int64)
+	for _, category := range all {
+		if category.ParentID != nil {
+			children[int64(*category.ParentID)] = append(children[int64(*category.ParentID)], int64(category.Id))
+		}
+	}
+
+	expanded := slices.Clone(req.CategoryIDs)
+	seen := make(map[int64]bool)
+	queue := slices.Clone(req.CategoryIDs)
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, child := range children[current] {
+			if !seen[child] {
+				seen[child] = true
+				expanded = append(expanded, child)
+				queue = append(queue, child)
+			}
+		}
+	}
+	slices.Sort(expanded)
+	req.CategoryIDs = slices.Compact(expanded)
+	return nil
+}
+
+// submitterUserID извлекает ID пользователя из отправителя "user:<id>"
+func submitterUserID(submittedBy string) (int64, bool) {
+	raw, ok := strings.CutPrefix(submittedBy, "user:")
+	if !ok {
+		return 0, false
+	}
+	id, err := strconv.ParseInt(raw, 10, 64)
+	return id, err == nil && id > 0
+}
+
+// limitArg проверяет аргумент limit
+func limitArg(args map[string]any) (int32, error) {
+	limit, _ := args["limit"].(int)
+	if limit < 1 || limit > maxPageLimit {
+		return 0, fmt.Errorf("invalid limit %q: must be between 1 and %d", strconv.Itoa(limit), maxPageLimit)
+	}
+	return int32(limit), nil
+}
+
+// limitCost множитель сложности списков: размер страницы
+func limitCost(args map[string]any) int {
+	limit, _ := args["limit"].(int)
+	return max(limit, 1)
+}
+
+// parseID разбирает положительный целочисленный ID
+func parseID(raw any) (int64, error) {
+	s, _ := raw.(string)
+	id, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid id format: %v", raw)
+	}
+	if id <= 0 {
+		return 0, fmt.Errorf("id must be a positive integer, got %d", id)
+	}
+	return id, nil
+}
+
+// source оборачивает функцию поля от родительского значения в резолвер
+func source[T any](fn func(T) any) graphql.ResolveFunc {
+	return func(p graphql.ResolveParams) (any, error) {
+		return fn(p.Source.(T)), nil
+	}
+}
+
+// translations возвращает переводы, упорядоченные по языку
+func translations(m map[string]eventHandler.Translation) []translation {
+	result := make([]translation, 0, len(m))
+	for lang, t := range m {
+		result = append(result, translation{Lang: lang, Translation: t})
+	}
+	slices.SortFunc(result, func(a, b translation) int { return strings.Compare(a.Lang, b.Lang) })
+	return result
+}
+
+// optional возвращает nil вместо пустой строки
+func optional(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func optionalFloat(v *float64) any {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+func optionalTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return formatTime(*t)
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// valueOrZero возвращает значение указателя или нулевое значение типа для nil
+func valueOrZero[T any](p *T) T {
+	var zero T
+	if p == nil {
+		return zero
+	}
+	return *p
+}
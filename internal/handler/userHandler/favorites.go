@@ -0,0 +1,156 @@
+package userhandler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+
+	pbEvent "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/event"
+	pbFavorite "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/favorite"
+	"github.com/rx3lixir/gateway-service/internal/handler/eventHandler"
+	contextkeys "github.com/rx3lixir/gateway-service/pkg/context"
+	"github.com/rx3lixir/gateway-service/pkg/token"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// defaultPageLimit и maxPageLimit ограничивают страницу избранного и регистраций
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+
+	// eventsConcurrency сколько событий загружается одновременно
+	eventsConcurrency = 8
+)
+
+// listMyFavorites возвращает избранные события текущего пользователя, новые первыми.
+//
+// Хранилище избранного возвращает только ID, сами события загружаются через
+// GetEvent параллельно. Удаленные и недоступные пользователю (снятые с
+// публикации) события пропускаются.
+func (h *userHandler) listMyFavorites(w http.ResponseWriter, r *http.Request) error {
+	if h.favorites == nil || h.eventClient == nil {
+		return status.Error(codes.Unimplemented, "favorites are not configured")
+	}
+
+	claims, ok := r.Context().Value(contextkeys.AuthKey).(*token.UserClaims)
+	if !ok || claims == nil {
+		h.logger.WarnContext(r.Context(), "No auth claims found in context")
+		return status.Error(codes.Unauthenticated, "authorization required")
+	}
+
+	limit, offset, err := parsePage(r)
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Invalid favorites pagination", "error", err)
+		return err
+	}
+
+	grpcCtx, cancel := h.createContext(r)
+	defer cancel()
+
+	list, err := h.favorites.ListFavorites(grpcCtx, &pbFavorite.ListFavoritesReq{
+		UserId: int64(claims.Id),
+		Limit:  int32(limit),
+		Offset: int32(offset),
+	})
+	if err != nil {
+		h.logger.ErrorContext(grpcCtx, "Failed to list favorites via gRPC", "user_id", claims.Id, "error", err)
+		return err
+	}
+
+	favorites := list.GetFavorites()
+	ids := make([]int64, len(favorites))
+	for i, favorite := range favorites {
+		ids[i] = favorite.GetEventId()
+	}
+	events, errs := h.getEvents(grpcCtx, ids)
+
+	// Сохраняем порядок избранного, пропуская удаленные и скрытые события
+	result := make([]*eventHandler.Event, 0, len(favorites))
+	for i, event := range events {
+		if err := errs[i]; err != nil {
+			if status.Code(err) == codes.NotFound {
+				h.logger.DebugContext(grpcCtx, "Skipping deleted favorite event", "event_id", favorites[i].GetEventId())
+				continue
+			}
+			h.logger.ErrorContext(grpcCtx, "Failed to get favorite event via gRPC",
+				"event_id", favorites[i].GetEventId(),
+				"user_id", claims.Id,
+				"error", err)
+			return err
+		}
+		if !eventHandler.CanView(r.Context(), event) {
+			h.logger.DebugContext(grpcCtx, "Skipping hidden favorite event", "event_id", favorites[i].GetEventId())
+			continue
+		}
+		isFavorite := true
+		event.IsFavorite = &isFavorite
+		result = append(result, event)
+	}
+
+	h.logger.InfoContext(grpcCtx, "Listed favorite events",
+		"user_id", claims.Id,
+		"total", list.GetTotal(),
+		"returned", len(result))
+
+	eventHandler.HideModerationDetails(r.Context(), result...)
+	if lang := eventHandler.LocalizeEvents(r, result...); lang != "" {
+		w.Header().Set("Content-Language", lang)
+	}
+
+	return WriteJSON(w, http.StatusOK, &FavoritesRes{
+		Events: result,
+		Pagination: &eventHandler.PaginationMeta{
+			TotalCount: int64(list.GetTotal()),
+			Limit:      int32(limit),
+			Offset:     int32(offset),
+			HasMore:    offset+len(favorites) < int(list.GetTotal()),
+		},
+	})
+}
+
+// getEvents параллельно загружает события по ID; errs[i] — ошибка загрузки ids[i]
+func (h *userHandler) getEvents(ctx context.Context, ids []int64) (events []*eventHandler.Event, errs []error) {
+	events = make([]*eventHandler.Event, len(ids))
+	errs = make([]error, len(ids))
+	sem := make(chan struct{}, eventsConcurrency)
+	var wg sync.WaitGroup
+
+	for i, id := range ids {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			res, err := h.eventClient.GetEvent(ctx, &pbEvent.GetEventReq{Id: id})
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			events[i] = eventHandler.ProtoEventResToHTTPEvent(res)
+		}()
+	}
+	wg.Wait()
+	return events, errs
+}
+
+// parsePage разбирает limit и offset страницы избранного или регистраций
+func parsePage(r *http.Request) (limit, offset int, err error) {
+	limit = defaultPageLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit <= 0 || limit > maxPageLimit {
+			return 0, 0, fmt.Errorf("invalid limit %q: must be between 1 and %d", raw, maxPageLimit)
+		}
+	}
+	if raw := r.URL.Query().Get("offset"); raw != "" {
+		offset, err = strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			return 0, 0, fmt.Errorf("invalid offset %q: must be a non-negative integer", raw)
+		}
+	}
+	return limit, offset, nil
+}
@@ -8,12 +8,17 @@ import (
 	"time"
 
 	pbAuth "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/auth"
+	pbEvent "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/event"
+	pbFavorite "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/favorite"
+	pbRsvp "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/rsvp"
 	pbUser "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/user"
 
 	contextkeys "github.com/rx3lixir/gateway-service/pkg/context"
 	"github.com/rx3lixir/gateway-service/pkg/logger"
 	"github.com/rx3lixir/gateway-service/pkg/password"
+	"github.com/rx3lixir/gateway-service/pkg/savedsearch"
 	"github.com/rx3lixir/gateway-service/pkg/token"
+	"github.com/rx3lixir/gateway-service/pkg/webhook"
 )
 
 type userHandler struct {
@@ -21,15 +26,29 @@ type userHandler struct {
 	authClient pbAuth.AuthServiceClient
 	tokenMaker *token.JWTMaker
 	logger     logger.Logger
+	webhooks   webhook.Publisher
+
+	favorites   pbFavorite.FavoriteServiceClient
+	rsvp        pbRsvp.RsvpServiceClient
+	eventClient pbEvent.EventServiceClient
+
+	savedSearches    savedsearch.Store
+	maxSavedSearches int
 }
 
-func NewUserHandler(userClient pbUser.UserServiceClient, authClient pbAuth.AuthServiceClient, secretKey string, log logger.Logger) *userHandler {
-	return &userHandler{
+func NewUserHandler(userClient pbUser.UserServiceClient, authClient pbAuth.AuthServiceClient, secretKey string, log logger.Logger, opts ...Option) *userHandler {
+	h := &userHandler{
 		userClient: userClient,
 		authClient: authClient,
 		tokenMaker: token.NewJWTMaker(secretKey),
 		logger:     log,
 	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
 }
 
 // createUser создает новго пользователя
@@ -76,6 +95,14 @@ func (h *userHandler) createUser(w http.ResponseWriter, r *http.Request) error {
 		return err
 	}
 
+	// Уведомляем подписчиков вебхуков
+	if h.webhooks != nil {
+		hook := webhook.Event{Type: webhook.UserCreated, Data: toUserWebhookData(createdUser)}
+		if err := h.webhooks.Publish(r.Context(), hook); err != nil {
+			h.logger.WarnContext(r.Context(), "Failed to enqueue user webhook", "email", createdUser.Email, "error", err)
+		}
+	}
+
 	// Конвертируем созданного пользователя из proto в ответ
 	res := toUserRes(createdUser)
 
@@ -5,6 +5,8 @@ import (
 	"google.golang.org/grpc/codes"  // Для кодов gRPC ошибо
 	"google.golang.org/grpc/status" // Для обработки gRPC ошибок
 
+	"github.com/rx3lixir/gateway-service/pkg/i18n"
+
 	"context"
 	"encoding/json"
 	"fmt"
@@ -89,12 +91,16 @@ func (h *userHandler) makeHTTPHandlerFunc(f apiFunc) http.HandlerFunc {
 					httpStatus = http.StatusUnauthorized
 				case codes.PermissionDenied:
 					httpStatus = http.StatusForbidden
+				case codes.ResourceExhausted:
+					httpStatus = http.StatusTooManyRequests
+				case codes.Unimplemented:
+					httpStatus = http.StatusNotImplemented
 				// Добавьте другие коды gRPC по мере необходимости
 				default:
 					h.logger.Error("Unhandled gRPC error", "code", st.Code(), "message", st.Message(), "path", r.URL.Path)
 					httpStatus = http.StatusInternalServerError
 				}
-				WriteJSON(w, httpStatus, APIError{Error: st.Message()})
+				WriteJSON(w, httpStatus, APIError{Error: i18n.Localize(r.Context(), st.Message())})
 				return
 			}
 
@@ -103,18 +109,18 @@ func (h *userHandler) makeHTTPHandlerFunc(f apiFunc) http.HandlerFunc {
 			// Проверка на "is required" или "invalid"
 			errStr := strings.ToLower(err.Error())
 			if strings.Contains(errStr, "required") || strings.Contains(errStr, "invalid") || strings.Contains(errStr, "format") || strings.Contains(errStr, "positive integer") {
-				WriteJSON(w, http.StatusBadRequest, APIError{Error: err.Error()})
+				WriteJSON(w, http.StatusBadRequest, APIError{Error: i18n.Localize(r.Context(), err.Error())})
 				return
 			}
 
 			// Если ошибка содержит "not found" (из старого кода, но лучше полагаться на gRPC codes.NotFound)
 			if strings.Contains(errStr, "not found") {
-				WriteJSON(w, http.StatusNotFound, APIError{Error: err.Error()})
+				WriteJSON(w, http.StatusNotFound, APIError{Error: i18n.Localize(r.Context(), err.Error())})
 				return
 			}
 
 			h.logger.Error("HTTP handler error", "error", err, "path", r.URL.Path)
-			WriteJSON(w, http.StatusInternalServerError, APIError{Error: "An unexpected error occurred"})
+			WriteJSON(w, http.StatusInternalServerError, APIError{Error: i18n.Localize(r.Context(), "An unexpected error occurred")})
 		}
 	}
 }
@@ -20,3 +20,13 @@ func toUserRes(u *pbUser.UserRes) UserRes {
 		IsAdmin: u.IsAdmin,
 	}
 }
+
+func toUserWebhookData(u *pbUser.UserRes) UserWebhookData {
+	return UserWebhookData{
+		ID:        u.GetId(),
+		Name:      u.GetName(),
+		Email:     u.GetEmail(),
+		IsAdmin:   u.GetIsAdmin(),
+		CreatedAt: u.GetCreatedAt().AsTime(),
+	}
+}
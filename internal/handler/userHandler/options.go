@@ -0,0 +1,44 @@
+package userhandler
+
+import (
+	pbEvent "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/event"
+	pbFavorite "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/favorite"
+	pbRsvp "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/rsvp"
+	"github.com/rx3lixir/gateway-service/pkg/savedsearch"
+	"github.com/rx3lixir/gateway-service/pkg/webhook"
+)
+
+// Option функция для настройки userHandler
+type Option func(*userHandler)
+
+// WithWebhooks включает рассылку вебхука user.created
+func WithWebhooks(publisher webhook.Publisher) Option {
+	return func(h *userHandler) {
+		h.webhooks = publisher
+	}
+}
+
+// WithFavorites включает /users/me/favorites. События загружаются через eventClient.
+func WithFavorites(favorites pbFavorite.FavoriteServiceClient, eventClient pbEvent.EventServiceClient) Option {
+	return func(h *userHandler) {
+		h.favorites = favorites
+		h.eventClient = eventClient
+	}
+}
+
+// WithRsvps включает /users/me/rsvps. События загружаются через eventClient.
+func WithRsvps(rsvp pbRsvp.RsvpServiceClient, eventClient pbEvent.EventServiceClient) Option {
+	return func(h *userHandler) {
+		h.rsvp = rsvp
+		h.eventClient = eventClient
+	}
+}
+
+// WithSavedSearches включает /users/me/saved-searches: не больше maxPerUser
+// поисков на пользователя (0 — без ограничения)
+func WithSavedSearches(store savedsearch.Store, maxPerUser int) Option {
+	return func(h *userHandler) {
+		h.savedSearches = store
+		h.maxSavedSearches = maxPerUser
+	}
+}
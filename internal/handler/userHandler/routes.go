@@ -27,6 +27,15 @@ func RegisterRoutes(u *userHandler) *chi.Mux {
 		// Защищенные эндпоинты (нужна аутентификация)
 		r.Group(func(r chi.Router) {
 			r.Use(middleware.RequireAuth(middlewareConfig, false))
+			r.Get("/me/favorites", u.makeHTTPHandlerFunc(u.listMyFavorites))
+			r.Get("/me/rsvps", u.makeHTTPHandlerFunc(u.listMyRsvps))
+
+			// Сохраненные поиски с оповещениями о новых событиях
+			r.Get("/me/saved-searches", u.makeHTTPHandlerFunc(u.listMySavedSearches))
+			r.Post("/me/saved-searches", u.makeHTTPHandlerFunc(u.createMySavedSearch))
+			r.Get("/me/saved-searches/{searchID}", u.makeHTTPHandlerFunc(u.getMySavedSearch))
+			r.Put("/me/saved-searches/{searchID}", u.makeHTTPHandlerFunc(u.updateMySavedSearch))
+			r.Delete("/me/saved-searches/{searchID}", u.makeHTTPHandlerFunc(u.deleteMySavedSearch))
 			r.Get("/{id}", u.makeHTTPHandlerFunc(u.getUser))
 			r.Put("/{id}", u.makeHTTPHandlerFunc(u.updateUser))
 		})
@@ -0,0 +1,94 @@
+package userhandler
+
+import (
+	"net/http"
+
+	pbRsvp "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/rsvp"
+	"github.com/rx3lixir/gateway-service/internal/handler/eventHandler"
+	contextkeys "github.com/rx3lixir/gateway-service/pkg/context"
+	"github.com/rx3lixir/gateway-service/pkg/token"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// listMyRsvps возвращает регистрации текущего пользователя вместе с событиями, новые первыми.
+// События удаленные после регистрации пропускаются.
+func (h *userHandler) listMyRsvps(w http.ResponseWriter, r *http.Request) error {
+	if h.rsvp == nil || h.eventClient == nil {
+		return status.Error(codes.Unimplemented, "rsvp is not configured")
+	}
+
+	claims, ok := r.Context().Value(contextkeys.AuthKey).(*token.UserClaims)
+	if !ok || claims == nil {
+		h.logger.WarnContext(r.Context(), "No auth claims found in context")
+		return status.Error(codes.Unauthenticated, "authorization required")
+	}
+
+	limit, offset, err := parsePage(r)
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Invalid rsvps pagination", "error", err)
+		return err
+	}
+
+	grpcCtx, cancel := h.createContext(r)
+	defer cancel()
+
+	list, err := h.rsvp.ListUserRsvps(grpcCtx, &pbRsvp.ListUserRsvpsReq{
+		UserId: int64(claims.Id),
+		Limit:  int32(limit),
+		Offset: int32(offset),
+	})
+	if err != nil {
+		h.logger.ErrorContext(grpcCtx, "Failed to list rsvps via gRPC", "user_id", claims.Id, "error", err)
+		return err
+	}
+
+	rsvps := list.GetRsvps()
+	ids := make([]int64, len(rsvps))
+	for i, rsvp := range rsvps {
+		ids[i] = rsvp.GetEventId()
+	}
+	events, errs := h.getEvents(grpcCtx, ids)
+
+	result := make([]*MyRsvp, 0, len(rsvps))
+	httpEvents := make([]*eventHandler.Event, 0, len(rsvps))
+	for i, event := range events {
+		if err := errs[i]; err != nil {
+			if status.Code(err) == codes.NotFound {
+				h.logger.DebugContext(grpcCtx, "Skipping deleted rsvp event", "event_id", ids[i])
+				continue
+			}
+			h.logger.ErrorContext(grpcCtx, "Failed to get rsvp event via gRPC",
+				"event_id", ids[i],
+				"user_id", claims.Id,
+				"error", err)
+			return err
+		}
+		if !eventHandler.CanView(r.Context(), event) {
+			h.logger.DebugContext(grpcCtx, "Skipping hidden rsvp event", "event_id", ids[i])
+			continue
+		}
+		result = append(result, &MyRsvp{RSVP: eventHandler.ProtoRsvpResToHTTP(rsvps[i]), Event: event})
+		httpEvents = append(httpEvents, event)
+	}
+
+	h.logger.InfoContext(grpcCtx, "Listed rsvps",
+		"user_id", claims.Id,
+		"total", list.GetTotal(),
+		"returned", len(result))
+
+	eventHandler.HideModerationDetails(r.Context(), httpEvents...)
+	if lang := eventHandler.LocalizeEvents(r, httpEvents...); lang != "" {
+		w.Header().Set("Content-Language", lang)
+	}
+
+	return WriteJSON(w, http.StatusOK, &RsvpsRes{
+		Rsvps: result,
+		Pagination: &eventHandler.PaginationMeta{
+			TotalCount: int64(list.GetTotal()),
+			Limit:      int32(limit),
+			Offset:     int32(offset),
+			HasMore:    offset+len(rsvps) < int(list.GetTotal()),
+		},
+	})
+}
@@ -0,0 +1,220 @@
+package userhandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/rx3lixir/gateway-service/internal/handler/eventHandler"
+	contextkeys "github.com/rx3lixir/gateway-service/pkg/context"
+	"github.com/rx3lixir/gateway-service/pkg/savedsearch"
+	"github.com/rx3lixir/gateway-service/pkg/token"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxSavedSearchNameLength ограничивает длину названия сохраненного поиска
+const maxSavedSearchNameLength = 100
+
+// savedSearchesUserID проверяет, что сохраненные поиски настроены, и возвращает ID пользователя
+func (h *userHandler) savedSearchesUserID(r *http.Request) (int64, error) {
+	if h.savedSearches == nil {
+		return 0, status.Error(codes.Unimplemented, "saved searches are not configured")
+	}
+	claims, ok := r.Context().Value(contextkeys.AuthKey).(*token.UserClaims)
+	if !ok || claims == nil {
+		h.logger.WarnContext(r.Context(), "No auth claims found in context")
+		return 0, status.Error(codes.Unauthenticated, "authorization required")
+	}
+	return int64(claims.Id), nil
+}
+
+// decodeSavedSearchReq разбирает и проверяет тело создания или изменения поиска
+func decodeSavedSearchReq(r *http.Request) (*SavedSearchReq, error) {
+	var req SavedSearchReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, fmt.Errorf("invalid request body: %w", err)
+	}
+	defer r.Body.Close()
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return nil, errors.New("saved search name is required")
+	}
+	if len([]rune(req.Name)) > maxSavedSearchNameLength {
+		return nil, fmt.Errorf("invalid name: must be at most %d characters", maxSavedSearchNameLength)
+	}
+
+	filters, err := eventHandler.NormalizeSavedSearchFilters(req.Filters)
+	if err != nil {
+		return nil, err
+	}
+	req.Filters = filters
+	return &req, nil
+}
+
+// savedSearchNotFoundOr превращает savedsearch.ErrNotFound в ошибку 404
+func savedSearchNotFoundOr(err error, id string) error {
+	if errors.Is(err, savedsearch.ErrNotFound) {
+		return status.Errorf(codes.NotFound, "saved search %s not found", id)
+	}
+	return err
+}
+
+// listMySavedSearches возвращает сохраненные поиски текущего пользователя
+func (h *userHandler) listMySavedSearches(w http.ResponseWriter, r *http.Request) error {
+	userID, err := h.savedSearchesUserID(r)
+	if err != nil {
+		return err
+	}
+
+	searches, err := h.savedSearches.List(r.Context(), userID)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "Failed to list saved searches", "user_id", userID, "error", err)
+		return err
+	}
+
+	result := make([]*SavedSearchRes, 0, len(searches))
+	for _, search := range searches {
+		result = append(result, toSavedSearchRes(search))
+	}
+	return WriteJSON(w, http.StatusOK, &SavedSearchesRes{SavedSearches: result})
+}
+
+// createMySavedSearch сохраняет поиск. Оповещения приходят только о событиях,
+// созданных после сохранения.
+func (h *userHandler) createMySavedSearch(w http.ResponseWriter, r *http.Request) error {
+	userID, err := h.savedSearchesUserID(r)
+	if err != nil {
+		return err
+	}
+
+	req, err := decodeSavedSearchReq(r)
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Invalid saved search request", "error", err)
+		return err
+	}
+
+	existing, err := h.savedSearches.List(r.Context(), userID)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "Failed to list saved searches", "user_id", userID, "error", err)
+		return err
+	}
+	if h.maxSavedSearches > 0 && len(existing) >= h.maxSavedSearches {
+		return status.Errorf(codes.ResourceExhausted, "saved search limit reached: at most %d per user", h.maxSavedSearches)
+	}
+
+	now := time.Now().UTC()
+	search := &savedsearch.SavedSearch{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		Name:      req.Name,
+		Filters:   req.Filters,
+		Alerts:    req.Alerts == nil || *req.Alerts,
+		Watermark: now,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := h.savedSearches.Create(r.Context(), search); err != nil {
+		h.logger.ErrorContext(r.Context(), "Failed to create saved search", "user_id", userID, "error", err)
+		return err
+	}
+
+	h.logger.InfoContext(r.Context(), "Saved search created", "user_id", userID, "saved_search_id", search.ID)
+
+	return WriteJSON(w, http.StatusCreated, toSavedSearchRes(search))
+}
+
+// getMySavedSearch возвращает сохраненный поиск текущего пользователя
+func (h *userHandler) getMySavedSearch(w http.ResponseWriter, r *http.Request) error {
+	userID, err := h.savedSearchesUserID(r)
+	if err != nil {
+		return err
+	}
+
+	id := chi.URLParam(r, "searchID")
+	search, err := h.savedSearches.Get(r.Context(), userID, id)
+	if err != nil {
+		return savedSearchNotFoundOr(err, id)
+	}
+	return WriteJSON(w, http.StatusOK, toSavedSearchRes(search))
+}
+
+// updateMySavedSearch заменяет название, фильтры и настройку оповещений.
+// При изменении фильтров водяной знак сдвигается на текущий момент, чтобы
+// не оповещать о старых событиях, подошедших только под новые фильтры.
+func (h *userHandler) updateMySavedSearch(w http.ResponseWriter, r *http.Request) error {
+	userID, err := h.savedSearchesUserID(r)
+	if err != nil {
+		return err
+	}
+
+	req, err := decodeSavedSearchReq(r)
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "Invalid saved search request", "error", err)
+		return err
+	}
+
+	id := chi.URLParam(r, "searchID")
+	search, err := h.savedSearches.Get(r.Context(), userID, id)
+	if err != nil {
+		return savedSearchNotFoundOr(err, id)
+	}
+
+	now := time.Now().UTC()
+	if !bytes.Equal(search.Filters, req.Filters) || (req.Alerts != nil && *req.Alerts && !search.Alerts) {
+		search.Watermark = now
+	}
+	search.Name = req.Name
+	search.Filters = req.Filters
+	if req.Alerts != nil {
+		search.Alerts = *req.Alerts
+	}
+	search.UpdatedAt = now
+
+	if err := h.savedSearches.Update(r.Context(), search); err != nil {
+		h.logger.ErrorContext(r.Context(), "Failed to update saved search", "saved_search_id", id, "error", err)
+		return savedSearchNotFoundOr(err, id)
+	}
+
+	h.logger.InfoContext(r.Context(), "Saved search updated", "user_id", userID, "saved_search_id", id)
+
+	return WriteJSON(w, http.StatusOK, toSavedSearchRes(search))
+}
+
+// deleteMySavedSearch удаляет сохраненный поиск текущего пользователя
+func (h *userHandler) deleteMySavedSearch(w http.ResponseWriter, r *http.Request) error {
+	userID, err := h.savedSearchesUserID(r)
+	if err != nil {
+		return err
+	}
+
+	id := chi.URLParam(r, "searchID")
+	if err := h.savedSearches.Delete(r.Context(), userID, id); err != nil {
+		return savedSearchNotFoundOr(err, id)
+	}
+
+	h.logger.InfoContext(r.Context(), "Saved search deleted", "user_id", userID, "saved_search_id", id)
+
+	return WriteJSON(w, http.StatusNoContent, nil)
+}
+
+// toSavedSearchRes конвертирует сохраненный поиск в HTTP ответ
+func toSavedSearchRes(search *savedsearch.SavedSearch) *SavedSearchRes {
+	return &SavedSearchRes{
+		ID:             search.ID,
+		Name:           search.Name,
+		Filters:        search.Filters,
+		Alerts:         search.Alerts,
+		LastCheckedAt:  search.LastCheckedAt,
+		LastNotifiedAt: search.LastNotifiedAt,
+		CreatedAt:      search.CreatedAt,
+		UpdatedAt:      search.UpdatedAt,
+	}
+}
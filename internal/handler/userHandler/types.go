@@ -1,6 +1,11 @@
 package userhandler
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/rx3lixir/gateway-service/internal/handler/eventHandler"
+)
 
 type UserReq struct {
 	Name     string `json:"name"`
@@ -15,6 +20,57 @@ type UserRes struct {
 	IsAdmin bool   `json:"is_admin"`
 }
 
+// UserWebhookData данные пользователя в вебхуке user.created (без пароля)
+type UserWebhookData struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	IsAdmin   bool      `json:"is_admin"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// FavoritesRes страница избранных событий пользователя
+type FavoritesRes struct {
+	Events     []*eventHandler.Event        `json:"events"`
+	Pagination *eventHandler.PaginationMeta `json:"pagination"`
+}
+
+// RsvpsRes регистрации пользователя с событиями
+type RsvpsRes struct {
+	Rsvps      []*MyRsvp                    `json:"rsvps"`
+	Pagination *eventHandler.PaginationMeta `json:"pagination"`
+}
+
+// MyRsvp регистрация пользователя вместе с событием
+type MyRsvp struct {
+	RSVP  *eventHandler.RsvpRes `json:"rsvp"`
+	Event *eventHandler.Event   `json:"event"`
+}
+
+// SavedSearchReq тело создания и изменения сохраненного поиска
+type SavedSearchReq struct {
+	Name    string          `json:"name"`
+	Filters json.RawMessage `json:"filters"`          // Фильтры GET /events (ListEventsReq)
+	Alerts  *bool           `json:"alerts,omitempty"` // По умолчанию true
+}
+
+// SavedSearchRes сохраненный поиск
+type SavedSearchRes struct {
+	ID             string          `json:"id"`
+	Name           string          `json:"name"`
+	Filters        json.RawMessage `json:"filters"`
+	Alerts         bool            `json:"alerts"`
+	LastCheckedAt  *time.Time      `json:"last_checked_at,omitempty"`
+	LastNotifiedAt *time.Time      `json:"last_notified_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+// SavedSearchesRes сохраненные поиски пользователя в порядке создания
+type SavedSearchesRes struct {
+	SavedSearches []*SavedSearchRes `json:"saved_searches"`
+}
+
 type ListUserRes struct {
 	Users []UserRes `json:"users"`
 }
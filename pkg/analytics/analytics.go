@@ -0,0 +1,84 @@
+// Package analytics поисковая аналитика: запросы, поиски без результатов и переходы из выдачи.
+//
+// Записи пишутся асинхронно через Recorder в Sink (JSONL файл, stdout, очередь),
+// MemoryStats агрегирует их для отчетов администратора.
+package analytics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+// Типы записей
+const (
+	TypeSearch     = "search"     // Поиск событий (search_text)
+	TypeSuggestion = "suggestion" // Запрос подсказок (q)
+	TypeClick      = "click"      // Переход из выдачи к событию
+)
+
+// Источники поиска
+const (
+	SourceEvents      = "events"      // GET /events
+	SourceSearch      = "search"      // POST /events/search
+	SourceSuggestions = "suggestions" // GET /events/suggestions
+)
+
+// Sources все источники поиска
+var Sources = []string{SourceEvents, SourceSearch, SourceSuggestions}
+
+// Record запись поисковой аналитики
+type Record struct {
+	Type       string    `json:"type"`
+	Source     string    `json:"source,omitempty"`
+	Query      string    `json:"query"`
+	Results    int       `json:"results"`              // Найдено событий или подсказок
+	Offset     int       `json:"offset,omitempty"`     // Смещение страницы; > 0 — листание, а не новый поиск
+	LatencyMs  int64     `json:"latency_ms,omitempty"` // Время ответа gateway
+	EventID    int64     `json:"event_id,omitempty"`   // Для переходов
+	Position   int       `json:"position,omitempty"`   // Позиция события в выдаче, с 1
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// Sink получатель записей: файл, stdout, очередь. Write вызывается из одной горутины Recorder.
+type Sink interface {
+	Write(ctx context.Context, records []Record) error
+}
+
+// NormalizeQuery приводит запрос к виду для агрегации: нижний регистр, одиночные пробелы
+func NormalizeQuery(query string) string {
+	return strings.Join(strings.Fields(strings.ToLower(query)), " ")
+}
+
+// multiSink пишет записи во все получатели
+type multiSink []Sink
+
+// MultiSink объединяет получатели: ошибка одного не мешает записи в остальные
+func MultiSink(sinks ...Sink) Sink {
+	return multiSink(sinks)
+}
+
+func (m multiSink) Write(ctx context.Context, records []Record) error {
+	var errs []error
+	for _, sink := range m {
+		if err := sink.Write(ctx, records); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close закрывает получатели, которые это поддерживают
+func (m multiSink) Close() error {
+	var errs []error
+	for _, sink := range m {
+		if closer, ok := sink.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,151 @@
+package analytics
+
+import (
+	"context"
+	"io"
+	"sync/atomic"
+	"time"
+
+	"github.com/rx3lixir/gateway-service/pkg/logger"
+)
+
+// Config параметры записи аналитики
+type Config struct {
+	Buffer        int           // Очередь записей; при переполнении записи отбрасываются
+	BatchSize     int           // Записей в одной пачке для Sink
+	FlushInterval time.Duration // Как часто сбрасывать неполную пачку
+	WriteTimeout  time.Duration // Таймаут записи одной пачки
+}
+
+// DefaultConfig параметры записи по умолчанию
+func DefaultConfig() Config {
+	return Config{
+		Buffer:        4096,
+		BatchSize:     256,
+		FlushInterval: 5 * time.Second,
+		WriteTimeout:  10 * time.Second,
+	}
+}
+
+// Recorder принимает записи без блокировки обработчиков запросов
+// и пишет их в Sink пачками из фоновой горутины Run.
+type Recorder struct {
+	sink    Sink
+	config  Config
+	records chan Record
+	dropped atomic.Int64
+	logger  logger.Logger
+	now     func() time.Time
+	done    chan struct{}
+}
+
+// NewRecorder создает Recorder. Нулевые поля config заменяются значениями по умолчанию.
+func NewRecorder(sink Sink, config Config, log logger.Logger) *Recorder {
+	defaults := DefaultConfig()
+	if config.Buffer <= 0 {
+		config.Buffer = defaults.Buffer
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaults.BatchSize
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = defaults.FlushInterval
+	}
+	if config.WriteTimeout <= 0 {
+		config.WriteTimeout = defaults.WriteTimeout
+	}
+
+	return &Recorder{
+		sink:    sink,
+		config:  config,
+		records: make(chan Record, config.Buffer),
+		logger:  log,
+		now:     time.Now,
+		done:    make(chan struct{}),
+	}
+}
+
+// Record ставит запись в очередь. Если очередь заполнена, запись отбрасывается:
+// аналитика не должна замедлять поиск.
+func (r *Recorder) Record(record Record) {
+	if record.OccurredAt.IsZero() {
+		record.OccurredAt = r.now().UTC()
+	}
+	select {
+	case r.records <- record:
+	default:
+		r.dropped.Add(1)
+	}
+}
+
+// Dropped возвращает число записей, отброшенных из-за переполнения очереди
+func (r *Recorder) Dropped() int64 {
+	return r.dropped.Load()
+}
+
+// Run пишет записи в Sink, пока не отменен ctx. После отмены записывает
+// оставшиеся в очереди записи, закрывает Sink и закрывает Done.
+func (r *Recorder) Run(ctx context.Context) {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Record, 0, r.config.BatchSize)
+	for {
+		select {
+		case record := <-r.records:
+			batch = append(batch, record)
+			if len(batch) >= r.config.BatchSize {
+				batch = r.flush(batch)
+			}
+		case <-ticker.C:
+			batch = r.flush(batch)
+		case <-ctx.Done():
+			r.drain(batch)
+			return
+		}
+	}
+}
+
+// drain записывает оставшиеся в очереди записи и закрывает Sink
+func (r *Recorder) drain(batch []Record) {
+	for {
+		select {
+		case record := <-r.records:
+			batch = append(batch, record)
+			if len(batch) >= r.config.BatchSize {
+				batch = r.flush(batch)
+			}
+		default:
+			r.flush(batch)
+			if closer, ok := r.sink.(io.Closer); ok {
+				if err := closer.Close(); err != nil {
+					r.logger.Warn("Failed to close analytics sink", "error", err)
+				}
+			}
+			return
+		}
+	}
+}
+
+// Done закрывается, когда Run записал все записи и завершился
+func (r *Recorder) Done() <-chan struct{} {
+	return r.done
+}
+
+// flush пишет пачку в Sink и возвращает новую пустую пачку.
+// Sink может сохранить переданный срез, поэтому он не переиспользуется.
+func (r *Recorder) flush(batch []Record) []Record {
+	if len(batch) == 0 {
+		return batch
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.config.WriteTimeout)
+	defer cancel()
+
+	if err := r.sink.Write(ctx, batch); err != nil {
+		r.logger.Warn("Failed to write analytics records", "count", len(batch), "error", err)
+	}
+	return make([]Record, 0, r.config.BatchSize)
+}
@@ -0,0 +1,63 @@
+package analytics
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// WriterSink пишет записи в формате JSON Lines: одна запись — одна строка
+type WriterSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriterSink создает получатель, пишущий в w (например, os.Stdout)
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w}
+}
+
+// Write дописывает записи одной пачкой
+func (s *WriterSink) Write(_ context.Context, records []Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	buf := bufio.NewWriter(s.w)
+	encoder := json.NewEncoder(buf)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return fmt.Errorf("failed to encode analytics record: %w", err)
+		}
+	}
+	if err := buf.Flush(); err != nil {
+		return fmt.Errorf("failed to write analytics records: %w", err)
+	}
+	return nil
+}
+
+// Close закрывает w, если он это поддерживает (кроме stdout и stderr)
+func (s *WriterSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.w == os.Stdout || s.w == os.Stderr {
+		return nil
+	}
+	if closer, ok := s.w.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// OpenFileSink открывает JSONL файл на дозапись, создавая его при необходимости
+func OpenFileSink(path string) (*WriterSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open analytics file: %w", err)
+	}
+	return NewWriterSink(file), nil
+}
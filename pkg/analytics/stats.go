@@ -0,0 +1,235 @@
+package analytics
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BucketSize точность окна отчета: записи агрегируются по интервалам этой длины
+const BucketSize = 5 * time.Minute
+
+// MaxQueriesPerBucket ограничивает число разных запросов в одном интервале, чтобы
+// поток случайных запросов не занял всю память. Запросы сверх лимита учитываются
+// в общих счетчиках отчета, но не попадают в списки запросов.
+const MaxQueriesPerBucket = 10000
+
+// otherQueries ключ счетчика запросов сверх MaxQueriesPerBucket. NormalizeQuery
+// не возвращает пустую строку для записей, поэтому ключ не пересекается с запросами.
+const otherQueries = ""
+
+// QueryStats статистика одного запроса (в виде NormalizeQuery)
+type QueryStats struct {
+	Query        string
+	Searches     int64   // Поиски (первые страницы)
+	ZeroResults  int64   // Поиски без результатов
+	Suggestions  int64   // Запросы подсказок
+	Clicks       int64   // Переходы из выдачи
+	AvgResults   float64 // Среднее число результатов поиска
+	AvgLatencyMs float64 // Среднее время ответа на поиск
+}
+
+// ClickThroughRate доля поисков, после которых был переход к событию
+func (s QueryStats) ClickThroughRate() float64 {
+	if s.Searches == 0 {
+		return 0
+	}
+	return float64(s.Clicks) / float64(s.Searches)
+}
+
+// Report отчет поисковой аналитики за окно [From, To)
+type Report struct {
+	From               time.Time
+	To                 time.Time
+	Searches           int64
+	ZeroResultSearches int64
+	Suggestions        int64
+	Clicks             int64
+	TopQueries         []QueryStats // По числу поисков
+	ZeroResultQueries  []QueryStats // По числу поисков без результатов
+}
+
+// Reporter строит отчеты поисковой аналитики
+type Reporter interface {
+	// Report возвращает статистику за последние window, не больше limit запросов в каждом списке
+	Report(ctx context.Context, window time.Duration, limit int) (*Report, error)
+
+	// Retention возвращает, за какой период хранится статистика
+	Retention() time.Duration
+}
+
+// queryCounter накопленная статистика запроса в интервале
+type queryCounter struct {
+	searches       int64
+	zeroResults    int64
+	suggestions    int64
+	clicks         int64
+	totalResults   int64
+	totalLatencyMs int64
+}
+
+func (c *queryCounter) add(other *queryCounter) {
+	c.searches += other.searches
+	c.zeroResults += other.zeroResults
+	c.suggestions += other.suggestions
+	c.clicks += other.clicks
+	c.totalResults += other.totalResults
+	c.totalLatencyMs += other.totalLatencyMs
+}
+
+// MemoryStats агрегирует записи в памяти интервалами по BucketSize и хранит их retention.
+// Реализует Sink и Reporter.
+type MemoryStats struct {
+	mu        sync.Mutex
+	retention time.Duration
+	buckets   map[time.Time]map[string]*queryCounter
+	now       func() time.Time
+}
+
+// NewMemoryStats создает агрегатор, хранящий статистику за retention (не меньше BucketSize)
+func NewMemoryStats(retention time.Duration) *MemoryStats {
+	return &MemoryStats{
+		retention: max(retention, BucketSize),
+		buckets:   make(map[time.Time]map[string]*queryCounter),
+		now:       time.Now,
+	}
+}
+
+// Retention возвращает, за какой период хранится статистика
+func (s *MemoryStats) Retention() time.Duration {
+	return s.retention
+}
+
+// Write добавляет записи в статистику. Листание страниц (Offset > 0) поиском не считается.
+func (s *MemoryStats) Write(_ context.Context, records []Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := s.now().Add(-s.retention).Truncate(BucketSize)
+	for _, record := range records {
+		start := record.OccurredAt.Truncate(BucketSize)
+		if start.Before(cutoff) || (record.Type == TypeSearch && record.Offset > 0) {
+			continue
+		}
+		query := NormalizeQuery(record.Query)
+		if query == "" {
+			continue
+		}
+
+		bucket, ok := s.buckets[start]
+		if !ok {
+			bucket = make(map[string]*queryCounter)
+			s.buckets[start] = bucket
+		}
+		counter, ok := bucket[query]
+		if !ok && len(bucket) >= MaxQueriesPerBucket {
+			query = otherQueries
+			counter, ok = bucket[query]
+		}
+		if !ok {
+			counter = &queryCounter{}
+			bucket[query] = counter
+		}
+
+		switch record.Type {
+		case TypeSearch:
+			counter.searches++
+			counter.totalResults += int64(record.Results)
+			counter.totalLatencyMs += record.LatencyMs
+			if record.Results == 0 {
+				counter.zeroResults++
+			}
+		case TypeSuggestion:
+			counter.suggestions++
+		case TypeClick:
+			counter.clicks++
+		}
+	}
+
+	// Удаляем интервалы старше retention
+	for start := range s.buckets {
+		if start.Before(cutoff) {
+			delete(s.buckets, start)
+		}
+	}
+	return nil
+}
+
+// Report возвращает статистику за последние window (с точностью до BucketSize)
+func (s *MemoryStats) Report(_ context.Context, window time.Duration, limit int) (*Report, error) {
+	now := s.now()
+	from := now.Add(-min(window, s.retention)).Truncate(BucketSize)
+
+	totals := make(map[string]*queryCounter)
+	s.mu.Lock()
+	for start, bucket := range s.buckets {
+		if start.Before(from) {
+			continue
+		}
+		for query, counter := range bucket {
+			total, ok := totals[query]
+			if !ok {
+				total = &queryCounter{}
+				totals[query] = total
+			}
+			total.add(counter)
+		}
+	}
+	s.mu.Unlock()
+
+	report := &Report{From: from.UTC(), To: now.UTC()}
+	var all []QueryStats
+	for query, total := range totals {
+		report.Searches += total.searches
+		report.ZeroResultSearches += total.zeroResults
+		report.Suggestions += total.suggestions
+		report.Clicks += total.clicks
+		if query == otherQueries {
+			continue
+		}
+
+		stats := QueryStats{
+			Query:       query,
+			Searches:    total.searches,
+			ZeroResults: total.zeroResults,
+			Suggestions: total.suggestions,
+			Clicks:      total.clicks,
+		}
+		if total.searches > 0 {
+			stats.AvgResults = float64(total.totalResults) / float64(total.searches)
+			stats.AvgLatencyMs = float64(total.totalLatencyMs) / float64(total.searches)
+		}
+		all = append(all, stats)
+	}
+
+	report.TopQueries = topQueries(all, limit, func(s QueryStats) int64 { return s.Searches })
+	report.ZeroResultQueries = topQueries(all, limit, func(s QueryStats) int64 { return s.ZeroResults })
+	return report, nil
+}
+
+// topQueries возвращает до limit запросов с наибольшим ненулевым count; при равенстве — по алфавиту
+func topQueries(all []QueryStats, limit int, count func(QueryStats) int64) []QueryStats {
+	if limit <= 0 {
+		return nil
+	}
+
+	result := make([]QueryStats, 0, min(len(all), limit))
+	for _, stats := range all {
+		if count(stats) > 0 {
+			result = append(result, stats)
+		}
+	}
+	slices.SortFunc(result, func(a, b QueryStats) int {
+		if c := cmp.Compare(count(b), count(a)); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Query, b.Query)
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
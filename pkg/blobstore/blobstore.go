@@ -0,0 +1,38 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ErrInvalidKey возвращается для ключей, выходящих за пределы хранилища
+var ErrInvalidKey = errors.New("blobstore: invalid key")
+
+// Store хранилище файлов (изображений и т.п.)
+type Store interface {
+	// Put сохраняет объект и возвращает его публичный URL
+	Put(ctx context.Context, key string, data []byte, contentType string) (string, error)
+
+	// Delete удаляет объект; отсутствующий объект не считается ошибкой
+	Delete(ctx context.Context, key string) error
+}
+
+// cleanKey нормализует ключ объекта и запрещает выход за пределы хранилища ("../")
+func cleanKey(key string) (string, error) {
+	if key == "" || strings.Contains(key, "\\") {
+		return "", fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	cleaned := path.Clean("/" + key)[1:]
+	if cleaned == "" || cleaned != strings.TrimPrefix(key, "/") {
+		return "", fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	return cleaned, nil
+}
+
+// joinURL склеивает базовый URL и ключ объекта
+func joinURL(base, key string) string {
+	return strings.TrimRight(base, "/") + "/" + key
+}
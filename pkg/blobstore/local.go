@@ -0,0 +1,90 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// LocalStore хранит объекты в локальной файловой системе.
+// Раздача файлов — через Handler, смонтированный по пути baseURL.
+type LocalStore struct {
+	root    string
+	baseURL string
+}
+
+// NewLocalStore создает хранилище в каталоге root.
+// baseURL — публичный префикс URL объектов, например "/media" или "https://cdn.example.com".
+func NewLocalStore(root, baseURL string) (*LocalStore, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create blob store directory: %w", err)
+	}
+	return &LocalStore{root: root, baseURL: baseURL}, nil
+}
+
+// Put атомарно записывает объект: сначала во временный файл, затем переименовывает
+func (s *LocalStore) Put(_ context.Context, key string, data []byte, _ string) (string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	target := filepath.Join(s.root, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create directory for %s: %w", key, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file for %s: %w", key, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("failed to write %s: %w", key, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", key, err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", key, err)
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return "", fmt.Errorf("failed to store %s: %w", key, err)
+	}
+
+	return joinURL(s.baseURL, key), nil
+}
+
+// Delete удаляет объект
+func (s *LocalStore) Delete(_ context.Context, key string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(filepath.Join(s.root, filepath.FromSlash(key)))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete %s: %w", key, err)
+	}
+	return nil
+}
+
+// Handler раздает сохраненные объекты. Листинг каталогов отключен.
+func (s *LocalStore) Handler() http.Handler {
+	fileServer := http.FileServer(http.Dir(s.root))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "" || r.URL.Path[len(r.URL.Path)-1] == '/' {
+			http.NotFound(w, r)
+			return
+		}
+		// Имена объектов уникальны, поэтому файлы можно кэшировать бессрочно
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		fileServer.ServeHTTP(w, r)
+	})
+}
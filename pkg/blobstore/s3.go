@@ -0,0 +1,182 @@
+package blobstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Config параметры S3-совместимого хранилища (AWS S3, MinIO, Yandex Object Storage и т.п.)
+type S3Config struct {
+	Endpoint  string // Например, https://storage.yandexcloud.net
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+
+	// PublicURL базовый URL для ссылок на объекты (CDN или сам бакет).
+	// По умолчанию — Endpoint/Bucket.
+	PublicURL string
+}
+
+// S3Store хранилище поверх S3 API с подписью запросов AWS Signature V4.
+// Используется path-style адресация, которую поддерживают все S3-совместимые хранилища.
+type S3Store struct {
+	config S3Config
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewS3Store создает S3-совместимое хранилище
+func NewS3Store(config S3Config) (*S3Store, error) {
+	if config.Endpoint == "" || config.Bucket == "" || config.Region == "" {
+		return nil, fmt.Errorf("s3 endpoint, region and bucket are required")
+	}
+	if config.AccessKey == "" || config.SecretKey == "" {
+		return nil, fmt.Errorf("s3 access key and secret key are required")
+	}
+	if config.PublicURL == "" {
+		config.PublicURL = joinURL(config.Endpoint, config.Bucket)
+	}
+
+	return &S3Store{
+		config: config,
+		client: &http.Client{Timeout: 30 * time.Second},
+		now:    time.Now,
+	}, nil
+}
+
+// Put загружает объект (PutObject)
+func (s *S3Store) Put(ctx context.Context, key string, data []byte, contentType string) (string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := s.newRequest(ctx, http.MethodPut, key, data)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Cache-Control", "public, max-age=31536000, immutable")
+
+	if err := s.do(req, data); err != nil {
+		return "", fmt.Errorf("failed to upload %s: %w", key, err)
+	}
+	return joinURL(s.config.PublicURL, key), nil
+}
+
+// Delete удаляет объект (DeleteObject). S3 не возвращает ошибку для отсутствующих объектов.
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+
+	req, err := s.newRequest(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	if err := s.do(req, nil); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *S3Store) newRequest(ctx context.Context, method, key string, body []byte) (*http.Request, error) {
+	endpoint, err := url.Parse(s.config.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid s3 endpoint: %w", err)
+	}
+	endpoint.Path = "/" + s.config.Bucket + "/" + key
+
+	return http.NewRequestWithContext(ctx, method, endpoint.String(), bytes.NewReader(body))
+}
+
+// do подписывает и выполняет запрос
+func (s *S3Store) do(req *http.Request, body []byte) error {
+	s.sign(req, body)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("s3 responded %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// sign добавляет заголовки подписи AWS Signature V4
+func (s *S3Store) sign(req *http.Request, body []byte) {
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	payloadHash := sha256Hex(body)
+	req.Header.Set("Host", req.URL.Host)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	// Канонические заголовки: имена в нижнем регистре, отсортированы
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, strings.ToLower(name))
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(req.Header.Get(name)) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.config.Region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.config.SecretKey), date)
+	key = hmacSHA256(key, s.config.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.config.AccessKey, scope, signedHeaders, signature))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
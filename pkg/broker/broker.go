@@ -0,0 +1,67 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrSlowConsumer подписка закрыта: подписчик не успевал читать сообщения
+	ErrSlowConsumer = errors.New("broker: subscriber is too slow")
+
+	// ErrClosed брокер остановлен
+	ErrClosed = errors.New("broker: closed")
+)
+
+// Message сообщение брокера
+type Message struct {
+	ID   uint64    // Монотонно возрастающий номер, присваивается брокером
+	Type string    // Тип сообщения, например created/updated/deleted
+	Time time.Time // Время публикации
+	Data any       // Полезная нагрузка
+}
+
+// SubscribeOptions параметры подписки
+type SubscribeOptions struct {
+	// AfterID номер последнего полученного сообщения: сообщения после него
+	// из истории брокера будут доставлены сразу после подписки
+	AfterID uint64
+
+	// Buffer размер очереди подписчика. Если очередь переполнена,
+	// подписка закрывается с ErrSlowConsumer.
+	Buffer int
+
+	// Filter отбирает сообщения для подписчика; nil — все сообщения.
+	// Вызывается при каждой публикации и должен быть быстрым.
+	Filter func(Message) bool
+}
+
+// Subscription подписка на сообщения брокера
+type Subscription interface {
+	// Messages канал сообщений; закрывается при завершении подписки
+	Messages() <-chan Message
+
+	// Err причина завершения подписки после закрытия канала Messages
+	Err() error
+
+	// Missed сообщает, что часть сообщений после AfterID уже недоступна
+	// и подписчику нужно заново загрузить состояние
+	Missed() bool
+
+	// Close отменяет подписку
+	Close()
+}
+
+// Broker публикует сообщения и доставляет их подписчикам.
+//
+// Встроенная реализация — MemoryBroker, работающий в пределах одного процесса.
+// Для нескольких экземпляров шлюза достаточно реализовать этот интерфейс
+// поверх Redis Pub/Sub, NATS и т.п.
+type Broker interface {
+	// Publish публикует сообщение и возвращает присвоенный ему номер
+	Publish(ctx context.Context, msg Message) (uint64, error)
+
+	// Subscribe создает подписку
+	Subscribe(ctx context.Context, opts SubscribeOptions) (Subscription, error)
+}
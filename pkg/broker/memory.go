@@ -0,0 +1,170 @@
+package broker
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// defaultBuffer размер очереди подписчика по умолчанию
+const defaultBuffer = 64
+
+// MemoryBroker брокер в памяти процесса.
+// Хранит последние сообщения, чтобы переподключившиеся подписчики могли продолжить с AfterID.
+type MemoryBroker struct {
+	mu      sync.Mutex
+	nextID  uint64
+	history []Message // Кольцевой буфер последних сообщений, по возрастанию ID
+	limit   int
+	subs    map[*memorySubscription]struct{}
+	closed  bool
+}
+
+// NewMemoryBroker создает брокер, хранящий historySize последних сообщений
+func NewMemoryBroker(historySize int) *MemoryBroker {
+	if historySize <= 0 {
+		historySize = 1
+	}
+	return &MemoryBroker{
+		nextID:  1,
+		history: make([]Message, 0, historySize),
+		limit:   historySize,
+		subs:    make(map[*memorySubscription]struct{}),
+	}
+}
+
+// Publish публикует сообщение. Подписчики с переполненной очередью отключаются.
+func (b *MemoryBroker) Publish(_ context.Context, msg Message) (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return 0, ErrClosed
+	}
+
+	msg.ID = b.nextID
+	b.nextID++
+	if msg.Time.IsZero() {
+		msg.Time = time.Now()
+	}
+
+	if len(b.history) == b.limit {
+		copy(b.history, b.history[1:])
+		b.history = b.history[:len(b.history)-1]
+	}
+	b.history = append(b.history, msg)
+
+	for sub := range b.subs {
+		if sub.filter != nil && !sub.filter(msg) {
+			continue
+		}
+		select {
+		case sub.ch <- msg:
+		default:
+			b.unsubscribeLocked(sub, ErrSlowConsumer)
+		}
+	}
+
+	return msg.ID, nil
+}
+
+// Subscribe создает подписку и досылает сообщения из истории после opts.AfterID
+func (b *MemoryBroker) Subscribe(ctx context.Context, opts SubscribeOptions) (Subscription, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, ErrClosed
+	}
+
+	buffer := opts.Buffer
+	if buffer <= 0 {
+		buffer = defaultBuffer
+	}
+
+	var replay []Message
+	missed := false
+	if opts.AfterID > 0 {
+		lastID := b.nextID - 1
+		switch {
+		case opts.AfterID > lastID:
+			// Номер из будущего: брокер перезапускался, история потеряна
+			missed = true
+		case len(b.history) > 0 && b.history[0].ID > opts.AfterID+1:
+			// Часть сообщений уже вытеснена из истории
+			missed = true
+		}
+		for _, msg := range b.history {
+			if msg.ID > opts.AfterID && (opts.Filter == nil || opts.Filter(msg)) {
+				replay = append(replay, msg)
+			}
+		}
+	}
+
+	sub := &memorySubscription{
+		broker: b,
+		ch:     make(chan Message, buffer+len(replay)),
+		filter: opts.Filter,
+		missed: missed,
+	}
+	for _, msg := range replay {
+		sub.ch <- msg
+	}
+	b.subs[sub] = struct{}{}
+
+	return sub, nil
+}
+
+// Close останавливает брокер и завершает все подписки с ErrClosed
+func (b *MemoryBroker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for sub := range b.subs {
+		b.unsubscribeLocked(sub, ErrClosed)
+	}
+}
+
+// Subscribers возвращает число активных подписок
+func (b *MemoryBroker) Subscribers() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs)
+}
+
+func (b *MemoryBroker) unsubscribeLocked(sub *memorySubscription, err error) {
+	if _, ok := b.subs[sub]; !ok {
+		return
+	}
+	delete(b.subs, sub)
+	sub.err = err
+	close(sub.ch)
+}
+
+type memorySubscription struct {
+	broker *MemoryBroker
+	ch     chan Message
+	filter func(Message) bool
+	missed bool
+	err    error // Защищено broker.mu
+}
+
+func (s *memorySubscription) Messages() <-chan Message { return s.ch }
+func (s *memorySubscription) Missed() bool             { return s.missed }
+
+func (s *memorySubscription) Err() error {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	return s.err
+}
+
+func (s *memorySubscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	s.broker.unsubscribeLocked(s, nil)
+}
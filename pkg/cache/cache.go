@@ -0,0 +1,84 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// ErrNotFound возвращается Store.Get, если ключ отсутствует или устарел
+var ErrNotFound = errors.New("cache: key not found")
+
+// Store хранилище закэшированных значений.
+//
+// Встроенная реализация — MemoryStore. Для кэша, общего между несколькими
+// экземплярами шлюза, достаточно реализовать этот интерфейс поверх Redis или Memcached.
+type Store interface {
+	// Get возвращает значение по ключу или ErrNotFound
+	Get(ctx context.Context, key string) ([]byte, error)
+
+	// Set сохраняет значение на время ttl
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+
+	// Delete удаляет ключи; отсутствующие ключи игнорируются
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// Stats метрики кэша
+type Stats struct {
+	Hits          uint64 `json:"hits"`
+	Misses        uint64 `json:"misses"`
+	Shared        uint64 `json:"shared"` // Запросы, дождавшиеся результата уже выполняющегося вызова
+	Invalidations uint64 `json:"invalidations"`
+	Errors        uint64 `json:"errors"`
+}
+
+// HitRatio возвращает долю попаданий в кэш
+func (s Stats) HitRatio() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// Metrics потокобезопасные счетчики кэша
+type Metrics struct {
+	hits          atomic.Uint64
+	misses        atomic.Uint64
+	shared        atomic.Uint64
+	invalidations atomic.Uint64
+	errors        atomic.Uint64
+}
+
+func (m *Metrics) Hit()              { m.hits.Add(1) }
+func (m *Metrics) Miss()             { m.misses.Add(1) }
+func (m *Metrics) SharedCall()       { m.shared.Add(1) }
+func (m *Metrics) Invalidated(n int) { m.invalidations.Add(uint64(n)) }
+func (m *Metrics) Error()            { m.errors.Add(1) }
+
+// Snapshot возвращает текущие значения счетчиков
+func (m *Metrics) Snapshot() Stats {
+	return Stats{
+		Hits:          m.hits.Load(),
+		Misses:        m.misses.Load(),
+		Shared:        m.shared.Load(),
+		Invalidations: m.invalidations.Load(),
+		Errors:        m.errors.Load(),
+	}
+}
+
+type bypassKey struct{}
+
+// Bypass помечает контекст: чтения с ним идут мимо кэша.
+// Используется там, где нужна актуальная версия ресурса (проверка If-Match, PATCH).
+func Bypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassKey{}, true)
+}
+
+// IsBypassed проверяет, помечен ли контекст Bypass
+func IsBypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(bypassKey{}).(bool)
+	return bypass
+}
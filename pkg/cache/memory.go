@@ -0,0 +1,112 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore in-memory хранилище с вытеснением по LRU и временем жизни записей
+type MemoryStore struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List // Начало списка — самые недавно использованные записи
+	now      func() time.Time
+}
+
+type memoryEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewMemoryStore создает in-memory хранилище не более чем на capacity записей
+func NewMemoryStore(capacity int) *MemoryStore {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &MemoryStore{
+		capacity: capacity,
+		items:    make(map[string]*list.Element, capacity),
+		order:    list.New(),
+		now:      time.Now,
+	}
+}
+
+// Get возвращает значение по ключу. Устаревшие записи удаляются при обращении.
+func (s *MemoryStore) Get(_ context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.items[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	entry := elem.Value.(*memoryEntry)
+	if !entry.expiresAt.IsZero() && s.now().After(entry.expiresAt) {
+		s.remove(elem)
+		return nil, ErrNotFound
+	}
+
+	s.order.MoveToFront(elem)
+	return entry.value, nil
+}
+
+// Set сохраняет значение. ttl <= 0 означает запись без срока жизни.
+func (s *MemoryStore) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = s.now().Add(ttl)
+	}
+
+	if elem, ok := s.items[key]; ok {
+		entry := elem.Value.(*memoryEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		s.order.MoveToFront(elem)
+		return nil
+	}
+
+	s.items[key] = s.order.PushFront(&memoryEntry{
+		key:       key,
+		value:     value,
+		expiresAt: expiresAt,
+	})
+
+	for s.order.Len() > s.capacity {
+		s.remove(s.order.Back())
+	}
+
+	return nil
+}
+
+// Delete удаляет ключи
+func (s *MemoryStore) Delete(_ context.Context, keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		if elem, ok := s.items[key]; ok {
+			s.remove(elem)
+		}
+	}
+	return nil
+}
+
+// Len возвращает количество записей, включая еще не удаленные устаревшие
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len()
+}
+
+func (s *MemoryStore) remove(elem *list.Element) {
+	s.order.Remove(elem)
+	delete(s.items, elem.Value.(*memoryEntry).key)
+}
@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Group схлопывает одновременные вызовы с одинаковым ключом в один:
+// первый вызов выполняет функцию, остальные дожидаются его результата.
+type Group struct {
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+type call struct {
+	wg  sync.WaitGroup
+	val any
+	err error
+}
+
+// Do выполняет fn для ключа key, если для него еще нет выполняющегося вызова.
+// shared = true, если результат получен от чужого вызова.
+// Паника в fn повторяется в вызвавшей горутине, ожидающие получают ошибку.
+func (g *Group) Do(key string, fn func() (any, error)) (val any, err error, shared bool) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*call)
+	}
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		c.wg.Wait()
+		return c.val, c.err, true
+	}
+
+	c := &call{}
+	c.wg.Add(1)
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		if g.calls[key] == c {
+			delete(g.calls, key)
+		}
+		g.mu.Unlock()
+		c.wg.Done()
+	}()
+	defer func() {
+		if p := recover(); p != nil {
+			c.val, c.err = nil, fmt.Errorf("cache: call for key %q panicked: %v", key, p)
+			panic(p)
+		}
+	}()
+
+	c.val, c.err = fn()
+	return c.val, c.err, false
+}
+
+// Forget отвязывает ключ от выполняющегося вызова: следующий Do выполнит fn заново.
+// Используется при инвалидации, чтобы не раздавать результат, полученный до изменения.
+func (g *Group) Forget(key string) {
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+}
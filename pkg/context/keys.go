@@ -4,3 +4,13 @@ type AuthContextKey struct{}
 
 // Экспортируемый контекстный ключ
 var AuthKey = AuthContextKey{}
+
+type APIKeyContextKey struct{}
+
+// APIKeyKey контекстный ключ с именем API ключа, которым аутентифицирован запрос
+var APIKeyKey = APIKeyContextKey{}
+
+type LocaleContextKey struct{}
+
+// LocaleKey контекстный ключ с языком запроса (i18n.Locale)
+var LocaleKey = LocaleContextKey{}
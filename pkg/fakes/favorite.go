@@ -0,0 +1,145 @@
+// Package fakes содержит реализации gRPC клиентов в памяти для сервисов,
+// у которых еще нет отдельного микросервиса. Данные не переживают перезапуск gateway.
+package fakes
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	pbFavorite "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/favorite"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// FavoriteClient реализация FavoriteServiceClient в памяти
+type FavoriteClient struct {
+	mu        sync.RWMutex
+	favorites map[int64]map[int64]time.Time // user_id -> event_id -> время добавления
+}
+
+var _ pbFavorite.FavoriteServiceClient = (*FavoriteClient)(nil)
+
+// NewFavoriteClient создает клиент избранного в памяти
+func NewFavoriteClient() *FavoriteClient {
+	return &FavoriteClient{favorites: make(map[int64]map[int64]time.Time)}
+}
+
+// AddFavorite добавляет событие в избранное; повторное добавление возвращает существующую запись
+func (c *FavoriteClient) AddFavorite(_ context.Context, in *pbFavorite.FavoriteReq, _ ...grpc.CallOption) (*pbFavorite.FavoriteRes, error) {
+	if err := validateFavoriteReq(in); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	events, ok := c.favorites[in.GetUserId()]
+	if !ok {
+		events = make(map[int64]time.Time)
+		c.favorites[in.GetUserId()] = events
+	}
+	createdAt, ok := events[in.GetEventId()]
+	if !ok {
+		createdAt = time.Now()
+		events[in.GetEventId()] = createdAt
+	}
+
+	return &pbFavorite.FavoriteRes{
+		UserId:    in.GetUserId(),
+		EventId:   in.GetEventId(),
+		CreatedAt: timestamppb.New(createdAt),
+	}, nil
+}
+
+// RemoveFavorite удаляет событие из избранного; отсутствие записи не ошибка
+func (c *FavoriteClient) RemoveFavorite(_ context.Context, in *pbFavorite.FavoriteReq, _ ...grpc.CallOption) (*emptypb.Empty, error) {
+	if err := validateFavoriteReq(in); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if events, ok := c.favorites[in.GetUserId()]; ok {
+		delete(events, in.GetEventId())
+		if len(events) == 0 {
+			delete(c.favorites, in.GetUserId())
+		}
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// ListFavorites возвращает избранное пользователя, новые первыми
+func (c *FavoriteClient) ListFavorites(_ context.Context, in *pbFavorite.ListFavoritesReq, _ ...grpc.CallOption) (*pbFavorite.ListFavoritesRes, error) {
+	if in.GetUserId() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	if in.GetLimit() < 0 || in.GetOffset() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "invalid limit or offset")
+	}
+
+	c.mu.RLock()
+	favorites := make([]*pbFavorite.FavoriteRes, 0, len(c.favorites[in.GetUserId()]))
+	for eventID, createdAt := range c.favorites[in.GetUserId()] {
+		favorites = append(favorites, &pbFavorite.FavoriteRes{
+			UserId:    in.GetUserId(),
+			EventId:   eventID,
+			CreatedAt: timestamppb.New(createdAt),
+		})
+	}
+	c.mu.RUnlock()
+
+	slices.SortFunc(favorites, func(a, b *pbFavorite.FavoriteRes) int {
+		if n := b.GetCreatedAt().AsTime().Compare(a.GetCreatedAt().AsTime()); n != 0 {
+			return n
+		}
+		return cmp.Compare(b.GetEventId(), a.GetEventId())
+	})
+
+	total := len(favorites)
+	start := min(int(in.GetOffset()), total)
+	end := total
+	if in.GetLimit() > 0 {
+		end = min(start+int(in.GetLimit()), total)
+	}
+
+	return &pbFavorite.ListFavoritesRes{
+		Favorites: favorites[start:end],
+		Total:     int32(total),
+	}, nil
+}
+
+// CheckFavorites возвращает те из event_ids, что есть в избранном пользователя
+func (c *FavoriteClient) CheckFavorites(_ context.Context, in *pbFavorite.CheckFavoritesReq, _ ...grpc.CallOption) (*pbFavorite.CheckFavoritesRes, error) {
+	if in.GetUserId() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	res := &pbFavorite.CheckFavoritesRes{}
+	events := c.favorites[in.GetUserId()]
+	for _, eventID := range in.GetEventIds() {
+		if _, ok := events[eventID]; ok && !slices.Contains(res.FavoriteEventIds, eventID) {
+			res.FavoriteEventIds = append(res.FavoriteEventIds, eventID)
+		}
+	}
+	return res, nil
+}
+
+func validateFavoriteReq(in *pbFavorite.FavoriteReq) error {
+	if in.GetUserId() <= 0 {
+		return status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	if in.GetEventId() <= 0 {
+		return status.Error(codes.InvalidArgument, "event_id is required")
+	}
+	return nil
+}
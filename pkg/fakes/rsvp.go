@@ -0,0 +1,291 @@
+package fakes
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	pbRsvp "github.com/rx3lixir/gateway-service/gateway-grpc/gen/go/rsvp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Статусы регистраций
+const (
+	RsvpConfirmed  = "confirmed"
+	RsvpWaitlisted = "waitlisted"
+)
+
+// RsvpClient реализация RsvpServiceClient в памяти
+type RsvpClient struct {
+	mu     sync.Mutex
+	events map[int64]*rsvpEvent
+}
+
+// rsvpEvent регистрации на одно событие
+type rsvpEvent struct {
+	capacity  int32        // 0 — без ограничения
+	confirmed []rsvpRecord // В порядке регистрации
+	waitlist  []rsvpRecord // В порядке очереди
+}
+
+type rsvpRecord struct {
+	userID    int64
+	createdAt time.Time
+}
+
+var _ pbRsvp.RsvpServiceClient = (*RsvpClient)(nil)
+
+// NewRsvpClient создает клиент регистраций в памяти
+func NewRsvpClient() *RsvpClient {
+	return &RsvpClient{events: make(map[int64]*rsvpEvent)}
+}
+
+// Register регистрирует пользователя или ставит в лист ожидания; повторная регистрация возвращает существующую запись
+func (c *RsvpClient) Register(_ context.Context, in *pbRsvp.RsvpReq, _ ...grpc.CallOption) (*pbRsvp.RsvpRes, error) {
+	if err := validateRsvpReq(in); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	event := c.event(in.GetEventId())
+	if res := event.find(in.GetEventId(), in.GetUserId()); res != nil {
+		return res, nil
+	}
+
+	record := rsvpRecord{userID: in.GetUserId(), createdAt: time.Now()}
+	if event.hasSeat() {
+		event.confirmed = append(event.confirmed, record)
+	} else {
+		event.waitlist = append(event.waitlist, record)
+	}
+	return event.find(in.GetEventId(), in.GetUserId()), nil
+}
+
+// Cancel отменяет регистрацию и переводит первых из листа ожидания на освободившиеся места
+func (c *RsvpClient) Cancel(_ context.Context, in *pbRsvp.RsvpReq, _ ...grpc.CallOption) (*pbRsvp.CancelRsvpRes, error) {
+	if err := validateRsvpReq(in); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	event, ok := c.events[in.GetEventId()]
+	if !ok {
+		return &pbRsvp.CancelRsvpRes{}, nil
+	}
+
+	res := &pbRsvp.CancelRsvpRes{}
+	isUser := func(r rsvpRecord) bool { return r.userID == in.GetUserId() }
+	if i := slices.IndexFunc(event.confirmed, isUser); i >= 0 {
+		event.confirmed = slices.Delete(event.confirmed, i, i+1)
+		res.Cancelled = true
+	} else if i := slices.IndexFunc(event.waitlist, isUser); i >= 0 {
+		event.waitlist = slices.Delete(event.waitlist, i, i+1)
+		res.Cancelled = true
+	}
+	res.Promoted = event.promote(in.GetEventId())
+	c.cleanup(in.GetEventId())
+	return res, nil
+}
+
+// ListAttendees возвращает регистрации на событие: подтвержденные, затем лист ожидания
+func (c *RsvpClient) ListAttendees(_ context.Context, in *pbRsvp.ListAttendeesReq, _ ...grpc.CallOption) (*pbRsvp.ListAttendeesRes, error) {
+	if in.GetEventId() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "event_id is required")
+	}
+	if in.GetLimit() < 0 || in.GetOffset() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "invalid limit or offset")
+	}
+	if s := in.GetStatus(); s != "" && s != RsvpConfirmed && s != RsvpWaitlisted {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid status %q", s)
+	}
+
+	c.mu.Lock()
+	var rsvps []*pbRsvp.RsvpRes
+	if event, ok := c.events[in.GetEventId()]; ok {
+		rsvps = event.list(in.GetEventId())
+	}
+	c.mu.Unlock()
+
+	if s := in.GetStatus(); s != "" {
+		rsvps = slices.DeleteFunc(rsvps, func(r *pbRsvp.RsvpRes) bool { return r.GetStatus() != s })
+	}
+	page, total := paginate(rsvps, in.GetLimit(), in.GetOffset())
+	return &pbRsvp.ListAttendeesRes{Rsvps: page, Total: total}, nil
+}
+
+// ListUserRsvps возвращает регистрации пользователя, новые первыми
+func (c *RsvpClient) ListUserRsvps(_ context.Context, in *pbRsvp.ListUserRsvpsReq, _ ...grpc.CallOption) (*pbRsvp.ListUserRsvpsRes, error) {
+	if in.GetUserId() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	if in.GetLimit() < 0 || in.GetOffset() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "invalid limit or offset")
+	}
+
+	c.mu.Lock()
+	var rsvps []*pbRsvp.RsvpRes
+	for eventID, event := range c.events {
+		if res := event.find(eventID, in.GetUserId()); res != nil {
+			rsvps = append(rsvps, res)
+		}
+	}
+	c.mu.Unlock()
+
+	slices.SortFunc(rsvps, func(a, b *pbRsvp.RsvpRes) int {
+		if n := b.GetCreatedAt().AsTime().Compare(a.GetCreatedAt().AsTime()); n != 0 {
+			return n
+		}
+		return cmp.Compare(b.GetEventId(), a.GetEventId())
+	})
+	page, total := paginate(rsvps, in.GetLimit(), in.GetOffset())
+	return &pbRsvp.ListUserRsvpsRes{Rsvps: page, Total: total}, nil
+}
+
+// GetCapacity возвращает вместимость и число регистраций на событие
+func (c *RsvpClient) GetCapacity(_ context.Context, in *pbRsvp.GetCapacityReq, _ ...grpc.CallOption) (*pbRsvp.CapacityRes, error) {
+	if in.GetEventId() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "event_id is required")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	event, ok := c.events[in.GetEventId()]
+	if !ok {
+		return &pbRsvp.CapacityRes{EventId: in.GetEventId()}, nil
+	}
+	res := event.capacityRes(in.GetEventId())
+	if in.UserId != nil {
+		res.Rsvp = event.find(in.GetEventId(), in.GetUserId())
+	}
+	return res, nil
+}
+
+// SetCapacity задает вместимость события; при увеличении переводит первых из листа ожидания
+func (c *RsvpClient) SetCapacity(_ context.Context, in *pbRsvp.SetCapacityReq, _ ...grpc.CallOption) (*pbRsvp.CapacityRes, error) {
+	if in.GetEventId() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "event_id is required")
+	}
+	if in.GetCapacity() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "capacity must not be negative")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	event := c.event(in.GetEventId())
+	event.capacity = in.GetCapacity()
+	promoted := event.promote(in.GetEventId())
+	res := event.capacityRes(in.GetEventId())
+	res.Promoted = promoted
+	c.cleanup(in.GetEventId())
+	return res, nil
+}
+
+// event возвращает регистрации на событие, создавая запись при необходимости. Вызывается под c.mu.
+func (c *RsvpClient) event(eventID int64) *rsvpEvent {
+	event, ok := c.events[eventID]
+	if !ok {
+		event = &rsvpEvent{}
+		c.events[eventID] = event
+	}
+	return event
+}
+
+// cleanup удаляет пустую запись события без ограничения вместимости. Вызывается под c.mu.
+func (c *RsvpClient) cleanup(eventID int64) {
+	if event := c.events[eventID]; event != nil && event.capacity == 0 && len(event.confirmed) == 0 && len(event.waitlist) == 0 {
+		delete(c.events, eventID)
+	}
+}
+
+func (e *rsvpEvent) hasSeat() bool {
+	return e.capacity == 0 || len(e.confirmed) < int(e.capacity)
+}
+
+// promote переводит первых из листа ожидания на свободные места
+func (e *rsvpEvent) promote(eventID int64) []*pbRsvp.RsvpRes {
+	var promoted []*pbRsvp.RsvpRes
+	for e.hasSeat() && len(e.waitlist) > 0 {
+		record := e.waitlist[0]
+		e.waitlist = e.waitlist[1:]
+		e.confirmed = append(e.confirmed, record)
+		promoted = append(promoted, rsvpRes(eventID, record, RsvpConfirmed, 0))
+	}
+	return promoted
+}
+
+// find возвращает регистрацию пользователя или nil
+func (e *rsvpEvent) find(eventID, userID int64) *pbRsvp.RsvpRes {
+	for _, record := range e.confirmed {
+		if record.userID == userID {
+			return rsvpRes(eventID, record, RsvpConfirmed, 0)
+		}
+	}
+	for i, record := range e.waitlist {
+		if record.userID == userID {
+			return rsvpRes(eventID, record, RsvpWaitlisted, i+1)
+		}
+	}
+	return nil
+}
+
+func (e *rsvpEvent) list(eventID int64) []*pbRsvp.RsvpRes {
+	rsvps := make([]*pbRsvp.RsvpRes, 0, len(e.confirmed)+len(e.waitlist))
+	for _, record := range e.confirmed {
+		rsvps = append(rsvps, rsvpRes(eventID, record, RsvpConfirmed, 0))
+	}
+	for i, record := range e.waitlist {
+		rsvps = append(rsvps, rsvpRes(eventID, record, RsvpWaitlisted, i+1))
+	}
+	return rsvps
+}
+
+func (e *rsvpEvent) capacityRes(eventID int64) *pbRsvp.CapacityRes {
+	return &pbRsvp.CapacityRes{
+		EventId:    eventID,
+		Capacity:   e.capacity,
+		Confirmed:  int32(len(e.confirmed)),
+		Waitlisted: int32(len(e.waitlist)),
+	}
+}
+
+func rsvpRes(eventID int64, record rsvpRecord, status string, position int) *pbRsvp.RsvpRes {
+	return &pbRsvp.RsvpRes{
+		UserId:           record.userID,
+		EventId:          eventID,
+		Status:           status,
+		WaitlistPosition: int32(position),
+		CreatedAt:        timestamppb.New(record.createdAt),
+	}
+}
+
+// paginate возвращает страницу списка и его длину; limit = 0 — без ограничения
+func paginate[T any](items []T, limit, offset int32) ([]T, int32) {
+	total := len(items)
+	start := min(int(offset), total)
+	end := total
+	if limit > 0 {
+		end = min(start+int(limit), total)
+	}
+	return items[start:end], int32(total)
+}
+
+func validateRsvpReq(in *pbRsvp.RsvpReq) error {
+	if in.GetUserId() <= 0 {
+		return status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	if in.GetEventId() <= 0 {
+		return status.Error(codes.InvalidArgument, "event_id is required")
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+// Package fanout параллельные вызовы для составных ответов (backend-for-frontend).
+//
+// Страница описывается набором частей (Part): Go запускает независимый вызов,
+// Then — вызов, которому нужен результат другой части. Все части стартуют сразу,
+// зависимые ждут свои зависимости. У каждой части свой таймаут. Ошибка
+// обязательной части отменяет остальные и возвращается из Plan.Run, ошибка
+// необязательной только помечает ее недоступной (Plan.Unavailable).
+package fanout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrDependencyFailed ошибка части, зависимость которой завершилась ошибкой
+var ErrDependencyFailed = errors.New("dependency failed")
+
+// Call параметры вызова
+type Call struct {
+	Name     string        // Имя части в Plan.Unavailable
+	Timeout  time.Duration // Таймаут самого вызова без ожидания зависимостей; 0 — без своего таймаута
+	Optional bool          // Ошибка не прерывает план, часть помечается недоступной
+}
+
+// Failure недоступная часть
+type Failure struct {
+	Name string
+	Err  error
+}
+
+// Plan набор частей одного ответа. Части добавляются до Run.
+type Plan struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	parts  []part
+}
+
+type part interface {
+	call() Call
+	run(ctx context.Context)
+	wait()
+	err() error
+}
+
+// New создает план; все вызовы выполняются в контексте ctx
+func New(ctx context.Context) *Plan {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Plan{ctx: ctx, cancel: cancel}
+}
+
+// Part результат части
+type Part[T any] struct {
+	c     Call
+	after func(ctx context.Context) error // Ожидание зависимости; nil у независимых частей
+	fn    func(ctx context.Context) (T, error)
+	done  chan struct{}
+	value T
+	e     error
+}
+
+// Go добавляет независимый вызов
+func Go[T any](p *Plan, c Call, fn func(ctx context.Context) (T, error)) *Part[T] {
+	part := &Part[T]{c: c, fn: fn, done: make(chan struct{})}
+	p.parts = append(p.parts, part)
+	return part
+}
+
+// Then добавляет вызов, которому нужен результат dep. Если dep завершилась
+// ошибкой, вызов не выполняется, а часть получает ErrDependencyFailed.
+func Then[D, T any](p *Plan, dep *Part[D], c Call, fn func(ctx context.Context, v D) (T, error)) *Part[T] {
+	part := &Part[T]{c: c, done: make(chan struct{})}
+	part.after = func(ctx context.Context) error {
+		select {
+		case <-dep.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if dep.e != nil {
+			return fmt.Errorf("%w: %s", ErrDependencyFailed, dep.c.Name)
+		}
+		return nil
+	}
+	part.fn = func(ctx context.Context) (T, error) {
+		return fn(ctx, dep.value)
+	}
+	p.parts = append(p.parts, part)
+	return part
+}
+
+// Value возвращает результат части после Plan.Run
+func (p *Part[T]) Value() (T, error) {
+	<-p.done
+	return p.value, p.e
+}
+
+// OK сообщает, завершилась ли часть без ошибки
+func (p *Part[T]) OK() bool {
+	_, err := p.Value()
+	return err == nil
+}
+
+func (p *Part[T]) call() Call { return p.c }
+
+func (p *Part[T]) wait() { <-p.done }
+
+func (p *Part[T]) err() error { return p.e }
+
+func (p *Part[T]) run(ctx context.Context) {
+	defer close(p.done)
+	defer func() {
+		if r := recover(); r != nil {
+			p.e = fmt.Errorf("panic in %s: %v", p.c.Name, r)
+		}
+	}()
+	if p.after != nil {
+		if p.e = p.after(ctx); p.e != nil {
+			return
+		}
+	}
+	// Таймаут отсчитывается от начала вызова, без ожидания зависимостей
+	if p.c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.c.Timeout)
+		defer cancel()
+	}
+	p.value, p.e = p.fn(ctx)
+}
+
+// Run выполняет все части и ждет их завершения. Возвращает ошибку первой
+// (в порядке объявления) обязательной части, завершившейся ошибкой.
+func (p *Plan) Run() error {
+	defer p.cancel()
+
+	var wg sync.WaitGroup
+	for _, part := range p.parts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			part.run(p.ctx)
+			if part.err() != nil && !part.call().Optional {
+				p.cancel()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Ошибки частей, отмененных из-за другой обязательной части, вторичны
+	var first error
+	for _, part := range p.parts {
+		if err := part.err(); err != nil && !part.call().Optional {
+			if first == nil || (errors.Is(first, context.Canceled) && !errors.Is(err, context.Canceled)) {
+				first = err
+			}
+		}
+	}
+	return first
+}
+
+// Unavailable возвращает необязательные части, завершившиеся ошибкой, в порядке объявления
+func (p *Plan) Unavailable() []Failure {
+	var failures []Failure
+	for _, part := range p.parts {
+		part.wait()
+		if err := part.err(); err != nil && part.call().Optional {
+			failures = append(failures, Failure{Name: part.call().Name, Err: err})
+		}
+	}
+	return failures
+}
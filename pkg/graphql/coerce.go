@@ -0,0 +1,307 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// inputType находит тип из определения переменной
+func (s *Schema) inputType(ref *typeRef) (Type, error) {
+	var t Type
+	if ref.elem != nil {
+		elem, err := s.inputType(ref.elem)
+		if err != nil {
+			return nil, err
+		}
+		t = NewList(elem)
+	} else {
+		t = s.types[ref.name]
+		if t == nil {
+			return nil, fmt.Errorf("unknown type %q", ref.name)
+		}
+	}
+	if ref.nonNull {
+		t = NewNonNull(t)
+	}
+	return t, nil
+}
+
+// coerceVariables приводит значения переменных к типам из определений операции
+// и подставляет значения по умолчанию
+func coerceVariables(s *Schema, op *operation, input map[string]any) (map[string]any, []*Error) {
+	vars := make(map[string]any, len(op.variables))
+	var errs []*Error
+	for _, def := range op.variables {
+		t, err := s.inputType(def.typ)
+		if err != nil {
+			errs = append(errs, newError(def.loc, "variable %q: %s", "$"+def.name, err))
+			continue
+		}
+		if !isInputType(t) {
+			errs = append(errs, newError(def.loc, "variable %q cannot be of non-input type %q", "$"+def.name, def.typ))
+			continue
+		}
+
+		if v, ok := input[def.name]; ok {
+			coerced, err := coerceValue(v, t)
+			if err != nil {
+				errs = append(errs, newError(def.loc, "variable %q got invalid value: %s", "$"+def.name, err))
+				continue
+			}
+			vars[def.name] = coerced
+			continue
+		}
+
+		if def.defaults != nil {
+			coerced, err := coerceLiteral(def.defaults, t, nil)
+			if err != nil {
+				errs = append(errs, newError(def.loc, "variable %q has invalid default value: %s", "$"+def.name, err))
+				continue
+			}
+			vars[def.name] = coerced
+			continue
+		}
+
+		if _, ok := t.(*NonNull); ok {
+			errs = append(errs, newError(def.loc, "variable %q of required type %q was not provided", "$"+def.name, def.typ))
+		}
+	}
+	return vars, errs
+}
+
+// coerceArgs приводит аргументы поля или директивы и подставляет значения по умолчанию.
+// Аргумент с переменной без значения считается не переданным.
+func coerceArgs(defs []*Argument, nodes []*argumentNode, vars map[string]any) (map[string]any, *Error) {
+	args := make(map[string]any, len(defs))
+	for _, def := range defs {
+		var node *argumentNode
+		for _, n := range nodes {
+			if n.name == def.Name {
+				node = n
+				break
+			}
+		}
+
+		present := false
+		var coerced any
+		if node != nil {
+			var err error
+			if node.value.kind == valueVariable {
+				if v, ok := vars[node.value.raw]; ok {
+					present = true
+					coerced, err = coerceValue(v, def.Type)
+				}
+			} else {
+				present = true
+				coerced, err = coerceLiteral(node.value, def.Type, vars)
+			}
+			if err != nil {
+				return nil, newError(node.loc, "argument %q has invalid value: %s", def.Name, err)
+			}
+		}
+
+		if present {
+			args[def.Name] = coerced
+			continue
+		}
+		if def.Default != nil {
+			args[def.Name] = def.Default
+			continue
+		}
+		if _, ok := def.Type.(*NonNull); ok {
+			loc := Location{}
+			if node != nil {
+				loc = node.loc
+			}
+			return nil, newError(loc, "argument %q of type %q is required", def.Name, def.Type)
+		}
+	}
+	return args, nil
+}
+
+// coerceValue приводит значение переменной к типу t
+func coerceValue(v any, t Type) (any, error) {
+	switch t := t.(type) {
+	case *NonNull:
+		if v == nil {
+			return nil, fmt.Errorf("expected non-null value of type %s", t)
+		}
+		return coerceValue(v, t.Of)
+	case *List:
+		if v == nil {
+			return nil, nil
+		}
+		items, ok := v.([]any)
+		if !ok {
+			item, err := coerceValue(v, t.Of)
+			if err != nil {
+				return nil, err
+			}
+			return []any{item}, nil
+		}
+		result := make([]any, len(items))
+		for i, item := range items {
+			coerced, err := coerceValue(item, t.Of)
+			if err != nil {
+				return nil, fmt.Errorf("item %d: %w", i, err)
+			}
+			result[i] = coerced
+		}
+		return result, nil
+	}
+
+	if v == nil {
+		return nil, nil
+	}
+	switch t := t.(type) {
+	case *Scalar:
+		return t.ParseValue(v)
+	case *Enum:
+		if s, ok := v.(string); ok && t.has(s) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("enum %s cannot represent value %v", t.Name, v)
+	case *InputObject:
+		fields, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("expected an object of type %s", t.Name)
+		}
+		for name := range fields {
+			if t.field(name) == nil {
+				return nil, fmt.Errorf("field %q is not defined by type %s", name, t.Name)
+			}
+		}
+		result := make(map[string]any, len(t.Fields))
+		for _, def := range t.Fields {
+			if fv, ok := fields[def.Name]; ok {
+				coerced, err := coerceValue(fv, def.Type)
+				if err != nil {
+					return nil, fmt.Errorf("field %q: %w", def.Name, err)
+				}
+				result[def.Name] = coerced
+			} else if err := inputFieldDefault(def, result); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	}
+	return nil, fmt.Errorf("%s is not an input type", t)
+}
+
+// coerceLiteral приводит литерал запроса к типу t; значения переменных берутся из vars
+func coerceLiteral(v *value, t Type, vars map[string]any) (any, error) {
+	if v.kind == valueVariable {
+		return coerceValue(vars[v.raw], t)
+	}
+
+	switch t := t.(type) {
+	case *NonNull:
+		if v.kind == valueNull {
+			return nil, fmt.Errorf("expected non-null value of type %s, found null", t)
+		}
+		return coerceLiteral(v, t.Of, vars)
+	case *List:
+		if v.kind == valueNull {
+			return nil, nil
+		}
+		if v.kind != valueList {
+			item, err := coerceLiteral(v, t.Of, vars)
+			if err != nil {
+				return nil, err
+			}
+			return []any{item}, nil
+		}
+		result := make([]any, len(v.list))
+		for i, item := range v.list {
+			coerced, err := coerceLiteral(item, t.Of, vars)
+			if err != nil {
+				return nil, fmt.Errorf("item %d: %w", i, err)
+			}
+			result[i] = coerced
+		}
+		return result, nil
+	}
+
+	if v.kind == valueNull {
+		return nil, nil
+	}
+	switch t := t.(type) {
+	case *Scalar:
+		var raw any
+		switch v.kind {
+		case valueInt:
+			i, err := strconv.ParseInt(v.raw, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s cannot represent value %s", t.Name, v)
+			}
+			raw = i
+		case valueFloat:
+			f, err := strconv.ParseFloat(v.raw, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s cannot represent value %s", t.Name, v)
+			}
+			raw = f
+		case valueString:
+			raw = v.raw
+		case valueBoolean:
+			raw = v.raw == "true"
+		default:
+			return nil, fmt.Errorf("%s cannot represent value %s", t.Name, v)
+		}
+		return t.ParseValue(raw)
+	case *Enum:
+		if v.kind != valueEnum || !t.has(v.raw) {
+			return nil, fmt.Errorf("enum %s cannot represent value %s", t.Name, v)
+		}
+		return v.raw, nil
+	case *InputObject:
+		if v.kind != valueObject {
+			return nil, fmt.Errorf("expected an object of type %s, found %s", t.Name, v)
+		}
+		for _, field := range v.fields {
+			if t.field(field.name) == nil {
+				return nil, fmt.Errorf("field %q is not defined by type %s", field.name, t.Name)
+			}
+		}
+		result := make(map[string]any, len(t.Fields))
+		for _, def := range t.Fields {
+			var node *objectField
+			for _, field := range v.fields {
+				if field.name == def.Name {
+					node = field
+					break
+				}
+			}
+			if node != nil && node.value.kind == valueVariable {
+				if _, ok := vars[node.value.raw]; !ok {
+					node = nil // Переменная без значения: поле не передано
+				}
+			}
+			if node == nil {
+				if err := inputFieldDefault(def, result); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			coerced, err := coerceLiteral(node.value, def.Type, vars)
+			if err != nil {
+				return nil, fmt.Errorf("field %q: %w", def.Name, err)
+			}
+			result[def.Name] = coerced
+		}
+		return result, nil
+	}
+	return nil, fmt.Errorf("%s is not an input type", t)
+}
+
+// inputFieldDefault подставляет значение по умолчанию для не переданного поля входного типа
+func inputFieldDefault(def *Argument, result map[string]any) error {
+	if def.Default != nil {
+		result[def.Name] = def.Default
+		return nil
+	}
+	if _, ok := def.Type.(*NonNull); ok {
+		return fmt.Errorf("field %q of required type %s was not provided", def.Name, def.Type)
+	}
+	return nil
+}
@@ -0,0 +1,452 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// maxPlanFields ограничивает число полей в плане выполнения: фрагменты
+// с алиасами позволяют раздуть план экспоненциально при линейном тексте запроса
+const maxPlanFields = 10000
+
+type executor struct {
+	ctx         context.Context
+	schema      *Schema
+	doc         *Document
+	vars        map[string]any
+	formatError func(ctx context.Context, err error) *Error
+	errors      []*Error
+	planned     int
+}
+
+// collectedField поле в плане выполнения. Одноименные поля выборки
+// (в том числе из разных фрагментов) объединяются в одно.
+type collectedField struct {
+	key      string
+	name     string
+	def      *Field // nil для __typename
+	parent   *Object
+	nodes    []*fieldNode
+	args     map[string]any
+	children []*collectedField // Для полей объектного типа
+}
+
+// plan собирает поля выборки с учетом @skip/@include, приводит аргументы
+// и считает сложность и глубину. depth — глубина полей этой выборки, с 1.
+func (e *executor) plan(t *Object, sels []selection, depth int) (fields []*collectedField, complexity, maxDepth int, err *Error) {
+	fields, err = e.collect(t, sels)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	maxDepth = depth
+	for _, cf := range fields {
+		e.planned++
+		if e.planned > maxPlanFields {
+			return nil, 0, 0, newError(cf.nodes[0].loc, "query has too many fields: the limit is %d", maxPlanFields)
+		}
+
+		complexity = saturatingAdd(complexity, 1)
+		if cf.def == nil {
+			continue
+		}
+
+		for i, node := range cf.nodes {
+			args, err := coerceArgs(cf.def.Args, node.arguments, e.vars)
+			if err != nil {
+				return nil, 0, 0, err
+			}
+			if i == 0 {
+				cf.args = args
+			} else if !reflect.DeepEqual(cf.args, args) {
+				return nil, 0, 0, newError(node.loc, "fields %q conflict because they have differing arguments", cf.key)
+			}
+		}
+
+		obj, ok := namedType(cf.def.Type).(*Object)
+		if !ok {
+			continue
+		}
+		var sub []selection
+		for _, node := range cf.nodes {
+			sub = append(sub, node.selections...)
+		}
+		children, childComplexity, childDepth, err := e.plan(obj, sub, depth+1)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		cf.children = children
+
+		multiplier := 1
+		if cf.def.Cost != nil {
+			multiplier = max(cf.def.Cost(cf.args), 0)
+		}
+		complexity = saturatingAdd(complexity, saturatingMul(childComplexity, multiplier))
+		maxDepth = max(maxDepth, childDepth)
+	}
+	return fields, complexity, maxDepth, nil
+}
+
+// collect собирает поля выборки объекта типа t, раскрывая фрагменты
+func (e *executor) collect(t *Object, sels []selection) ([]*collectedField, *Error) {
+	var fields []*collectedField
+	byKey := make(map[string]*collectedField)
+	visited := make(map[string]bool)
+
+	var walk func(sels []selection) *Error
+	walk = func(sels []selection) *Error {
+		for _, sel := range sels {
+			switch sel := sel.(type) {
+			case *fieldNode:
+				if ok, err := e.include(sel.directives); err != nil || !ok {
+					if err != nil {
+						return err
+					}
+					continue
+				}
+				key := sel.responseKey()
+				if cf, ok := byKey[key]; ok {
+					if cf.name != sel.name {
+						return newError(sel.loc, "fields %q conflict because %q and %q are different fields", key, cf.name, sel.name)
+					}
+					cf.nodes = append(cf.nodes, sel)
+					continue
+				}
+				cf := &collectedField{key: key, name: sel.name, parent: t, nodes: []*fieldNode{sel}}
+				if sel.name != "__typename" {
+					cf.def = t.Field(sel.name)
+				}
+				byKey[key] = cf
+				fields = append(fields, cf)
+			case *inlineFragment:
+				if ok, err := e.include(sel.directives); err != nil || !ok {
+					if err != nil {
+						return err
+					}
+					continue
+				}
+				if err := walk(sel.selections); err != nil {
+					return err
+				}
+			case *fragmentSpread:
+				if ok, err := e.include(sel.directives); err != nil || !ok {
+					if err != nil {
+						return err
+					}
+					continue
+				}
+				if visited[sel.name] {
+					continue
+				}
+				visited[sel.name] = true
+				if err := walk(e.doc.fragments[sel.name].selections); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	if err := walk(sels); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// include вычисляет директивы @skip и @include
+func (e *executor) include(dirs []*directive) (bool, *Error) {
+	for _, d := range dirs {
+		args, err := coerceArgs(directiveArgs, d.arguments, e.vars)
+		if err != nil {
+			return false, err
+		}
+		cond, _ := args["if"].(bool)
+		if (d.name == "skip" && cond) || (d.name == "include" && !cond) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// objectTask объект ответа, поля которого вычисляются на следующем уровне
+type objectTask struct {
+	typ    *Object
+	source any
+	fields []*collectedField
+	out    *result
+	path   []any
+}
+
+// fieldTask значение поля, полученное от резолвера и еще не вычисленное
+type fieldTask struct {
+	task  *objectTask
+	index int
+	field *collectedField
+	value any
+	err   error
+	path  []any
+}
+
+// execute выполняет план по уровням: на каждом уровне сначала вызываются
+// все резолверы, затем вычисляются их значения. Так Thunk загрузчиков
+// со всего уровня попадают в один пакет.
+func (e *executor) execute(fields []*collectedField) *result {
+	root := newObjectResult(nil, true, fields)
+	level := []*objectTask{{typ: e.schema.Query, fields: fields, out: root}}
+
+	for len(level) > 0 {
+		var pending []*fieldTask
+		for _, task := range level {
+			if task.out.dead() {
+				continue
+			}
+			for i, cf := range task.fields {
+				if cf.def == nil {
+					task.out.values[i] = task.typ.Name
+					continue
+				}
+				path := appendPath(task.path, cf.key)
+				value, err := e.resolve(cf, task.source, path)
+				pending = append(pending, &fieldTask{task: task, index: i, field: cf, value: value, err: err, path: path})
+			}
+		}
+
+		var next []*objectTask
+		for _, ft := range pending {
+			if ft.task.out.dead() {
+				continue
+			}
+			ft.task.out.values[ft.index] = e.complete(ft.task.out, ft.field.def.Type, ft.field, ft.value, ft.err, ft.path, &next)
+		}
+		level = next
+	}
+	return root
+}
+
+func (e *executor) resolve(cf *collectedField, source any, path []any) (value any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic in %s.%s: %v", cf.parent.Name, cf.name, r)
+		}
+	}()
+	return cf.def.Resolve(ResolveParams{
+		Context: e.ctx,
+		Source:  source,
+		Args:    cf.args,
+		Path:    path,
+	})
+}
+
+// force вычисляет Thunk, в том числе вложенные
+func force(value any) (_ any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	for {
+		thunk, ok := value.(Thunk)
+		if !ok {
+			return value, nil
+		}
+		if value, err = thunk(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// complete приводит значение поля к типу t. Объекты добавляются в next
+// и вычисляются на следующем уровне. null в обязательной позиции делает null
+// ближайший родительский объект или список, который может быть null.
+func (e *executor) complete(parent *result, t Type, cf *collectedField, value any, err error, path []any, next *[]*objectTask) any {
+	nonNull := false
+	if nn, ok := t.(*NonNull); ok {
+		t, nonNull = nn.Of, true
+	}
+
+	if err == nil {
+		value, err = force(value)
+	}
+	if err == nil && isNull(value) && nonNull {
+		err = fmt.Errorf("cannot return null for non-nullable field %s.%s", cf.parent.Name, cf.name)
+	}
+	if err == nil && !isNull(value) {
+		switch t := t.(type) {
+		case *Scalar:
+			if value, err = t.Serialize(value); err == nil {
+				return value
+			}
+		case *Enum:
+			rv := reflect.ValueOf(value)
+			if rv.Kind() == reflect.String && t.has(rv.String()) {
+				return rv.String()
+			}
+			err = fmt.Errorf("enum %s cannot represent value %v", t.Name, value)
+		case *List:
+			rv := reflect.ValueOf(value)
+			if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+				err = fmt.Errorf("expected a list for field %s.%s, got %T", cf.parent.Name, cf.name, value)
+				break
+			}
+			list := &result{parent: parent, nullable: !nonNull, list: true, values: make([]any, rv.Len())}
+			for i := range rv.Len() {
+				list.values[i] = e.complete(list, t.Of, cf, rv.Index(i).Interface(), nil, appendPath(path, i), next)
+			}
+			return list
+		case *Object:
+			obj := newObjectResult(parent, !nonNull, cf.children)
+			*next = append(*next, &objectTask{typ: t, source: value, fields: cf.children, out: obj, path: path})
+			return obj
+		}
+	}
+
+	if err != nil {
+		e.fieldError(err, cf, path)
+		if nonNull {
+			parent.invalidate()
+		}
+	}
+	return nil
+}
+
+func (e *executor) fieldError(err error, cf *collectedField, path []any) {
+	var out *Error
+	if e.formatError != nil {
+		out = e.formatError(e.ctx, err)
+	}
+	if out == nil {
+		var gqlErr *Error
+		if errors.As(err, &gqlErr) {
+			out = gqlErr
+		} else {
+			out = &Error{Message: err.Error(), Err: err}
+		}
+	}
+
+	c := *out
+	c.Path = path
+	c.Locations = []Location{cf.nodes[0].loc}
+	e.errors = append(e.errors, &c)
+}
+
+// isNull проверяет nil, в том числе типизированный. Пустой слайс — не null, а пустой список.
+func isNull(v any) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Interface, reflect.Func, reflect.Chan:
+		return rv.IsNil()
+	}
+	return false
+}
+
+func appendPath(path []any, elem any) []any {
+	p := make([]any, len(path)+1)
+	copy(p, path)
+	p[len(path)] = elem
+	return p
+}
+
+func saturatingAdd(a, b int) int {
+	if a > math.MaxInt-b {
+		return math.MaxInt
+	}
+	return a + b
+}
+
+func saturatingMul(a, b int) int {
+	if a != 0 && b > math.MaxInt/a {
+		return math.MaxInt
+	}
+	return a * b
+}
+
+// result объект или список ответа
+type result struct {
+	parent   *result
+	nullable bool // Может ли позиция этого значения быть null
+	null     bool
+	list     bool
+	keys     []string
+	values   []any // Значения полей или элементы списка; вложенные объекты и списки — *result
+}
+
+func newObjectResult(parent *result, nullable bool, fields []*collectedField) *result {
+	keys := make([]string, len(fields))
+	for i, cf := range fields {
+		keys[i] = cf.key
+	}
+	return &result{parent: parent, nullable: nullable, keys: keys, values: make([]any, len(fields))}
+}
+
+// invalidate делает значение null и поднимает null до ближайшей позиции, где он допустим
+func (r *result) invalidate() {
+	for n := r; n != nil; n = n.parent {
+		n.null = true
+		if n.nullable {
+			return
+		}
+	}
+}
+
+// dead сообщает, что значение или один из его родителей стал null
+func (r *result) dead() bool {
+	for n := r; n != nil; n = n.parent {
+		if n.null {
+			return true
+		}
+	}
+	return false
+}
+
+// MarshalJSON сохраняет порядок полей выборки
+func (r *result) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := r.encode(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (r *result) encode(buf *bytes.Buffer) error {
+	if r.null {
+		buf.WriteString("null")
+		return nil
+	}
+
+	open, end := byte('{'), byte('}')
+	if r.list {
+		open, end = '[', ']'
+	}
+	buf.WriteByte(open)
+	for i, v := range r.values {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if !r.list {
+			key, _ := json.Marshal(r.keys[i])
+			buf.Write(key)
+			buf.WriteByte(':')
+		}
+		if nested, ok := v.(*result); ok {
+			if err := nested.encode(buf); err != nil {
+				return err
+			}
+			continue
+		}
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		buf.Write(data)
+	}
+	buf.WriteByte(end)
+	return nil
+}
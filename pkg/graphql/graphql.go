@@ -0,0 +1,172 @@
+// Package graphql минимальный исполнитель GraphQL запросов для gateway.
+//
+// Поддерживается только чтение (операции query): переменные со значениями
+// по умолчанию, алиасы, аргументы, именованные и inline фрагменты, директивы
+// @skip и @include. Из интроспекции есть только __typename, схему целиком
+// отдает Schema.SDL.
+//
+// Поля выполняются по уровням: сначала вызываются резолверы всех полей уровня,
+// затем вычисляются отложенные значения (Thunk). Так загрузчики (Loader)
+// собирают ключи со всего уровня и загружают их одним вызовом.
+//
+// Перед выполнением запрос проверяется по схеме и ограничениям глубины
+// и сложности (Limits).
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Location позиция в тексте запроса, с 1
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Error ошибка запроса в формате ответа GraphQL
+type Error struct {
+	Message    string         `json:"message"`
+	Locations  []Location     `json:"locations,omitempty"`
+	Path       []any          `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+
+	// Исходная ошибка резолвера
+	Err error `json:"-"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func newError(loc Location, format string, args ...any) *Error {
+	return &Error{Message: fmt.Sprintf(format, args...), Locations: []Location{loc}}
+}
+
+// Limits ограничения запроса; 0 — без ограничения
+type Limits struct {
+	MaxDepth      int // Максимальная вложенность полей
+	MaxComplexity int // Максимальная сложность, см. Field.Cost
+}
+
+// Params параметры выполнения запроса
+type Params struct {
+	Schema        *Schema
+	Query         string
+	OperationName string
+	Variables     map[string]any
+	Limits        Limits
+
+	// FormatError превращает ошибку резолвера в ошибку ответа. Путь и позицию
+	// заполняет исполнитель. nil — в ответ попадает текст ошибки.
+	FormatError func(ctx context.Context, err error) *Error
+}
+
+// Result результат выполнения запроса
+type Result struct {
+	Data   any      `json:"data"`
+	Errors []*Error `json:"errors,omitempty"`
+
+	// Глубина и сложность запроса; заполняются, если запрос прошел проверку
+	Depth      int `json:"-"`
+	Complexity int `json:"-"`
+
+	// false, если запрос не дошел до выполнения: тогда в ответе нет data
+	executed bool
+}
+
+// MarshalJSON опускает data для запросов, не дошедших до выполнения
+func (r *Result) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	if r.executed {
+		data, err := json.Marshal(r.Data)
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString(`"data":`)
+		buf.Write(data)
+	}
+	if len(r.Errors) > 0 {
+		errs, err := json.Marshal(r.Errors)
+		if err != nil {
+			return nil, err
+		}
+		if r.executed {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(`"errors":`)
+		buf.Write(errs)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// Executed сообщает, дошел ли запрос до выполнения
+func (r *Result) Executed() bool {
+	return r.executed
+}
+
+// Execute разбирает, проверяет и выполняет запрос
+func Execute(ctx context.Context, p Params) *Result {
+	doc, err := Parse(p.Query)
+	if err != nil {
+		return &Result{Errors: []*Error{asError(err)}}
+	}
+
+	op, err := doc.operation(p.OperationName)
+	if err != nil {
+		return &Result{Errors: []*Error{asError(err)}}
+	}
+
+	if errs := validate(p.Schema, doc, op); len(errs) > 0 {
+		return &Result{Errors: errs}
+	}
+
+	vars, errs := coerceVariables(p.Schema, op, p.Variables)
+	if len(errs) > 0 {
+		return &Result{Errors: errs}
+	}
+
+	e := &executor{
+		ctx:         ctx,
+		schema:      p.Schema,
+		doc:         doc,
+		vars:        vars,
+		formatError: p.FormatError,
+	}
+
+	fields, complexity, depth, planErr := e.plan(p.Schema.Query, op.selections, 1)
+	if planErr != nil {
+		return &Result{Errors: []*Error{planErr}}
+	}
+	if p.Limits.MaxDepth > 0 && depth > p.Limits.MaxDepth {
+		return &Result{Errors: []*Error{newError(op.loc, "query depth %d exceeds the limit of %d", depth, p.Limits.MaxDepth)}}
+	}
+	if p.Limits.MaxComplexity > 0 && complexity > p.Limits.MaxComplexity {
+		return &Result{Errors: []*Error{newError(op.loc, "query complexity %d exceeds the limit of %d", complexity, p.Limits.MaxComplexity)}}
+	}
+
+	data := e.execute(fields)
+	return &Result{
+		Data:       data,
+		Errors:     e.errors,
+		Depth:      depth,
+		Complexity: complexity,
+		executed:   true,
+	}
+}
+
+// asError приводит ошибку разбора к ошибке ответа
+func asError(err error) *Error {
+	if gqlErr, ok := err.(*Error); ok {
+		return gqlErr
+	}
+	return &Error{Message: err.Error()}
+}
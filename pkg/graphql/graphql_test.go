@@ -0,0 +1,618 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type testBook struct {
+	ID       int
+	Title    string
+	AuthorID int
+	Genre    string
+}
+
+type testAuthor struct {
+	ID   int
+	Name string
+}
+
+var (
+	testBooks = []*testBook{
+		{ID: 1, Title: "Solaris", AuthorID: 1, Genre: "FICTION"},
+		{ID: 2, Title: "Summa Technologiae", AuthorID: 1, Genre: "SCIENCE"},
+		{ID: 3, Title: "Roadside Picnic", AuthorID: 2, Genre: "FICTION"},
+	}
+	testAuthors = map[int]*testAuthor{
+		1: {ID: 1, Name: "Stanislaw Lem"},
+		2: {ID: 2, Name: "Strugatsky"},
+	}
+)
+
+type authorLoaderKey struct{}
+
+// testSchema схема для тестов: книги с авторами, авторы загружаются пакетно
+func testSchema(t *testing.T) *Schema {
+	t.Helper()
+
+	genre := &Enum{Name: "Genre", Values: []*EnumValue{{Name: "FICTION"}, {Name: "SCIENCE"}}}
+	filter := &InputObject{Name: "BookFilter", Fields: []*Argument{
+		{Name: "genre", Type: genre},
+		{Name: "titlePrefix", Type: String},
+	}}
+
+	author := &Object{Name: "Author"}
+	book := &Object{Name: "Book", Fields: []*Field{
+		{Name: "id", Type: NewNonNull(ID), Resolve: func(p ResolveParams) (any, error) { return p.Source.(*testBook).ID, nil }},
+		{Name: "title", Type: NewNonNull(String), Resolve: func(p ResolveParams) (any, error) { return p.Source.(*testBook).Title, nil }},
+		{Name: "genre", Type: genre, Resolve: func(p ResolveParams) (any, error) { return p.Source.(*testBook).Genre, nil }},
+		{Name: "isbn", Type: NewNonNull(String), Resolve: func(p ResolveParams) (any, error) { return nil, nil }},
+		{Name: "author", Type: author, Resolve: func(p ResolveParams) (any, error) {
+			loader := p.Context.Value(authorLoaderKey{}).(*Loader[int, *testAuthor])
+			return loader.Load(p.Context, p.Source.(*testBook).AuthorID), nil
+		}},
+	}}
+	author.AddField(&Field{Name: "id", Type: NewNonNull(ID), Resolve: func(p ResolveParams) (any, error) { return p.Source.(*testAuthor).ID, nil }})
+	author.AddField(&Field{Name: "name", Type: NewNonNull(String), Resolve: func(p ResolveParams) (any, error) { return p.Source.(*testAuthor).Name, nil }})
+	author.AddField(&Field{Name: "books", Type: NewNonNull(NewList(NewNonNull(book))), Resolve: func(p ResolveParams) (any, error) {
+		var books []*testBook
+		for _, b := range testBooks {
+			if b.AuthorID == p.Source.(*testAuthor).ID {
+				books = append(books, b)
+			}
+		}
+		return books, nil
+	}})
+
+	query := &Object{Name: "Query", Fields: []*Field{
+		{Name: "hello", Type: NewNonNull(String), Args: []*Argument{{Name: "name", Type: String, Default: "world"}},
+			Resolve: func(p ResolveParams) (any, error) { return "hello, " + p.Args["name"].(string), nil }},
+		{Name: "book", Type: book, Args: []*Argument{{Name: "id", Type: NewNonNull(ID)}},
+			Resolve: func(p ResolveParams) (any, error) {
+				for _, b := range testBooks {
+					if fmt.Sprint(b.ID) == p.Args["id"] {
+						return b, nil
+					}
+				}
+				return nil, nil
+			}},
+		{Name: "books", Type: NewNonNull(NewList(NewNonNull(book))),
+			Args: []*Argument{{Name: "limit", Type: Int, Default: 10}, {Name: "filter", Type: filter}},
+			Cost: func(args map[string]any) int { return args["limit"].(int) },
+			Resolve: func(p ResolveParams) (any, error) {
+				f, _ := p.Args["filter"].(map[string]any)
+				books := []*testBook{}
+				for _, b := range testBooks {
+					if g, ok := f["genre"]; ok && g != nil && g != b.Genre {
+						continue
+					}
+					if prefix, ok := f["titlePrefix"].(string); ok && !strings.HasPrefix(b.Title, prefix) {
+						continue
+					}
+					if len(books) < p.Args["limit"].(int) {
+						books = append(books, b)
+					}
+				}
+				return books, nil
+			}},
+		{Name: "fail", Type: String, Resolve: func(p ResolveParams) (any, error) { return nil, errors.New("boom") }},
+		{Name: "failNonNull", Type: NewNonNull(String), Resolve: func(p ResolveParams) (any, error) { return nil, nil }},
+		{Name: "panics", Type: String, Resolve: func(p ResolveParams) (any, error) { panic("unexpected") }},
+		{Name: "lazy", Type: String, Resolve: func(p ResolveParams) (any, error) {
+			return Thunk(func() (any, error) { return Thunk(func() (any, error) { return "nested thunk", nil }), nil }), nil
+		}},
+	}}
+	query.AddField(&Field{Name: "brokenBook", Type: NewNonNull(book), Resolve: func(p ResolveParams) (any, error) {
+		return &testBook{ID: 9, AuthorID: 404}, nil
+	}})
+
+	schema, err := NewSchema(query)
+	if err != nil {
+		t.Fatalf("NewSchema: %v", err)
+	}
+	return schema
+}
+
+// authorBatches считает вызовы пакетной загрузки авторов
+type authorBatches struct {
+	mu    sync.Mutex
+	calls [][]int
+}
+
+func (b *authorBatches) load(ctx context.Context, ids []int) (map[int]*testAuthor, error) {
+	b.mu.Lock()
+	b.calls = append(b.calls, slices.Sorted(slices.Values(ids)))
+	b.mu.Unlock()
+
+	result := make(map[int]*testAuthor, len(ids))
+	for _, id := range ids {
+		if a, ok := testAuthors[id]; ok {
+			result[id] = a
+		}
+	}
+	return result, nil
+}
+
+func execute(t *testing.T, p Params) (*Result, *authorBatches) {
+	t.Helper()
+	batches := &authorBatches{}
+	ctx := context.WithValue(context.Background(), authorLoaderKey{}, NewLoader(batches.load))
+	if p.Schema == nil {
+		p.Schema = testSchema(t)
+	}
+	return Execute(ctx, p), batches
+}
+
+func toJSON(t *testing.T, v any) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+	return string(data)
+}
+
+func TestExecute(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		operation string
+		variables map[string]any
+		want      string
+	}{
+		{
+			name:  "arguments and defaults",
+			query: `{ hello a: hello(name: "Lem") }`,
+			want:  `{"data":{"hello":"hello, world","a":"hello, Lem"}}`,
+		},
+		{
+			name:  "nested objects and lists",
+			query: `{ book(id: 1) { id title genre author { name books { title } } } }`,
+			want:  `{"data":{"book":{"id":"1","title":"Solaris","genre":"FICTION","author":{"name":"Stanislaw Lem","books":[{"title":"Solaris"},{"title":"Summa Technologiae"}]}}}}`,
+		},
+		{
+			name:  "typename",
+			query: `{ __typename book(id: "3") { __typename title } }`,
+			want:  `{"data":{"__typename":"Query","book":{"__typename":"Book","title":"Roadside Picnic"}}}`,
+		},
+		{
+			name:  "missing object is null",
+			query: `{ book(id: 42) { title } }`,
+			want:  `{"data":{"book":null}}`,
+		},
+		{
+			name:  "input object and enum literal",
+			query: `{ books(filter: {genre: FICTION, titlePrefix: "Road"}) { title } }`,
+			want:  `{"data":{"books":[{"title":"Roadside Picnic"}]}}`,
+		},
+		{
+			name:  "field merging",
+			query: `{ book(id: 1) { title } book(id: 1) { id title } }`,
+			want:  `{"data":{"book":{"title":"Solaris","id":"1"}}}`,
+		},
+		{
+			name:  "nested thunks",
+			query: `{ lazy }`,
+			want:  `{"data":{"lazy":"nested thunk"}}`,
+		},
+		{
+			name: "operation name",
+			query: `query A { hello }
+			        query B { hello(name: "B") }`,
+			operation: "B",
+			want:      `{"data":{"hello":"hello, B"}}`,
+		},
+		{
+			name:  "comments, commas and block strings",
+			query: "# comment\n{ hello(name: \"\"\"\n    block\n    string\n\"\"\"),, }",
+			want:  `{"data":{"hello":"hello, block\nstring"}}`,
+		},
+		{
+			name:  "string escapes",
+			query: `{ hello(name: "Ж\t\"q\"") }`,
+			want:  `{"data":{"hello":"hello, Ж\t\"q\""}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, _ := execute(t, Params{Query: tt.query, OperationName: tt.operation, Variables: tt.variables})
+			if got := toJSON(t, res); got != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFragments(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name: "named fragment",
+			query: `{ book(id: 1) { ...BookFields } }
+			        fragment BookFields on Book { id title }`,
+			want: `{"data":{"book":{"id":"1","title":"Solaris"}}}`,
+		},
+		{
+			name: "nested fragments",
+			query: `{ book(id: 2) { ...WithAuthor } }
+			        fragment WithAuthor on Book { title author { ...AuthorName } }
+			        fragment AuthorName on Author { name }`,
+			want: `{"data":{"book":{"title":"Summa Technologiae","author":{"name":"Stanislaw Lem"}}}}`,
+		},
+		{
+			name:  "inline fragment with and without type condition",
+			query: `{ book(id: 3) { ... on Book { id } ... { title } } }`,
+			want:  `{"data":{"book":{"id":"3","title":"Roadside Picnic"}}}`,
+		},
+		{
+			name: "fragment fields merge with selection",
+			query: `{ book(id: 1) { author { id } ...A } }
+			        fragment A on Book { author { name } }`,
+			want: `{"data":{"book":{"author":{"id":"1","name":"Stanislaw Lem"}}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, _ := execute(t, Params{Query: tt.query})
+			if got := toJSON(t, res); got != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVariables(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		variables map[string]any
+		want      string
+	}{
+		{
+			name:      "scalar variable",
+			query:     `query($id: ID!) { book(id: $id) { title } }`,
+			variables: map[string]any{"id": "2"},
+			want:      `{"data":{"book":{"title":"Summa Technologiae"}}}`,
+		},
+		{
+			name:      "ID from JSON number",
+			query:     `query($id: ID!) { book(id: $id) { title } }`,
+			variables: map[string]any{"id": float64(3)},
+			want:      `{"data":{"book":{"title":"Roadside Picnic"}}}`,
+		},
+		{
+			name:  "variable default",
+			query: `query($name: String = "default") { hello(name: $name) }`,
+			want:  `{"data":{"hello":"hello, default"}}`,
+		},
+		{
+			name:      "input object variable with enum",
+			query:     `query($f: BookFilter, $limit: Int) { books(filter: $f, limit: $limit) { id } }`,
+			variables: map[string]any{"f": map[string]any{"genre": "FICTION"}, "limit": float64(1)},
+			want:      `{"data":{"books":[{"id":"1"}]}}`,
+		},
+		{
+			name:      "variable inside input object literal",
+			query:     `query($g: Genre) { books(filter: {genre: $g}) { id } }`,
+			variables: map[string]any{"g": "SCIENCE"},
+			want:      `{"data":{"books":[{"id":"2"}]}}`,
+		},
+		{
+			name:      "skip and include",
+			query:     `query($yes: Boolean!) { book(id: 1) { id @skip(if: $yes) title @include(if: $yes) genre @include(if: false) } }`,
+			variables: map[string]any{"yes": true},
+			want:      `{"data":{"book":{"title":"Solaris"}}}`,
+		},
+		{
+			name:      "skip on fragment spread",
+			query:     `query($no: Boolean!) { book(id: 1) { id ...F @skip(if: $no) } } fragment F on Book { title }`,
+			variables: map[string]any{"no": false},
+			want:      `{"data":{"book":{"id":"1","title":"Solaris"}}}`,
+		},
+		{
+			name:  "required variable missing",
+			query: `query($id: ID!) { book(id: $id) { title } }`,
+			want:  `{"errors":[{"message":"variable \"$id\" of required type \"ID!\" was not provided","locations":[{"line":1,"column":7}]}]}`,
+		},
+		{
+			name:      "variable of wrong type",
+			query:     `query($limit: Int) { books(limit: $limit) { id } }`,
+			variables: map[string]any{"limit": "ten"},
+			want:      `{"errors":[{"message":"variable \"$limit\" got invalid value: Int cannot represent value ten","locations":[{"line":1,"column":7}]}]}`,
+		},
+		{
+			name:      "unknown enum value",
+			query:     `query($g: Genre) { books(filter: {genre: $g}) { id } }`,
+			variables: map[string]any{"g": "POETRY"},
+			want:      `{"errors":[{"message":"variable \"$g\" got invalid value: enum Genre cannot represent value POETRY","locations":[{"line":1,"column":7}]}]}`,
+		},
+		{
+			name:  "undefined variable",
+			query: `{ book(id: $id) { title } }`,
+			want:  `{"errors":[{"message":"variable \"$id\" is not defined","locations":[{"line":1,"column":12}]}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, _ := execute(t, Params{Query: tt.query, Variables: tt.variables})
+			if got := toJSON(t, res); got != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		operation string
+		want      string
+	}{
+		{"empty document", ``, "", `document does not contain any operations`},
+		{"unterminated selection", `{ hello`, "", `syntax error: unexpected end of query`},
+		{"empty selection", `{ }`, "", `syntax error: selection set must not be empty`},
+		{"unterminated string", `{ hello(name: "x) }`, "", `syntax error: unterminated string`},
+		{"invalid number", `{ books(limit: 01) { id } }`, "", `syntax error: invalid number, unexpected digit after 0`},
+		{"mutation", `mutation { hello }`, "", `schema does not support mutation operations`},
+		{"unknown field", `{ nope }`, "", `cannot query field "nope" on type "Query"`},
+		{"missing subselection", `{ book(id: 1) }`, "", `field "book" of type "Book" must have a selection of subfields`},
+		{"subselection on scalar", `{ hello { x } }`, "", `field "hello" must not have a selection since type "String!" has no subfields`},
+		{"unknown argument", `{ hello(nick: "x") }`, "", `unknown argument "nick" on field "Query.hello"`},
+		{"missing required argument", `{ book { id } }`, "", `argument "id" of type "ID!" is required on field "Query.book"`},
+		{"argument of wrong type", `{ books(limit: "x") { id } }`, "", `argument "limit" has invalid value: Int cannot represent value x`},
+		{"unknown fragment", `{ ...Missing }`, "", `unknown fragment "Missing"`},
+		{"fragment cycle", `{ book(id: 1) { ...A } } fragment A on Book { ...B } fragment B on Book { ...A }`, "", `cannot spread fragment "A" within itself`},
+		{"wrong type condition", `{ book(id: 1) { ... on Author { name } } }`, "", `fragment cannot be spread here as objects of type "Book" can never be of type "Author"`},
+		{"unknown directive", `{ hello @defer }`, "", `unknown directive @defer`},
+		{"conflicting arguments", `{ hello(name: "a") hello(name: "b") }`, "", `fields "hello" conflict because they have differing arguments`},
+		{"conflicting fields", `{ x: hello x: lazy }`, "", `fields "x" conflict because "hello" and "lazy" are different fields`},
+		{"duplicate operation", `query A { hello } query A { hello }`, "", `there can be only one operation named "A"`},
+		{"anonymous with others", `{ hello } query A { hello }`, "", `anonymous operation must be the only defined operation`},
+		{"unknown operation", `query A { hello }`, "B", `unknown operation "B"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, _ := execute(t, Params{Query: tt.query, OperationName: tt.operation})
+			if res.Executed() {
+				t.Fatalf("request was executed: %s", toJSON(t, res))
+			}
+			if len(res.Errors) == 0 || res.Errors[0].Message != tt.want {
+				t.Errorf("got errors %s, want %q", toJSON(t, res.Errors), tt.want)
+			}
+			if got := toJSON(t, res); strings.Contains(got, `"data"`) {
+				t.Errorf("response of a rejected request must not contain data: %s", got)
+			}
+		})
+	}
+}
+
+func TestErrorLocations(t *testing.T) {
+	res, _ := execute(t, Params{Query: "{\n  hello\n  nope\n}"})
+	want := `{"errors":[{"message":"cannot query field \"nope\" on type \"Query\"","locations":[{"line":3,"column":3}]}]}`
+	if got := toJSON(t, res); got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
+
+func TestFieldErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "nullable field error",
+			query: `{ hello fail }`,
+			want:  `{"data":{"hello":"hello, world","fail":null},"errors":[{"message":"boom","locations":[{"line":1,"column":9}],"path":["fail"]}]}`,
+		},
+		{
+			name:  "null in non-null root field nulls data",
+			query: `{ hello failNonNull }`,
+			want:  `{"data":null,"errors":[{"message":"cannot return null for non-nullable field Query.failNonNull","locations":[{"line":1,"column":9}],"path":["failNonNull"]}]}`,
+		},
+		{
+			name:  "non-null propagates to nearest nullable parent",
+			query: `{ hello book(id: 1) { title isbn } }`,
+			want:  `{"data":{"hello":"hello, world","book":null},"errors":[{"message":"cannot return null for non-nullable field Book.isbn","locations":[{"line":1,"column":29}],"path":["book","isbn"]}]}`,
+		},
+		{
+			name:  "non-null propagates through non-null parents to data",
+			query: `{ hello brokenBook { author { name } isbn } }`,
+			want:  `{"data":null,"errors":[{"message":"cannot return null for non-nullable field Book.isbn","locations":[{"line":1,"column":38}],"path":["brokenBook","isbn"]}]}`,
+		},
+		{
+			name:  "panic in resolver",
+			query: `{ panics hello }`,
+			want:  `{"data":{"panics":null,"hello":"hello, world"},"errors":[{"message":"panic in Query.panics: unexpected","locations":[{"line":1,"column":3}],"path":["panics"]}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, _ := execute(t, Params{Query: tt.query})
+			if !res.Executed() {
+				t.Fatalf("request was not executed: %s", toJSON(t, res))
+			}
+			if got := toJSON(t, res); got != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatError(t *testing.T) {
+	res, _ := execute(t, Params{
+		Query: `{ fail }`,
+		FormatError: func(ctx context.Context, err error) *Error {
+			return &Error{Message: "internal error", Extensions: map[string]any{"code": "INTERNAL"}, Err: err}
+		},
+	})
+	want := `{"data":{"fail":null},"errors":[{"message":"internal error","locations":[{"line":1,"column":3}],"path":["fail"],"extensions":{"code":"INTERNAL"}}]}`
+	if got := toJSON(t, res); got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+	if err := res.Errors[0].Err; err == nil || err.Error() != "boom" {
+		t.Errorf("original error is lost: %v", err)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		limits Limits
+		want   string // Пусто — запрос выполняется
+		depth  int
+		cost   int
+	}{
+		{
+			name:  "depth and complexity are reported",
+			query: `{ book(id: 1) { author { name } } }`,
+			depth: 3,
+			cost:  3,
+		},
+		{
+			name:  "list cost multiplies nested selection",
+			query: `{ books(limit: 5) { id author { name } } }`,
+			depth: 3,
+			cost:  1 + 5*3,
+		},
+		{
+			name:  "fragments count towards depth",
+			query: `{ book(id: 1) { ...A } } fragment A on Book { author { books { title } } }`,
+			depth: 4,
+			cost:  4,
+		},
+		{
+			name:   "depth limit",
+			query:  `{ book(id: 1) { author { books { title } } } }`,
+			limits: Limits{MaxDepth: 3},
+			want:   `query depth 4 exceeds the limit of 3`,
+		},
+		{
+			name:   "complexity limit",
+			query:  `{ books(limit: 100) { author { books { id } } } }`,
+			limits: Limits{MaxComplexity: 300},
+			want:   `query complexity 301 exceeds the limit of 300`,
+		},
+		{
+			name:   "complexity uses variable values",
+			query:  `query($n: Int) { books(limit: $n) { id } }`,
+			limits: Limits{MaxComplexity: 10},
+			want:   `query complexity 21 exceeds the limit of 10`,
+		},
+		{
+			name:   "within limits",
+			query:  `{ books(limit: 3) { id } }`,
+			limits: Limits{MaxDepth: 2, MaxComplexity: 4},
+			depth:  2,
+			cost:   4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, _ := execute(t, Params{Query: tt.query, Limits: tt.limits, Variables: map[string]any{"n": float64(20)}})
+			if tt.want != "" {
+				if res.Executed() || len(res.Errors) != 1 || res.Errors[0].Message != tt.want {
+					t.Fatalf("got %s, want error %q", toJSON(t, res), tt.want)
+				}
+				return
+			}
+			if !res.Executed() || len(res.Errors) > 0 {
+				t.Fatalf("unexpected errors: %s", toJSON(t, res))
+			}
+			if res.Depth != tt.depth || res.Complexity != tt.cost {
+				t.Errorf("depth %d, complexity %d; want %d, %d", res.Depth, res.Complexity, tt.depth, tt.cost)
+			}
+		})
+	}
+}
+
+func TestLoaderBatchesLevel(t *testing.T) {
+	res, batches := execute(t, Params{Query: `{ books { author { name books { author { id } } } } }`})
+	if len(res.Errors) > 0 {
+		t.Fatalf("unexpected errors: %s", toJSON(t, res))
+	}
+	// Первый уровень авторов — один пакет; второй уровень берет значения из кэша загрузчика
+	if want := [][]int{{1, 2}}; !slices.EqualFunc(batches.calls, want, slices.Equal[[]int]) {
+		t.Errorf("batches %v, want %v", batches.calls, want)
+	}
+}
+
+func TestPersistedQueries(t *testing.T) {
+	store := NewMemoryPersistedQueries(1)
+	query := `{ hello }`
+	hash := QueryHash(query)
+	ext := map[string]any{"persistedQuery": map[string]any{"version": 1, "sha256Hash": hash}}
+
+	// Только хэш, запрос еще не сохранен
+	req := &Request{Extensions: ext}
+	if err := req.ResolvePersisted(store); err != ErrPersistedQueryNotFound {
+		t.Fatalf("got %v, want PersistedQueryNotFound", err)
+	}
+
+	// Текст с неверным хэшем не сохраняется
+	bad := &Request{Query: `{ lazy }`, Extensions: ext}
+	if err := bad.ResolvePersisted(store); err != ErrPersistedQueryHashMismatch {
+		t.Fatalf("got %v, want hash mismatch", err)
+	}
+
+	// Текст и хэш сохраняют запрос, затем достаточно хэша
+	if err := (&Request{Query: query, Extensions: ext}).ResolvePersisted(store); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	req = &Request{Extensions: ext}
+	if err := req.ResolvePersisted(store); err != nil || req.Query != query {
+		t.Fatalf("got query %q, error %v", req.Query, err)
+	}
+
+	// Вытеснение по емкости
+	other := `{ lazy }`
+	store.Put(QueryHash(other), other)
+	if _, ok := store.Get(hash); ok || store.Len() != 1 {
+		t.Errorf("query was not evicted, len %d", store.Len())
+	}
+
+	if err := (&Request{Extensions: ext}).ResolvePersisted(nil); err != ErrPersistedQueryNotSupported {
+		t.Errorf("got %v, want PersistedQueryNotSupported", err)
+	}
+}
+
+func TestNewSchemaErrors(t *testing.T) {
+	input := &InputObject{Name: "In", Fields: []*Argument{{Name: "x", Type: Int}}}
+	tests := []struct {
+		name  string
+		query *Object
+	}{
+		{"nil query", nil},
+		{"invalid name", &Object{Name: "Query", Fields: []*Field{{Name: "bad-name", Type: String}}}},
+		{"input type as output", &Object{Name: "Query", Fields: []*Field{{Name: "x", Type: input}}}},
+		{"duplicate type name", &Object{Name: "Query", Fields: []*Field{
+			{Name: "a", Type: &Object{Name: "T", Fields: []*Field{{Name: "x", Type: Int}}}},
+			{Name: "b", Type: &Object{Name: "T", Fields: []*Field{{Name: "y", Type: Int}}}},
+		}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewSchema(tt.query); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
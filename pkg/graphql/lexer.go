@@ -0,0 +1,287 @@
+package graphql
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunct
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+type token struct {
+	kind  tokenKind
+	value string // Для строк — значение без кавычек и экранирования
+	loc   Location
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of query"
+	case tokenString:
+		return strconv.Quote(t.value)
+	default:
+		return t.value
+	}
+}
+
+// lexer разбивает текст запроса на токены. Запятые, пробелы и комментарии пропускаются.
+type lexer struct {
+	src       string
+	pos       int
+	line      int
+	lineStart int
+}
+
+func newLexer(src string) *lexer {
+	src = strings.TrimPrefix(src, "\uFEFF")
+	return &lexer{src: src, line: 1}
+}
+
+func (l *lexer) loc() Location {
+	return Location{Line: l.line, Column: l.pos - l.lineStart + 1}
+}
+
+func (l *lexer) newline() {
+	l.line++
+	l.lineStart = l.pos
+}
+
+func (l *lexer) skipIgnored() {
+	for l.pos < len(l.src) {
+		switch c := l.src[l.pos]; c {
+		case ' ', '\t', ',':
+			l.pos++
+		case '\n':
+			l.pos++
+			l.newline()
+		case '\r':
+			l.pos++
+			if l.pos < len(l.src) && l.src[l.pos] == '\n' {
+				l.pos++
+			}
+			l.newline()
+		case '#':
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' && l.src[l.pos] != '\r' {
+				l.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (l *lexer) next() (token, error) {
+	l.skipIgnored()
+	loc := l.loc()
+	if l.pos >= len(l.src) {
+		return token{kind: tokenEOF, loc: loc}, nil
+	}
+
+	c := l.src[l.pos]
+	switch {
+	case strings.IndexByte("!$&()[]{}:=@|", c) >= 0:
+		l.pos++
+		return token{kind: tokenPunct, value: string(c), loc: loc}, nil
+	case c == '.':
+		if strings.HasPrefix(l.src[l.pos:], "...") {
+			l.pos += 3
+			return token{kind: tokenPunct, value: "...", loc: loc}, nil
+		}
+		return token{}, newError(loc, "syntax error: unexpected %q", ".")
+	case isNameStart(c):
+		start := l.pos
+		for l.pos < len(l.src) && isNameContinue(l.src[l.pos]) {
+			l.pos++
+		}
+		return token{kind: tokenName, value: l.src[start:l.pos], loc: loc}, nil
+	case c == '-' || isDigit(c):
+		return l.number(loc)
+	case c == '"':
+		if strings.HasPrefix(l.src[l.pos:], `"""`) {
+			return l.blockString(loc)
+		}
+		return l.string(loc)
+	}
+
+	r, _ := utf8.DecodeRuneInString(l.src[l.pos:])
+	return token{}, newError(loc, "syntax error: unexpected character %q", r)
+}
+
+func (l *lexer) number(loc Location) (token, error) {
+	start := l.pos
+	if l.src[l.pos] == '-' {
+		l.pos++
+	}
+	if l.pos < len(l.src) && l.src[l.pos] == '0' {
+		l.pos++
+		if l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+			return token{}, newError(loc, "syntax error: invalid number, unexpected digit after 0")
+		}
+	} else if !l.digits() {
+		return token{}, newError(loc, "syntax error: invalid number %q", l.src[start:l.pos])
+	}
+
+	kind := tokenInt
+	if l.pos < len(l.src) && l.src[l.pos] == '.' {
+		kind = tokenFloat
+		l.pos++
+		if !l.digits() {
+			return token{}, newError(loc, "syntax error: invalid number %q", l.src[start:l.pos])
+		}
+	}
+	if l.pos < len(l.src) && (l.src[l.pos] == 'e' || l.src[l.pos] == 'E') {
+		kind = tokenFloat
+		l.pos++
+		if l.pos < len(l.src) && (l.src[l.pos] == '+' || l.src[l.pos] == '-') {
+			l.pos++
+		}
+		if !l.digits() {
+			return token{}, newError(loc, "syntax error: invalid number %q", l.src[start:l.pos])
+		}
+	}
+	if l.pos < len(l.src) && (l.src[l.pos] == '.' || isNameStart(l.src[l.pos])) {
+		return token{}, newError(loc, "syntax error: invalid number %q", l.src[start:l.pos+1])
+	}
+	return token{kind: kind, value: l.src[start:l.pos], loc: loc}, nil
+}
+
+func (l *lexer) digits() bool {
+	start := l.pos
+	for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+		l.pos++
+	}
+	return l.pos > start
+}
+
+func (l *lexer) string(loc Location) (token, error) {
+	l.pos++ // "
+	var b strings.Builder
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == '"':
+			l.pos++
+			return token{kind: tokenString, value: b.String(), loc: loc}, nil
+		case c == '\n' || c == '\r':
+			return token{}, newError(loc, "syntax error: unterminated string")
+		case c == '\\':
+			if l.pos+1 >= len(l.src) {
+				return token{}, newError(loc, "syntax error: unterminated string")
+			}
+			esc := l.src[l.pos+1]
+			l.pos += 2
+			switch esc {
+			case '"', '\\', '/':
+				b.WriteByte(esc)
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'u':
+				if l.pos+4 > len(l.src) {
+					return token{}, newError(loc, "syntax error: invalid unicode escape")
+				}
+				code, err := strconv.ParseUint(l.src[l.pos:l.pos+4], 16, 32)
+				if err != nil {
+					return token{}, newError(loc, "syntax error: invalid unicode escape %q", `\u`+l.src[l.pos:l.pos+4])
+				}
+				l.pos += 4
+				b.WriteRune(rune(code))
+			default:
+				return token{}, newError(loc, "syntax error: invalid escape sequence %q", `\`+string(esc))
+			}
+		default:
+			b.WriteByte(c)
+			l.pos++
+		}
+	}
+	return token{}, newError(loc, "syntax error: unterminated string")
+}
+
+func (l *lexer) blockString(loc Location) (token, error) {
+	l.pos += 3 // """
+	var b strings.Builder
+	for l.pos < len(l.src) {
+		switch {
+		case strings.HasPrefix(l.src[l.pos:], `"""`):
+			l.pos += 3
+			return token{kind: tokenString, value: dedentBlockString(b.String()), loc: loc}, nil
+		case strings.HasPrefix(l.src[l.pos:], `\"""`):
+			b.WriteString(`"""`)
+			l.pos += 4
+		case l.src[l.pos] == '\n':
+			b.WriteByte('\n')
+			l.pos++
+			l.newline()
+		case l.src[l.pos] == '\r':
+			b.WriteByte('\n')
+			l.pos++
+			if l.pos < len(l.src) && l.src[l.pos] == '\n' {
+				l.pos++
+			}
+			l.newline()
+		default:
+			b.WriteByte(l.src[l.pos])
+			l.pos++
+		}
+	}
+	return token{}, newError(loc, "syntax error: unterminated string")
+}
+
+// dedentBlockString убирает общий отступ и пустые первые и последние строки блочной строки
+func dedentBlockString(raw string) string {
+	lines := strings.Split(raw, "\n")
+
+	common := -1
+	for _, line := range lines[1:] {
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent < len(line) && (common < 0 || indent < common) {
+			common = indent
+		}
+	}
+	if common > 0 {
+		for i := 1; i < len(lines); i++ {
+			if len(lines[i]) >= common {
+				lines[i] = lines[i][common:]
+			} else {
+				lines[i] = ""
+			}
+		}
+	}
+
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isNameContinue(c byte) bool {
+	return isNameStart(c) || isDigit(c)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,112 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// BatchFunc загружает значения по ключам одним вызовом. Ключи, которых нет
+// в результате, считаются ненайденными: поле получает null.
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// Loader накапливает ключи и загружает их пакетом при вычислении первого Thunk.
+// Загруженные значения кэшируются, поэтому загрузчик создается на один запрос.
+type Loader[K comparable, V any] struct {
+	batch BatchFunc[K, V]
+
+	mu      sync.Mutex
+	entries map[K]*loaderEntry[V]
+	queue   []K
+}
+
+type loaderEntry[V any] struct {
+	done  chan struct{}
+	value V
+	found bool
+	err   error
+}
+
+// NewLoader создает загрузчик
+func NewLoader[K comparable, V any](batch BatchFunc[K, V]) *Loader[K, V] {
+	return &Loader[K, V]{batch: batch, entries: make(map[K]*loaderEntry[V])}
+}
+
+// Load ставит ключ в очередь и возвращает Thunk значения
+func (l *Loader[K, V]) Load(ctx context.Context, key K) Thunk {
+	entry := l.enqueue(key)
+	return func() (any, error) {
+		l.dispatch(ctx)
+		<-entry.done
+		if entry.err != nil || !entry.found {
+			return nil, entry.err
+		}
+		return entry.value, nil
+	}
+}
+
+// LoadMany ставит ключи в очередь и возвращает Thunk для каждого
+func (l *Loader[K, V]) LoadMany(ctx context.Context, keys []K) []Thunk {
+	thunks := make([]Thunk, len(keys))
+	for i, key := range keys {
+		thunks[i] = l.Load(ctx, key)
+	}
+	return thunks
+}
+
+// Get загружает значение сразу вместе с уже накопленными ключами.
+// found = false, если значения нет.
+func (l *Loader[K, V]) Get(ctx context.Context, key K) (value V, found bool, err error) {
+	entry := l.enqueue(key)
+	l.dispatch(ctx)
+	<-entry.done
+	return entry.value, entry.found, entry.err
+}
+
+func (l *Loader[K, V]) enqueue(key K) *loaderEntry[V] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entry, ok := l.entries[key]
+	if !ok {
+		entry = &loaderEntry[V]{done: make(chan struct{})}
+		l.entries[key] = entry
+		l.queue = append(l.queue, key)
+	}
+	return entry
+}
+
+// dispatch загружает накопленные ключи. Ключи, которые уже загружает
+// другая горутина, ждут ее результата в Load.
+func (l *Loader[K, V]) dispatch(ctx context.Context) {
+	l.mu.Lock()
+	keys := l.queue
+	l.queue = nil
+	entries := make([]*loaderEntry[V], len(keys))
+	for i, key := range keys {
+		entries[i] = l.entries[key]
+	}
+	l.mu.Unlock()
+	if len(keys) == 0 {
+		return
+	}
+
+	values, err := l.load(ctx, keys)
+	for i, key := range keys {
+		entry := entries[i]
+		if err != nil {
+			entry.err = err
+		} else {
+			entry.value, entry.found = values[key]
+		}
+		close(entry.done)
+	}
+}
+
+func (l *Loader[K, V]) load(ctx context.Context, keys []K) (values map[K]V, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic in loader: %v", r)
+		}
+	}()
+	return l.batch(ctx, keys)
+}
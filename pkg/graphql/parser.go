@@ -0,0 +1,669 @@
+package graphql
+
+import "fmt"
+
+// maxNesting ограничивает вложенность выборок и значений при разборе,
+// чтобы глубокие запросы отсекались до проверки лимитов
+const maxNesting = 128
+
+// Document разобранный запрос
+type Document struct {
+	operations []*operation
+	fragments  map[string]*fragment
+}
+
+type operation struct {
+	kind       string // query, mutation или subscription
+	name       string
+	variables  []*variableDefinition
+	directives []*directive
+	selections []selection
+	loc        Location
+}
+
+type variableDefinition struct {
+	name     string
+	typ      *typeRef
+	defaults *value
+	loc      Location
+}
+
+// typeRef тип в определении переменной: именованный или список
+type typeRef struct {
+	name    string
+	elem    *typeRef
+	nonNull bool
+}
+
+func (t *typeRef) String() string {
+	s := t.name
+	if t.elem != nil {
+		s = "[" + t.elem.String() + "]"
+	}
+	if t.nonNull {
+		s += "!"
+	}
+	return s
+}
+
+type selection interface {
+	location() Location
+}
+
+type fieldNode struct {
+	alias      string
+	name       string
+	arguments  []*argumentNode
+	directives []*directive
+	selections []selection
+	loc        Location
+}
+
+type fragmentSpread struct {
+	name       string
+	directives []*directive
+	loc        Location
+}
+
+type inlineFragment struct {
+	typeCondition string // Пусто — тип текущего объекта
+	directives    []*directive
+	selections    []selection
+	loc           Location
+}
+
+type fragment struct {
+	name          string
+	typeCondition string
+	directives    []*directive
+	selections    []selection
+	loc           Location
+}
+
+type directive struct {
+	name      string
+	arguments []*argumentNode
+	loc       Location
+}
+
+type argumentNode struct {
+	name  string
+	value *value
+	loc   Location
+}
+
+func (f *fieldNode) location() Location      { return f.loc }
+func (f *fragmentSpread) location() Location { return f.loc }
+func (f *inlineFragment) location() Location { return f.loc }
+
+// responseKey ключ поля в ответе: алиас или имя
+func (f *fieldNode) responseKey() string {
+	if f.alias != "" {
+		return f.alias
+	}
+	return f.name
+}
+
+type valueKind int
+
+const (
+	valueVariable valueKind = iota
+	valueInt
+	valueFloat
+	valueString
+	valueBoolean
+	valueNull
+	valueEnum
+	valueList
+	valueObject
+)
+
+// value литерал значения в запросе
+type value struct {
+	kind   valueKind
+	raw    string // Имя переменной, число, строка, true/false или имя значения enum
+	list   []*value
+	fields []*objectField
+	loc    Location
+}
+
+type objectField struct {
+	name  string
+	value *value
+	loc   Location
+}
+
+func (v *value) String() string {
+	switch v.kind {
+	case valueVariable:
+		return "$" + v.raw
+	case valueString:
+		return fmt.Sprintf("%q", v.raw)
+	case valueNull:
+		return "null"
+	case valueList:
+		return "list"
+	case valueObject:
+		return "object"
+	default:
+		return v.raw
+	}
+}
+
+// Parse разбирает текст запроса
+func Parse(query string) (*Document, error) {
+	p := &parser{lex: newLexer(query)}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	return p.document()
+}
+
+// operation выбирает операцию по имени; без имени документ должен содержать одну операцию
+func (d *Document) operation(name string) (*operation, error) {
+	if name == "" {
+		if len(d.operations) > 1 {
+			return nil, &Error{Message: "operationName is required when the document contains several operations"}
+		}
+		return d.operations[0], nil
+	}
+	for _, op := range d.operations {
+		if op.name == name {
+			return op, nil
+		}
+	}
+	return nil, &Error{Message: fmt.Sprintf("unknown operation %q", name)}
+}
+
+type parser struct {
+	lex     *lexer
+	tok     token
+	nesting int
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *parser) is(punct string) bool {
+	return p.tok.kind == tokenPunct && p.tok.value == punct
+}
+
+func (p *parser) isKeyword(name string) bool {
+	return p.tok.kind == tokenName && p.tok.value == name
+}
+
+func (p *parser) unexpected() error {
+	return newError(p.tok.loc, "syntax error: unexpected %s", p.tok)
+}
+
+func (p *parser) expect(punct string) error {
+	if !p.is(punct) {
+		return newError(p.tok.loc, "syntax error: expected %q, found %s", punct, p.tok)
+	}
+	return p.advance()
+}
+
+// skip пропускает punct, если он следующий
+func (p *parser) skip(punct string) (bool, error) {
+	if !p.is(punct) {
+		return false, nil
+	}
+	return true, p.advance()
+}
+
+func (p *parser) name() (string, error) {
+	if p.tok.kind != tokenName {
+		return "", newError(p.tok.loc, "syntax error: expected name, found %s", p.tok)
+	}
+	name := p.tok.value
+	return name, p.advance()
+}
+
+func (p *parser) enter() error {
+	p.nesting++
+	if p.nesting > maxNesting {
+		return newError(p.tok.loc, "syntax error: query is nested too deeply")
+	}
+	return nil
+}
+
+func (p *parser) leave() {
+	p.nesting--
+}
+
+func (p *parser) document() (*Document, error) {
+	doc := &Document{fragments: make(map[string]*fragment)}
+	for p.tok.kind != tokenEOF {
+		switch {
+		case p.is("{"):
+			op, err := p.operation()
+			if err != nil {
+				return nil, err
+			}
+			doc.operations = append(doc.operations, op)
+		case p.isKeyword("query"), p.isKeyword("mutation"), p.isKeyword("subscription"):
+			op, err := p.operation()
+			if err != nil {
+				return nil, err
+			}
+			for _, other := range doc.operations {
+				if op.name != "" && other.name == op.name {
+					return nil, newError(op.loc, "there can be only one operation named %q", op.name)
+				}
+			}
+			doc.operations = append(doc.operations, op)
+		case p.isKeyword("fragment"):
+			frag, err := p.fragment()
+			if err != nil {
+				return nil, err
+			}
+			if _, dup := doc.fragments[frag.name]; dup {
+				return nil, newError(frag.loc, "there can be only one fragment named %q", frag.name)
+			}
+			doc.fragments[frag.name] = frag
+		default:
+			return nil, p.unexpected()
+		}
+	}
+
+	if len(doc.operations) == 0 {
+		return nil, &Error{Message: "document does not contain any operations"}
+	}
+	if len(doc.operations) > 1 {
+		for _, op := range doc.operations {
+			if op.name == "" {
+				return nil, newError(op.loc, "anonymous operation must be the only defined operation")
+			}
+		}
+	}
+	return doc, nil
+}
+
+func (p *parser) operation() (*operation, error) {
+	op := &operation{kind: "query", loc: p.tok.loc}
+	if p.is("{") {
+		sels, err := p.selectionSet()
+		if err != nil {
+			return nil, err
+		}
+		op.selections = sels
+		return op, nil
+	}
+
+	op.kind = p.tok.value
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind == tokenName {
+		op.name = p.tok.value
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+
+	var err error
+	if p.is("(") {
+		if op.variables, err = p.variableDefinitions(); err != nil {
+			return nil, err
+		}
+	}
+	if op.directives, err = p.directives(false); err != nil {
+		return nil, err
+	}
+	if op.selections, err = p.selectionSet(); err != nil {
+		return nil, err
+	}
+	return op, nil
+}
+
+func (p *parser) variableDefinitions() ([]*variableDefinition, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var defs []*variableDefinition
+	for !p.is(")") {
+		def := &variableDefinition{loc: p.tok.loc}
+		if err := p.expect("$"); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		def.name = name
+		for _, other := range defs {
+			if other.name == name {
+				return nil, newError(def.loc, "there can be only one variable named %q", "$"+name)
+			}
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		if def.typ, err = p.typeRef(); err != nil {
+			return nil, err
+		}
+		if ok, err := p.skip("="); err != nil {
+			return nil, err
+		} else if ok {
+			if def.defaults, err = p.value(true); err != nil {
+				return nil, err
+			}
+		}
+		// Директивы переменных разбираются, но не поддерживаются
+		if _, err := p.directives(true); err != nil {
+			return nil, err
+		}
+		defs = append(defs, def)
+	}
+	return defs, p.advance()
+}
+
+func (p *parser) typeRef() (*typeRef, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	t := &typeRef{}
+	if ok, err := p.skip("["); err != nil {
+		return nil, err
+	} else if ok {
+		if t.elem, err = p.typeRef(); err != nil {
+			return nil, err
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+	} else {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		t.name = name
+	}
+
+	ok, err := p.skip("!")
+	if err != nil {
+		return nil, err
+	}
+	t.nonNull = ok
+	return t, nil
+}
+
+func (p *parser) selectionSet() ([]selection, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var sels []selection
+	for !p.is("}") {
+		if p.tok.kind == tokenEOF {
+			return nil, p.unexpected()
+		}
+		sel, err := p.selection()
+		if err != nil {
+			return nil, err
+		}
+		sels = append(sels, sel)
+	}
+	if len(sels) == 0 {
+		return nil, newError(p.tok.loc, "syntax error: selection set must not be empty")
+	}
+	return sels, p.advance()
+}
+
+func (p *parser) selection() (selection, error) {
+	loc := p.tok.loc
+	if ok, err := p.skip("..."); err != nil {
+		return nil, err
+	} else if ok {
+		return p.fragmentSelection(loc)
+	}
+
+	field := &fieldNode{loc: loc}
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	field.name = name
+	if ok, err := p.skip(":"); err != nil {
+		return nil, err
+	} else if ok {
+		field.alias = name
+		if field.name, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	if p.is("(") {
+		if field.arguments, err = p.arguments(false); err != nil {
+			return nil, err
+		}
+	}
+	if field.directives, err = p.directives(false); err != nil {
+		return nil, err
+	}
+	if p.is("{") {
+		if field.selections, err = p.selectionSet(); err != nil {
+			return nil, err
+		}
+	}
+	return field, nil
+}
+
+func (p *parser) fragmentSelection(loc Location) (selection, error) {
+	if p.tok.kind == tokenName && p.tok.value != "on" {
+		spread := &fragmentSpread{name: p.tok.value, loc: loc}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		var err error
+		if spread.directives, err = p.directives(false); err != nil {
+			return nil, err
+		}
+		return spread, nil
+	}
+
+	inline := &inlineFragment{loc: loc}
+	if p.isKeyword("on") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		inline.typeCondition = name
+	}
+	var err error
+	if inline.directives, err = p.directives(false); err != nil {
+		return nil, err
+	}
+	if inline.selections, err = p.selectionSet(); err != nil {
+		return nil, err
+	}
+	return inline, nil
+}
+
+func (p *parser) fragment() (*fragment, error) {
+	frag := &fragment{loc: p.tok.loc}
+	if err := p.advance(); err != nil { // fragment
+		return nil, err
+	}
+	if p.isKeyword("on") {
+		return nil, p.unexpected()
+	}
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	frag.name = name
+	if !p.isKeyword("on") {
+		return nil, newError(p.tok.loc, "syntax error: expected \"on\", found %s", p.tok)
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if frag.typeCondition, err = p.name(); err != nil {
+		return nil, err
+	}
+	if frag.directives, err = p.directives(false); err != nil {
+		return nil, err
+	}
+	if frag.selections, err = p.selectionSet(); err != nil {
+		return nil, err
+	}
+	return frag, nil
+}
+
+func (p *parser) directives(constant bool) ([]*directive, error) {
+	var dirs []*directive
+	for p.is("@") {
+		d := &directive{loc: p.tok.loc}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		d.name = name
+		if p.is("(") {
+			if d.arguments, err = p.arguments(constant); err != nil {
+				return nil, err
+			}
+		}
+		dirs = append(dirs, d)
+	}
+	return dirs, nil
+}
+
+func (p *parser) arguments(constant bool) ([]*argumentNode, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var args []*argumentNode
+	for !p.is(")") {
+		arg := &argumentNode{loc: p.tok.loc}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		arg.name = name
+		for _, other := range args {
+			if other.name == name {
+				return nil, newError(arg.loc, "there can be only one argument named %q", name)
+			}
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		if arg.value, err = p.value(constant); err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	if len(args) == 0 {
+		return nil, newError(p.tok.loc, "syntax error: argument list must not be empty")
+	}
+	return args, p.advance()
+}
+
+func (p *parser) value(constant bool) (*value, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	v := &value{loc: p.tok.loc}
+	switch p.tok.kind {
+	case tokenPunct:
+		switch p.tok.value {
+		case "$":
+			if constant {
+				return nil, newError(v.loc, "syntax error: unexpected variable in constant value")
+			}
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			v.kind, v.raw = valueVariable, name
+			return v, nil
+		case "[":
+			v.kind = valueList
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			for !p.is("]") {
+				if p.tok.kind == tokenEOF {
+					return nil, p.unexpected()
+				}
+				item, err := p.value(constant)
+				if err != nil {
+					return nil, err
+				}
+				v.list = append(v.list, item)
+			}
+			return v, p.advance()
+		case "{":
+			v.kind = valueObject
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			for !p.is("}") {
+				field := &objectField{loc: p.tok.loc}
+				name, err := p.name()
+				if err != nil {
+					return nil, err
+				}
+				field.name = name
+				for _, other := range v.fields {
+					if other.name == name {
+						return nil, newError(field.loc, "there can be only one input field named %q", name)
+					}
+				}
+				if err := p.expect(":"); err != nil {
+					return nil, err
+				}
+				if field.value, err = p.value(constant); err != nil {
+					return nil, err
+				}
+				v.fields = append(v.fields, field)
+			}
+			return v, p.advance()
+		}
+	case tokenInt:
+		v.kind, v.raw = valueInt, p.tok.value
+		return v, p.advance()
+	case tokenFloat:
+		v.kind, v.raw = valueFloat, p.tok.value
+		return v, p.advance()
+	case tokenString:
+		v.kind, v.raw = valueString, p.tok.value
+		return v, p.advance()
+	case tokenName:
+		switch p.tok.value {
+		case "true", "false":
+			v.kind = valueBoolean
+		case "null":
+			v.kind = valueNull
+		default:
+			v.kind = valueEnum
+		}
+		v.raw = p.tok.value
+		return v, p.advance()
+	}
+	return nil, p.unexpected()
+}
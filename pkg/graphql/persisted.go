@@ -0,0 +1,128 @@
+package graphql
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+)
+
+// Ошибки сохраненных запросов в формате Automatic Persisted Queries:
+// клиент, получив PersistedQueryNotFound, повторяет запрос с текстом
+var (
+	ErrPersistedQueryNotFound = &Error{
+		Message:    "PersistedQueryNotFound",
+		Extensions: map[string]any{"code": "PERSISTED_QUERY_NOT_FOUND"},
+	}
+	ErrPersistedQueryNotSupported = &Error{
+		Message:    "PersistedQueryNotSupported",
+		Extensions: map[string]any{"code": "PERSISTED_QUERY_NOT_SUPPORTED"},
+	}
+	ErrPersistedQueryHashMismatch = &Error{
+		Message:    "provided sha does not match query",
+		Extensions: map[string]any{"code": "PERSISTED_QUERY_HASH_MISMATCH"},
+	}
+)
+
+// PersistedQueries хранилище текстов запросов по SHA-256
+type PersistedQueries interface {
+	Get(hash string) (string, bool)
+	Put(hash, query string)
+}
+
+// QueryHash возвращает SHA-256 текста запроса в hex
+func QueryHash(query string) string {
+	sum := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(sum[:])
+}
+
+// MemoryPersistedQueries хранилище запросов в памяти; при превышении
+// емкости вытесняются запросы, дольше всех не использовавшиеся
+type MemoryPersistedQueries struct {
+	mu       sync.Mutex
+	capacity int
+	queries  map[string]*list.Element
+	order    *list.List // Начало списка — последние использованные
+}
+
+type persistedQuery struct {
+	hash  string
+	query string
+}
+
+// NewMemoryPersistedQueries создает хранилище на capacity запросов
+func NewMemoryPersistedQueries(capacity int) *MemoryPersistedQueries {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &MemoryPersistedQueries{
+		capacity: capacity,
+		queries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Get возвращает текст запроса по хэшу
+func (s *MemoryPersistedQueries) Get(hash string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	elem, ok := s.queries[hash]
+	if !ok {
+		return "", false
+	}
+	s.order.MoveToFront(elem)
+	return elem.Value.(*persistedQuery).query, true
+}
+
+// Put сохраняет текст запроса
+func (s *MemoryPersistedQueries) Put(hash, query string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if elem, ok := s.queries[hash]; ok {
+		s.order.MoveToFront(elem)
+		return
+	}
+	s.queries[hash] = s.order.PushFront(&persistedQuery{hash: hash, query: query})
+	for s.order.Len() > s.capacity {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.queries, oldest.Value.(*persistedQuery).hash)
+	}
+}
+
+// Len возвращает число сохраненных запросов
+func (s *MemoryPersistedQueries) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len()
+}
+
+// ResolvePersisted подставляет текст сохраненного запроса по extensions.persistedQuery.
+// Запрос с текстом и хэшем сохраняется, если хэш совпадает. store = nil — сохраненные
+// запросы выключены.
+func (r *Request) ResolvePersisted(store PersistedQueries) *Error {
+	pq := r.persistedQuery()
+	if pq == nil {
+		return nil
+	}
+	if store == nil {
+		return ErrPersistedQueryNotSupported
+	}
+	hash := strings.ToLower(pq.SHA256Hash)
+
+	if r.Query == "" {
+		query, ok := store.Get(hash)
+		if !ok {
+			return ErrPersistedQueryNotFound
+		}
+		r.Query = query
+		return nil
+	}
+
+	if QueryHash(r.Query) != hash {
+		return ErrPersistedQueryHashMismatch
+	}
+	store.Put(hash, r.Query)
+	return nil
+}
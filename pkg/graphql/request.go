@@ -0,0 +1,97 @@
+package graphql
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+)
+
+// Request GraphQL запрос по HTTP
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+	Extensions    map[string]any `json:"extensions,omitempty"`
+}
+
+// PersistedQuery расширение persistedQuery (Automatic Persisted Queries)
+type PersistedQuery struct {
+	Version    int    `json:"version"`
+	SHA256Hash string `json:"sha256Hash"`
+}
+
+// persistedQuery возвращает extensions.persistedQuery или nil
+func (r *Request) persistedQuery() *PersistedQuery {
+	raw, ok := r.Extensions["persistedQuery"]
+	if !ok {
+		return nil
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+	var pq PersistedQuery
+	if err := json.Unmarshal(data, &pq); err != nil || pq.SHA256Hash == "" {
+		return nil
+	}
+	return &pq
+}
+
+// ParseRequest читает запрос из GET параметров (query, operationName, variables,
+// extensions) или тела POST (application/json или application/graphql).
+// Тело ограничено maxBody байтами.
+func ParseRequest(r *http.Request, maxBody int64) (*Request, error) {
+	req := &Request{}
+	switch r.Method {
+	case http.MethodGet:
+		q := r.URL.Query()
+		req.Query = q.Get("query")
+		req.OperationName = q.Get("operationName")
+		if v := q.Get("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				return nil, fmt.Errorf("invalid variables: %w", err)
+			}
+		}
+		if v := q.Get("extensions"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Extensions); err != nil {
+				return nil, fmt.Errorf("invalid extensions: %w", err)
+			}
+		}
+	case http.MethodPost:
+		body := http.MaxBytesReader(nil, r.Body, maxBody)
+		defer body.Close()
+
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		switch mediaType {
+		case "application/json", "":
+			if err := json.NewDecoder(body).Decode(req); err != nil {
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					return nil, fmt.Errorf("invalid request body: larger than %d bytes", maxBody)
+				}
+				return nil, fmt.Errorf("invalid request body: %w", err)
+			}
+		case "application/graphql":
+			data, err := io.ReadAll(body)
+			if err != nil {
+				return nil, fmt.Errorf("invalid request body: %w", err)
+			}
+			req.Query = string(data)
+		default:
+			return nil, fmt.Errorf("invalid content type %q: expected application/json or application/graphql", mediaType)
+		}
+		if q := r.URL.Query().Get("operationName"); q != "" && req.OperationName == "" {
+			req.OperationName = q
+		}
+	default:
+		return nil, fmt.Errorf("invalid method %s: expected GET or POST", r.Method)
+	}
+
+	if req.Query == "" && req.persistedQuery() == nil {
+		return nil, fmt.Errorf("query is required")
+	}
+	return req, nil
+}
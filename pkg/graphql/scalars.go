@@ -0,0 +1,139 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// Встроенные скаляры
+var (
+	Int = &Scalar{
+		Name:        "Int",
+		Description: "32-битное целое число",
+		Serialize:   serializeInt,
+		ParseValue:  parseInt,
+	}
+	Float = &Scalar{
+		Name:        "Float",
+		Description: "Число с плавающей точкой",
+		Serialize:   parseFloat,
+		ParseValue:  parseFloat,
+	}
+	String = &Scalar{
+		Name:        "String",
+		Description: "Строка UTF-8",
+		Serialize:   parseString,
+		ParseValue:  parseString,
+	}
+	Boolean = &Scalar{
+		Name:        "Boolean",
+		Description: "true или false",
+		Serialize:   parseBoolean,
+		ParseValue:  parseBoolean,
+	}
+	ID = &Scalar{
+		Name:        "ID",
+		Description: "Идентификатор; в ответе всегда строка, на входе — строка или целое число",
+		Serialize:   parseID,
+		ParseValue:  parseID,
+	}
+)
+
+// toInt64 приводит целые числа любых типов и целые float64 к int64
+func toInt64(v any) (int64, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		i, err := n.Int64()
+		return i, err == nil
+	case float32:
+		return toInt64(float64(n))
+	case float64:
+		if n != math.Trunc(n) || math.IsInf(n, 0) || n < math.MinInt64 || n > math.MaxInt64 {
+			return 0, false
+		}
+		return int64(n), true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if rv.Uint() > math.MaxInt64 {
+			return 0, false
+		}
+		return int64(rv.Uint()), true
+	}
+	return 0, false
+}
+
+func serializeInt(v any) (any, error) {
+	i, ok := toInt64(v)
+	if !ok || i < math.MinInt32 || i > math.MaxInt32 {
+		return nil, fmt.Errorf("Int cannot represent value %v", v)
+	}
+	return i, nil
+}
+
+func parseInt(v any) (any, error) {
+	i, ok := toInt64(v)
+	if !ok || i < math.MinInt32 || i > math.MaxInt32 {
+		return nil, fmt.Errorf("Int cannot represent value %v", v)
+	}
+	return int(i), nil
+}
+
+func parseFloat(v any) (any, error) {
+	var f float64
+	switch n := v.(type) {
+	case float64:
+		f = n
+	case float32:
+		// Через строку, чтобы 0.1 не превращалось в 0.10000000149011612
+		f, _ = strconv.ParseFloat(strconv.FormatFloat(float64(n), 'g', -1, 32), 64)
+	case json.Number:
+		parsed, err := n.Float64()
+		if err != nil {
+			return nil, fmt.Errorf("Float cannot represent value %v", v)
+		}
+		f = parsed
+	default:
+		i, ok := toInt64(v)
+		if !ok {
+			return nil, fmt.Errorf("Float cannot represent value %v", v)
+		}
+		f = float64(i)
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, fmt.Errorf("Float cannot represent value %v", v)
+	}
+	return f, nil
+}
+
+func parseString(v any) (any, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("String cannot represent value %v", v)
+	}
+	return s, nil
+}
+
+func parseBoolean(v any) (any, error) {
+	b, ok := v.(bool)
+	if !ok {
+		return nil, fmt.Errorf("Boolean cannot represent value %v", v)
+	}
+	return b, nil
+}
+
+func parseID(v any) (any, error) {
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	if i, ok := toInt64(v); ok {
+		return strconv.FormatInt(i, 10), nil
+	}
+	return nil, fmt.Errorf("ID cannot represent value %v", v)
+}
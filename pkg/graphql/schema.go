@@ -0,0 +1,338 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+)
+
+// Type тип схемы: *Scalar, *Enum, *Object, *InputObject, *List или *NonNull
+type Type interface {
+	String() string
+}
+
+// Scalar скалярный тип
+type Scalar struct {
+	Name        string
+	Description string
+
+	// Serialize приводит значение резолвера к значению JSON
+	Serialize func(v any) (any, error)
+
+	// ParseValue приводит входное значение к значению Go. На вход приходят
+	// литералы запроса (string, bool, int64, float64) и значения переменных из JSON.
+	ParseValue func(v any) (any, error)
+}
+
+func (s *Scalar) String() string { return s.Name }
+
+// Enum перечисление; значения передаются резолверам и возвращаются ими как строки
+type Enum struct {
+	Name        string
+	Description string
+	Values      []*EnumValue
+}
+
+// EnumValue значение перечисления
+type EnumValue struct {
+	Name        string
+	Description string
+}
+
+func (e *Enum) String() string { return e.Name }
+
+func (e *Enum) has(name string) bool {
+	for _, v := range e.Values {
+		if v.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Object объектный тип. Поля можно добавлять после создания, чтобы типы
+// могли ссылаться друг на друга.
+type Object struct {
+	Name        string
+	Description string
+	Fields      []*Field
+}
+
+func (o *Object) String() string { return o.Name }
+
+// AddField добавляет поле и возвращает объект
+func (o *Object) AddField(f *Field) *Object {
+	o.Fields = append(o.Fields, f)
+	return o
+}
+
+// Field возвращает поле по имени или nil
+func (o *Object) Field(name string) *Field {
+	for _, f := range o.Fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// Field поле объектного типа
+type Field struct {
+	Name              string
+	Description       string
+	Type              Type
+	Args              []*Argument
+	Resolve           ResolveFunc
+	DeprecationReason string
+
+	// Cost возвращает множитель сложности вложенной выборки, обычно размер
+	// страницы списка. Сложность поля — 1 плюс сложность вложенной выборки,
+	// умноженная на Cost. nil — множитель 1.
+	Cost func(args map[string]any) int
+}
+
+// Argument аргумент поля или поле входного типа
+type Argument struct {
+	Name        string
+	Description string
+	Type        Type
+	Default     any // Значение Go после приведения; nil — без значения по умолчанию
+}
+
+// InputObject входной объектный тип; в резолвер приходит как map[string]any
+type InputObject struct {
+	Name        string
+	Description string
+	Fields      []*Argument
+}
+
+func (o *InputObject) String() string { return o.Name }
+
+func (o *InputObject) field(name string) *Argument {
+	for _, f := range o.Fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// List список значений типа Of
+type List struct {
+	Of Type
+}
+
+// NewList создает тип списка
+func NewList(of Type) *List {
+	return &List{Of: of}
+}
+
+func (l *List) String() string { return "[" + l.Of.String() + "]" }
+
+// NonNull обязательное значение типа Of
+type NonNull struct {
+	Of Type
+}
+
+// NewNonNull создает обязательный тип
+func NewNonNull(of Type) *NonNull {
+	return &NonNull{Of: of}
+}
+
+func (n *NonNull) String() string { return n.Of.String() + "!" }
+
+// ResolveFunc вычисляет значение поля. Вместо значения можно вернуть Thunk:
+// он будет вычислен после вызова резолверов всех полей того же уровня.
+type ResolveFunc func(p ResolveParams) (any, error)
+
+// ResolveParams параметры вызова резолвера
+type ResolveParams struct {
+	Context context.Context
+	Source  any            // Значение родительского объекта; для полей Query — nil
+	Args    map[string]any // Аргументы после приведения и подстановки значений по умолчанию
+	Path    []any          // Путь к полю в ответе
+}
+
+// Thunk отложенное значение поля
+type Thunk func() (any, error)
+
+// Async запускает fn в отдельной горутине и возвращает Thunk ее результата.
+// Подходит для независимых вызовов на одном уровне, например полей Query.
+func Async(fn func() (any, error)) Thunk {
+	type result struct {
+		value any
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- result{err: fmt.Errorf("panic: %v", r)}
+			}
+		}()
+		value, err := fn()
+		done <- result{value: value, err: err}
+	}()
+
+	var res *result
+	return func() (any, error) {
+		if res == nil {
+			r := <-done
+			res = &r
+		}
+		return res.value, res.err
+	}
+}
+
+// Schema схема запросов
+type Schema struct {
+	Query *Object
+	types map[string]Type
+	order []Type // Именованные типы схемы в порядке обхода, без встроенных скаляров
+}
+
+var nameRe = regexp.MustCompile(`^[_A-Za-z][_0-9A-Za-z]*$`)
+
+// NewSchema проверяет типы, достижимые из query, и создает схему
+func NewSchema(query *Object) (*Schema, error) {
+	if query == nil {
+		return nil, fmt.Errorf("query type is required")
+	}
+	s := &Schema{Query: query, types: make(map[string]Type)}
+	for _, scalar := range []*Scalar{Int, Float, String, Boolean, ID} {
+		s.types[scalar.Name] = scalar
+	}
+	if err := s.collect(query); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Type возвращает именованный тип схемы или nil
+func (s *Schema) Type(name string) Type {
+	return s.types[name]
+}
+
+func (s *Schema) collect(t Type) error {
+	switch t := t.(type) {
+	case *List:
+		return s.collect(t.Of)
+	case *NonNull:
+		if _, ok := t.Of.(*NonNull); ok {
+			return fmt.Errorf("type %s: non-null of non-null", t)
+		}
+		return s.collect(t.Of)
+	case nil:
+		return fmt.Errorf("nil type")
+	}
+
+	name := t.String()
+	if !nameRe.MatchString(name) {
+		return fmt.Errorf("invalid type name %q", name)
+	}
+	if existing, ok := s.types[name]; ok {
+		if existing != t {
+			return fmt.Errorf("duplicate type %q", name)
+		}
+		return nil
+	}
+	s.types[name] = t
+	s.order = append(s.order, t)
+
+	switch t := t.(type) {
+	case *Scalar:
+		if t.Serialize == nil || t.ParseValue == nil {
+			return fmt.Errorf("scalar %q: Serialize and ParseValue are required", t.Name)
+		}
+	case *Enum:
+		if len(t.Values) == 0 {
+			return fmt.Errorf("enum %q has no values", t.Name)
+		}
+	case *Object:
+		if len(t.Fields) == 0 {
+			return fmt.Errorf("object %q has no fields", t.Name)
+		}
+		seen := make(map[string]bool, len(t.Fields))
+		for _, f := range t.Fields {
+			if !nameRe.MatchString(f.Name) || f.Name[:min(2, len(f.Name))] == "__" {
+				return fmt.Errorf("%s: invalid field name %q", t.Name, f.Name)
+			}
+			if seen[f.Name] {
+				return fmt.Errorf("%s: duplicate field %q", t.Name, f.Name)
+			}
+			seen[f.Name] = true
+			if f.Resolve == nil {
+				return fmt.Errorf("%s.%s: resolver is required", t.Name, f.Name)
+			}
+			if err := s.collect(f.Type); err != nil {
+				return fmt.Errorf("%s.%s: %w", t.Name, f.Name, err)
+			}
+			if !isOutputType(f.Type) {
+				return fmt.Errorf("%s.%s: %s is not an output type", t.Name, f.Name, f.Type)
+			}
+			if err := s.collectArgs(f.Args); err != nil {
+				return fmt.Errorf("%s.%s: %w", t.Name, f.Name, err)
+			}
+		}
+	case *InputObject:
+		if len(t.Fields) == 0 {
+			return fmt.Errorf("input %q has no fields", t.Name)
+		}
+		if err := s.collectArgs(t.Fields); err != nil {
+			return fmt.Errorf("%s: %w", t.Name, err)
+		}
+	default:
+		return fmt.Errorf("unsupported type %T", t)
+	}
+	return nil
+}
+
+func (s *Schema) collectArgs(args []*Argument) error {
+	seen := make(map[string]bool, len(args))
+	for _, arg := range args {
+		if !nameRe.MatchString(arg.Name) {
+			return fmt.Errorf("invalid argument name %q", arg.Name)
+		}
+		if seen[arg.Name] {
+			return fmt.Errorf("duplicate argument %q", arg.Name)
+		}
+		seen[arg.Name] = true
+		if err := s.collect(arg.Type); err != nil {
+			return fmt.Errorf("argument %q: %w", arg.Name, err)
+		}
+		if !isInputType(arg.Type) {
+			return fmt.Errorf("argument %q: %s is not an input type", arg.Name, arg.Type)
+		}
+	}
+	return nil
+}
+
+// namedType снимает обертки List и NonNull
+func namedType(t Type) Type {
+	for {
+		switch w := t.(type) {
+		case *List:
+			t = w.Of
+		case *NonNull:
+			t = w.Of
+		default:
+			return t
+		}
+	}
+}
+
+func isInputType(t Type) bool {
+	switch namedType(t).(type) {
+	case *Scalar, *Enum, *InputObject:
+		return true
+	}
+	return false
+}
+
+func isOutputType(t Type) bool {
+	switch namedType(t).(type) {
+	case *Scalar, *Enum, *Object:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// SDL возвращает схему на языке определения схем GraphQL.
+// Встроенные скаляры не выводятся.
+func (s *Schema) SDL() string {
+	var b strings.Builder
+	for i, t := range s.order {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		switch t := t.(type) {
+		case *Scalar:
+			writeDescription(&b, "", t.Description)
+			fmt.Fprintf(&b, "scalar %s\n", t.Name)
+		case *Enum:
+			writeDescription(&b, "", t.Description)
+			fmt.Fprintf(&b, "enum %s {\n", t.Name)
+			for _, v := range t.Values {
+				writeDescription(&b, "  ", v.Description)
+				fmt.Fprintf(&b, "  %s\n", v.Name)
+			}
+			b.WriteString("}\n")
+		case *Object:
+			writeDescription(&b, "", t.Description)
+			fmt.Fprintf(&b, "type %s {\n", t.Name)
+			for _, f := range t.Fields {
+				writeDescription(&b, "  ", f.Description)
+				fmt.Fprintf(&b, "  %s%s: %s", f.Name, sdlArgs(f.Args), f.Type)
+				if f.DeprecationReason != "" {
+					reason, _ := json.Marshal(f.DeprecationReason)
+					fmt.Fprintf(&b, " @deprecated(reason: %s)", reason)
+				}
+				b.WriteByte('\n')
+			}
+			b.WriteString("}\n")
+		case *InputObject:
+			writeDescription(&b, "", t.Description)
+			fmt.Fprintf(&b, "input %s {\n", t.Name)
+			for _, f := range t.Fields {
+				writeDescription(&b, "  ", f.Description)
+				fmt.Fprintf(&b, "  %s\n", sdlInputValue(f))
+			}
+			b.WriteString("}\n")
+		}
+	}
+	return b.String()
+}
+
+func sdlArgs(args []*Argument) string {
+	if len(args) == 0 {
+		return ""
+	}
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		parts[i] = sdlInputValue(arg)
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}
+
+func sdlInputValue(arg *Argument) string {
+	s := arg.Name + ": " + arg.Type.String()
+	if arg.Default != nil {
+		s += " = " + sdlValue(arg.Default, arg.Type)
+	}
+	return s
+}
+
+// sdlValue записывает значение Go литералом GraphQL
+func sdlValue(v any, t Type) string {
+	if v == nil {
+		return "null"
+	}
+	switch t := t.(type) {
+	case *NonNull:
+		return sdlValue(v, t.Of)
+	case *List:
+		items, ok := v.([]any)
+		if !ok {
+			return sdlValue(v, t.Of)
+		}
+		parts := make([]string, len(items))
+		for i, item := range items {
+			parts[i] = sdlValue(item, t.Of)
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+	case *Enum:
+		return fmt.Sprint(v)
+	case *InputObject:
+		fields, _ := v.(map[string]any)
+		var parts []string
+		for _, f := range t.Fields {
+			if fv, ok := fields[f.Name]; ok {
+				parts = append(parts, f.Name+": "+sdlValue(fv, f.Type))
+			}
+		}
+		return "{" + strings.Join(parts, ", ") + "}"
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+func writeDescription(b *strings.Builder, indent, description string) {
+	if description == "" {
+		return
+	}
+	description = strings.ReplaceAll(description, `"""`, `\"""`)
+	if !strings.Contains(description, "\n") {
+		fmt.Fprintf(b, "%s\"\"\"%s\"\"\"\n", indent, description)
+		return
+	}
+	fmt.Fprintf(b, "%s\"\"\"\n", indent)
+	for _, line := range strings.Split(description, "\n") {
+		fmt.Fprintf(b, "%s%s\n", indent, line)
+	}
+	fmt.Fprintf(b, "%s\"\"\"\n", indent)
+}
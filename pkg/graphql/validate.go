@@ -0,0 +1,188 @@
+package graphql
+
+import "fmt"
+
+// directiveArgs аргументы директив @skip и @include
+var directiveArgs = []*Argument{{Name: "if", Type: NewNonNull(Boolean)}}
+
+// validator проверяет операцию по схеме до выполнения: существование полей
+// и аргументов, выборки у объектов и скаляров, фрагменты и переменные.
+// Значения аргументов проверяются при приведении (executor.analyze).
+type validator struct {
+	schema    *Schema
+	doc       *Document
+	vars      map[string]bool
+	spreading map[string]bool // Фрагменты на текущем пути, для поиска циклов
+	validated map[string]bool
+	errs      []*Error
+}
+
+func validate(s *Schema, doc *Document, op *operation) []*Error {
+	if op.kind != "query" {
+		return []*Error{newError(op.loc, "schema does not support %s operations", op.kind)}
+	}
+
+	v := &validator{
+		schema:    s,
+		doc:       doc,
+		vars:      make(map[string]bool, len(op.variables)),
+		spreading: make(map[string]bool),
+		validated: make(map[string]bool),
+	}
+	for _, def := range op.variables {
+		v.vars[def.name] = true
+	}
+	for _, d := range op.directives {
+		v.errorf(d.loc, "directive @%s is not allowed on operations", d.name)
+	}
+	v.selections(s.Query, op.selections)
+	return v.errs
+}
+
+func (v *validator) errorf(loc Location, format string, args ...any) {
+	v.errs = append(v.errs, newError(loc, format, args...))
+}
+
+func (v *validator) selections(t *Object, sels []selection) {
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *fieldNode:
+			v.directives(sel.directives)
+			v.field(t, sel)
+		case *inlineFragment:
+			v.directives(sel.directives)
+			if v.typeCondition(t, sel.typeCondition, sel.loc) {
+				v.selections(t, sel.selections)
+			}
+		case *fragmentSpread:
+			v.directives(sel.directives)
+			v.spread(t, sel)
+		}
+	}
+}
+
+func (v *validator) field(t *Object, node *fieldNode) {
+	if node.name == "__typename" {
+		for _, arg := range node.arguments {
+			v.errorf(arg.loc, "unknown argument %q on field %q", arg.name, "__typename")
+		}
+		if len(node.selections) > 0 {
+			v.errorf(node.loc, "field %q must not have a selection since type %q has no subfields", node.name, String.Name)
+		}
+		return
+	}
+
+	f := t.Field(node.name)
+	if f == nil {
+		v.errorf(node.loc, "cannot query field %q on type %q", node.name, t.Name)
+		return
+	}
+	v.arguments(f.Args, node.arguments, fmt.Sprintf("field %q", t.Name+"."+f.Name), node.loc)
+
+	if obj, ok := namedType(f.Type).(*Object); ok {
+		if len(node.selections) == 0 {
+			v.errorf(node.loc, "field %q of type %q must have a selection of subfields", node.name, f.Type)
+			return
+		}
+		v.selections(obj, node.selections)
+	} else if len(node.selections) > 0 {
+		v.errorf(node.loc, "field %q must not have a selection since type %q has no subfields", node.name, f.Type)
+	}
+}
+
+func (v *validator) spread(t *Object, spread *fragmentSpread) {
+	frag, ok := v.doc.fragments[spread.name]
+	if !ok {
+		v.errorf(spread.loc, "unknown fragment %q", spread.name)
+		return
+	}
+	if v.spreading[spread.name] {
+		v.errorf(spread.loc, "cannot spread fragment %q within itself", spread.name)
+		return
+	}
+	if !v.typeCondition(t, frag.typeCondition, spread.loc) || v.validated[spread.name] {
+		return
+	}
+
+	v.spreading[spread.name] = true
+	v.directives(frag.directives)
+	v.selections(t, frag.selections)
+	delete(v.spreading, spread.name)
+	v.validated[spread.name] = true
+}
+
+// typeCondition проверяет условие типа фрагмента. В схеме только объектные типы,
+// поэтому условие должно совпадать с типом объекта.
+func (v *validator) typeCondition(t *Object, condition string, loc Location) bool {
+	if condition == "" || condition == t.Name {
+		return true
+	}
+	if _, ok := v.schema.types[condition]; !ok {
+		v.errorf(loc, "unknown type %q", condition)
+	} else {
+		v.errorf(loc, "fragment cannot be spread here as objects of type %q can never be of type %q", t.Name, condition)
+	}
+	return false
+}
+
+func (v *validator) directives(dirs []*directive) {
+	seen := make(map[string]bool, len(dirs))
+	for _, d := range dirs {
+		if d.name != "skip" && d.name != "include" {
+			v.errorf(d.loc, "unknown directive @%s", d.name)
+			continue
+		}
+		if seen[d.name] {
+			v.errorf(d.loc, "directive @%s can only be used once at this location", d.name)
+		}
+		seen[d.name] = true
+		v.arguments(directiveArgs, d.arguments, "directive @"+d.name, d.loc)
+	}
+}
+
+func (v *validator) arguments(defs []*Argument, nodes []*argumentNode, where string, loc Location) {
+	for _, node := range nodes {
+		known := false
+		for _, def := range defs {
+			if def.Name == node.name {
+				known = true
+				break
+			}
+		}
+		if !known {
+			v.errorf(node.loc, "unknown argument %q on %s", node.name, where)
+		}
+		v.variables(node.value)
+	}
+
+	for _, def := range defs {
+		if _, ok := def.Type.(*NonNull); !ok || def.Default != nil {
+			continue
+		}
+		provided := false
+		for _, node := range nodes {
+			provided = provided || node.name == def.Name
+		}
+		if !provided {
+			v.errorf(loc, "argument %q of type %q is required on %s", def.Name, def.Type, where)
+		}
+	}
+}
+
+// variables проверяет, что переменные в значении объявлены в операции
+func (v *validator) variables(val *value) {
+	switch val.kind {
+	case valueVariable:
+		if !v.vars[val.raw] {
+			v.errorf(val.loc, "variable %q is not defined", "$"+val.raw)
+		}
+	case valueList:
+		for _, item := range val.list {
+			v.variables(item)
+		}
+	case valueObject:
+		for _, field := range val.fields {
+			v.variables(field.value)
+		}
+	}
+}
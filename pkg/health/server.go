@@ -72,6 +72,10 @@ func (s *Server) setupChecks() {
 		s.health.AddCheck("event_service", GRPCChecker(s.eventClient, "event-service"))
 	}
 
+	for name, checker := range s.config.Checks {
+		s.health.AddCheck(name, checker)
+	}
+
 	s.log.Info("Health checks configured",
 		"service", s.config.ServiceName,
 		"version", s.config.Version,
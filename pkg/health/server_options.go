@@ -16,6 +16,8 @@ type Config struct {
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
 	IdleTimeout  time.Duration
+
+	Checks map[string]Checker // Дополнительные проверки
 }
 
 // Option функция для настройки health server
@@ -89,6 +91,16 @@ func WithHTTPTimeouts(read, write, idle time.Duration) Option {
 	}
 }
 
+// WithCheck добавляет дополнительную проверку
+func WithCheck(name string, checker Checker) Option {
+	return func(c *Config) {
+		if c.Checks == nil {
+			c.Checks = make(map[string]Checker)
+		}
+		c.Checks[name] = checker
+	}
+}
+
 // - Предустановленные конфигурации - \\
 
 // EventServiceOptions возвращает специфичные для event-service опции
@@ -0,0 +1,37 @@
+// Package history история переходов пользователей к событиям.
+//
+// Переходы приходят из выдачи (POST /events/search/click) и хранятся в gateway:
+// по истории пользователя строятся рекомендации, по всем переходам — популярность
+// событий для новых пользователей.
+package history
+
+import (
+	"context"
+	"time"
+)
+
+// Click переход к событию
+type Click struct {
+	EventID   int64
+	ClickedAt time.Time
+}
+
+// EventCount число переходов к событию
+type EventCount struct {
+	EventID int64
+	Count   int
+}
+
+// Store хранилище переходов. Повторный переход пользователя к событию поднимает
+// его наверх истории, а в популярности учитывается один раз.
+type Store interface {
+	// Add сохраняет переход. userID = 0 — анонимный переход: он не сохраняется,
+	// иначе популярность накручивалась бы повторными запросами без токена
+	Add(ctx context.Context, userID, eventID int64) error
+
+	// List возвращает переходы пользователя, новые первыми
+	List(ctx context.Context, userID int64) ([]Click, error)
+
+	// Popular возвращает события с наибольшим числом пользователей, переходивших к ним начиная с since
+	Popular(ctx context.Context, since time.Time, limit int) ([]EventCount, error)
+}
@@ -0,0 +1,149 @@
+package history
+
+import (
+	"cmp"
+	"container/list"
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// MemoryStore in-memory хранилище переходов. Для каждого пользователя хранится
+// не больше perUser событий; при превышении maxUsers вытесняются пользователи,
+// дольше всех не переходившие к событиям. Для популярности хранятся последние
+// maxClicks переходов всех пользователей; повторные переходы пользователя
+// к событию считаются один раз.
+type MemoryStore struct {
+	mu       sync.Mutex
+	perUser  int
+	maxUsers int
+	users    map[int64]*list.Element
+	order    *list.List // Начало списка — пользователи, переходившие последними
+
+	clicks []userClick // Кольцевой буфер переходов всех пользователей
+	next   int
+
+	now func() time.Time
+}
+
+type userClick struct {
+	Click
+	userID int64
+}
+
+type memoryUser struct {
+	id     int64
+	clicks []Click // Новые первыми
+}
+
+// NewMemoryStore создает хранилище на perUser событий для не более чем maxUsers
+// пользователей и maxClicks переходов для популярности
+func NewMemoryStore(perUser, maxUsers, maxClicks int) *MemoryStore {
+	if perUser <= 0 {
+		perUser = 1
+	}
+	if maxUsers <= 0 {
+		maxUsers = 1
+	}
+	if maxClicks <= 0 {
+		maxClicks = 1
+	}
+	return &MemoryStore{
+		perUser:  perUser,
+		maxUsers: maxUsers,
+		users:    make(map[int64]*list.Element),
+		order:    list.New(),
+		clicks:   make([]userClick, 0, maxClicks),
+		now:      time.Now,
+	}
+}
+
+// Add сохраняет переход
+func (s *MemoryStore) Add(_ context.Context, userID, eventID int64) error {
+	if eventID <= 0 || userID == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	click := Click{EventID: eventID, ClickedAt: s.now()}
+	if len(s.clicks) < cap(s.clicks) {
+		s.clicks = append(s.clicks, userClick{Click: click, userID: userID})
+	} else {
+		s.clicks[s.next] = userClick{Click: click, userID: userID}
+		s.next = (s.next + 1) % len(s.clicks)
+	}
+
+	elem, ok := s.users[userID]
+	if !ok {
+		elem = s.order.PushFront(&memoryUser{id: userID})
+		s.users[userID] = elem
+		for s.order.Len() > s.maxUsers {
+			oldest := s.order.Back()
+			s.order.Remove(oldest)
+			delete(s.users, oldest.Value.(*memoryUser).id)
+		}
+	}
+	s.order.MoveToFront(elem)
+
+	user := elem.Value.(*memoryUser)
+	clicks := make([]Click, 0, s.perUser)
+	clicks = append(clicks, click)
+	for _, c := range user.clicks {
+		if len(clicks) == s.perUser {
+			break
+		}
+		if c.EventID != eventID {
+			clicks = append(clicks, c)
+		}
+	}
+	user.clicks = clicks
+	return nil
+}
+
+// List возвращает переходы пользователя, новые первыми
+func (s *MemoryStore) List(_ context.Context, userID int64) ([]Click, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.users[userID]
+	if !ok {
+		return nil, nil
+	}
+	return slices.Clone(elem.Value.(*memoryUser).clicks), nil
+}
+
+// Popular возвращает события с наибольшим числом пользователей, переходивших к ним начиная с since
+func (s *MemoryStore) Popular(_ context.Context, since time.Time, limit int) ([]EventCount, error) {
+	s.mu.Lock()
+	counts := make(map[int64]int)
+	seen := make(map[userClick]bool)
+	for _, click := range s.clicks {
+		if click.ClickedAt.Before(since) {
+			continue
+		}
+		key := userClick{Click: Click{EventID: click.EventID}, userID: click.userID}
+		if !seen[key] {
+			seen[key] = true
+			counts[click.EventID]++
+		}
+	}
+	s.mu.Unlock()
+
+	popular := make([]EventCount, 0, len(counts))
+	for eventID, count := range counts {
+		popular = append(popular, EventCount{EventID: eventID, Count: count})
+	}
+	slices.SortFunc(popular, func(a, b EventCount) int {
+		if c := cmp.Compare(b.Count, a.Count); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.EventID, b.EventID)
+	})
+	if limit > 0 && len(popular) > limit {
+		popular = popular[:limit]
+	}
+	return popular, nil
+}
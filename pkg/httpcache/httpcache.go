@@ -0,0 +1,183 @@
+package httpcache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Policy политика HTTP кэширования для маршрута
+type Policy struct {
+	// MaxAge время, в течение которого ответ считается свежим.
+	// Нулевое значение означает "no-cache": клиент обязан перепроверять ответ (304 по-прежнему работает).
+	MaxAge time.Duration
+
+	// StaleWhileRevalidate время, в течение которого устаревший ответ можно отдавать,
+	// обновляя его в фоне
+	StaleWhileRevalidate time.Duration
+}
+
+// varyHeaders заголовки, от которых может зависеть ответ: аутентификация и язык
+var varyHeaders = []string{"Authorization", "Cookie", "Accept-Language"}
+
+// CacheControl формирует значение заголовка Cache-Control.
+// Для аутентифицированных запросов ответ помечается как private,
+// чтобы общие кэши (CDN, прокси) не отдавали его другим пользователям.
+func (p Policy) CacheControl(authenticated bool) string {
+	scope := "public"
+	if authenticated {
+		scope = "private"
+	}
+
+	if p.MaxAge <= 0 {
+		return scope + ", no-cache"
+	}
+
+	value := fmt.Sprintf("%s, max-age=%d", scope, int(p.MaxAge.Seconds()))
+	if p.StaleWhileRevalidate > 0 {
+		value += fmt.Sprintf(", stale-while-revalidate=%d", int(p.StaleWhileRevalidate.Seconds()))
+	}
+	return value
+}
+
+// Middleware добавляет заголовки кэширования и обрабатывает условные GET запросы.
+//
+// Ответ буферизуется: если обработчик не установил ETag, он вычисляется
+// из тела ответа. Last-Modified устанавливает сам обработчик, если знает время
+// изменения ресурса. На If-None-Match / If-Modified-Since отвечает 304.
+// Кэшируются только успешные (200) ответы на GET и HEAD.
+func Middleware(policy Policy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			rec := &recorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rec, r)
+
+			header := w.Header()
+			for _, h := range varyHeaders {
+				header.Add("Vary", h)
+			}
+
+			if rec.status != http.StatusOK {
+				rec.flush()
+				return
+			}
+
+			if header.Get("ETag") == "" {
+				header.Set("ETag", bodyETag(rec.body.Bytes()))
+			}
+			if header.Get("Cache-Control") == "" {
+				header.Set("Cache-Control", policy.CacheControl(isAuthenticated(r)))
+			}
+
+			if notModified(r, header) {
+				for _, h := range []string{"Content-Type", "Content-Length"} {
+					header.Del(h)
+				}
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+
+			rec.flush()
+		})
+	}
+}
+
+// isAuthenticated проверяет, передал ли клиент учетные данные
+func isAuthenticated(r *http.Request) bool {
+	if r.Header.Get("Authorization") != "" {
+		return true
+	}
+	for _, c := range r.Cookies() {
+		if c.Name == "access_token" {
+			return true
+		}
+	}
+	return false
+}
+
+// bodyETag вычисляет слабый ETag по телу ответа.
+// ETag слабый, так как тело может быть сжато дальше по цепочке middleware.
+func bodyETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `W/"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`
+}
+
+// notModified проверяет условия If-None-Match и If-Modified-Since (RFC 9110, 13.2.2).
+// If-Modified-Since учитывается только при отсутствии If-None-Match.
+func notModified(r *http.Request, header http.Header) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return etagMatches(inm, header.Get("ETag"))
+	}
+
+	ims := r.Header.Get("If-Modified-Since")
+	lm := header.Get("Last-Modified")
+	if ims == "" || lm == "" {
+		return false
+	}
+
+	since, err := http.ParseTime(ims)
+	if err != nil {
+		return false
+	}
+	modified, err := http.ParseTime(lm)
+	if err != nil {
+		return false
+	}
+	return !modified.After(since)
+}
+
+// etagMatches выполняет слабое сравнение ETag из списка If-None-Match с текущим
+func etagMatches(list, etag string) bool {
+	if etag == "" {
+		return false
+	}
+	if strings.TrimSpace(list) == "*" {
+		return true
+	}
+
+	current := strings.TrimPrefix(etag, "W/")
+	for _, tag := range strings.Split(list, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(tag), "W/") == current {
+			return true
+		}
+	}
+	return false
+}
+
+// recorder буферизует ответ обработчика до принятия решения о 304
+type recorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rec *recorder) WriteHeader(status int) {
+	if rec.wroteHeader {
+		return
+	}
+	rec.status = status
+	rec.wroteHeader = true
+}
+
+func (rec *recorder) Write(b []byte) (int, error) {
+	if !rec.wroteHeader {
+		rec.WriteHeader(http.StatusOK)
+	}
+	return rec.body.Write(b)
+}
+
+// flush отправляет буферизованный ответ клиенту
+func (rec *recorder) flush() {
+	rec.ResponseWriter.WriteHeader(rec.status)
+	rec.ResponseWriter.Write(rec.body.Bytes())
+}
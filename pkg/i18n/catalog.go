@@ -0,0 +1,109 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// verbPattern глаголы fmt в английских шаблонах сообщений
+var verbPattern = regexp.MustCompile(`%[-+# 0]*[0-9]*[vsdqwTfg]`)
+
+// Catalog переводы сообщений шлюза.
+//
+// Сообщения об ошибках формируются через fmt.Errorf на английском, поэтому
+// ключ перевода — исходный шаблон ("invalid limit %q: must be between 1 and %d").
+// Аргументы извлекаются из готового сообщения и подставляются в перевод
+// по номерам: "недопустимый limit %[1]s: допустимо от 1 до %[2]s".
+type Catalog struct {
+	mu      sync.RWMutex
+	entries map[string][]catalogEntry
+}
+
+type catalogEntry struct {
+	pattern     *regexp.Regexp
+	literal     int // Длина шаблона без глаголов: при нескольких совпадениях побеждает самый конкретный
+	translation string
+}
+
+// NewCatalog создает пустой каталог
+func NewCatalog() *Catalog {
+	return &Catalog{entries: make(map[string][]catalogEntry)}
+}
+
+// Add регистрирует перевод английского шаблона на язык lang.
+// Все аргументы в переводе — строки, на них ссылаются как %[n]s.
+func (c *Catalog) Add(lang, format, translation string) {
+	literals := verbPattern.Split(format, -1)
+	quoted := make([]string, len(literals))
+	length := 0
+	for i, literal := range literals {
+		quoted[i] = regexp.QuoteMeta(literal)
+		length += len(literal)
+	}
+
+	entry := catalogEntry{
+		pattern:     regexp.MustCompile(`^(?s)` + strings.Join(quoted, `(.+?)`) + `$`),
+		literal:     length,
+		translation: translation,
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	lang = Normalize(lang)
+	c.entries[lang] = append(c.entries[lang], entry)
+}
+
+// Translate переводит сообщение на lang. Если перевода нет, возвращает msg и false.
+func (c *Catalog) Translate(lang, msg string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var best *catalogEntry
+	var args []string
+	for i := range c.entries[Normalize(lang)] {
+		entry := &c.entries[Normalize(lang)][i]
+		if best != nil && entry.literal <= best.literal {
+			continue
+		}
+		if match := entry.pattern.FindStringSubmatch(msg); match != nil {
+			best, args = entry, match[1:]
+		}
+	}
+	if best == nil {
+		return msg, false
+	}
+
+	values := make([]any, len(args))
+	for i, arg := range args {
+		values[i] = arg
+	}
+	return fmt.Sprintf(best.translation, values...), true
+}
+
+// Messages каталог сообщений шлюза
+var Messages = NewCatalog()
+
+// Localize переводит сообщение на язык запроса, если клиент его указал
+func Localize(ctx context.Context, msg string) string {
+	locale, ok := FromContext(ctx)
+	if !ok || !locale.Requested {
+		return msg
+	}
+	translated, _ := Messages.Translate(locale.Lang, msg)
+	return translated
+}
+
+// Text переводит пользовательский текст (не ошибку) на язык контента запроса.
+// В отличие от Localize, переводит и без явно указанного языка: текст должен
+// быть на том же языке, что и названия событий рядом с ним.
+func Text(ctx context.Context, msg string) string {
+	locale, ok := FromContext(ctx)
+	if !ok {
+		return msg
+	}
+	translated, _ := Messages.Translate(locale.Lang, msg)
+	return translated
+}
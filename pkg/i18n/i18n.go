@@ -0,0 +1,149 @@
+// Package i18n выбор языка ответа и перевод сообщений шлюза.
+//
+// Язык выбирается из параметра ?lang= или заголовка Accept-Language среди
+// поддерживаемых (для en-US подходит en) и сохраняется в контексте запроса
+// (Middleware). Если перевода контента на выбранный язык нет, используется
+// язык по умолчанию. Сообщения об ошибках переводятся каталогом Messages.
+package i18n
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	contextkeys "github.com/rx3lixir/gateway-service/pkg/context"
+)
+
+// LangParam параметр запроса, имеющий приоритет над Accept-Language
+const LangParam = "lang"
+
+// Languages поддерживаемые языки контента
+type Languages struct {
+	Default   string   // Язык основных полей (name, description)
+	Supported []string // Включая Default
+}
+
+// Locale выбранный для запроса язык
+type Locale struct {
+	Lang    string // Выбранный поддерживаемый язык
+	Default string // Язык основных полей
+
+	// Requested клиент явно указал язык (lang или Accept-Language).
+	// Без этого сообщения об ошибках остаются на английском, как раньше.
+	Requested bool
+
+	// Fallback цепочка языков для контента: Lang, затем Default
+	Fallback []string
+}
+
+// Normalize приводит код языка к виду "en", "en-us"
+func Normalize(tag string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+}
+
+// base возвращает основной язык тега: "en-us" -> "en"
+func base(tag string) string {
+	if i := strings.IndexByte(tag, '-'); i > 0 {
+		return tag[:i]
+	}
+	return tag
+}
+
+// ParseAcceptLanguage разбирает Accept-Language (RFC 9110, 12.5.4) и возвращает
+// теги по убыванию веса. Теги с q=0 и "*" пропускаются.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		tag = Normalize(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if ok && strings.EqualFold(name, "q") {
+				if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		if q > 0 {
+			tags = append(tags, weighted{tag, q})
+		}
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	result := make([]string, len(tags))
+	for i, t := range tags {
+		result[i] = t.tag
+	}
+	return result
+}
+
+// Negotiate выбирает язык из запрошенных тегов: точное совпадение или базовый язык
+func (l Languages) Negotiate(requested []string) (string, bool) {
+	for _, tag := range requested {
+		tag = Normalize(tag)
+		if slices.Contains(l.Supported, tag) {
+			return tag, true
+		}
+		if b := base(tag); slices.Contains(l.Supported, b) {
+			return b, true
+		}
+	}
+	return l.Default, false
+}
+
+// LocaleFor выбирает язык запроса: ?lang= важнее Accept-Language
+func (l Languages) LocaleFor(r *http.Request) Locale {
+	var requested []string
+	if lang := r.URL.Query().Get(LangParam); lang != "" {
+		requested = append(requested, lang)
+	}
+	requested = append(requested, ParseAcceptLanguage(r.Header.Get("Accept-Language"))...)
+
+	lang, ok := l.Negotiate(requested)
+	locale := Locale{Lang: lang, Default: l.Default, Requested: ok, Fallback: []string{lang}}
+	if lang != l.Default {
+		locale.Fallback = append(locale.Fallback, l.Default)
+	}
+	return locale
+}
+
+// Middleware выбирает язык запроса и сохраняет Locale в контексте
+func Middleware(languages Languages) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), contextkeys.LocaleKey, languages.LocaleFor(r))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// FromContext возвращает язык запроса. Без Middleware — пустой Locale.
+func FromContext(ctx context.Context) (Locale, bool) {
+	locale, ok := ctx.Value(contextkeys.LocaleKey).(Locale)
+	return locale, ok
+}
+
+// Resolve выбирает первый язык цепочки Fallback, для которого есть перевод.
+// Язык по умолчанию доступен всегда: это основные поля.
+func (l Locale) Resolve(has func(lang string) bool) string {
+	for _, lang := range l.Fallback {
+		if lang == l.Default || has(lang) {
+			return lang
+		}
+	}
+	return l.Default
+}
@@ -0,0 +1,148 @@
+package i18n
+
+// Переводы сообщений шлюза на русский. Ключ — шаблон fmt из обработчика,
+// поэтому при изменении текста ошибки нужно обновить и шаблон здесь.
+func init() {
+	for format, translation := range messagesRu {
+		Messages.Add("ru", format, translation)
+	}
+}
+
+var messagesRu = map[string]string{
+	// Общие
+	"An unexpected error occurred":             "Произошла непредвиденная ошибка",
+	"Unauthorized":                             "Требуется авторизация",
+	"unauthorized":                             "требуется авторизация",
+	"Authorization required":                   "Требуется авторизация",
+	"authorization required":                   "требуется авторизация",
+	"Invalid authorization header format":      "Неверный формат заголовка Authorization",
+	"Invalid or expired token":                 "Токен недействителен или истек",
+	"Invalid API key":                          "Неверный API ключ",
+	"Access denied: admin privileges required": "Доступ запрещен: требуются права администратора",
+	"access denied":                            "доступ запрещен",
+	"permission denied":                        "доступ запрещен",
+	"invalid credentials":                      "неверный email или пароль",
+	"email and password are required":          "email и пароль обязательны",
+	"name, email and password are required":    "имя, email и пароль обязательны",
+	"invalid session":                          "сессия недействительна",
+	"session is revoked":                       "сессия отозвана",
+	"refresh token not found":                  "refresh токен не найден",
+	"Too many requests, retry later":           "Слишком много запросов, повторите позже",
+
+	// Запрос
+	"invalid request body: %s":                                         "некорректное тело запроса: %[1]s",
+	"invalid request body: no fields to update":                        "некорректное тело запроса: нет полей для обновления",
+	"invalid request body: batch is empty":                             "некорректное тело запроса: пакет пуст",
+	"invalid request body: batch contains %d events, maximum is %d":    "некорректное тело запроса: в пакете %[1]s событий, максимум %[2]s",
+	"invalid request body: larger than %d bytes":                       "некорректное тело запроса: больше %[1]s байт",
+	"invalid query parameters: %s":                                     "некорректные параметры запроса: %[1]s",
+	"invalid %s format: %s":                                            "неверный формат %[1]s: %[2]s",
+	"%s must be a positive integer, got %d":                            "%[1]s должен быть положительным целым числом, получено %[2]s",
+	"invalid %s parameter %q: expected true or false":                  "неверный параметр %[1]s %[2]s: ожидается true или false",
+	"invalid q: must be at most %d characters":                         "неверный q: допустимо не более %[1]s символов",
+	"invalid max_results %q: must be between 1 and %d":                 "неверный max_results %[1]s: допустимо от 1 до %[2]s",
+	"invalid fields %q: expected any of %s":                            "неверный fields %[1]s: допустимы %[2]s",
+	"invalid group_by %q: expected type":                               "неверный group_by %[1]s: допустимо type",
+	"invalid window %q: expected duration between %s and %s":           "неверный window %[1]s: допустима длительность от %[2]s до %[3]s",
+	"saved search name is required":                                    "название сохраненного поиска обязательно",
+	"saved search %s not found":                                        "сохраненный поиск %[1]s не найден",
+	"saved search limit reached: at most %d per user":                  "достигнут лимит сохраненных поисков: не более %[1]s на пользователя",
+	"filters are required":                                             "фильтры обязательны",
+	"invalid filters: statuses are not supported in saved searches":    "неверные фильтры: статусы не поддерживаются в сохраненных поисках",
+	"invalid filters: at least one filter is required":                 "неверные фильтры: нужен хотя бы один фильтр",
+	"invalid name: must be at most %d characters":                      "неверное название: допустимо не более %[1]s символов",
+	"query is required":                                                "query обязателен",
+	"invalid event_id: must be a positive integer":                     "неверный event_id: должен быть положительным целым числом",
+	"invalid position: must be a positive integer":                     "неверный position: должен быть положительным целым числом",
+	"invalid source %q: expected one of %s":                            "неверный source %[1]s: допустимы %[2]s",
+	"invalid query: must be at most %d characters":                     "неверный query: допустимо не более %[1]s символов",
+	"invalid limit %q: must be between 1 and %d":                       "неверный limit %[1]s: допустимо от 1 до %[2]s",
+	"invalid offset %q: must be a non-negative integer":                "неверный offset %[1]s: ожидается неотрицательное целое число",
+	"invalid related_limit %q: must be between 0 and %d":               "неверный related_limit %[1]s: допустимо от 0 до %[2]s",
+	"invalid Content-Type: %s":                                         "неверный Content-Type: %[1]s",
+	"resource has been modified: If-Match does not match current ETag": "ресурс изменен: If-Match не совпадает с текущим ETag",
+	"patch operation %d: test failed for %q: value does not match":     "операция патча %[1]s: проверка %[2]s не прошла, значение не совпадает",
+
+	// События
+	"event name is required":                                                  "название события обязательно",
+	"event with id %d not found":                                              "событие с id %[1]s не найдено",
+	"event %d was modified concurrently, retry":                               "событие %[1]s было изменено параллельно, повторите запрос",
+	"category %d was modified concurrently, retry":                            "категория %[1]s была изменена параллельно, повторите запрос",
+	"invalid date format: expected YYYY-MM-DD, got %q":                        "неверный формат даты: ожидается ГГГГ-ММ-ДД, получено %[1]s",
+	"invalid %s format: expected YYYY-MM-DD, got %q":                          "неверный формат %[1]s: ожидается ГГГГ-ММ-ДД, получено %[2]s",
+	"invalid time format: expected HH:MM, got %q":                             "неверный формат времени: ожидается ЧЧ:ММ, получено %[1]s",
+	"invalid date range: date_from must not be after date_to":                 "неверный диапазон дат: date_from не может быть позже date_to",
+	"date is required when time is set":                                       "при указании времени дата обязательна",
+	"lat and lon are required together":                                       "lat и lon указываются вместе",
+	"invalid latitude %v: must be between -90 and 90":                         "неверная широта %[1]s: допустимо от -90 до 90",
+	"invalid longitude %v: must be between -180 and 180":                      "неверная долгота %[1]s: допустимо от -180 до 180",
+	"invalid starts_at format: expected RFC 3339, got %q":                     "неверный формат starts_at: ожидается RFC 3339, получено %[1]s",
+	"invalid ends_at format: expected RFC 3339, got %q":                       "неверный формат ends_at: ожидается RFC 3339, получено %[1]s",
+	"invalid ends_at: must be after starts_at":                                "неверный ends_at: должен быть позже starts_at",
+	"starts_at is required when ends_at is set":                               "при указании ends_at starts_at обязателен",
+	"starts_at is required when recurrence_rule is set":                       "при указании recurrence_rule starts_at обязателен",
+	"invalid time_zone %q: expected IANA time zone name":                      "неверный time_zone %[1]s: ожидается часовой пояс IANA",
+	"invalid status %q: expected one of %s":                                   "неверный статус %[1]s: допустимые значения %[2]s",
+	"status filter requires moderator rights":                                 "фильтр по статусу доступен только модераторам",
+	"only moderators can set event status":                                    "только модераторы могут задавать статус события",
+	"reason is required to %s an event":                                       "для действия %[1]s нужно указать причину",
+	"cannot %s event %d in status %q":                                         "нельзя выполнить %[1]s для события %[2]s в статусе %[3]s",
+	"possible duplicate events found, retry with force=true to create anyway": "найдены возможные дубликаты, чтобы все равно создать событие, повторите запрос с force=true",
+	"event has no start time and cannot be added to a calendar":               "у события нет времени начала, его нельзя добавить в календарь",
+	"invalid image: %s":                                                       "некорректное изображение: %[1]s",
+	"invalid language %q: supported languages are %s":                         "неверный язык %[1]s: поддерживаются %[2]s",
+	"translation name is required":                                            "название перевода обязательно",
+	"translation for %q not found":                                            "перевод на %[1]s не найден",
+
+	// Категории
+	"category name is required":            "название категории обязательно",
+	"category slug is required":            "slug категории обязателен",
+	"category with id %d not found":        "категория с id %[1]s не найдена",
+	"category with slug %q not found":      "категория со slug %[1]s не найдена",
+	"category with slug %q already exists": "категория со slug %[1]s уже существует",
+	"invalid slug %q: expected lowercase latin letters, digits and hyphens, at most %d characters":                 "неверный slug %[1]s: допустимы строчные латинские буквы, цифры и дефисы, не более %[2]s символов",
+	"invalid slug %q: must contain at least one letter or hyphen":                                                  "неверный slug %[1]s: нужна хотя бы одна буква или дефис",
+	"invalid name %q: cannot derive a slug from it, slug is required":                                              "неверное название %[1]s: из него не получается slug, укажите slug явно",
+	"invalid parent_id %d: no such category":                                                                       "неверный parent_id %[1]s: категория не существует",
+	"invalid parent_id %d: category cannot be moved under itself or its subcategory":                               "неверный parent_id %[1]s: категорию нельзя перенести в нее саму или ее подкатегорию",
+	"category %d is referenced by %d events and %d subcategories: use strategy=reassign&to=ID or strategy=cascade": "на категорию %[1]s ссылаются событий: %[2]s, подкатегорий: %[3]s; используйте strategy=reassign&to=ID или strategy=cascade",
+	"invalid strategy %q: expected one of reject, reassign, cascade":                                               "неверная стратегия %[1]s: допустимы reject, reassign, cascade",
+	"to parameter is required for strategy=reassign":                                                               "для strategy=reassign нужен параметр to",
+
+	// Регистрации на события
+	"registration for event %d is not open":            "регистрация на событие %[1]s не открыта",
+	"event %d has already taken place":                 "событие %[1]s уже прошло",
+	"capacity is required":                             "вместимость обязательна",
+	"invalid capacity: must be a non-negative integer": "неверная вместимость: ожидается неотрицательное целое число",
+
+	// Объяснения рекомендаций
+	"Similar to your favorites in %s":                  "Похоже на ваше избранное в категории «%[1]s»",
+	"Takes place at %s, like events in your favorites": "Проходит в месте «%[1]s», как события из вашего избранного",
+	"You recently viewed events in %s":                 "Вы недавно смотрели события в категории «%[1]s»",
+	"Matches your saved search %q":                     "Подходит под ваш сохраненный поиск %[1]s",
+	"Matches your recent search %q":                    "Подходит под ваш недавний запрос %[1]s",
+	"Popular with other users":                         "Популярно у других пользователей",
+	"Coming up soon":                                   "Скоро состоится",
+
+	// GraphQL
+	"query depth %d exceeds the limit of %d":                                    "глубина запроса %[1]s превышает лимит %[2]s",
+	"query complexity %d exceeds the limit of %d":                               "сложность запроса %[1]s превышает лимит %[2]s",
+	"cannot query field %q on type %q":                                          "нельзя запросить поле %[1]s у типа %[2]s",
+	"invalid content type %q: expected application/json or application/graphql": "неверный Content-Type %[1]s: ожидается application/json или application/graphql",
+	"invalid method %s: expected GET or POST":                                   "неверный метод %[1]s: ожидается GET или POST",
+	"invalid variables: %s":                                                     "неверные variables: %[1]s",
+	"invalid extensions: %s":                                                    "неверные extensions: %[1]s",
+	"provided sha does not match query":                                         "хэш не совпадает с текстом запроса",
+	"either id or slug is required":                                             "нужен id или slug",
+	"invalid arguments: specify either id or slug, not both":                    "неверные аргументы: укажите id или slug, но не оба",
+	"request timed out":                                                         "превышено время ожидания запроса",
+
+	// Не настроено
+	"saved searches are not configured":   "сохраненные поиски не настроены",
+	"search analytics are not configured": "поисковая аналитика не настроена",
+	"recent searches are not configured":  "недавние запросы не настроены",
+	"favorites are not configured":        "избранное не настроено",
+	"rsvp is not configured":              "регистрации на события не настроены",
+	"image upload is not configured":      "загрузка изображений не настроена",
+	"event streams are not configured":    "уведомления о событиях не настроены",
+}
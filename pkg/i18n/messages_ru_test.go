@@ -0,0 +1,106 @@
+package i18n
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// sourceStrings собирает строковые литералы из Go файлов модуля, кроме
+// пакета i18n, тестов и сгенерированного кода. Глаголы fmt приводятся к %v:
+// каталог сопоставляет аргументы независимо от глагола.
+func sourceStrings(t *testing.T) map[string]bool {
+	t.Helper()
+
+	root := filepath.Join("..", "..")
+	self, err := filepath.Abs(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	literals := make(map[string]bool)
+	fset := token.NewFileSet()
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			abs, err := filepath.Abs(path)
+			if err != nil {
+				return err
+			}
+			if abs == self || d.Name() == "gen" || strings.HasPrefix(d.Name(), ".") && path != root {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+
+		file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return err
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			if lit, ok := n.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+				if value, err := strconv.Unquote(lit.Value); err == nil {
+					literals[verbPattern.ReplaceAllString(value, "%v")] = true
+				}
+			}
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return literals
+}
+
+// Шаблон в каталоге должен совпадать с текстом ошибки в обработчике:
+// после правки сообщения перевод молча перестает применяться
+func TestMessagesRuMatchSource(t *testing.T) {
+	literals := sourceStrings(t)
+	for format := range messagesRu {
+		if !literals[verbPattern.ReplaceAllString(format, "%v")] {
+			t.Errorf("message %q is not used in the source: update the template or remove the translation", format)
+		}
+	}
+}
+
+var translationArgPattern = regexp.MustCompile(`%\[(\d+)\]s`)
+
+func TestMessagesRuArguments(t *testing.T) {
+	for format, translation := range messagesRu {
+		verbs := len(verbPattern.FindAllString(format, -1))
+		for _, match := range translationArgPattern.FindAllStringSubmatch(translation, -1) {
+			if n, _ := strconv.Atoi(match[1]); n < 1 || n > verbs {
+				t.Errorf("translation of %q refers to argument %s, template has %d", format, match[1], verbs)
+			}
+		}
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want string
+	}{
+		{msg: "category name is required", want: "название категории обязательно"},
+		{msg: `invalid dry_run parameter "yes": expected true or false`, want: `неверный параметр dry_run "yes": ожидается true или false`},
+		{msg: "no such message", want: "no such message"},
+	}
+
+	for _, tt := range tests {
+		if got, _ := Messages.Translate("ru", tt.msg); got != tt.want {
+			t.Errorf("Translate(%q) = %q, want %q", tt.msg, got, tt.want)
+		}
+	}
+}
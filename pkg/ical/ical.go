@@ -0,0 +1,291 @@
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// maxLineOctets максимальная длина строки без переноса (RFC 5545, 3.1)
+	maxLineOctets = 75
+
+	dateTimeUTCLayout   = "20060102T150405Z"
+	dateTimeLocalLayout = "20060102T150405"
+
+	// tzTransitionYears на сколько лет вперед от последнего события описываются переходы часового пояса
+	tzTransitionYears = 3
+)
+
+// Event событие календаря (VEVENT)
+type Event struct {
+	UID          string
+	Summary      string
+	Description  string
+	Location     string
+	URL          string
+	Categories   []string
+	Start        time.Time
+	End          *time.Time
+	RRule        string // Без префикса "RRULE:"
+	Created      time.Time
+	LastModified time.Time
+	Lat, Lon     *float64
+}
+
+// Writer последовательно записывает календарь в формате iCalendar (RFC 5545).
+//
+// Описания часовых поясов (VTIMEZONE) для всех TZID, встреченных в событиях,
+// записываются при Close: порядок компонентов внутри VCALENDAR не важен,
+// а события можно отдавать клиенту потоком.
+type Writer struct {
+	w      *bufio.Writer
+	now    func() time.Time
+	err    error
+	opened bool
+	zones  map[string]*zoneSpan
+}
+
+// zoneSpan часовой пояс и диапазон времен событий в нем
+type zoneSpan struct {
+	loc      *time.Location
+	from, to time.Time
+}
+
+// NewWriter создает Writer и записывает заголовок календаря.
+// name — отображаемое имя календаря (X-WR-CALNAME), может быть пустым.
+func NewWriter(w io.Writer, prodID, name string) *Writer {
+	cw := &Writer{w: bufio.NewWriter(w), now: time.Now, zones: make(map[string]*zoneSpan)}
+	cw.line("BEGIN:VCALENDAR")
+	cw.line("VERSION:2.0")
+	cw.line("PRODID:" + prodID)
+	cw.line("CALSCALE:GREGORIAN")
+	cw.line("METHOD:PUBLISH")
+	if name != "" {
+		cw.line("X-WR-CALNAME:" + escapeText(name))
+	}
+	cw.opened = true
+	return cw
+}
+
+// WriteEvent записывает одно событие.
+//
+// Времена в часовом поясе, отличном от UTC, записываются с TZID,
+// чтобы клиенты календаря показывали их в поясе события и сохраняли
+// "настенное" время повторений при переходе на летнее время.
+//
+// DTSTAMP равен времени последнего изменения события (или создания), а не
+// текущему: так календарь без изменений отдается одинаковым телом и
+// условные запросы по ETag получают 304.
+func (cw *Writer) WriteEvent(e Event) error {
+	stamp := e.LastModified
+	if stamp.IsZero() {
+		stamp = e.Created
+	}
+	if stamp.IsZero() {
+		stamp = cw.now()
+	}
+
+	cw.line("BEGIN:VEVENT")
+	cw.line("UID:" + escapeText(e.UID))
+	cw.line("DTSTAMP:" + stamp.UTC().Format(dateTimeUTCLayout))
+	cw.line(cw.dateTimeProperty("DTSTART", e.Start))
+	if e.End != nil {
+		cw.line(cw.dateTimeProperty("DTEND", *e.End))
+	}
+	if e.RRule != "" {
+		cw.line("RRULE:" + strings.TrimPrefix(e.RRule, "RRULE:"))
+	}
+	cw.line("SUMMARY:" + escapeText(e.Summary))
+	if e.Description != "" {
+		cw.line("DESCRIPTION:" + escapeText(e.Description))
+	}
+	if e.Location != "" {
+		cw.line("LOCATION:" + escapeText(e.Location))
+	}
+	if e.Lat != nil && e.Lon != nil {
+		cw.line(fmt.Sprintf("GEO:%.6f;%.6f", *e.Lat, *e.Lon))
+	}
+	if e.URL != "" {
+		cw.line("URL:" + e.URL)
+	}
+	if len(e.Categories) > 0 {
+		escaped := make([]string, 0, len(e.Categories))
+		for _, c := range e.Categories {
+			escaped = append(escaped, escapeText(c))
+		}
+		cw.line("CATEGORIES:" + strings.Join(escaped, ","))
+	}
+	if !e.Created.IsZero() {
+		cw.line("CREATED:" + e.Created.UTC().Format(dateTimeUTCLayout))
+	}
+	if !e.LastModified.IsZero() {
+		cw.line("LAST-MODIFIED:" + e.LastModified.UTC().Format(dateTimeUTCLayout))
+	}
+	cw.line("END:VEVENT")
+	return cw.err
+}
+
+// Flush отправляет записанные данные в нижележащий io.Writer
+func (cw *Writer) Flush() error {
+	if cw.err == nil {
+		cw.err = cw.w.Flush()
+	}
+	return cw.err
+}
+
+// Close записывает описания часовых поясов, окончание календаря и сбрасывает буфер
+func (cw *Writer) Close() error {
+	if cw.opened {
+		names := make([]string, 0, len(cw.zones))
+		for name := range cw.zones {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			cw.writeTimezone(name, cw.zones[name])
+		}
+		cw.line("END:VCALENDAR")
+		cw.opened = false
+	}
+	return cw.Flush()
+}
+
+// line записывает строку контента с переносом длинных строк и CRLF
+func (cw *Writer) line(content string) {
+	if cw.err != nil {
+		return
+	}
+	_, cw.err = cw.w.WriteString(fold(content) + "\r\n")
+}
+
+// dateTimeProperty форматирует DTSTART/DTEND и запоминает использованный часовой пояс
+func (cw *Writer) dateTimeProperty(name string, t time.Time) string {
+	tzid := t.Location().String()
+	if t.Location() == time.UTC || tzid == "UTC" || tzid == "Local" {
+		return name + ":" + t.UTC().Format(dateTimeUTCLayout)
+	}
+
+	span, ok := cw.zones[tzid]
+	if !ok {
+		cw.zones[tzid] = &zoneSpan{loc: t.Location(), from: t, to: t}
+	} else {
+		if t.Before(span.from) {
+			span.from = t
+		}
+		if t.After(span.to) {
+			span.to = t
+		}
+	}
+
+	return fmt.Sprintf("%s;TZID=%s:%s", name, tzid, t.Format(dateTimeLocalLayout))
+}
+
+// writeTimezone записывает VTIMEZONE с переходами часового пояса вокруг времен событий.
+// Диапазон расширяется на год назад и на несколько лет вперед, чтобы покрыть повторения.
+func (cw *Writer) writeTimezone(tzid string, span *zoneSpan) {
+	from := span.from.AddDate(-1, 0, 0).In(span.loc)
+	to := span.to.AddDate(tzTransitionYears, 0, 0).In(span.loc)
+
+	cw.line("BEGIN:VTIMEZONE")
+	cw.line("TZID:" + tzid)
+
+	// Начальное состояние пояса на момент from
+	name, offset := from.Zone()
+	cw.observance(from.IsDST(), from.Format(dateTimeLocalLayout), offset, offset, name)
+
+	prev := from
+	for t := from.AddDate(0, 0, 1); !t.After(to); t = t.AddDate(0, 0, 1) {
+		_, prevOffset := prev.Zone()
+		if _, curOffset := t.Zone(); curOffset != prevOffset {
+			transition := findTransition(prev, t)
+			newName, newOffset := transition.Zone()
+			// DTSTART наблюдения задается в локальном времени до перехода
+			local := transition.UTC().Add(time.Duration(prevOffset) * time.Second)
+			cw.observance(transition.IsDST(), local.Format(dateTimeLocalLayout), prevOffset, newOffset, newName)
+		}
+		prev = t
+	}
+
+	cw.line("END:VTIMEZONE")
+}
+
+// observance записывает компонент STANDARD или DAYLIGHT
+func (cw *Writer) observance(dst bool, dtstart string, offsetFrom, offsetTo int, name string) {
+	kind := "STANDARD"
+	if dst {
+		kind = "DAYLIGHT"
+	}
+	cw.line("BEGIN:" + kind)
+	cw.line("DTSTART:" + dtstart)
+	cw.line("TZOFFSETFROM:" + formatOffset(offsetFrom))
+	cw.line("TZOFFSETTO:" + formatOffset(offsetTo))
+	if name != "" {
+		cw.line("TZNAME:" + escapeText(name))
+	}
+	cw.line("END:" + kind)
+}
+
+// findTransition бинарным поиском находит момент смены смещения между a и b с точностью до секунды
+func findTransition(a, b time.Time) time.Time {
+	_, offsetA := a.Zone()
+	for b.Sub(a) > time.Second {
+		mid := a.Add(b.Sub(a) / 2)
+		if _, offset := mid.Zone(); offset == offsetA {
+			a = mid
+		} else {
+			b = mid
+		}
+	}
+	return b.Truncate(time.Second)
+}
+
+// formatOffset форматирует смещение UTC в виде +HHMM или +HHMMSS
+func formatOffset(seconds int) string {
+	sign := '+'
+	if seconds < 0 {
+		sign = '-'
+		seconds = -seconds
+	}
+	h, m, s := seconds/3600, seconds%3600/60, seconds%60
+	if s != 0 {
+		return fmt.Sprintf("%c%02d%02d%02d", sign, h, m, s)
+	}
+	return fmt.Sprintf("%c%02d%02d", sign, h, m)
+}
+
+// escapeText экранирует значение типа TEXT (RFC 5545, 3.3.11)
+func escapeText(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, ";", `\;`)
+	s = strings.ReplaceAll(s, ",", `\,`)
+	s = strings.ReplaceAll(s, "\r\n", `\n`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return s
+}
+
+// fold переносит строку длиннее 75 октетов, не разрывая UTF-8 символы
+func fold(s string) string {
+	if len(s) <= maxLineOctets {
+		return s
+	}
+
+	var b strings.Builder
+	lineLen := 0
+	limit := maxLineOctets
+	for _, r := range s {
+		size := len(string(r))
+		if lineLen+size > limit {
+			b.WriteString("\r\n ")
+			lineLen = 0
+			// Продолжение начинается с пробела, который тоже занимает октет
+			limit = maxLineOctets - 1
+		}
+		b.WriteRune(r)
+		lineLen += size
+	}
+	return b.String()
+}
@@ -0,0 +1,145 @@
+package imageproc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+const (
+	// MaxPixels ограничивает размер изображения в пикселях, защищая от "бомб" декомпрессии
+	MaxPixels = 40_000_000
+
+	jpegQuality = 85
+)
+
+var (
+	// ErrUnsupportedType возвращается для форматов, отличных от JPEG, PNG и GIF
+	ErrUnsupportedType = errors.New("unsupported image type")
+
+	// ErrTooLarge возвращается для изображений больше MaxPixels
+	ErrTooLarge = errors.New("image dimensions are too large")
+)
+
+// Fit способ вписывания изображения в размеры варианта
+type Fit int
+
+const (
+	// FitInside уменьшает изображение, сохраняя пропорции, чтобы оно поместилось в размеры
+	FitInside Fit = iota
+	// FitCover заполняет размеры целиком, обрезая лишнее по центру
+	FitCover
+)
+
+// Variant описание варианта изображения
+type Variant struct {
+	Name   string
+	Width  int
+	Height int
+	Fit    Fit
+}
+
+// DefaultVariants варианты изображений событий
+var DefaultVariants = []Variant{
+	{Name: "thumbnail", Width: 320, Height: 320, Fit: FitCover},
+	{Name: "card", Width: 800, Height: 450, Fit: FitCover},
+	{Name: "full", Width: 1920, Height: 1920, Fit: FitInside},
+}
+
+// Result готовый вариант изображения
+type Result struct {
+	Variant     Variant
+	Data        []byte
+	ContentType string
+	Extension   string
+	Width       int
+	Height      int
+}
+
+// DetectType определяет тип изображения по содержимому, а не по заголовкам запроса
+func DetectType(data []byte) (string, error) {
+	contentType := http.DetectContentType(data)
+	switch contentType {
+	case "image/jpeg", "image/png", "image/gif":
+		return contentType, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedType, contentType)
+	}
+}
+
+// Process декодирует изображение и создает варианты.
+//
+// Все варианты перекодируются заново, поэтому метаданные (EXIF, GPS и т.п.)
+// в результат не попадают. Ориентация из EXIF применяется до удаления метаданных.
+// JPEG сохраняется в JPEG, PNG и GIF — в PNG (с прозрачностью, без анимации).
+func Process(data []byte, variants []Variant) ([]Result, error) {
+	contentType, err := DetectType(data)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid image: %w", err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > MaxPixels {
+		return nil, fmt.Errorf("%w: %dx%d", ErrTooLarge, cfg.Width, cfg.Height)
+	}
+
+	var src image.Image
+	switch contentType {
+	case "image/jpeg":
+		src, err = jpeg.Decode(bytes.NewReader(data))
+	case "image/png":
+		src, err = png.Decode(bytes.NewReader(data))
+	case "image/gif":
+		src, err = gif.Decode(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid image: %w", err)
+	}
+
+	if contentType == "image/jpeg" {
+		src = applyOrientation(src, jpegOrientation(data))
+	}
+
+	results := make([]Result, 0, len(variants))
+	for _, v := range variants {
+		img := resize(toRGBA(src), v)
+
+		var buf bytes.Buffer
+		result := Result{Variant: v, Width: img.Bounds().Dx(), Height: img.Bounds().Dy()}
+		if contentType == "image/jpeg" {
+			err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+			result.ContentType, result.Extension = "image/jpeg", "jpg"
+		} else {
+			err = png.Encode(&buf, img)
+			result.ContentType, result.Extension = "image/png", "png"
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s variant: %w", v.Name, err)
+		}
+
+		result.Data = buf.Bytes()
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// toRGBA приводит изображение к *image.RGBA с началом координат в (0, 0)
+func toRGBA(src image.Image) *image.RGBA {
+	if rgba, ok := src.(*image.RGBA); ok && rgba.Bounds().Min == (image.Point{}) {
+		return rgba
+	}
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Src)
+	return dst
+}
@@ -0,0 +1,120 @@
+package imageproc
+
+import (
+	"encoding/binary"
+	"image"
+)
+
+// jpegOrientation читает тег Orientation (0x0112) из EXIF сегмента APP1 JPEG файла.
+// Возвращает 1 (нормальная ориентация), если тег не найден или данные повреждены.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	for pos := 2; pos+4 <= len(data); {
+		if data[pos] != 0xFF {
+			return 1
+		}
+		marker := data[pos+1]
+		// SOS: дальше идут данные изображения, метаданных уже не будет
+		if marker == 0xDA {
+			return 1
+		}
+		size := int(binary.BigEndian.Uint16(data[pos+2:]))
+		if size < 2 || pos+2+size > len(data) {
+			return 1
+		}
+
+		segment := data[pos+4 : pos+2+size]
+		if marker == 0xE1 && len(segment) > 6 && string(segment[:6]) == "Exif\x00\x00" {
+			return tiffOrientation(segment[6:])
+		}
+		pos += 2 + size
+	}
+
+	return 1
+}
+
+// tiffOrientation ищет тег Orientation в IFD0 TIFF заголовка EXIF
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) {
+		return 1
+	}
+
+	entries := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < entries; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			orientation := int(order.Uint16(tiff[entry+8:]))
+			if orientation < 1 || orientation > 8 {
+				return 1
+			}
+			return orientation
+		}
+	}
+
+	return 1
+}
+
+// applyOrientation поворачивает и отражает изображение согласно EXIF Orientation
+func applyOrientation(src image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return src
+	}
+
+	rgba := toRGBA(src)
+	w, h := rgba.Bounds().Dx(), rgba.Bounds().Dy()
+
+	// Ориентации 5-8 меняют ширину и высоту местами
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // Отражение по горизонтали
+				dx, dy = w-1-x, y
+			case 3: // Поворот на 180°
+				dx, dy = w-1-x, h-1-y
+			case 4: // Отражение по вертикали
+				dx, dy = x, h-1-y
+			case 5: // Транспонирование
+				dx, dy = y, x
+			case 6: // Поворот на 90° по часовой
+				dx, dy = h-1-y, x
+			case 7: // Транспонирование с поворотом на 180°
+				dx, dy = h-1-y, w-1-x
+			case 8: // Поворот на 90° против часовой
+				dx, dy = y, w-1-x
+			}
+			s := rgba.PixOffset(x, y)
+			d := dst.PixOffset(dx, dy)
+			copy(dst.Pix[d:d+4], rgba.Pix[s:s+4])
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,90 @@
+package imageproc
+
+import (
+	"image"
+)
+
+// resize масштабирует изображение под вариант. Изображения не увеличиваются.
+func resize(src *image.RGBA, v Variant) *image.RGBA {
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+
+	switch v.Fit {
+	case FitCover:
+		// Обрезаем по центру до пропорций варианта, затем уменьшаем
+		crop := centerCrop(sw, sh, v.Width, v.Height)
+		cropped := src.SubImage(crop).(*image.RGBA)
+		w, h := v.Width, v.Height
+		if crop.Dx() < w {
+			w, h = crop.Dx(), crop.Dy()
+		}
+		return scale(cropped, w, h)
+
+	default:
+		w, h := sw, sh
+		if w > v.Width {
+			h = h * v.Width / w
+			w = v.Width
+		}
+		if h > v.Height {
+			w = w * v.Height / h
+			h = v.Height
+		}
+		return scale(src, max(w, 1), max(h, 1))
+	}
+}
+
+// centerCrop вычисляет прямоугольник по центру с пропорциями w:h
+func centerCrop(sw, sh, w, h int) image.Rectangle {
+	cw, ch := sw, sw*h/w
+	if ch > sh {
+		cw, ch = sh*w/h, sh
+	}
+	x0, y0 := (sw-cw)/2, (sh-ch)/2
+	return image.Rect(x0, y0, x0+cw, y0+ch)
+}
+
+// scale уменьшает изображение усреднением по площади (box filter).
+// Для уменьшения дает результат без "лесенки" и муара, в отличие от выборки ближайшего пикселя.
+// Пиксели image.RGBA хранятся с премультиплицированной альфой, поэтому каналы
+// усредняются независимо и прозрачные пиксели не "пачкают" края.
+func scale(src *image.RGBA, w, h int) *image.RGBA {
+	b := src.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+
+	if sw == w && sh == h {
+		for y := 0; y < h; y++ {
+			copy(dst.Pix[y*dst.Stride:y*dst.Stride+w*4], src.Pix[src.PixOffset(b.Min.X, b.Min.Y+y):])
+		}
+		return dst
+	}
+
+	for y := 0; y < h; y++ {
+		y0 := y * sh / h
+		y1 := max((y+1)*sh/h, y0+1)
+		for x := 0; x < w; x++ {
+			x0 := x * sw / w
+			x1 := max((x+1)*sw/w, x0+1)
+
+			var sum [4]uint64
+			n := uint64(0)
+			for sy := y0; sy < y1; sy++ {
+				off := src.PixOffset(b.Min.X+x0, b.Min.Y+sy)
+				for sx := x0; sx < x1; sx++ {
+					for c := 0; c < 4; c++ {
+						sum[c] += uint64(src.Pix[off+c])
+					}
+					n++
+					off += 4
+				}
+			}
+
+			d := dst.PixOffset(x, y)
+			for c := 0; c < 4; c++ {
+				dst.Pix[d+c] = uint8(sum[c] / n)
+			}
+		}
+	}
+
+	return dst
+}
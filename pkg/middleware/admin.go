@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	contextkeys "github.com/rx3lixir/gateway-service/pkg/context"
+	"github.com/rx3lixir/gateway-service/pkg/i18n"
 	"github.com/rx3lixir/gateway-service/pkg/token"
 )
 
@@ -14,13 +15,13 @@ func AdminMiddleware(config *Config) func(http.Handler) http.Handler {
 			claims, ok := r.Context().Value(contextkeys.AuthKey).(*token.UserClaims)
 			if !ok || claims == nil {
 				config.Logger.WarnContext(r.Context(), "No auth claims found in context")
-				WriteJSON(w, http.StatusUnauthorized, APIError{Error: "Unauthorized"})
+				WriteJSON(w, http.StatusUnauthorized, APIError{Error: i18n.Localize(r.Context(), "Unauthorized")})
 				return
 			}
 
 			if !claims.IsAdmin {
 				config.Logger.WarnContext(r.Context(), "Access denied: user is not admin", "email", claims.Email)
-				WriteJSON(w, http.StatusForbidden, APIError{Error: "Access denied: admin privileges required"})
+				WriteJSON(w, http.StatusForbidden, APIError{Error: i18n.Localize(r.Context(), "Access denied: admin privileges required")})
 				return
 			}
 
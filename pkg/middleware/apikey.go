@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+
+	contextkeys "github.com/rx3lixir/gateway-service/pkg/context"
+	"github.com/rx3lixir/gateway-service/pkg/i18n"
+)
+
+// APIKeyHeader заголовок с API ключом машинного клиента
+const APIKeyHeader = "X-API-Key"
+
+// APIKey API ключ машинного клиента (например, парсера источника событий).
+// В конфигурации хранится только SHA-256 хэш ключа.
+type APIKey struct {
+	Name   string // Имя клиента, попадает в submitted_by
+	SHA256 string // Hex SHA-256 от ключа
+}
+
+// RequireAuthOrAPIKey пропускает запросы с API ключом из config.APIKeys
+// или с валидным JWT токеном. Имя API ключа кладется в контекст под contextkeys.APIKeyKey.
+func RequireAuthOrAPIKey(config *Config) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		withToken := AuthMiddleware(config)(next)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(APIKeyHeader)
+			if key == "" {
+				withToken.ServeHTTP(w, r)
+				return
+			}
+
+			name, ok := matchAPIKey(config.APIKeys, key)
+			if !ok {
+				config.Logger.WarnContext(r.Context(), "Invalid API key")
+				WriteJSON(w, http.StatusUnauthorized, APIError{Error: i18n.Localize(r.Context(), "Invalid API key")})
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), contextkeys.APIKeyKey, name)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// matchAPIKey ищет ключ среди разрешенных, сравнивая хэши за постоянное время
+func matchAPIKey(keys []APIKey, key string) (string, bool) {
+	sum := sha256.Sum256([]byte(key))
+	presented := hex.EncodeToString(sum[:])
+
+	for _, k := range keys {
+		if subtle.ConstantTimeCompare([]byte(presented), []byte(k.SHA256)) == 1 {
+			return k.Name, true
+		}
+	}
+	return "", false
+}
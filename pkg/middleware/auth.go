@@ -7,6 +7,7 @@ import (
 	"time"
 
 	contextkeys "github.com/rx3lixir/gateway-service/pkg/context"
+	"github.com/rx3lixir/gateway-service/pkg/i18n"
 )
 
 // AuthMiddleware проверяет JWT токен
@@ -24,14 +25,14 @@ func AuthMiddleware(config *Config) func(http.Handler) http.Handler {
 				authHeader := r.Header.Get("Authorization")
 				if authHeader == "" {
 					config.Logger.WarnContext(r.Context(), "No access token found in cookies or Authorization")
-					WriteJSON(w, http.StatusUnauthorized, APIError{Error: "Authorization required"})
+					WriteJSON(w, http.StatusUnauthorized, APIError{Error: i18n.Localize(r.Context(), "Authorization required")})
 					return
 				}
 
 				// Проверяем формат заголовка Bearer token
 				parts := strings.Split(authHeader, " ")
 				if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
-					WriteJSON(w, http.StatusUnauthorized, APIError{Error: "Invalid authorization header format"})
+					WriteJSON(w, http.StatusUnauthorized, APIError{Error: i18n.Localize(r.Context(), "Invalid authorization header format")})
 					return
 				}
 				tokenString = parts[1]
@@ -48,7 +49,7 @@ func AuthMiddleware(config *Config) func(http.Handler) http.Handler {
 					clearAuthCookies(w)
 				}
 
-				WriteJSON(w, http.StatusUnauthorized, APIError{Error: "Invalid or expired token"})
+				WriteJSON(w, http.StatusUnauthorized, APIError{Error: i18n.Localize(r.Context(), "Invalid or expired token")})
 				return
 			}
 
@@ -75,3 +76,33 @@ func clearAuthCookies(w http.ResponseWriter) {
 		http.SetCookie(w, cookie)
 	}
 }
+
+// OptionalAuth добавляет данные пользователя в контекст, если запрос содержит валидный токен.
+// В отличие от AuthMiddleware, запросы без токена или с невалидным токеном не отклоняются.
+func OptionalAuth(config *Config) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var tokenString string
+			if cookie, err := r.Cookie("access_token"); err == nil && cookie.Value != "" {
+				tokenString = cookie.Value
+			} else if parts := strings.Split(r.Header.Get("Authorization"), " "); len(parts) == 2 && strings.ToLower(parts[0]) == "bearer" {
+				tokenString = parts[1]
+			}
+
+			if tokenString == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			claims, err := config.TokenMaker.VerifyToken(tokenString)
+			if err != nil {
+				config.Logger.DebugContext(r.Context(), "Ignoring invalid token on optional auth route", "error", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), contextkeys.AuthKey, claims)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -19,6 +19,17 @@ func CommonMiddlewares(config *Config) []func(http.Handler) http.Handler {
 	}
 }
 
+// StreamMiddlewares возвращает middleware для долгоживущих потоков (SSE, WebSocket):
+// без таймаута запроса и без сжатия, которое буферизует ответ
+func StreamMiddlewares(config *Config) []func(http.Handler) http.Handler {
+	return []func(http.Handler) http.Handler{
+		middleware.Logger,
+		middleware.Recoverer,
+		CORSMiddleware(config.CORSConfig),
+		middleware.RequestID,
+	}
+}
+
 // SecurityHeaders добавляет security заголовки
 func SecurityHeaders() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -3,6 +3,7 @@ package middleware
 import (
 	"github.com/go-chi/cors"
 	"net/http"
+	"strings"
 )
 
 // CORSMiddleware обрабатывает CORS запросы
@@ -14,3 +15,35 @@ func CORSMiddleware(config CORSConfig) func(http.Handler) http.Handler {
 		MaxAge:           86400,
 	})
 }
+
+// AllowsOrigin проверяет источник по списку AllowedOrigins так же, как CORSMiddleware:
+// "*" разрешает любой источник, "https://*.example.com" — поддомены
+func (c CORSConfig) AllowsOrigin(origin string) bool {
+	origin = strings.ToLower(origin)
+	for _, allowed := range c.AllowedOrigins {
+		allowed = strings.ToLower(allowed)
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+		if prefix, suffix, ok := strings.Cut(allowed, "*"); ok &&
+			len(origin) >= len(prefix)+len(suffix) && strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckOrigin проверка Origin для WebSocket рукопожатия. Браузер не применяет CORS
+// к WebSocket, поэтому без нее любая страница может подключиться от имени
+// пользователя с его cookies. Запросы без Origin (не из браузера) и с того же
+// хоста разрешены.
+func (c CORSConfig) CheckOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if _, host, ok := strings.Cut(origin, "://"); ok && strings.EqualFold(host, r.Host) {
+		return true
+	}
+	return c.AllowsOrigin(origin)
+}
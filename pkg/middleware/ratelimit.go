@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	contextkeys "github.com/rx3lixir/gateway-service/pkg/context"
+	"github.com/rx3lixir/gateway-service/pkg/i18n"
+	"github.com/rx3lixir/gateway-service/pkg/token"
+)
+
+// RateLimitPerClient ограничивает число запросов одного клиента: не больше limit
+// за window (фиксированное окно). Клиент — пользователь из токена, если перед
+// middleware стоит OptionalAuth или AuthMiddleware, иначе IP адрес.
+// Сверх лимита отвечает 429 с Retry-After.
+func RateLimitPerClient(limit int, window time.Duration) func(http.Handler) http.Handler {
+	limiter := &windowLimiter{limit: limit, window: window, counts: make(map[string]int)}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if retryAfter, ok := limiter.allow(clientKey(r), time.Now()); !ok {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+				WriteJSON(w, http.StatusTooManyRequests, APIError{Error: i18n.Localize(r.Context(), "Too many requests, retry later")})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// windowLimiter счетчики запросов клиентов в текущем окне. Счетчики
+// сбрасываются целиком в начале окна, поэтому память ограничена
+// числом клиентов за одно окно.
+type windowLimiter struct {
+	mu          sync.Mutex
+	limit       int
+	window      time.Duration
+	windowStart time.Time
+	counts      map[string]int
+}
+
+// allow учитывает запрос клиента key. Если лимит исчерпан, возвращает false
+// и время до начала следующего окна.
+func (l *windowLimiter) allow(key string, now time.Time) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.windowStart) >= l.window {
+		l.windowStart = now.Truncate(l.window)
+		clear(l.counts)
+	}
+	if l.counts[key] >= l.limit {
+		return l.windowStart.Add(l.window).Sub(now), false
+	}
+	l.counts[key]++
+	return 0, true
+}
+
+// clientKey идентификатор клиента для rate limiting
+func clientKey(r *http.Request) string {
+	if claims, ok := r.Context().Value(contextkeys.AuthKey).(*token.UserClaims); ok && claims != nil {
+		return fmt.Sprintf("user:%d", claims.Id)
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
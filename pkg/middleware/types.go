@@ -18,6 +18,7 @@ type Config struct {
 	TokenMaker *token.JWTMaker
 	Logger     logger.Logger
 	CORSConfig CORSConfig
+	APIKeys    []APIKey // Ключи для RequireAuthOrAPIKey
 }
 
 // CORSConfig конфигурация CORS
@@ -0,0 +1,122 @@
+package recent
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryStore in-memory хранилище недавних запросов. Для каждого пользователя
+// хранится не больше perUser запросов; при превышении maxUsers вытесняются
+// пользователи, дольше всех не искавшие.
+type MemoryStore struct {
+	mu       sync.Mutex
+	perUser  int
+	maxUsers int
+	users    map[int64]*list.Element
+	order    *list.List // Начало списка — пользователи, искавшие последними
+	now      func() time.Time
+}
+
+type memoryUser struct {
+	id       int64
+	searches []Search // Новые первыми
+}
+
+// NewMemoryStore создает хранилище на perUser запросов для не более чем maxUsers пользователей
+func NewMemoryStore(perUser, maxUsers int) *MemoryStore {
+	if perUser <= 0 {
+		perUser = 1
+	}
+	if maxUsers <= 0 {
+		maxUsers = 1
+	}
+	return &MemoryStore{
+		perUser:  perUser,
+		maxUsers: maxUsers,
+		users:    make(map[int64]*list.Element),
+		order:    list.New(),
+		now:      time.Now,
+	}
+}
+
+// Add сохраняет запрос пользователя
+func (s *MemoryStore) Add(_ context.Context, userID int64, query string) error {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil
+	}
+	key := Normalize(query)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.users[userID]
+	if !ok {
+		elem = s.order.PushFront(&memoryUser{id: userID})
+		s.users[userID] = elem
+		for s.order.Len() > s.maxUsers {
+			oldest := s.order.Back()
+			s.order.Remove(oldest)
+			delete(s.users, oldest.Value.(*memoryUser).id)
+		}
+	}
+	s.order.MoveToFront(elem)
+
+	user := elem.Value.(*memoryUser)
+	searches := make([]Search, 0, s.perUser)
+	searches = append(searches, Search{Query: query, SearchedAt: s.now()})
+	for _, search := range user.searches {
+		if len(searches) == s.perUser {
+			break
+		}
+		if Normalize(search.Query) != key {
+			searches = append(searches, search)
+		}
+	}
+	user.searches = searches
+	return nil
+}
+
+// List возвращает запросы пользователя, новые первыми
+func (s *MemoryStore) List(_ context.Context, userID int64) ([]Search, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.users[userID]
+	if !ok {
+		return nil, nil
+	}
+	searches := elem.Value.(*memoryUser).searches
+	return append([]Search(nil), searches...), nil
+}
+
+// Remove удаляет запрос пользователя; пустой query удаляет все запросы
+func (s *MemoryStore) Remove(_ context.Context, userID int64, query string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.users[userID]
+	if !ok {
+		return nil
+	}
+
+	user := elem.Value.(*memoryUser)
+	key := Normalize(query)
+	if key != "" {
+		kept := user.searches[:0]
+		for _, search := range user.searches {
+			if Normalize(search.Query) != key {
+				kept = append(kept, search)
+			}
+		}
+		user.searches = kept
+	}
+	if key == "" || len(user.searches) == 0 {
+		s.order.Remove(elem)
+		delete(s.users, userID)
+	}
+	return nil
+}
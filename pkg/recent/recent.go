@@ -0,0 +1,35 @@
+// Package recent недавние поисковые запросы пользователей.
+//
+// Запросы хранит gateway: их показывают в подсказках, пока пользователь
+// еще ничего не ввел или ввел начало прежнего запроса.
+package recent
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Search поисковый запрос пользователя
+type Search struct {
+	Query      string
+	SearchedAt time.Time
+}
+
+// Store хранилище недавних запросов. Повторный запрос поднимается наверх,
+// а не дублируется; запросы, отличающиеся только регистром и пробелами, совпадают.
+type Store interface {
+	// Add сохраняет запрос пользователя
+	Add(ctx context.Context, userID int64, query string) error
+
+	// List возвращает запросы пользователя, новые первыми
+	List(ctx context.Context, userID int64) ([]Search, error)
+
+	// Remove удаляет запрос пользователя; пустой query удаляет все запросы
+	Remove(ctx context.Context, userID int64, query string) error
+}
+
+// Normalize приводит запрос к виду для сравнения: нижний регистр, одиночные пробелы
+func Normalize(query string) string {
+	return strings.Join(strings.Fields(strings.ToLower(query)), " ")
+}
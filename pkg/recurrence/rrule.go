@@ -0,0 +1,305 @@
+package recurrence
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency частота повторения правила RRULE
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// maxIterations защищает от бесконечного перебора для редких правил
+const maxIterations = 100000
+
+// maxTime верхняя граница поиска в Next
+var maxTime = time.Date(9999, time.December, 31, 23, 59, 59, 0, time.UTC)
+
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// Rule разобранное правило повторения (подмножество RFC 5545).
+//
+// Поддерживаются FREQ, INTERVAL, COUNT, UNTIL, BYDAY (без порядковых номеров,
+// только с DAILY и WEEKLY) и BYMONTHDAY (только с MONTHLY). WKST принимается,
+// но неделя всегда начинается с понедельника.
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	Count      int       // 0 = без ограничения
+	Until      time.Time // Нулевое значение = без ограничения
+	ByDay      []time.Weekday
+	ByMonthDay []int
+}
+
+// Parse разбирает строку RRULE, например "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10".
+// Префикс "RRULE:" допускается.
+func Parse(value string) (*Rule, error) {
+	value = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(value), "RRULE:"))
+	if value == "" {
+		return nil, fmt.Errorf("empty recurrence rule")
+	}
+
+	rule := &Rule{Interval: 1}
+
+	for _, part := range strings.Split(value, ";") {
+		key, val, ok := strings.Cut(part, "=")
+		if !ok || val == "" {
+			return nil, fmt.Errorf("invalid recurrence rule part %q", part)
+		}
+
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			switch f := Frequency(strings.ToUpper(val)); f {
+			case Daily, Weekly, Monthly, Yearly:
+				rule.Freq = f
+			default:
+				return nil, fmt.Errorf("unsupported recurrence frequency %q", val)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(val)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("invalid recurrence interval %q", val)
+			}
+			rule.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(val)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("invalid recurrence count %q", val)
+			}
+			rule.Count = n
+		case "UNTIL":
+			until, err := parseUntil(val)
+			if err != nil {
+				return nil, err
+			}
+			rule.Until = until
+		case "BYDAY":
+			for _, d := range strings.Split(val, ",") {
+				wd, ok := weekdays[strings.ToUpper(strings.TrimSpace(d))]
+				if !ok {
+					return nil, fmt.Errorf("unsupported recurrence BYDAY value %q", d)
+				}
+				rule.ByDay = append(rule.ByDay, wd)
+			}
+		case "BYMONTHDAY":
+			for _, d := range strings.Split(val, ",") {
+				n, err := strconv.Atoi(strings.TrimSpace(d))
+				if err != nil || n < 1 || n > 31 {
+					return nil, fmt.Errorf("unsupported recurrence BYMONTHDAY value %q", d)
+				}
+				rule.ByMonthDay = append(rule.ByMonthDay, n)
+			}
+		case "WKST":
+			// Принимаем, но не используем
+		default:
+			return nil, fmt.Errorf("unsupported recurrence rule part %q", key)
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, fmt.Errorf("recurrence rule FREQ is required")
+	}
+	if rule.Count > 0 && !rule.Until.IsZero() {
+		return nil, fmt.Errorf("recurrence rule must not contain both COUNT and UNTIL")
+	}
+	// В MONTHLY и YEARLY BYDAY означает "все такие дни месяца или года",
+	// а не фильтр; такие правила не поддерживаем, чтобы не считать их неверно
+	if len(rule.ByDay) > 0 && rule.Freq != Daily && rule.Freq != Weekly {
+		return nil, fmt.Errorf("recurrence BYDAY is supported only with FREQ=DAILY or FREQ=WEEKLY")
+	}
+	if len(rule.ByMonthDay) > 0 && rule.Freq != Monthly {
+		return nil, fmt.Errorf("recurrence BYMONTHDAY is supported only with FREQ=MONTHLY")
+	}
+
+	sort.Slice(rule.ByDay, func(i, j int) bool {
+		return mondayIndex(rule.ByDay[i]) < mondayIndex(rule.ByDay[j])
+	})
+	sort.Ints(rule.ByMonthDay)
+
+	return rule, nil
+}
+
+// String возвращает каноническое представление правила
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, 0, len(r.ByDay))
+		for _, wd := range r.ByDay {
+			for name, d := range weekdays {
+				if d == wd {
+					days = append(days, name)
+				}
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, 0, len(r.ByMonthDay))
+		for _, d := range r.ByMonthDay {
+			days = append(days, strconv.Itoa(d))
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Between возвращает начала повторений в интервале [from, to], не более limit штук.
+// dtstart задает первое повторение и часовой пояс, в котором считается "настенное" время,
+// поэтому переходы на летнее время не сдвигают время начала.
+func (r *Rule) Between(dtstart, from, to time.Time, limit int) []time.Time {
+	var result []time.Time
+	produced := 0
+
+	for period := 0; period < maxIterations; period++ {
+		candidates := r.periodCandidates(dtstart, period)
+		if candidates == nil {
+			break
+		}
+
+		for _, c := range candidates {
+			if c.Before(dtstart) {
+				continue
+			}
+			if !r.Until.IsZero() && c.After(r.Until) {
+				return result
+			}
+			if c.After(to) {
+				return result
+			}
+
+			produced++
+			if r.Count > 0 && produced > r.Count {
+				return result
+			}
+
+			if !c.Before(from) {
+				result = append(result, c)
+				if limit > 0 && len(result) >= limit {
+					return result
+				}
+			}
+		}
+	}
+
+	return result
+}
+
+// Next возвращает первое повторение, начинающееся не раньше from.
+// false — повторений после from нет (правило закончилось по COUNT или UNTIL).
+func (r *Rule) Next(dtstart, from time.Time) (time.Time, bool) {
+	next := r.Between(dtstart, from, maxTime, 1)
+	if len(next) == 0 {
+		return time.Time{}, false
+	}
+	return next[0], true
+}
+
+// periodCandidates возвращает кандидатов на повторение внутри периода с номером period.
+// Пустой (не nil) слайс означает, что в периоде нет подходящих дат.
+func (r *Rule) periodCandidates(dtstart time.Time, period int) []time.Time {
+	loc := dtstart.Location()
+	y, m, d := dtstart.Date()
+	hh, mm, ss := dtstart.Clock()
+	ns := dtstart.Nanosecond()
+	step := period * r.Interval
+
+	switch r.Freq {
+	case Daily:
+		c := time.Date(y, m, d+step, hh, mm, ss, ns, loc)
+		if len(r.ByDay) > 0 && !containsWeekday(r.ByDay, c.Weekday()) {
+			return []time.Time{}
+		}
+		return []time.Time{c}
+
+	case Weekly:
+		weekStart := d - mondayIndex(dtstart.Weekday()) + step*7
+		days := r.ByDay
+		if len(days) == 0 {
+			days = []time.Weekday{dtstart.Weekday()}
+		}
+		candidates := make([]time.Time, 0, len(days))
+		for _, wd := range days {
+			candidates = append(candidates, time.Date(y, m, weekStart+mondayIndex(wd), hh, mm, ss, ns, loc))
+		}
+		return candidates
+
+	case Monthly:
+		days := r.ByMonthDay
+		if len(days) == 0 {
+			days = []int{d}
+		}
+		candidates := make([]time.Time, 0, len(days))
+		for _, day := range days {
+			c := time.Date(y, m+time.Month(step), day, hh, mm, ss, ns, loc)
+			// Пропускаем несуществующие даты (например, 31 февраля)
+			if c.Day() == day {
+				candidates = append(candidates, c)
+			}
+		}
+		return candidates
+
+	case Yearly:
+		c := time.Date(y+step, m, d, hh, mm, ss, ns, loc)
+		// 29 февраля повторяется только в високосные годы
+		if c.Day() != d {
+			return []time.Time{}
+		}
+		return []time.Time{c}
+	}
+
+	return nil
+}
+
+// parseUntil разбирает UNTIL в форматах YYYYMMDD и YYYYMMDDTHHMMSSZ
+func parseUntil(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			if layout == "20060102" {
+				// Дата без времени включает весь день
+				t = t.Add(24*time.Hour - time.Nanosecond)
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid recurrence UNTIL value %q", value)
+}
+
+// mondayIndex возвращает номер дня недели, начиная с понедельника (0..6)
+func mondayIndex(wd time.Weekday) int {
+	return (int(wd) + 6) % 7
+}
+
+func containsWeekday(days []time.Weekday, wd time.Weekday) bool {
+	for _, d := range days {
+		if d == wd {
+			return true
+		}
+	}
+	return false
+}
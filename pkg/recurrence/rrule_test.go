@@ -0,0 +1,228 @@
+package recurrence
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string // Каноническая форма; пусто, если ожидается ошибка
+		err   string
+	}{
+		{name: "weekly", value: "FREQ=WEEKLY;BYDAY=WE,MO;COUNT=10", want: "FREQ=WEEKLY;COUNT=10;BYDAY=MO,WE"},
+		{name: "prefix and case", value: "RRULE:freq=daily;interval=2", want: "FREQ=DAILY;INTERVAL=2"},
+		{name: "daily by day", value: "FREQ=DAILY;BYDAY=SA,SU", want: "FREQ=DAILY;BYDAY=SA,SU"},
+		{name: "monthly by month day", value: "FREQ=MONTHLY;BYMONTHDAY=15,1", want: "FREQ=MONTHLY;BYMONTHDAY=1,15"},
+		{name: "until date", value: "FREQ=YEARLY;UNTIL=20301231", want: "FREQ=YEARLY;UNTIL=20301231T235959Z"},
+		{name: "wkst ignored", value: "FREQ=WEEKLY;WKST=SU", want: "FREQ=WEEKLY"},
+
+		{name: "empty", value: " ", err: "empty recurrence rule"},
+		{name: "no freq", value: "COUNT=3", err: "FREQ is required"},
+		{name: "unknown freq", value: "FREQ=HOURLY", err: "unsupported recurrence frequency"},
+		{name: "bad part", value: "FREQ=DAILY;COUNT", err: "invalid recurrence rule part"},
+		{name: "unknown part", value: "FREQ=DAILY;BYHOUR=10", err: "unsupported recurrence rule part"},
+		{name: "zero interval", value: "FREQ=DAILY;INTERVAL=0", err: "invalid recurrence interval"},
+		{name: "negative count", value: "FREQ=DAILY;COUNT=-1", err: "invalid recurrence count"},
+		{name: "bad until", value: "FREQ=DAILY;UNTIL=tomorrow", err: "invalid recurrence UNTIL"},
+		{name: "count and until", value: "FREQ=DAILY;COUNT=2;UNTIL=20300101", err: "both COUNT and UNTIL"},
+		{name: "ordinal by day", value: "FREQ=WEEKLY;BYDAY=1MO", err: "unsupported recurrence BYDAY value"},
+		{name: "monthly by day", value: "FREQ=MONTHLY;BYDAY=MO", err: "BYDAY is supported only with FREQ=DAILY or FREQ=WEEKLY"},
+		{name: "yearly by day", value: "FREQ=YEARLY;BYDAY=FR", err: "BYDAY is supported only with FREQ=DAILY or FREQ=WEEKLY"},
+		{name: "month day out of range", value: "FREQ=MONTHLY;BYMONTHDAY=32", err: "unsupported recurrence BYMONTHDAY value"},
+		{name: "yearly by month day", value: "FREQ=YEARLY;BYMONTHDAY=1", err: "BYMONTHDAY is supported only with FREQ=MONTHLY"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.value)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("Parse(%q) error = %v, want containing %q", tt.value, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.value, err)
+			}
+			if got := rule.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBetween(t *testing.T) {
+	utc := func(y int, m time.Month, d, hh int) time.Time { return time.Date(y, m, d, hh, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name     string
+		rule     string
+		dtstart  time.Time
+		from, to time.Time
+		limit    int
+		want     []time.Time
+	}{
+		{
+			name:    "daily interval",
+			rule:    "FREQ=DAILY;INTERVAL=2",
+			dtstart: utc(2026, time.January, 1, 10),
+			from:    utc(2026, time.January, 1, 0),
+			to:      utc(2026, time.January, 7, 0),
+			want:    []time.Time{utc(2026, time.January, 1, 10), utc(2026, time.January, 3, 10), utc(2026, time.January, 5, 10)},
+		},
+		{
+			name:    "daily by day skips weekdays",
+			rule:    "FREQ=DAILY;BYDAY=SA,SU",
+			dtstart: utc(2026, time.January, 1, 10), // четверг
+			from:    utc(2026, time.January, 1, 0),
+			to:      utc(2026, time.January, 12, 0),
+			want:    []time.Time{utc(2026, time.January, 3, 10), utc(2026, time.January, 4, 10), utc(2026, time.January, 10, 10), utc(2026, time.January, 11, 10)},
+		},
+		{
+			name:    "weekly by day does not go before dtstart",
+			rule:    "FREQ=WEEKLY;BYDAY=MO,FR",
+			dtstart: utc(2026, time.January, 7, 19), // среда
+			from:    utc(2026, time.January, 1, 0),
+			to:      utc(2026, time.January, 20, 0),
+			want:    []time.Time{utc(2026, time.January, 9, 19), utc(2026, time.January, 12, 19), utc(2026, time.January, 16, 19), utc(2026, time.January, 19, 19)},
+		},
+		{
+			name:    "count includes occurrences before from",
+			rule:    "FREQ=WEEKLY;COUNT=3",
+			dtstart: utc(2026, time.January, 5, 19),
+			from:    utc(2026, time.January, 10, 0),
+			to:      utc(2026, time.March, 1, 0),
+			want:    []time.Time{utc(2026, time.January, 12, 19), utc(2026, time.January, 19, 19)},
+		},
+		{
+			name:    "until is inclusive",
+			rule:    "FREQ=DAILY;UNTIL=20260103",
+			dtstart: utc(2026, time.January, 1, 23),
+			from:    utc(2026, time.January, 1, 0),
+			to:      utc(2026, time.February, 1, 0),
+			want:    []time.Time{utc(2026, time.January, 1, 23), utc(2026, time.January, 2, 23), utc(2026, time.January, 3, 23)},
+		},
+		{
+			name:    "monthly skips missing days",
+			rule:    "FREQ=MONTHLY",
+			dtstart: utc(2026, time.January, 31, 12),
+			from:    utc(2026, time.January, 1, 0),
+			to:      utc(2026, time.May, 31, 0),
+			want:    []time.Time{utc(2026, time.January, 31, 12), utc(2026, time.March, 31, 12)},
+		},
+		{
+			name:    "monthly by month day",
+			rule:    "FREQ=MONTHLY;BYMONTHDAY=1,15",
+			dtstart: utc(2026, time.January, 10, 18),
+			from:    utc(2026, time.January, 1, 0),
+			to:      utc(2026, time.February, 20, 0),
+			want:    []time.Time{utc(2026, time.January, 15, 18), utc(2026, time.February, 1, 18), utc(2026, time.February, 15, 18)},
+		},
+		{
+			name:    "yearly leap day",
+			rule:    "FREQ=YEARLY",
+			dtstart: utc(2024, time.February, 29, 9),
+			from:    utc(2024, time.January, 1, 0),
+			to:      utc(2032, time.December, 31, 0),
+			want:    []time.Time{utc(2024, time.February, 29, 9), utc(2028, time.February, 29, 9), utc(2032, time.February, 29, 9)},
+		},
+		{
+			name:    "limit",
+			rule:    "FREQ=DAILY",
+			dtstart: utc(2026, time.January, 1, 10),
+			from:    utc(2026, time.January, 1, 0),
+			to:      utc(2026, time.December, 31, 0),
+			limit:   2,
+			want:    []time.Time{utc(2026, time.January, 1, 10), utc(2026, time.January, 2, 10)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.rule, err)
+			}
+			got := rule.Between(tt.dtstart, tt.from, tt.to, tt.limit)
+			assertTimes(t, got, tt.want)
+		})
+	}
+}
+
+func TestBetweenKeepsWallClockAcrossDST(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+
+	rule, err := Parse("FREQ=WEEKLY;COUNT=3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 29 марта 2026 Европа переходит на летнее время
+	dtstart := time.Date(2026, time.March, 21, 19, 0, 0, 0, berlin)
+	got := rule.Between(dtstart, dtstart, dtstart.AddDate(0, 1, 0), 0)
+
+	if len(got) != 3 {
+		t.Fatalf("got %d occurrences, want 3: %v", len(got), got)
+	}
+	for _, occurrence := range got {
+		if hh, mm, _ := occurrence.Clock(); hh != 19 || mm != 0 {
+			t.Errorf("occurrence %v starts at %02d:%02d, want 19:00 local time", occurrence, hh, mm)
+		}
+	}
+	if offset := got[2].Sub(got[1]); offset != 7*24*time.Hour-time.Hour {
+		t.Errorf("week across DST lasts %v, want 167h", offset)
+	}
+}
+
+func TestNext(t *testing.T) {
+	dtstart := time.Date(2026, time.January, 5, 19, 0, 0, 0, time.UTC) // понедельник
+
+	tests := []struct {
+		name   string
+		rule   string
+		from   time.Time
+		want   time.Time
+		wantOK bool
+	}{
+		{name: "before start", rule: "FREQ=WEEKLY", from: dtstart.AddDate(0, 0, -3), want: dtstart, wantOK: true},
+		{name: "exactly at occurrence", rule: "FREQ=WEEKLY", from: dtstart.AddDate(0, 0, 7), want: dtstart.AddDate(0, 0, 7), wantOK: true},
+		{name: "between occurrences", rule: "FREQ=WEEKLY;BYDAY=MO,TH", from: dtstart.Add(time.Minute), want: dtstart.AddDate(0, 0, 3), wantOK: true},
+		{name: "far in the future", rule: "FREQ=DAILY", from: dtstart.AddDate(10, 0, 0).Add(time.Hour), want: dtstart.AddDate(10, 0, 1), wantOK: true},
+		{name: "after count", rule: "FREQ=WEEKLY;COUNT=2", from: dtstart.AddDate(0, 0, 8), wantOK: false},
+		{name: "after until", rule: "FREQ=DAILY;UNTIL=20260110", from: dtstart.AddDate(0, 0, 6), wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.rule, err)
+			}
+			got, ok := rule.Next(dtstart, tt.from)
+			if ok != tt.wantOK {
+				t.Fatalf("Next() ok = %v, want %v (got %v)", ok, tt.wantOK, got)
+			}
+			if ok && !got.Equal(tt.want) {
+				t.Errorf("Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func assertTimes(t *testing.T, got, want []time.Time) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d occurrences %v, want %d %v", len(got), got, len(want), want)
+	}
+	for i := range want {
+		if !got[i].Equal(want[i]) {
+			t.Errorf("occurrence %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
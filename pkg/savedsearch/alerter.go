@@ -0,0 +1,137 @@
+package savedsearch
+
+import (
+	"context"
+	"time"
+
+	"github.com/rx3lixir/gateway-service/pkg/logger"
+)
+
+// Config параметры проверки сохраненных поисков
+type Config struct {
+	Interval   time.Duration // Как часто проверять поиски
+	DigestSize int           // Событий в одном дайджесте
+	Timeout    time.Duration // Таймаут проверки одного поиска
+}
+
+// DefaultConfig параметры проверки по умолчанию
+func DefaultConfig() Config {
+	return Config{
+		Interval:   15 * time.Minute,
+		DigestSize: 20,
+		Timeout:    30 * time.Second,
+	}
+}
+
+// Alerter периодически проверяет сохраненные поиски с включенными оповещениями
+// и отправляет дайджесты о событиях, опубликованных после водяного знака
+type Alerter struct {
+	store    Store
+	searcher Searcher
+	notifier Notifier
+	config   Config
+	logger   logger.Logger
+	now      func() time.Time
+}
+
+// NewAlerter создает Alerter. Нулевые поля config заменяются значениями по умолчанию.
+func NewAlerter(store Store, searcher Searcher, notifier Notifier, config Config, log logger.Logger) *Alerter {
+	defaults := DefaultConfig()
+	if config.Interval <= 0 {
+		config.Interval = defaults.Interval
+	}
+	if config.DigestSize <= 0 {
+		config.DigestSize = defaults.DigestSize
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaults.Timeout
+	}
+
+	return &Alerter{
+		store:    store,
+		searcher: searcher,
+		notifier: notifier,
+		config:   config,
+		logger:   log,
+		now:      time.Now,
+	}
+}
+
+// Run проверяет поиски каждые Interval, пока не отменен ctx
+func (a *Alerter) Run(ctx context.Context) {
+	ticker := time.NewTicker(a.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.CheckAll(ctx)
+		}
+	}
+}
+
+// CheckAll проверяет все поиски с включенными оповещениями. Ошибка одного поиска
+// не мешает остальным: его водяной знак не сдвигается, и он проверится в следующий раз.
+func (a *Alerter) CheckAll(ctx context.Context) {
+	searches, err := a.store.ListAlerting(ctx)
+	if err != nil {
+		a.logger.ErrorContext(ctx, "Failed to list saved searches", "error", err)
+		return
+	}
+
+	notified := 0
+	for _, search := range searches {
+		if ctx.Err() != nil {
+			return
+		}
+		ok, err := a.check(ctx, search)
+		if err != nil {
+			a.logger.WarnContext(ctx, "Failed to check saved search",
+				"saved_search_id", search.ID,
+				"user_id", search.UserID,
+				"error", err)
+			continue
+		}
+		if ok {
+			notified++
+		}
+	}
+
+	a.logger.InfoContext(ctx, "Saved searches checked", "checked", len(searches), "notified", notified)
+}
+
+// check выполняет поиск среди событий, опубликованных после водяного знака,
+// отправляет дайджест, если что-то нашлось, и сдвигает водяной знак
+func (a *Alerter) check(ctx context.Context, search *SavedSearch) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, a.config.Timeout)
+	defer cancel()
+
+	checkedAt := a.now().UTC()
+	matches, total, err := a.searcher.NewMatches(ctx, search.Filters, search.Watermark, checkedAt, a.config.DigestSize)
+	if err != nil {
+		return false, err
+	}
+
+	notified := len(matches) > 0
+	if notified {
+		digest := Digest{
+			UserID:        search.UserID,
+			SavedSearchID: search.ID,
+			Name:          search.Name,
+			Events:        matches,
+			Total:         max(total, len(matches)),
+			From:          search.Watermark,
+			To:            checkedAt,
+		}
+		if err := a.notifier.Notify(ctx, digest); err != nil {
+			return false, err
+		}
+	}
+
+	if err := a.store.Advance(ctx, search.ID, checkedAt, checkedAt, notified); err != nil {
+		return notified, err
+	}
+	return notified, nil
+}
@@ -0,0 +1,100 @@
+package savedsearch
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/rx3lixir/gateway-service/pkg/jsonfile"
+)
+
+// searchesFile файл FileStore в каталоге хранилища
+const searchesFile = "saved_searches.json"
+
+var _ Store = (*FileStore)(nil)
+
+// FileStore хранилище сохраненных поисков в JSON файле для одного экземпляра
+// gateway. Поиски читаются из памяти, файл перезаписывается при каждом изменении;
+// если запись не удалась, изменение откатывается.
+type FileStore struct {
+	mu   sync.Mutex // Сериализует изменения вместе с записью файла
+	mem  *MemoryStore
+	path string
+}
+
+// NewFileStore открывает хранилище сохраненных поисков в каталоге dir
+func NewFileStore(dir string) (*FileStore, error) {
+	s := &FileStore{mem: NewMemoryStore(), path: filepath.Join(dir, searchesFile)}
+
+	var stored []*SavedSearch
+	if _, err := jsonfile.Read(s.path, &stored); err != nil {
+		return nil, err
+	}
+	for _, search := range stored {
+		s.mem.searches[search.ID] = search
+	}
+	return s, nil
+}
+
+// Create сохраняет поиск
+func (s *FileStore) Create(ctx context.Context, search *SavedSearch) error {
+	return s.change(search.ID, func() error { return s.mem.Create(ctx, search) })
+}
+
+// Get возвращает поиск пользователя по ID
+func (s *FileStore) Get(ctx context.Context, userID int64, id string) (*SavedSearch, error) {
+	return s.mem.Get(ctx, userID, id)
+}
+
+// List возвращает поиски пользователя в порядке создания
+func (s *FileStore) List(ctx context.Context, userID int64) ([]*SavedSearch, error) {
+	return s.mem.List(ctx, userID)
+}
+
+// ListAlerting возвращает поиски с включенными оповещениями
+func (s *FileStore) ListAlerting(ctx context.Context) ([]*SavedSearch, error) {
+	return s.mem.ListAlerting(ctx)
+}
+
+// Update сохраняет изменения поиска
+func (s *FileStore) Update(ctx context.Context, search *SavedSearch) error {
+	return s.change(search.ID, func() error { return s.mem.Update(ctx, search) })
+}
+
+// Delete удаляет поиск пользователя
+func (s *FileStore) Delete(ctx context.Context, userID int64, id string) error {
+	return s.change(id, func() error { return s.mem.Delete(ctx, userID, id) })
+}
+
+// Advance сдвигает водяной знак поиска
+func (s *FileStore) Advance(ctx context.Context, id string, watermark, checkedAt time.Time, notified bool) error {
+	return s.change(id, func() error { return s.mem.Advance(ctx, id, watermark, checkedAt, notified) })
+}
+
+// change применяет изменение поиска id в памяти и записывает файл.
+// Если запись не удалась, поиск возвращается в прежнее состояние.
+func (s *FileStore) change(id string, apply func() error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prev := s.mem.snapshot(id)
+	if err := apply(); err != nil {
+		return err
+	}
+	if err := s.save(); err != nil {
+		s.mem.restore(id, prev)
+		return err
+	}
+	return nil
+}
+
+func (s *FileStore) save() error {
+	s.mem.mu.RLock()
+	stored := make([]*SavedSearch, 0, len(s.mem.searches))
+	for _, search := range s.mem.searches {
+		stored = append(stored, cloneSearch(search))
+	}
+	s.mem.mu.RUnlock()
+	return jsonfile.Write(s.path, stored)
+}
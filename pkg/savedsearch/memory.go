@@ -0,0 +1,131 @@
+package savedsearch
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// MemoryStore хранилище сохраненных поисков в памяти
+type MemoryStore struct {
+	mu       sync.RWMutex
+	searches map[string]*SavedSearch
+}
+
+// NewMemoryStore создает хранилище сохраненных поисков в памяти
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{searches: make(map[string]*SavedSearch)}
+}
+
+// Create сохраняет поиск
+func (s *MemoryStore) Create(_ context.Context, search *SavedSearch) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.searches[search.ID] = cloneSearch(search)
+	return nil
+}
+
+// Get возвращает поиск пользователя по ID
+func (s *MemoryStore) Get(_ context.Context, userID int64, id string) (*SavedSearch, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	search, ok := s.searches[id]
+	if !ok || search.UserID != userID {
+		return nil, ErrNotFound
+	}
+	return cloneSearch(search), nil
+}
+
+// List возвращает поиски пользователя в порядке создания
+func (s *MemoryStore) List(_ context.Context, userID int64) ([]*SavedSearch, error) {
+	return s.list(func(search *SavedSearch) bool { return search.UserID == userID }), nil
+}
+
+// ListAlerting возвращает поиски с включенными оповещениями
+func (s *MemoryStore) ListAlerting(_ context.Context) ([]*SavedSearch, error) {
+	return s.list(func(search *SavedSearch) bool { return search.Alerts }), nil
+}
+
+func (s *MemoryStore) list(match func(*SavedSearch) bool) []*SavedSearch {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	searches := make([]*SavedSearch, 0)
+	for _, search := range s.searches {
+		if match(search) {
+			searches = append(searches, cloneSearch(search))
+		}
+	}
+	slices.SortFunc(searches, func(a, b *SavedSearch) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return searches
+}
+
+// Update сохраняет изменения поиска
+func (s *MemoryStore) Update(_ context.Context, search *SavedSearch) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	existing, ok := s.searches[search.ID]
+	if !ok || existing.UserID != search.UserID {
+		return ErrNotFound
+	}
+	s.searches[search.ID] = cloneSearch(search)
+	return nil
+}
+
+// Delete удаляет поиск пользователя
+func (s *MemoryStore) Delete(_ context.Context, userID int64, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	search, ok := s.searches[id]
+	if !ok || search.UserID != userID {
+		return ErrNotFound
+	}
+	delete(s.searches, id)
+	return nil
+}
+
+// Advance сдвигает водяной знак поиска. Водяной знак не сдвигается назад:
+// пользователь мог изменить фильтры, пока шла проверка.
+func (s *MemoryStore) Advance(_ context.Context, id string, watermark, checkedAt time.Time, notified bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	search, ok := s.searches[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if watermark.After(search.Watermark) {
+		search.Watermark = watermark
+	}
+	search.LastCheckedAt = &checkedAt
+	if notified {
+		search.LastNotifiedAt = &checkedAt
+	}
+	return nil
+}
+
+func cloneSearch(search *SavedSearch) *SavedSearch {
+	c := *search
+	c.Filters = slices.Clone(search.Filters)
+	return &c
+}
+
+// snapshot возвращает копию поиска id или nil, если его нет
+func (s *MemoryStore) snapshot(id string) *SavedSearch {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if search, ok := s.searches[id]; ok {
+		return cloneSearch(search)
+	}
+	return nil
+}
+
+// restore возвращает поиск id в состояние snapshot; nil удаляет поиск
+func (s *MemoryStore) restore(id string, search *SavedSearch) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if search == nil {
+		delete(s.searches, id)
+		return
+	}
+	s.searches[id] = search
+}
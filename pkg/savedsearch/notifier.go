@@ -0,0 +1,49 @@
+package savedsearch
+
+import (
+	"context"
+
+	"github.com/rx3lixir/gateway-service/pkg/logger"
+	"github.com/rx3lixir/gateway-service/pkg/webhook"
+)
+
+// LogNotifier пишет дайджесты в лог. Подходит для разработки.
+type LogNotifier struct {
+	logger logger.Logger
+}
+
+// NewLogNotifier создает Notifier, пишущий дайджесты в лог
+func NewLogNotifier(log logger.Logger) *LogNotifier {
+	return &LogNotifier{logger: log}
+}
+
+// Notify пишет дайджест в лог
+func (n *LogNotifier) Notify(ctx context.Context, digest Digest) error {
+	ids := make([]int64, 0, len(digest.Events))
+	for _, match := range digest.Events {
+		ids = append(ids, match.EventID)
+	}
+	n.logger.InfoContext(ctx, "Saved search digest",
+		"user_id", digest.UserID,
+		"saved_search_id", digest.SavedSearchID,
+		"name", digest.Name,
+		"total", digest.Total,
+		"event_ids", ids)
+	return nil
+}
+
+// WebhookNotifier отправляет дайджесты вебхуком saved_search.matched.
+// Доставку пользователю (email, push) выполняет подписанный сервис уведомлений.
+type WebhookNotifier struct {
+	publisher webhook.Publisher
+}
+
+// NewWebhookNotifier создает Notifier, публикующий дайджесты через вебхуки
+func NewWebhookNotifier(publisher webhook.Publisher) *WebhookNotifier {
+	return &WebhookNotifier{publisher: publisher}
+}
+
+// Notify ставит вебхук с дайджестом в очередь доставки
+func (n *WebhookNotifier) Notify(ctx context.Context, digest Digest) error {
+	return n.publisher.Publish(ctx, webhook.Event{Type: webhook.SavedSearchMatched, Data: digest})
+}
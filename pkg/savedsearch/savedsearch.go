@@ -0,0 +1,87 @@
+// Package savedsearch сохраненные поиски пользователей и оповещения о новых подходящих событиях.
+//
+// Поиск хранит фильтры GET /events в сериализованном виде. Alerter периодически
+// повторяет поиски среди событий, опубликованных после водяного знака (Watermark),
+// и отправляет найденное дайджестом через Notifier.
+package savedsearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// ErrNotFound сохраненный поиск не найден
+var ErrNotFound = errors.New("saved search not found")
+
+// SavedSearch сохраненный поиск пользователя
+type SavedSearch struct {
+	ID        string
+	UserID    int64
+	Name      string
+	Filters   json.RawMessage // Сериализованный ListEventsReq
+	Alerts    bool            // Оповещать о новых событиях
+	Watermark time.Time       // События, опубликованные не позже, уже проверены
+
+	LastCheckedAt  *time.Time
+	LastNotifiedAt *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// Store хранилище сохраненных поисков
+type Store interface {
+	Create(ctx context.Context, search *SavedSearch) error
+
+	// Get возвращает поиск пользователя; чужой поиск — ErrNotFound
+	Get(ctx context.Context, userID int64, id string) (*SavedSearch, error)
+
+	// List возвращает поиски пользователя в порядке создания
+	List(ctx context.Context, userID int64) ([]*SavedSearch, error)
+
+	// ListAlerting возвращает поиски всех пользователей с включенными оповещениями
+	ListAlerting(ctx context.Context) ([]*SavedSearch, error)
+
+	Update(ctx context.Context, search *SavedSearch) error
+	Delete(ctx context.Context, userID int64, id string) error
+
+	// Advance сдвигает водяной знак после проверки. Поля, которые меняет
+	// пользователь, не затрагиваются. notified — был ли отправлен дайджест.
+	Advance(ctx context.Context, id string, watermark, checkedAt time.Time, notified bool) error
+}
+
+// Match событие, подошедшее под сохраненный поиск
+type Match struct {
+	EventID     int64     `json:"event_id"`
+	Name        string    `json:"name"`
+	Date        string    `json:"date"`
+	Time        string    `json:"time,omitempty"`
+	Location    string    `json:"location,omitempty"`
+	Price       float32   `json:"price"`
+	CreatedAt   time.Time `json:"created_at"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// Searcher выполняет сохраненный поиск среди событий, опубликованных в (after, before].
+// Время публикации, а не создания: иначе события, прошедшие модерацию, никогда не попадут в дайджест.
+type Searcher interface {
+	// NewMatches возвращает не больше limit подходящих событий и общее их число
+	NewMatches(ctx context.Context, filters json.RawMessage, after, before time.Time, limit int) ([]Match, int, error)
+}
+
+// Digest оповещение о новых событиях по сохраненному поиску
+type Digest struct {
+	UserID        int64     `json:"user_id"`
+	SavedSearchID string    `json:"saved_search_id"`
+	Name          string    `json:"name"`
+	Events        []Match   `json:"events"`
+	Total         int       `json:"total"` // Всего новых событий; в Events — не больше DigestSize
+	From          time.Time `json:"from"`
+	To            time.Time `json:"to"`
+}
+
+// Notifier отправляет дайджесты пользователям (email, вебхук, лог)
+type Notifier interface {
+	Notify(ctx context.Context, digest Digest) error
+}
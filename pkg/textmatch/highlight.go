@@ -0,0 +1,144 @@
+package textmatch
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Span фрагмент текста, совпавший с запросом: [Start, End) в символах (рунах)
+type Span struct {
+	Start int
+	End   int
+}
+
+// MaxEdits допустимое число опечаток в слове запроса: короткие слова
+// сравниваются точно, иначе почти любое слово совпадет с любым
+func MaxEdits(word string) int {
+	switch n := len([]rune(word)); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// EditDistance расстояние Левенштейна между строками в рунах
+func EditDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// word слово текста и его позиция в рунах
+type word struct {
+	text  string
+	start int
+}
+
+// foldRune приводит символ к виду для сравнения, не меняя длину текста
+func foldRune(r rune) rune {
+	r = unicode.ToLower(r)
+	if r == 'ё' {
+		r = 'е'
+	}
+	return r
+}
+
+// words разбивает текст на слова из букв и цифр
+func words(text string) []word {
+	var result []word
+	var b strings.Builder
+	start := -1
+	i := 0
+	for _, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			b.WriteRune(foldRune(r))
+		} else if start >= 0 {
+			result = append(result, word{text: b.String(), start: start})
+			b.Reset()
+			start = -1
+		}
+		i++
+	}
+	if start >= 0 {
+		result = append(result, word{text: b.String(), start: start})
+	}
+	return result
+}
+
+// prefixMatch возвращает длину начала слова w, совпадающего со словом запроса q.
+// При fuzzy допускаются опечатки (MaxEdits): сравниваются начала w длиной len(q)±1,
+// выбирается ближайшее. 0 — совпадения нет.
+func prefixMatch(w, q []rune, fuzzy bool) int {
+	if len(w) >= len(q) && slices.Equal(w[:len(q)], q) {
+		return len(q)
+	}
+	if !fuzzy {
+		return 0
+	}
+
+	maxEdits := MaxEdits(string(q))
+	best, bestDistance := 0, maxEdits+1
+	for n := len(q) - 1; n <= len(q)+1; n++ {
+		if n <= 0 || n > len(w) {
+			continue
+		}
+		if d := EditDistance(string(w[:n]), string(q)); d < bestDistance {
+			best, bestDistance = n, d
+		}
+	}
+	return best
+}
+
+// Highlight находит в тексте слова, начинающиеся со слов запроса, и возвращает
+// их совпавшие части, упорядоченные и без пересечений. Регистр и ё/е не учитываются.
+// При fuzzy допускаются опечатки, см. MaxEdits.
+func Highlight(text, query string, fuzzy bool) []Span {
+	queryWords := words(query)
+	if len(queryWords) == 0 {
+		return nil
+	}
+
+	var spans []Span
+	for _, w := range words(text) {
+		wr := []rune(w.text)
+		for _, q := range queryWords {
+			if n := prefixMatch(wr, []rune(q.text), fuzzy); n > 0 {
+				spans = append(spans, Span{Start: w.start, End: w.start + n})
+			}
+		}
+	}
+
+	// Сливаем пересекающиеся фрагменты
+	slices.SortFunc(spans, func(a, b Span) int { return a.Start - b.Start })
+	merged := spans[:0]
+	for _, span := range spans {
+		if last := len(merged) - 1; last >= 0 && span.Start <= merged[last].End {
+			merged[last].End = max(merged[last].End, span.End)
+			continue
+		}
+		merged = append(merged, span)
+	}
+	return merged
+}
@@ -0,0 +1,106 @@
+// Package textmatch нечеткое сравнение коротких строк: названий событий, адресов.
+//
+// Строки нормализуются (регистр, ё/е, пунктуация), после чего сравниваются
+// по триграммам слов, как pg_trgm: порядок слов и мелкие опечатки
+// почти не влияют на результат. Highlight находит в тексте фрагменты,
+// совпавшие с поисковым запросом (для подсветки подсказок).
+package textmatch
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Normalize приводит строку к нижнему регистру, заменяет ё на е,
+// а все, кроме букв и цифр, — на пробелы; пробелы схлопываются
+func Normalize(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+
+	space := true
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case r == 'ё':
+			r = 'е'
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+		default:
+			if !space {
+				b.WriteByte(' ')
+				space = true
+			}
+			continue
+		}
+		b.WriteRune(r)
+		space = false
+	}
+	return strings.TrimSuffix(b.String(), " ")
+}
+
+// Tokens возвращает уникальные слова нормализованной строки в алфавитном порядке
+func Tokens(s string) []string {
+	tokens := strings.Fields(Normalize(s))
+	slices.Sort(tokens)
+	return slices.Compact(tokens)
+}
+
+// Fingerprint строит ключ из нескольких частей: каждая часть — отсортированные
+// уникальные слова, части разделены "|". Строки, отличающиеся только регистром,
+// пунктуацией и порядком слов, получают одинаковый ключ.
+func Fingerprint(parts ...string) string {
+	keys := make([]string, len(parts))
+	for i, part := range parts {
+		keys[i] = strings.Join(Tokens(part), " ")
+	}
+	return strings.Join(keys, "|")
+}
+
+// Profile подготовленная для сравнения строка. Полезен, когда одна строка
+// сравнивается со многими.
+type Profile struct {
+	trigrams map[string]struct{}
+}
+
+// NewProfile нормализует строку и строит ее триграммы
+func NewProfile(s string) Profile {
+	p := Profile{trigrams: make(map[string]struct{})}
+	for _, token := range strings.Fields(Normalize(s)) {
+		// Как в pg_trgm: два пробела в начале слова и один в конце
+		runes := []rune("  " + token + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			p.trigrams[string(runes[i:i+3])] = struct{}{}
+		}
+	}
+	return p
+}
+
+// Empty сообщает, что в строке нет ни букв, ни цифр
+func (p Profile) Empty() bool {
+	return len(p.trigrams) == 0
+}
+
+// Similarity коэффициент Дайса по триграммам: 1 — одинаковые строки, 0 — ничего общего.
+// Для двух пустых строк возвращает 0.
+func (p Profile) Similarity(other Profile) float64 {
+	if p.Empty() || other.Empty() {
+		return 0
+	}
+
+	small, large := p.trigrams, other.trigrams
+	if len(small) > len(large) {
+		small, large = large, small
+	}
+
+	common := 0
+	for gram := range small {
+		if _, ok := large[gram]; ok {
+			common++
+		}
+	}
+	return 2 * float64(common) / float64(len(p.trigrams)+len(other.trigrams))
+}
+
+// Similarity сравнивает две строки, см. Profile.Similarity
+func Similarity(a, b string) float64 {
+	return NewProfile(a).Similarity(NewProfile(b))
+}
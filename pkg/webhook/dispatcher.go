@@ -0,0 +1,335 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	mrand "math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rx3lixir/gateway-service/pkg/logger"
+)
+
+// Config параметры доставки вебхуков
+type Config struct {
+	MaxAttempts    int           // Попыток до перевода в dead letter
+	InitialBackoff time.Duration // Пауза перед первым повтором, дальше удваивается
+	MaxBackoff     time.Duration // Верхняя граница паузы
+	Timeout        time.Duration // Таймаут одного запроса
+	Workers        int           // Одновременных доставок
+	PollInterval   time.Duration // Как часто проверять очередь на отложенные повторы
+
+	// AllowPrivateNetworks разрешает подписки на адреса внутренней сети
+	// (localhost, частные сети). Только для разработки.
+	AllowPrivateNetworks bool
+}
+
+// DefaultConfig параметры доставки по умолчанию: 8 попыток на протяжении примерно суток
+func DefaultConfig() Config {
+	return Config{
+		MaxAttempts:    8,
+		InitialBackoff: 30 * time.Second,
+		MaxBackoff:     6 * time.Hour,
+		Timeout:        10 * time.Second,
+		Workers:        4,
+		PollInterval:   time.Second,
+	}
+}
+
+// errEndpointDisabled подписка выключена после постановки доставки в очередь
+var errEndpointDisabled = errors.New("endpoint is disabled")
+
+// payload тело запроса вебхука
+type payload struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       any       `json:"data"`
+}
+
+// Dispatcher рассылает события подписчикам через очередь доставок.
+// Publish только ставит доставки в очередь, отправку выполняет Run.
+type Dispatcher struct {
+	endpoints EndpointStore
+	queue     Queue
+	config    Config
+	client    *http.Client
+	logger    logger.Logger
+	wake      chan struct{}
+	now       func() time.Time
+}
+
+// NewDispatcher создает диспетчер вебхуков. Нулевые поля config заменяются значениями по умолчанию.
+func NewDispatcher(endpoints EndpointStore, queue Queue, config Config, log logger.Logger) *Dispatcher {
+	defaults := DefaultConfig()
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defaults.MaxAttempts
+	}
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = defaults.InitialBackoff
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = defaults.MaxBackoff
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaults.Timeout
+	}
+	if config.Workers <= 0 {
+		config.Workers = defaults.Workers
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = defaults.PollInterval
+	}
+
+	return &Dispatcher{
+		endpoints: endpoints,
+		queue:     queue,
+		config:    config,
+		client:    newHTTPClient(config),
+		logger:    log,
+		wake:      make(chan struct{}, 1),
+		now:       time.Now,
+	}
+}
+
+// Endpoints возвращает хранилище подписок
+func (d *Dispatcher) Endpoints() EndpointStore { return d.endpoints }
+
+// Deliveries возвращает очередь доставок
+func (d *Dispatcher) Deliveries() Queue { return d.queue }
+
+// NewSecret генерирует ключ подписи для новой подписки
+func NewSecret() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("webhook: failed to generate secret: %v", err))
+	}
+	return "whsec_" + hex.EncodeToString(b)
+}
+
+// Publish ставит событие в очередь для всех подходящих подписок
+func (d *Dispatcher) Publish(ctx context.Context, event Event) error {
+	endpoints, err := d.endpoints.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list webhook endpoints: %w", err)
+	}
+
+	now := d.now()
+	eventID := uuid.NewString()
+	body, err := json.Marshal(payload{
+		ID:         eventID,
+		Type:       event.Type,
+		OccurredAt: now.UTC(),
+		Data:       event.Data,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	enqueued := 0
+	for _, endpoint := range endpoints {
+		if !endpoint.Matches(event) {
+			continue
+		}
+		delivery := &Delivery{
+			ID:            uuid.NewString(),
+			EndpointID:    endpoint.ID,
+			EventID:       eventID,
+			EventType:     event.Type,
+			Payload:       body,
+			Status:        StatusPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}
+		if err := d.queue.Enqueue(ctx, delivery); err != nil {
+			return fmt.Errorf("failed to enqueue webhook delivery: %w", err)
+		}
+		enqueued++
+	}
+
+	if enqueued > 0 {
+		d.logger.DebugContext(ctx, "Webhook deliveries enqueued", "type", event.Type, "count", enqueued)
+		d.notify()
+	}
+	return nil
+}
+
+// Redeliver ставит доставку на повторную отправку вручную (обычно из dead letter)
+func (d *Dispatcher) Redeliver(ctx context.Context, id string) (*Delivery, error) {
+	delivery, err := d.queue.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if delivery.Status == StatusDelivering {
+		return nil, fmt.Errorf("delivery %s is in progress", id)
+	}
+
+	now := d.now()
+	delivery.Status = StatusPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = now
+	delivery.UpdatedAt = now
+	if err := d.queue.Update(ctx, delivery); err != nil {
+		return nil, err
+	}
+
+	d.notify()
+	return delivery, nil
+}
+
+// Run отправляет доставки из очереди, пока не отменен ctx
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		d.processDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
+
+func (d *Dispatcher) notify() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// processDue отправляет все готовые доставки пачками по Workers
+func (d *Dispatcher) processDue(ctx context.Context) {
+	// Аренда с запасом: за это время доставка гарантированно завершится или упадет по таймауту
+	lease := 2 * d.config.Timeout
+
+	for ctx.Err() == nil {
+		batch, err := d.queue.Claim(ctx, d.now(), d.config.Workers, lease)
+		if err != nil {
+			d.logger.ErrorContext(ctx, "Failed to claim webhook deliveries", "error", err)
+			return
+		}
+		if len(batch) == 0 {
+			return
+		}
+
+		var wg sync.WaitGroup
+		for _, delivery := range batch {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				d.deliver(ctx, delivery)
+			}()
+		}
+		wg.Wait()
+	}
+}
+
+// deliver выполняет одну попытку доставки и сохраняет результат
+func (d *Dispatcher) deliver(ctx context.Context, delivery *Delivery) {
+	delivery.Attempts++
+	statusCode, err := d.send(ctx, delivery)
+
+	now := d.now()
+	delivery.UpdatedAt = now
+	delivery.LastStatusCode = statusCode
+
+	switch {
+	case err == nil:
+		delivery.Status = StatusSucceeded
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+
+	// Удаленная или выключенная подписка — повторять бессмысленно
+	case errors.Is(err, ErrNotFound) || errors.Is(err, errEndpointDisabled) || delivery.Attempts >= d.config.MaxAttempts:
+		delivery.Status = StatusDead
+		delivery.LastError = err.Error()
+		d.logger.WarnContext(ctx, "Webhook delivery moved to dead letter",
+			"delivery_id", delivery.ID,
+			"endpoint_id", delivery.EndpointID,
+			"attempts", delivery.Attempts,
+			"error", err)
+
+	default:
+		delivery.Status = StatusPending
+		delivery.LastError = err.Error()
+		delivery.NextAttemptAt = now.Add(d.backoff(delivery.Attempts))
+		d.logger.InfoContext(ctx, "Webhook delivery failed, will retry",
+			"delivery_id", delivery.ID,
+			"endpoint_id", delivery.EndpointID,
+			"attempts", delivery.Attempts,
+			"next_attempt_at", delivery.NextAttemptAt,
+			"error", err)
+	}
+
+	// Сохраняем без отмены: результат попытки не должен потеряться при остановке
+	if err := d.queue.Update(context.WithoutCancel(ctx), delivery); err != nil {
+		d.logger.ErrorContext(ctx, "Failed to save webhook delivery", "delivery_id", delivery.ID, "error", err)
+	}
+}
+
+// send отправляет подписанный запрос. Успех — любой 2xx ответ.
+func (d *Dispatcher) send(ctx context.Context, delivery *Delivery) (int, error) {
+	endpoint, err := d.endpoints.Get(ctx, delivery.EndpointID)
+	if err != nil {
+		return 0, fmt.Errorf("endpoint %s: %w", delivery.EndpointID, err)
+	}
+	if !endpoint.Active {
+		return 0, errEndpointDisabled
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	now := d.now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "gateway-service-webhooks/1.0")
+	req.Header.Set(HeaderID, delivery.EventID)
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(HeaderSignature, Sign(endpoint.Secret, now, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		if msg := strings.TrimSpace(string(body)); msg != "" {
+			return resp.StatusCode, fmt.Errorf("endpoint responded %s: %s", resp.Status, msg)
+		}
+		return resp.StatusCode, fmt.Errorf("endpoint responded %s", resp.Status)
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	return resp.StatusCode, nil
+}
+
+// backoff пауза перед следующей попыткой: экспонента с джиттером ±20%
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.config.InitialBackoff
+	for i := 1; i < attempts && delay < d.config.MaxBackoff; i++ {
+		delay *= 2
+	}
+	delay = min(delay, d.config.MaxBackoff)
+
+	jitter := time.Duration(float64(delay) * (mrand.Float64()*0.4 - 0.2))
+	return delay + jitter
+}
@@ -0,0 +1,207 @@
+package webhook
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/rx3lixir/gateway-service/pkg/jsonfile"
+)
+
+// Файлы FileEndpointStore и FileQueue в каталоге хранилища
+const (
+	endpointsFile  = "endpoints.json"
+	deliveriesFile = "deliveries.json"
+)
+
+var (
+	_ EndpointStore = (*FileEndpointStore)(nil)
+	_ Queue         = (*FileQueue)(nil)
+)
+
+// storedEndpoint подписка в файле; в отличие от ответа API содержит ключ подписи
+type storedEndpoint struct {
+	*Endpoint
+	Secret string `json:"secret"`
+}
+
+// FileEndpointStore хранилище подписок в JSON файле. Подписки читаются
+// из памяти, файл перезаписывается при каждом изменении.
+type FileEndpointStore struct {
+	mu   sync.Mutex // Сериализует изменения вместе с записью файла
+	mem  *MemoryEndpointStore
+	path string
+}
+
+// NewFileEndpointStore открывает хранилище подписок в каталоге dir
+func NewFileEndpointStore(dir string) (*FileEndpointStore, error) {
+	s := &FileEndpointStore{mem: NewMemoryEndpointStore(), path: filepath.Join(dir, endpointsFile)}
+
+	var stored []storedEndpoint
+	if _, err := jsonfile.Read(s.path, &stored); err != nil {
+		return nil, err
+	}
+	for _, e := range stored {
+		e.Endpoint.Secret = e.Secret
+		s.mem.endpoints[e.ID] = e.Endpoint
+	}
+	return s, nil
+}
+
+// Create сохраняет подписку
+func (s *FileEndpointStore) Create(ctx context.Context, endpoint *Endpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.mem.Create(ctx, endpoint); err != nil {
+		return err
+	}
+	if err := s.save(ctx); err != nil {
+		s.mem.Delete(ctx, endpoint.ID)
+		return err
+	}
+	return nil
+}
+
+// Get возвращает подписку по ID
+func (s *FileEndpointStore) Get(ctx context.Context, id string) (*Endpoint, error) {
+	return s.mem.Get(ctx, id)
+}
+
+// List возвращает подписки в порядке создания
+func (s *FileEndpointStore) List(ctx context.Context) ([]*Endpoint, error) {
+	return s.mem.List(ctx)
+}
+
+// Update сохраняет изменения подписки
+func (s *FileEndpointStore) Update(ctx context.Context, endpoint *Endpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prev, err := s.mem.Get(ctx, endpoint.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.mem.Update(ctx, endpoint); err != nil {
+		return err
+	}
+	if err := s.save(ctx); err != nil {
+		s.mem.Update(ctx, prev)
+		return err
+	}
+	return nil
+}
+
+// Delete удаляет подписку
+func (s *FileEndpointStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prev, err := s.mem.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.mem.Delete(ctx, id); err != nil {
+		return err
+	}
+	if err := s.save(ctx); err != nil {
+		s.mem.Create(ctx, prev)
+		return err
+	}
+	return nil
+}
+
+func (s *FileEndpointStore) save(ctx context.Context) error {
+	endpoints, _ := s.mem.List(ctx)
+	stored := make([]storedEndpoint, len(endpoints))
+	for i, e := range endpoints {
+		stored[i] = storedEndpoint{Endpoint: e, Secret: e.Secret}
+	}
+	return jsonfile.Write(s.path, stored)
+}
+
+// storedDelivery доставка в файле вместе с телом запроса и арендой
+type storedDelivery struct {
+	*Delivery
+	Payload    []byte    `json:"payload"`
+	LeaseUntil time.Time `json:"lease_until"`
+}
+
+// FileQueue очередь доставок в JSON файле для одного экземпляра gateway.
+//
+// Состояние очереди в памяти главное: если запись файла не удалась, изменение
+// все равно применяется, а ошибка возвращается. Файл догонит память при
+// следующей успешной записи. Доставки, взятые в работу до перезапуска,
+// снова отправляются после истечения аренды.
+type FileQueue struct {
+	mu   sync.Mutex // Сериализует изменения вместе с записью файла
+	mem  *MemoryQueue
+	path string
+}
+
+// NewFileQueue открывает очередь доставок в каталоге dir
+func NewFileQueue(dir string) (*FileQueue, error) {
+	q := &FileQueue{mem: NewMemoryQueue(), path: filepath.Join(dir, deliveriesFile)}
+
+	var stored []storedDelivery
+	if _, err := jsonfile.Read(q.path, &stored); err != nil {
+		return nil, err
+	}
+	for _, d := range stored {
+		d.Delivery.Payload = d.Payload
+		d.Delivery.LeaseUntil = d.LeaseUntil
+		q.mem.deliveries[d.ID] = d.Delivery
+		q.mem.order = append(q.mem.order, d.ID)
+	}
+	return q, nil
+}
+
+// Enqueue добавляет доставку в конец очереди
+func (q *FileQueue) Enqueue(ctx context.Context, delivery *Delivery) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if err := q.mem.Enqueue(ctx, delivery); err != nil {
+		return err
+	}
+	return q.save()
+}
+
+// Claim забирает готовые к отправке доставки в порядке добавления
+func (q *FileQueue) Claim(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]*Delivery, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	claimed, err := q.mem.Claim(ctx, now, limit, lease)
+	if err != nil || len(claimed) == 0 {
+		return claimed, err
+	}
+	return claimed, q.save()
+}
+
+// Update сохраняет состояние доставки
+func (q *FileQueue) Update(ctx context.Context, delivery *Delivery) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if err := q.mem.Update(ctx, delivery); err != nil {
+		return err
+	}
+	return q.save()
+}
+
+// Get возвращает доставку по ID
+func (q *FileQueue) Get(ctx context.Context, id string) (*Delivery, error) {
+	return q.mem.Get(ctx, id)
+}
+
+// List возвращает доставки, новые первыми
+func (q *FileQueue) List(ctx context.Context, filter DeliveryFilter) ([]*Delivery, error) {
+	return q.mem.List(ctx, filter)
+}
+
+func (q *FileQueue) save() error {
+	q.mem.mu.Lock()
+	stored := make([]storedDelivery, len(q.mem.order))
+	for i, id := range q.mem.order {
+		d := cloneDelivery(q.mem.deliveries[id])
+		stored[i] = storedDelivery{Delivery: d, Payload: d.Payload, LeaseUntil: d.LeaseUntil}
+	}
+	q.mem.mu.Unlock()
+	return jsonfile.Write(q.path, stored)
+}
@@ -0,0 +1,114 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenDestination адрес подписчика во внутренней сети
+var ErrForbiddenDestination = errors.New("webhook destination must be a public address")
+
+// forbiddenPrefixes сети, не покрытые методами netip.Addr: общий адрес
+// провайдера (CGNAT), тестовые и зарезервированные сети, NAT64
+var forbiddenPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("2001:db8::/32"),
+}
+
+// forbiddenAddr проверяет, что адрес не публичный: loopback, частные сети,
+// link-local (в том числе метаданные облака 169.254.169.254), multicast
+func forbiddenAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return true
+	}
+	for _, prefix := range forbiddenPrefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckDestination проверяет URL подписки: все адреса хоста должны быть публичными.
+// При отправке адрес проверяется еще раз, уже тот, к которому идет подключение:
+// DNS хоста мог измениться после проверки.
+func (d *Dispatcher) CheckDestination(ctx context.Context, rawURL string) error {
+	if d.config.AllowPrivateNetworks {
+		return nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid url %q: %w", rawURL, err)
+	}
+	host := u.Hostname()
+
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if forbiddenAddr(addr) {
+			return ErrForbiddenDestination
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("invalid url %q: cannot resolve host: %w", rawURL, err)
+	}
+	for _, addr := range addrs {
+		if forbiddenAddr(addr) {
+			return ErrForbiddenDestination
+		}
+	}
+	return nil
+}
+
+// newHTTPClient создает клиент доставки. Если внутренние сети не разрешены,
+// адрес проверяется при каждом подключении, после разрешения DNS.
+func newHTTPClient(config Config) *http.Client {
+	dialer := &net.Dialer{Timeout: config.Timeout}
+	if !config.AllowPrivateNetworks {
+		dialer.Control = func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			addr, err := netip.ParseAddr(host)
+			if err != nil || forbiddenAddr(addr) {
+				return ErrForbiddenDestination
+			}
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// Прокси из окружения обошел бы проверку адреса при подключении
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   config.Timeout,
+		Transport: transport,
+		// Редиректы не выполняем: подписчик должен указать окончательный URL
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+}
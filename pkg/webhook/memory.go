@@ -0,0 +1,208 @@
+package webhook
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// memoryQueueMaxSucceeded сколько успешных доставок MemoryQueue хранит для истории
+const memoryQueueMaxSucceeded = 1000
+
+// MemoryEndpointStore хранилище подписок в памяти
+type MemoryEndpointStore struct {
+	mu        sync.RWMutex
+	endpoints map[string]*Endpoint
+}
+
+// NewMemoryEndpointStore создает хранилище подписок в памяти
+func NewMemoryEndpointStore() *MemoryEndpointStore {
+	return &MemoryEndpointStore{endpoints: make(map[string]*Endpoint)}
+}
+
+// Create сохраняет подписку
+func (s *MemoryEndpointStore) Create(_ context.Context, endpoint *Endpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.endpoints[endpoint.ID] = cloneEndpoint(endpoint)
+	return nil
+}
+
+// Get возвращает подписку по ID
+func (s *MemoryEndpointStore) Get(_ context.Context, id string) (*Endpoint, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	endpoint, ok := s.endpoints[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return cloneEndpoint(endpoint), nil
+}
+
+// List возвращает подписки в порядке создания
+func (s *MemoryEndpointStore) List(_ context.Context) ([]*Endpoint, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	endpoints := make([]*Endpoint, 0, len(s.endpoints))
+	for _, endpoint := range s.endpoints {
+		endpoints = append(endpoints, cloneEndpoint(endpoint))
+	}
+	slices.SortFunc(endpoints, func(a, b *Endpoint) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return endpoints, nil
+}
+
+// Update сохраняет изменения подписки
+func (s *MemoryEndpointStore) Update(_ context.Context, endpoint *Endpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.endpoints[endpoint.ID]; !ok {
+		return ErrNotFound
+	}
+	s.endpoints[endpoint.ID] = cloneEndpoint(endpoint)
+	return nil
+}
+
+// Delete удаляет подписку
+func (s *MemoryEndpointStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.endpoints[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.endpoints, id)
+	return nil
+}
+
+func cloneEndpoint(e *Endpoint) *Endpoint {
+	c := *e
+	c.EventTypes = slices.Clone(e.EventTypes)
+	c.CategoryIDs = slices.Clone(e.CategoryIDs)
+	return &c
+}
+
+// MemoryQueue очередь доставок в памяти. Не переживает перезапуск процесса.
+type MemoryQueue struct {
+	mu         sync.Mutex
+	deliveries map[string]*Delivery
+	order      []string // ID в порядке добавления
+}
+
+// NewMemoryQueue создает очередь доставок в памяти
+func NewMemoryQueue() *MemoryQueue {
+	return &MemoryQueue{deliveries: make(map[string]*Delivery)}
+}
+
+// Enqueue добавляет доставку в конец очереди
+func (q *MemoryQueue) Enqueue(_ context.Context, delivery *Delivery) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.deliveries[delivery.ID] = cloneDelivery(delivery)
+	q.order = append(q.order, delivery.ID)
+	return nil
+}
+
+// Claim забирает готовые к отправке доставки в порядке добавления
+func (q *MemoryQueue) Claim(_ context.Context, now time.Time, limit int, lease time.Duration) ([]*Delivery, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var claimed []*Delivery
+	for _, id := range q.order {
+		if len(claimed) >= limit {
+			break
+		}
+		d := q.deliveries[id]
+		ready := d.Status == StatusPending && !d.NextAttemptAt.After(now)
+		expired := d.Status == StatusDelivering && d.LeaseUntil.Before(now)
+		if !ready && !expired {
+			continue
+		}
+		d.Status = StatusDelivering
+		d.LeaseUntil = now.Add(lease)
+		claimed = append(claimed, cloneDelivery(d))
+	}
+	return claimed, nil
+}
+
+// Update сохраняет состояние доставки. Старые успешные доставки вытесняются.
+func (q *MemoryQueue) Update(_ context.Context, delivery *Delivery) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if _, ok := q.deliveries[delivery.ID]; !ok {
+		return ErrNotFound
+	}
+	q.deliveries[delivery.ID] = cloneDelivery(delivery)
+	if delivery.Status == StatusSucceeded {
+		q.pruneSucceededLocked()
+	}
+	return nil
+}
+
+// Get возвращает доставку по ID
+func (q *MemoryQueue) Get(_ context.Context, id string) (*Delivery, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	d, ok := q.deliveries[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return cloneDelivery(d), nil
+}
+
+// List возвращает доставки, новые первыми
+func (q *MemoryQueue) List(_ context.Context, filter DeliveryFilter) ([]*Delivery, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var result []*Delivery
+	for i := len(q.order) - 1; i >= 0; i-- {
+		if filter.Limit > 0 && len(result) >= filter.Limit {
+			break
+		}
+		d := q.deliveries[q.order[i]]
+		if filter.EndpointID != "" && d.EndpointID != filter.EndpointID {
+			continue
+		}
+		if filter.Status != "" && d.Status != filter.Status {
+			continue
+		}
+		result = append(result, cloneDelivery(d))
+	}
+	return result, nil
+}
+
+// pruneSucceededLocked удаляет самые старые успешные доставки сверх лимита
+func (q *MemoryQueue) pruneSucceededLocked() {
+	succeeded := 0
+	for _, id := range q.order {
+		if q.deliveries[id].Status == StatusSucceeded {
+			succeeded++
+		}
+	}
+	excess := succeeded - memoryQueueMaxSucceeded
+	if excess <= 0 {
+		return
+	}
+
+	kept := q.order[:0]
+	for _, id := range q.order {
+		if excess > 0 && q.deliveries[id].Status == StatusSucceeded {
+			delete(q.deliveries, id)
+			excess--
+			continue
+		}
+		kept = append(kept, id)
+	}
+	q.order = kept
+}
+
+func cloneDelivery(d *Delivery) *Delivery {
+	c := *d
+	c.Payload = slices.Clone(d.Payload)
+	if d.DeliveredAt != nil {
+		t := *d.DeliveredAt
+		c.DeliveredAt = &t
+	}
+	return &c
+}
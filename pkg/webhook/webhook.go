@@ -0,0 +1,138 @@
+// Package webhook исходящие вебхуки: подписки, очередь доставок с повторами и подпись запросов.
+package webhook
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+)
+
+// Типы событий вебхуков
+const (
+	EventCreated = "event.created"
+	EventUpdated = "event.updated"
+	EventDeleted = "event.deleted"
+	UserCreated  = "user.created"
+
+	// SavedSearchMatched дайджест новых событий по сохраненному поиску пользователя
+	SavedSearchMatched = "saved_search.matched"
+
+	// RsvpPromoted пользователь переведен из листа ожидания на освободившееся место
+	RsvpPromoted = "rsvp.promoted"
+)
+
+// EventTypes все поддерживаемые типы событий
+var EventTypes = []string{EventCreated, EventUpdated, EventDeleted, UserCreated, SavedSearchMatched, RsvpPromoted}
+
+// IsKnownType проверяет, что тип события поддерживается
+func IsKnownType(eventType string) bool {
+	return slices.Contains(EventTypes, eventType)
+}
+
+// ErrNotFound подписка или доставка не найдена
+var ErrNotFound = errors.New("webhook: not found")
+
+// Endpoint подписка партнера на события
+type Endpoint struct {
+	ID          string    `json:"id"`
+	URL         string    `json:"url"`
+	Secret      string    `json:"-"` // Ключ подписи; отдается только при создании
+	Description string    `json:"description,omitempty"`
+	EventTypes  []string  `json:"event_types"`
+	CategoryIDs []int64   `json:"category_ids,omitempty"` // Пусто = все категории
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Matches проверяет, подписана ли точка на событие
+func (e *Endpoint) Matches(event Event) bool {
+	if !e.Active || !slices.Contains(e.EventTypes, event.Type) {
+		return false
+	}
+	// Фильтр по категориям применяется только к событиям, у которых категория есть
+	if len(e.CategoryIDs) > 0 && event.CategoryID != nil {
+		return slices.Contains(e.CategoryIDs, *event.CategoryID)
+	}
+	return true
+}
+
+// Event событие для рассылки подписчикам
+type Event struct {
+	Type       string
+	Data       any    // Сериализуется в поле data тела запроса
+	CategoryID *int64 // Для фильтра подписок по категориям
+}
+
+// Publisher публикует события для доставки подписчикам
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// EndpointStore хранилище подписок
+type EndpointStore interface {
+	Create(ctx context.Context, endpoint *Endpoint) error
+	Get(ctx context.Context, id string) (*Endpoint, error)
+	List(ctx context.Context) ([]*Endpoint, error)
+	Update(ctx context.Context, endpoint *Endpoint) error
+	Delete(ctx context.Context, id string) error
+}
+
+// Статусы доставки
+const (
+	StatusPending    = "pending"    // Ждет первой или повторной попытки
+	StatusDelivering = "delivering" // Взята в работу
+	StatusSucceeded  = "succeeded"
+	StatusDead       = "dead" // Попытки исчерпаны; повтор только вручную
+)
+
+// Delivery доставка одного события одной подписке
+type Delivery struct {
+	ID             string     `json:"id"`
+	EndpointID     string     `json:"endpoint_id"`
+	EventID        string     `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	Payload        []byte     `json:"-"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at"`
+	LeaseUntil     time.Time  `json:"-"` // До какого момента доставка занята обработчиком
+	LastError      string     `json:"last_error,omitempty"`
+	LastStatusCode int        `json:"last_status_code,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+}
+
+// DeliveryFilter фильтр списка доставок
+type DeliveryFilter struct {
+	EndpointID string
+	Status     string
+	Limit      int
+}
+
+// Queue персистентная очередь доставок.
+//
+// Встроенные реализации — MemoryQueue (для тестов и разработки) и FileQueue
+// (один экземпляр шлюза). Для нескольких экземпляров интерфейс реализуется
+// поверх базы данных: Claim должен атомарно забирать доставки, чтобы
+// экземпляры не отправили одно и то же дважды.
+type Queue interface {
+	// Enqueue добавляет доставку
+	Enqueue(ctx context.Context, delivery *Delivery) error
+
+	// Claim забирает до limit доставок, готовых к отправке на момент now
+	// (pending с наступившим NextAttemptAt или delivering с истекшей арендой),
+	// и переводит их в delivering с арендой до now+lease
+	Claim(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]*Delivery, error)
+
+	// Update сохраняет состояние доставки
+	Update(ctx context.Context, delivery *Delivery) error
+
+	// Get возвращает доставку по ID
+	Get(ctx context.Context, id string) (*Delivery, error)
+
+	// List возвращает доставки, новые первыми
+	List(ctx context.Context, filter DeliveryFilter) ([]*Delivery, error)
+}
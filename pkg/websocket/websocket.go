@@ -0,0 +1,345 @@
+// Package websocket минимальная серверная реализация протокола WebSocket (RFC 6455).
+//
+// Поддерживается то, что нужно для серверных уведомлений: рукопожатие,
+// текстовые и бинарные сообщения (с фрагментацией), ping/pong и закрытие.
+// Расширения (permessage-deflate) и подпротоколы не поддерживаются.
+package websocket
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// acceptGUID константа из RFC 6455 для вычисления Sec-WebSocket-Accept
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// Коды операций фреймов
+const (
+	opContinuation = 0x0
+	OpText         = 0x1
+	OpBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+)
+
+// Коды закрытия соединения
+const (
+	CloseNormal          = 1000
+	CloseGoingAway       = 1001
+	CloseProtocolError   = 1002
+	CloseMessageTooBig   = 1009
+	CloseInternalError   = 1011
+	maxControlPayloadLen = 125
+)
+
+// ErrClosed соединение закрыто
+var ErrClosed = errors.New("websocket: connection closed")
+
+// CloseError получен фрейм закрытия от клиента
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *CloseError) Error() string {
+	return fmt.Sprintf("websocket: closed by peer: %d %s", e.Code, e.Reason)
+}
+
+// Upgrader параметры рукопожатия
+type Upgrader struct {
+	// CheckOrigin проверяет заголовок Origin; nil — любой источник
+	CheckOrigin func(r *http.Request) bool
+
+	// MaxMessageSize максимальный размер входящего сообщения; по умолчанию 64 КБ
+	MaxMessageSize int64
+
+	// WriteTimeout таймаут записи одного сообщения; по умолчанию 10 секунд
+	WriteTimeout time.Duration
+}
+
+// Upgrade выполняет рукопожатие и захватывает TCP соединение.
+// При ошибке ответ клиенту уже отправлен.
+func (u *Upgrader) Upgrade(w http.ResponseWriter, r *http.Request) (*Conn, error) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return nil, fmt.Errorf("websocket: invalid method %s", r.Method)
+	}
+	if !headerContainsToken(r.Header, "Connection", "upgrade") || !headerContainsToken(r.Header, "Upgrade", "websocket") {
+		http.Error(w, "websocket upgrade required", http.StatusUpgradeRequired)
+		return nil, errors.New("websocket: missing upgrade headers")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "unsupported websocket version", http.StatusUpgradeRequired)
+		return nil, errors.New("websocket: unsupported version")
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if decoded, err := base64.StdEncoding.DecodeString(key); err != nil || len(decoded) != 16 {
+		http.Error(w, "invalid Sec-WebSocket-Key", http.StatusBadRequest)
+		return nil, errors.New("websocket: invalid key")
+	}
+	if u.CheckOrigin != nil && !u.CheckOrigin(r) {
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return nil, errors.New("websocket: origin not allowed")
+	}
+
+	netConn, rw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		http.Error(w, "websocket is not supported", http.StatusInternalServerError)
+		return nil, fmt.Errorf("websocket: hijack failed: %w", err)
+	}
+
+	// Дедлайны сервера больше не действуют, ими управляет Conn
+	netConn.SetDeadline(time.Time{})
+
+	response := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + acceptKey(key) + "\r\n\r\n"
+	if _, err := netConn.Write([]byte(response)); err != nil {
+		netConn.Close()
+		return nil, fmt.Errorf("websocket: handshake failed: %w", err)
+	}
+
+	conn := &Conn{
+		conn:           netConn,
+		reader:         rw.Reader,
+		maxMessageSize: u.MaxMessageSize,
+		writeTimeout:   u.WriteTimeout,
+	}
+	if conn.maxMessageSize <= 0 {
+		conn.maxMessageSize = 64 << 10
+	}
+	if conn.writeTimeout <= 0 {
+		conn.writeTimeout = 10 * time.Second
+	}
+	return conn, nil
+}
+
+// Conn WebSocket соединение на стороне сервера.
+// Запись потокобезопасна; чтение должно выполняться из одной горутины.
+type Conn struct {
+	conn           net.Conn
+	reader         *bufio.Reader
+	maxMessageSize int64
+	writeTimeout   time.Duration
+
+	writeMu sync.Mutex
+	closed  bool
+}
+
+// WriteMessage отправляет текстовое (OpText) или бинарное (OpBinary) сообщение
+func (c *Conn) WriteMessage(opcode int, data []byte) error {
+	return c.writeFrame(opcode, data)
+}
+
+// WritePing отправляет ping
+func (c *Conn) WritePing(data []byte) error {
+	return c.writeFrame(opPing, data)
+}
+
+// Close отправляет фрейм закрытия и закрывает соединение
+func (c *Conn) Close(code int, reason string) error {
+	payload := make([]byte, 2, 2+len(reason))
+	binary.BigEndian.PutUint16(payload, uint16(code))
+	payload = append(payload, reason...)
+	if len(payload) > maxControlPayloadLen {
+		payload = payload[:maxControlPayloadLen]
+	}
+
+	err := c.writeFrame(opClose, payload)
+
+	c.writeMu.Lock()
+	c.closed = true
+	c.writeMu.Unlock()
+
+	if closeErr := c.conn.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// SetReadDeadline задает дедлайн чтения; используется для контроля pong
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	return c.conn.SetReadDeadline(t)
+}
+
+// ReadMessage читает следующее сообщение с данными.
+// Ping отвечается автоматически, pong пропускается, фрейм закрытия возвращается как *CloseError.
+// onFrame (если задан) вызывается на каждый полученный фрейм — например, для продления дедлайна.
+func (c *Conn) ReadMessage(onFrame func()) (opcode int, data []byte, err error) {
+	var message []byte
+	messageOp := -1
+
+	for {
+		fin, op, payload, err := c.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+		if onFrame != nil {
+			onFrame()
+		}
+
+		switch op {
+		case opPing:
+			if err := c.writeFrame(opPong, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case opPong:
+			continue
+		case opClose:
+			closeErr := &CloseError{Code: 1005}
+			if len(payload) >= 2 {
+				closeErr.Code = int(binary.BigEndian.Uint16(payload))
+				closeErr.Reason = string(payload[2:])
+			}
+			c.Close(CloseNormal, "")
+			return 0, nil, closeErr
+		case OpText, OpBinary:
+			if messageOp != -1 {
+				c.Close(CloseProtocolError, "expected continuation frame")
+				return 0, nil, errors.New("websocket: unexpected data frame")
+			}
+			messageOp = op
+		case opContinuation:
+			if messageOp == -1 {
+				c.Close(CloseProtocolError, "unexpected continuation frame")
+				return 0, nil, errors.New("websocket: unexpected continuation frame")
+			}
+		default:
+			c.Close(CloseProtocolError, "unknown opcode")
+			return 0, nil, fmt.Errorf("websocket: unknown opcode %d", op)
+		}
+
+		if int64(len(message)+len(payload)) > c.maxMessageSize {
+			c.Close(CloseMessageTooBig, "message too big")
+			return 0, nil, errors.New("websocket: message too big")
+		}
+		message = append(message, payload...)
+
+		if fin {
+			return messageOp, message, nil
+		}
+	}
+}
+
+// readFrame читает один фрейм. Фреймы клиента обязаны быть замаскированы.
+func (c *Conn) readFrame() (fin bool, opcode int, payload []byte, err error) {
+	var header [2]byte
+	if _, err := io.ReadFull(c.reader, header[:]); err != nil {
+		return false, 0, nil, err
+	}
+
+	fin = header[0]&0x80 != 0
+	if header[0]&0x70 != 0 {
+		c.Close(CloseProtocolError, "reserved bits set")
+		return false, 0, nil, errors.New("websocket: reserved bits set")
+	}
+	opcode = int(header[0] & 0x0F)
+	masked := header[1]&0x80 != 0
+	length := int64(header[1] & 0x7F)
+
+	if !masked {
+		c.Close(CloseProtocolError, "client frames must be masked")
+		return false, 0, nil, errors.New("websocket: unmasked client frame")
+	}
+
+	isControl := opcode&0x8 != 0
+	if isControl && (!fin || length > maxControlPayloadLen) {
+		c.Close(CloseProtocolError, "invalid control frame")
+		return false, 0, nil, errors.New("websocket: invalid control frame")
+	}
+
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.reader, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = int64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.reader, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = int64(binary.BigEndian.Uint64(ext[:]))
+	}
+	if length < 0 || length > c.maxMessageSize {
+		c.Close(CloseMessageTooBig, "message too big")
+		return false, 0, nil, errors.New("websocket: frame too big")
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(c.reader, mask[:]); err != nil {
+		return false, 0, nil, err
+	}
+
+	payload = make([]byte, length)
+	if _, err := io.ReadFull(c.reader, payload); err != nil {
+		return false, 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+
+	return fin, opcode, payload, nil
+}
+
+// writeFrame отправляет один незамаскированный фрейм с таймаутом записи
+func (c *Conn) writeFrame(opcode int, payload []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if c.closed {
+		return ErrClosed
+	}
+
+	header := make([]byte, 0, 10)
+	header = append(header, 0x80|byte(opcode))
+	switch n := len(payload); {
+	case n <= 125:
+		header = append(header, byte(n))
+	case n <= 0xFFFF:
+		header = append(header, 126)
+		header = binary.BigEndian.AppendUint16(header, uint16(n))
+	default:
+		header = append(header, 127)
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+
+	c.conn.SetWriteDeadline(time.Now().Add(c.writeTimeout))
+	if _, err := (&net.Buffers{header, payload}).WriteTo(c.conn); err != nil {
+		return err
+	}
+	return nil
+}
+
+// acceptKey вычисляет Sec-WebSocket-Accept для ключа клиента
+func acceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// headerContainsToken проверяет наличие токена в списке значений заголовка (без учета регистра)
+func headerContainsToken(header http.Header, name, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}